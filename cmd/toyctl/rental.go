@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"final-project/repository"
+	"final-project/service"
+	"fmt"
+)
+
+// recomputeFees menghitung ulang denda rental yang sudah dikembalikan, misalnya setelah tarif
+// denda mainan dikoreksi
+func recomputeFees(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("rental recompute-fees")
+	dryRun := flags.Bool("dry-run", false, "Show the changes without saving them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// Hanya perhitungan denda yang dipakai, dependensi checkout tidak diperlukan
+	rentalSvc := service.NewRentalService(
+		repository.NewRentalRepository(a.db.DB),
+		repository.NewUserRepository(a.db.DB),
+		repository.NewToyRepository(a.db.DB),
+		nil, nil, nil, nil, nil, nil,
+	)
+
+	adjustments, err := rentalSvc.RecomputeFees(ctx, *dryRun)
+	for _, adjustment := range adjustments {
+		fmt.Fprintf(a.out, "Rental %s: late fee %.2f -> %.2f, damage fee %.2f -> %.2f, total %.2f -> %.2f\n",
+			adjustment.RentalID, adjustment.OldLateFee, adjustment.NewLateFee, adjustment.OldDamageFee, adjustment.NewDamageFee,
+			adjustment.OldTotalAmount, adjustment.NewTotalAmount)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(adjustments) == 0:
+		fmt.Fprintln(a.out, "All fees are up to date")
+	case *dryRun:
+		fmt.Fprintf(a.out, "Dry run: %d rentals would change\n", len(adjustments))
+	default:
+		fmt.Fprintf(a.out, "Updated %d rentals\n", len(adjustments))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"fmt"
+	"strings"
+)
+
+var demoCategories = []entity.ToyCategory{
+	{Name: "Edukasi", Description: "Mainan yang melatih logika, bahasa, dan angka"},
+	{Name: "Puzzle", Description: "Puzzle dan permainan susun untuk segala usia"},
+	{Name: "Outdoor", Description: "Mainan untuk bermain di luar ruangan"},
+	{Name: "Bayi & Balita", Description: "Mainan aman untuk bayi dan balita"},
+}
+
+var demoToys = []struct {
+	toy        entity.Toy
+	categories []string
+}{
+	{entity.Toy{Name: "Balok Kayu Warna-warni", Description: "Set 50 balok kayu dengan cat aman anak", AgeRecommendation: "2-5", Condition: entity.ConditionExcellent, RentalPrice: 25000, LateFeePerDay: 5000, ReplacementPrice: 250000, Stock: 5}, []string{"Edukasi", "Bayi & Balita"}},
+	{entity.Toy{Name: "Puzzle Peta Indonesia", Description: "Puzzle 100 keping bergambar peta Indonesia", AgeRecommendation: "6+", Condition: entity.ConditionGood, RentalPrice: 15000, LateFeePerDay: 3000, ReplacementPrice: 120000, Stock: 4}, []string{"Puzzle", "Edukasi"}},
+	{entity.Toy{Name: "Sepeda Keseimbangan", Description: "Balance bike tanpa pedal untuk belajar bersepeda", AgeRecommendation: "3-5", Condition: entity.ConditionExcellent, RentalPrice: 50000, LateFeePerDay: 10000, ReplacementPrice: 850000, Stock: 2}, []string{"Outdoor"}},
+	{entity.Toy{Name: "Gym Bayi Musikal", Description: "Alas bermain dengan mainan gantung dan musik", AgeRecommendation: "0-1", Condition: entity.ConditionNew, RentalPrice: 40000, LateFeePerDay: 8000, ReplacementPrice: 600000, Stock: 3}, []string{"Bayi & Balita"}},
+	{entity.Toy{Name: "Robot Coding Pemula", Description: "Robot yang diprogram dengan kartu perintah", AgeRecommendation: "5-8", Condition: entity.ConditionGood, RentalPrice: 60000, LateFeePerDay: 12000, ReplacementPrice: 1200000, Stock: 2}, []string{"Edukasi"}},
+	{entity.Toy{Name: "Tenda Bermain Anak", Description: "Tenda lipat untuk bermain di taman atau di dalam rumah", AgeRecommendation: "3+", Condition: entity.ConditionFair, RentalPrice: 30000, LateFeePerDay: 6000, ReplacementPrice: 350000, Stock: 3}, []string{"Outdoor"}},
+}
+
+var demoBranch = entity.Branch{
+	Name:         "Cabang Kebayoran",
+	Address:      "Jl. Panglima Polim Raya No. 10, Kebayoran Baru",
+	City:         "Jakarta Selatan",
+	PhoneNumber:  "021-7200100",
+	Latitude:     -6.2443,
+	Longitude:    106.7991,
+	OpeningHours: "Senin-Sabtu 09:00-20:00",
+	IsActive:     true,
+}
+
+var demoPlan = entity.Plan{
+	Name:              "Paket Keluarga",
+	Description:       "Sewa hingga 3 mainan sekaligus dengan 2 kali tukar per bulan",
+	MonthlyFee:        150000,
+	MaxConcurrentToys: 3,
+	SwapsPerMonth:     2,
+	MaxToyValue:       1000000,
+	IsActive:          true,
+}
+
+// seedDemoData mengisi data contoh untuk development. Data yang namanya sudah ada dilewati
+// sehingga command aman dijalankan berulang kali.
+func seedDemoData(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("seed demo-data")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if a.cfg.IsProd {
+		return errors.New("demo data cannot be seeded in production")
+	}
+
+	categoryRepo := repository.NewToyCategoryRepository(a.db.DB)
+	toyRepo := repository.NewToyRepository(a.db.DB)
+
+	var created []string
+	for _, category := range demoCategories {
+		ok, err := a.insertIfMissing(ctx, &category, category.Name, func() error {
+			return categoryRepo.Insert(ctx, &category)
+		})
+		if err != nil {
+			return err
+		}
+		if ok {
+			created = append(created, "category "+category.Name)
+		}
+	}
+
+	for _, demo := range demoToys {
+		toy := demo.toy
+		toy.IsAvailable = true
+		if toy.Categories, _ = categoryRepo.FindByNames(ctx, demo.categories); len(toy.Categories) != len(demo.categories) {
+			return fmt.Errorf("categories of %s not found", toy.Name)
+		}
+		if errs := toy.Validate(); len(errs) > 0 {
+			return fmt.Errorf("invalid demo toy %s: %s", toy.Name, strings.Join(errs, "; "))
+		}
+
+		ok, err := a.insertIfMissing(ctx, &toy, toy.Name, func() error {
+			return toyRepo.Insert(ctx, &toy)
+		})
+		if err != nil {
+			return err
+		}
+		if ok {
+			created = append(created, "toy "+toy.Name)
+		}
+	}
+
+	branch := demoBranch
+	ok, err := a.insertIfMissing(ctx, &branch, branch.Name, func() error {
+		return repository.NewBranchRepository(a.db.DB).Insert(ctx, &branch)
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		created = append(created, "branch "+branch.Name)
+	}
+
+	plan := demoPlan
+	ok, err = a.insertIfMissing(ctx, &plan, plan.Name, func() error {
+		return repository.NewPlanRepository(a.db.DB).Insert(ctx, &plan)
+	})
+	if err != nil {
+		return err
+	}
+	if ok {
+		created = append(created, "plan "+plan.Name)
+	}
+
+	if len(created) == 0 {
+		fmt.Fprintln(a.out, "Demo data already present")
+	}
+	for _, item := range created {
+		fmt.Fprintf(a.out, "Created %s\n", item)
+	}
+	return nil
+}
+
+// insertIfMissing menjalankan insert jika belum ada baris model dengan nama yang sama
+func (a *app) insertIfMissing(ctx context.Context, model interface{}, name string, insert func() error) (bool, error) {
+	var count int64
+	if err := a.db.DB.WithContext(ctx).Model(model).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+	return true, insert()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"final-project/repository"
+	"fmt"
+	"time"
+)
+
+// purgeExpiredTokens menghapus sesi dan token aksi yang sudah kedaluwarsa lebih dari -older-than
+func purgeExpiredTokens(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("tokens purge-expired")
+	olderThan := flags.Duration("older-than", 0, "Only purge tokens expired for at least this long, e.g. 168h")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	before := time.Now().Add(-*olderThan)
+
+	sessions, err := repository.NewUserTokenRepository(a.db.DB).DeleteExpired(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	actionTokens, err := repository.NewUserActionTokenRepository(a.db.DB).DeleteExpired(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Purged %d sessions and %d action tokens\n", sessions, actionTokens)
+	return nil
+}
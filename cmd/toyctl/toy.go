@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/service"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// importToys mengimpor katalog dari CSV atau XLSX dengan kolom sesuai entity.ToyImportColumns
+func importToys(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("toy import")
+	file := flags.String("file", "", "CSV or XLSX file with a header row")
+	dryRun := flags.Bool("dry-run", false, "Validate rows without saving them")
+	mode := flags.String("mode", entity.ToyImportModeAtomic, "atomic saves all rows or none, row saves every valid row")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"file": *file}); err != nil {
+		return err
+	}
+
+	req := entity.ToyImportRequest{DryRun: *dryRun, Mode: *mode}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	catalogSvc := service.NewToyCatalogService(repository.NewToyRepository(a.db.DB), repository.NewToyCategoryRepository(a.db.DB))
+	rows, err := catalogSvc.Parse(*file, f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	result, err := catalogSvc.Import(ctx, rows, req)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range result.Rows {
+		if len(row.Errors) > 0 {
+			fmt.Fprintf(a.out, "Row %d (%s): %s\n", row.Row, row.Name, strings.Join(row.Errors, "; "))
+		}
+	}
+
+	if result.DryRun {
+		fmt.Fprintf(a.out, "Dry run: %d of %d rows valid\n", result.Valid, result.Total)
+	} else {
+		fmt.Fprintf(a.out, "Imported %d of %d rows, %d failed\n", result.Imported, result.Total, result.Failed)
+	}
+	if result.Failed > 0 {
+		return fmt.Errorf("%d rows failed", result.Failed)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"os"
+	"strings"
+	"time"
+)
+
+// createAdmin membuat akun admin pertama. Registrasi lewat API selalu menghasilkan customer,
+// sehingga admin awal hanya bisa dibuat dari sini.
+func createAdmin(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("user create-admin")
+	email := flags.String("email", "", "Email of the admin")
+	username := flags.String("username", "", "Username of the admin")
+	fullName := flags.String("full-name", "", "Full name of the admin")
+	password := flags.String("password", "", "Password, read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"email": *email, "username": *username, "full-name": *fullName}); err != nil {
+		return err
+	}
+
+	if *password == "" {
+		var err error
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+
+	user := entity.User{
+		Email:    strings.TrimSpace(*email),
+		Username: strings.TrimSpace(*username),
+		FullName: strings.TrimSpace(*fullName),
+		Password: *password,
+		IsActive: true,
+	}
+	if errs := user.Validate(true); len(errs) > 0 {
+		return fmt.Errorf("invalid admin: %s", strings.Join(errs, "; "))
+	}
+
+	userRepo := repository.NewUserRepository(a.db.DB)
+	for _, key := range []string{user.Email, user.Username} {
+		if _, err := userRepo.FindByEmailOrUsername(ctx, key); err == nil {
+			return fmt.Errorf("%q is already registered", key)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	// Role admin harus sudah ada di tabel roles sebelum server pertama kali dijalankan
+	if err := a.db.SeedRoles(); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.Password = string(hashedPassword)
+	user.Role = entity.RoleAdmin
+	user.EmailVerifiedAt = &now
+	if err := userRepo.Insert(ctx, &user); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Created admin %s (%s)\n", user.Username, user.ID)
+	return nil
+}
+
+// resetPassword mengganti password user dan mencabut semua sesinya. Server yang sedang berjalan
+// bisa masih menerima sesi dari cache sampai SESSION_CACHE_TTL habis.
+func resetPassword(ctx context.Context, a *app, args []string) error {
+	flags := newFlagSet("user reset-password")
+	login := flags.String("user", "", "Email or username of the user")
+	password := flags.String("password", "", "New password, read from stdin when omitted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := requireFlags(map[string]string{"user": *login}); err != nil {
+		return err
+	}
+
+	userRepo := repository.NewUserRepository(a.db.DB)
+	user, err := userRepo.FindByEmailOrUsername(ctx, strings.TrimSpace(*login))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("user %q not found", *login)
+		}
+		return err
+	}
+
+	if *password == "" {
+		if *password, err = readPassword(); err != nil {
+			return err
+		}
+	}
+	if errs := entity.ValidatePassword(*password); len(errs) > 0 {
+		return fmt.Errorf("invalid password: %s", strings.Join(errs, "; "))
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := userRepo.UpdatePassword(ctx, user.ID.String(), string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := repository.NewUserTokenRepository(a.db.DB).BlockAllByUserID(ctx, user.ID.String(), ""); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(a.out, "Password of %s changed and all sessions revoked\n", user.Username)
+	return nil
+}
+
+// readPassword membaca password dari baris pertama stdin agar tidak tersimpan di history shell
+func readPassword() (string, error) {
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("password is required")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
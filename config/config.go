@@ -1,15 +1,28 @@
 package config
 
 import (
+	"errors"
+	"final-project/entity"
+	"fmt"
 	"github.com/joho/godotenv"
+	"net/http"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// DefaultJWTSecret hanya untuk development, aplikasi menolak berjalan di production dengan nilai ini
+const DefaultJWTSecret = "secret"
+
 type Config struct {
 	// Server
 	ServerPort string
 	IsProd     bool
+	// Batas waktu (detik) menunggu request yang sedang berjalan, worker, dan database saat shutdown
+	ShutdownTimeout int
+	// Jeda (detik) antara readiness menjadi false dan mulai drain, agar load balancer sempat berhenti mengirim request
+	ShutdownDrainDelay int
 
 	// Database
 	DBHost     string
@@ -17,22 +30,120 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	DBPort     string
+	// Jalankan migrasi yang belum diterapkan saat server start, aman untuk banyak replika
+	DBAutoMigrate bool
+
+	// Database pool, durasi dalam detik. DBStatementTimeout 0 berarti tanpa batas.
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  int
+	DBConnMaxIdleTime  int
+	DBConnectTimeout   int
+	DBStatementTimeout int
 
 	// JWT
-	JWTSecret       string
-	AccessTokenExp  int
-	RefreshTokenExp int
-	Issuer          string
+	JWTSecret               string
+	JWTSigningAlg           string
+	JWTAcceptHS256          bool
+	JWTKeyRotationDays      int
+	JWTKeyPrepublishMinutes int
+	JWTKeyRefreshInterval   int
+	AccessTokenExp          int
+	RefreshTokenExp         int
+	Issuer                  string
+
+	// Session
+	SessionCacheTTL int
+	CookieDomain    string
+	CookieSecure    bool
+	CookieSameSite  string
+
+	// CSRF
+	CSRFEnabled     bool
+	CSRFExemptPaths []string
+
+	// API key
+	APIKeyRateLimit int
+
+	// Impersonation
+	ImpersonationTTL int
+
+	// Account erasure
+	AccountErasureGraceDays int
+	AccountErasureInterval  int
+
+	// Login protection
+	LoginMaxAttempts      int
+	LoginIPMaxAttempts    int
+	LoginLockoutMinutes   int
+	LoginDelayBaseSeconds int
+
+	// Two-factor
+	MFAEnforcedRoles []string
+	MFATokenTTL      int
+
+	// OpenID Connect
+	APIBaseURL    string
+	OIDCProviders []OIDCProviderConfig
+	OIDCStateTTL  int
+
+	// Account
+	AppBaseURL                string
+	PasswordResetTokenTTL     int
+	EmailVerificationTokenTTL int
+
+	// Mailer
+	MailerDriver   string
+	MailerFrom     string
+	MailerFilePath string
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUsername   string
+	SMTPPassword   string
+
+	// Notifier
+	NotifierDriver   string
+	NotifierFilePath string
+
+	// Waitlist
+	WaitlistHoldHours      int
+	WaitlistExpiryInterval int
+
+	// Subscription
+	SubscriptionRenewalInterval int
+
+	// Fulfilment
+	DeliveryZones        []string
+	DeliverySlots        []string
+	DeliverySlotCapacity int
+	DeliveryLeadHours    int
+	DeliveryTimezone     string
+}
+
+// OIDCProviderConfig berisi pengaturan satu provider OpenID Connect, dibaca dari
+// OIDC_<NAMA>_ISSUER, OIDC_<NAMA>_CLIENT_ID, OIDC_<NAMA>_CLIENT_SECRET, OIDC_<NAMA>_REDIRECT_URL
+// dan OIDC_<NAMA>_SCOPES untuk setiap nama di OIDC_PROVIDERS
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
 }
 
 func LoadConfig() *Config {
 	// Load .env file jika ada
 	godotenv.Load()
 
+	apiBaseURL := strings.TrimRight(getEnv("API_BASE_URL", "http://localhost:8080"), "/")
+
 	return &Config{
 		// Server
-		ServerPort: getEnv("SERVER_PORT", "8080"),
-		IsProd:     getEnvAsBool("IS_PROD", false),
+		ServerPort:         getEnv("SERVER_PORT", "8080"),
+		IsProd:             getEnvAsBool("IS_PROD", false),
+		ShutdownTimeout:    getEnvAsInt("SHUTDOWN_TIMEOUT", 30),
+		ShutdownDrainDelay: getEnvAsInt("SHUTDOWN_DRAIN_DELAY", 0),
 
 		// Database
 		DBHost:     getEnv("DB_HOST", "localhost"),
@@ -41,15 +152,181 @@ func LoadConfig() *Config {
 		DBName:     getEnv("DB_NAME", "toyrentals"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 
+		DBAutoMigrate: getEnvAsBool("DB_AUTO_MIGRATE", true),
+
+		DBMaxOpenConns:     getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:     getEnvAsInt("DB_MAX_IDLE_CONNS", 10),
+		DBConnMaxLifetime:  getEnvAsInt("DB_CONN_MAX_LIFETIME", 1800),
+		DBConnMaxIdleTime:  getEnvAsInt("DB_CONN_MAX_IDLE_TIME", 300),
+		DBConnectTimeout:   getEnvAsInt("DB_CONNECT_TIMEOUT", 10),
+		DBStatementTimeout: getEnvAsInt("DB_STATEMENT_TIMEOUT", 0),
+
 		// JWT
-		JWTSecret:       getEnv("JWT_SECRET", "secret"),
-		AccessTokenExp:  getEnvAsInt("ACCESS_TOKEN_EXP", 1),
-		RefreshTokenExp: getEnvAsInt("REFRESH_TOKEN_EXP", 7),
-		Issuer:          getEnv("ISSUER", "toyrentals"),
+		JWTSecret:               getEnv("JWT_SECRET", DefaultJWTSecret),
+		JWTSigningAlg:           getEnv("JWT_SIGNING_ALG", "RS256"),
+		JWTAcceptHS256:          getEnvAsBool("JWT_ACCEPT_HS256", false), // aktifkan hanya selama migrasi dari HS256
+		JWTKeyRotationDays:      getEnvAsInt("JWT_KEY_ROTATION_DAYS", 30),
+		JWTKeyPrepublishMinutes: getEnvAsInt("JWT_KEY_PREPUBLISH_MINUTES", 60),
+		JWTKeyRefreshInterval:   getEnvAsInt("JWT_KEY_REFRESH_INTERVAL", 60),
+		AccessTokenExp:          getEnvAsInt("ACCESS_TOKEN_EXP", 1),
+		RefreshTokenExp:         getEnvAsInt("REFRESH_TOKEN_EXP", 7),
+		Issuer:                  getEnv("ISSUER", "toyrentals"),
+
+		// Session
+		SessionCacheTTL: getEnvAsInt("SESSION_CACHE_TTL", 30),
+		CookieDomain:    getEnv("COOKIE_DOMAIN", ""),
+		CookieSecure:    getEnvAsBool("COOKIE_SECURE", false),
+		CookieSameSite:  strings.ToLower(getEnv("COOKIE_SAMESITE", "lax")),
+
+		// CSRF
+		CSRFEnabled:     getEnvAsBool("CSRF_ENABLED", true),
+		CSRFExemptPaths: getEnvAsSlice("CSRF_EXEMPT_PATHS", []string{"/api/webhooks/"}),
+
+		// API key
+		APIKeyRateLimit: getEnvAsInt("API_KEY_RATE_LIMIT", 60),
+
+		// Impersonation
+		ImpersonationTTL: getEnvAsInt("IMPERSONATION_TTL", 15),
+
+		// Account erasure
+		AccountErasureGraceDays: getEnvAsInt("ACCOUNT_ERASURE_GRACE_DAYS", 30),
+		AccountErasureInterval:  getEnvAsInt("ACCOUNT_ERASURE_INTERVAL", 60),
+
+		// Login protection
+		LoginMaxAttempts:      getEnvAsInt("LOGIN_MAX_ATTEMPTS", 5),
+		LoginIPMaxAttempts:    getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 50),
+		LoginLockoutMinutes:   getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15),
+		LoginDelayBaseSeconds: getEnvAsInt("LOGIN_DELAY_BASE_SECONDS", 1),
+
+		// Two-factor
+		MFAEnforcedRoles: getEnvAsSlice("MFA_ENFORCED_ROLES", []string{"admin"}),
+		MFATokenTTL:      getEnvAsInt("MFA_TOKEN_TTL", 5),
+
+		// OpenID Connect
+		APIBaseURL:    apiBaseURL,
+		OIDCProviders: loadOIDCProviders(apiBaseURL),
+		OIDCStateTTL:  getEnvAsInt("OIDC_STATE_TTL", 10),
+
+		// Account
+		AppBaseURL:                getEnv("APP_BASE_URL", "http://localhost:3000"),
+		PasswordResetTokenTTL:     getEnvAsInt("PASSWORD_RESET_TOKEN_TTL", 60),
+		EmailVerificationTokenTTL: getEnvAsInt("EMAIL_VERIFICATION_TOKEN_TTL", 48),
+
+		// Mailer
+		MailerDriver:   getEnv("MAILER_DRIVER", "log"),
+		MailerFrom:     getEnv("MAILER_FROM", "no-reply@toyrentals.local"),
+		MailerFilePath: getEnv("MAILER_FILE_PATH", "mails.log"),
+		SMTPHost:       getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:       getEnv("SMTP_PORT", "1025"),
+		SMTPUsername:   getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+
+		// Notifier
+		NotifierDriver:   getEnv("NOTIFIER_DRIVER", "log"),
+		NotifierFilePath: getEnv("NOTIFIER_FILE_PATH", "notifications.log"),
+
+		// Waitlist
+		WaitlistHoldHours:      getEnvAsInt("WAITLIST_HOLD_HOURS", 24),
+		WaitlistExpiryInterval: getEnvAsInt("WAITLIST_EXPIRY_INTERVAL", 5),
+
+		// Subscription
+		SubscriptionRenewalInterval: getEnvAsInt("SUBSCRIPTION_RENEWAL_INTERVAL", 60),
+
+		// Fulfilment
+		DeliveryZones:        getEnvAsSlice("DELIVERY_ZONES", []string{"5:10000", "10:20000", "20:35000"}),
+		DeliverySlots:        getEnvAsSlice("DELIVERY_SLOTS", []string{"09:00-12:00", "13:00-16:00", "17:00-20:00"}),
+		DeliverySlotCapacity: getEnvAsInt("DELIVERY_SLOT_CAPACITY", 10),
+		DeliveryLeadHours:    getEnvAsInt("DELIVERY_LEAD_HOURS", 24),
+		DeliveryTimezone:     getEnv("DELIVERY_TIMEZONE", "Asia/Jakarta"),
 	}
 
 }
 
+// Validate menolak konfigurasi yang tidak valid atau tidak aman untuk production
+func (c *Config) Validate() error {
+	if c.IsProd && c.JWTSecret == DefaultJWTSecret {
+		return errors.New("JWT_SECRET masih memakai nilai default, ganti sebelum menjalankan di production")
+	}
+
+	switch c.JWTSigningAlg {
+	case "HS256", "RS256", "EdDSA":
+	default:
+		return fmt.Errorf("JWT_SIGNING_ALG %q tidak didukung, gunakan RS256, EdDSA, atau HS256", c.JWTSigningAlg)
+	}
+
+	switch c.CookieSameSite {
+	case "lax", "strict":
+	case "none":
+		// Browser menolak cookie SameSite=None tanpa Secure
+		if !c.CookieSecure {
+			return errors.New("COOKIE_SAMESITE=none membutuhkan COOKIE_SECURE=true")
+		}
+	default:
+		return fmt.Errorf("COOKIE_SAMESITE %q tidak didukung, gunakan lax, strict, atau none", c.CookieSameSite)
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		return errors.New("SHUTDOWN_TIMEOUT harus lebih dari 0")
+	}
+	if c.ShutdownDrainDelay < 0 || c.ShutdownDrainDelay >= c.ShutdownTimeout {
+		return errors.New("SHUTDOWN_DRAIN_DELAY harus antara 0 dan kurang dari SHUTDOWN_TIMEOUT")
+	}
+	if c.DBMaxOpenConns < 0 || c.DBMaxIdleConns < 0 || c.DBConnMaxLifetime < 0 || c.DBConnMaxIdleTime < 0 ||
+		c.DBConnectTimeout < 0 || c.DBStatementTimeout < 0 {
+		return errors.New("pengaturan pool database tidak boleh negatif")
+	}
+
+	if _, err := c.DeliveryZoneList(); err != nil {
+		return fmt.Errorf("DELIVERY_ZONES tidak valid: %w", err)
+	}
+	if _, err := c.DeliverySlotWindows(); err != nil {
+		return fmt.Errorf("DELIVERY_SLOTS tidak valid: %w", err)
+	}
+	if _, err := time.LoadLocation(c.DeliveryTimezone); err != nil {
+		return fmt.Errorf("DELIVERY_TIMEZONE %q tidak dikenal: %w", c.DeliveryTimezone, err)
+	}
+
+	return nil
+}
+
+// CookieSameSiteMode mengubah COOKIE_SAMESITE menjadi nilai http.SameSite
+func (c *Config) CookieSameSiteMode() http.SameSite {
+	switch c.CookieSameSite {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// DeliveryZoneList membaca DELIVERY_ZONES, setiap zona berformat "<jarak maksimal km>:<tarif>"
+func (c *Config) DeliveryZoneList() ([]entity.DeliveryZone, error) {
+	zones := make([]entity.DeliveryZone, 0, len(c.DeliveryZones))
+	for _, value := range c.DeliveryZones {
+		zone, err := entity.ParseDeliveryZone(value)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// DeliverySlotWindows membaca DELIVERY_SLOTS, setiap slot berformat "HH:MM-HH:MM"
+func (c *Config) DeliverySlotWindows() ([]entity.DeliverySlotWindow, error) {
+	windows := make([]entity.DeliverySlotWindow, 0, len(c.DeliverySlots))
+	for _, value := range c.DeliverySlots {
+		window, err := entity.ParseDeliverySlotWindow(value)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -73,3 +350,46 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsSlice membaca daftar yang dipisahkan koma. Nilai "none" menghasilkan daftar kosong.
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := strings.TrimSpace(os.Getenv(key))
+	if valueStr == "" {
+		return defaultValue
+	}
+	if valueStr == "none" {
+		return []string{}
+	}
+
+	var values []string
+	for _, value := range strings.Split(valueStr, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// loadOIDCProviders membaca provider yang terdaftar di OIDC_PROVIDERS. Provider tanpa issuer
+// atau client id dilewati.
+func loadOIDCProviders(apiBaseURL string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range getEnvAsSlice("OIDC_PROVIDERS", nil) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", apiBaseURL+"/api/user/auth/oidc/"+name+"/callback"),
+			Scopes:       getEnvAsSlice(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
+	"time"
 )
 
 type Database struct {
@@ -14,13 +16,18 @@ type Database struct {
 }
 
 func NewDatabase(config *Config) *Database {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s",
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s connect_timeout=%d",
 		config.DBHost,
 		config.DBUser,
 		config.DBPassword,
 		config.DBName,
 		config.DBPort,
+		config.DBConnectTimeout,
 	)
+	if config.DBStatementTimeout > 0 {
+		// Parameter yang tidak dikenal pgx dikirim sebagai runtime parameter sesi
+		dsn += fmt.Sprintf(" statement_timeout=%d", config.DBStatementTimeout*1000)
+	}
 
 	// Buka koneksi
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
@@ -29,31 +36,86 @@ func NewDatabase(config *Config) *Database {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database pool: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.DBConnMaxLifetime) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(config.DBConnMaxIdleTime) * time.Second)
+
 	helpers.Logger.Info("Connected to database")
 
 	return &Database{db}
 }
 
-// AutoMigrate
-func (db *Database) AutoMigrate() error {
-	return db.DB.AutoMigrate(
-		&entity.User{},
-		&entity.ToyCategory{},
-		&entity.Toy{},
-		&entity.ToyImage{},
-		&entity.Rental{},
-		&entity.RentalItem{},
-		&entity.Payment{},
-		&entity.UserToken{},
-	)
+// SeedRoles membuat permission dan role bawaan. Permission baru selalu diberikan ke admin,
+// sedangkan permission awal role lain hanya diisi saat role pertama kali dibuat.
+func (db *Database) SeedRoles() error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		var permissions []entity.Permission
+		for code, description := range entity.DefaultPermissions {
+			// ON CONFLICT DO NOTHING agar replika yang start bersamaan tidak gagal karena unique index
+			if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).
+				Create(&entity.Permission{Code: code, Description: description}).Error; err != nil {
+				return err
+			}
+
+			var permission entity.Permission
+			if err := tx.Where("code = ?", code).First(&permission).Error; err != nil {
+				return err
+			}
+			permissions = append(permissions, permission)
+		}
+
+		roles := map[string]string{
+			entity.RoleAdmin:    "Akses penuh ke seluruh fitur admin",
+			entity.RoleStaff:    "Staf gudang, memproses pengembalian dan stok",
+			entity.RoleCustomer: "Pelanggan",
+		}
+		for name, description := range roles {
+			result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+				Omit("Permissions").
+				Create(&entity.Role{Name: name, Description: description, IsSystem: true})
+			if result.Error != nil {
+				return result.Error
+			}
+			created := result.RowsAffected > 0
+
+			var role entity.Role
+			if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+				return err
+			}
+
+			for _, permission := range permissions {
+				grant := name == entity.RoleAdmin
+				if created {
+					for _, code := range entity.DefaultRolePermissions[name] {
+						grant = grant || code == permission.Code
+					}
+				}
+				if !grant {
+					continue
+				}
+
+				if err := tx.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?) ON CONFLICT DO NOTHING",
+					role.ID, permission.ID).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
 }
 
-// CloseConnection menutup koneksi database
-func (db *Database) CloseConnection() {
+// CloseConnection menutup koneksi database. Query yang sedang berjalan ditunggu sampai selesai.
+func (db *Database) CloseConnection() error {
 	sqlDB, err := db.DB.DB()
 	if err != nil {
 		log.Printf("Error getting database instance: %v", err)
-		return
+		return err
 	}
-	sqlDB.Close()
+	return sqlDB.Close()
 }
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"final-project/migrations"
+	"final-project/utils/helpers"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationLockKey adalah kunci pg_advisory_lock yang dipegang selama migrasi berjalan, replika
+// lain yang start bersamaan menunggu sampai migrasi selesai
+const migrationLockKey int64 = 727001
+
+// baselineVersion adalah migrasi yang dianggap sudah diterapkan pada database lama yang
+// skemanya dibuat oleh AutoMigrate
+const baselineVersion int64 = 1
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// SchemaMigration adalah baris di tabel schema_migrations
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (*SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// LoadMigrations membaca file migrasi dan mengurutkannya berdasarkan versi
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, file := range files {
+		match := migrationFilePattern.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("versi migrasi %q tidak valid", file.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("versi migrasi %d dipakai oleh %s dan %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migrasi %d_%s harus memiliki file up dan down", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// MigrateUp menerapkan semua migrasi yang belum tercatat di schema_migrations
+func (db *Database) MigrateUp(ctx context.Context) ([]Migration, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = db.withMigrationLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		// Database yang dibuat AutoMigrate sudah memiliki skema awal, cukup dicatat tanpa dijalankan
+		if len(done) == 0 && conn.Migrator().HasTable("users") {
+			for _, migration := range all {
+				if migration.Version > baselineVersion {
+					break
+				}
+				if err := conn.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error; err != nil {
+					return err
+				}
+				done[migration.Version] = SchemaMigration{Version: migration.Version}
+				helpers.Logger.Warnf("Existing schema baselined at migration %d_%s", migration.Version, migration.Name)
+			}
+		}
+
+		for _, migration := range all {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			helpers.Logger.Infof("Applied migration %d_%s", migration.Version, migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown membatalkan steps migrasi terakhir yang sudah diterapkan
+func (db *Database) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("jumlah migrasi yang dibatalkan minimal 1")
+	}
+
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	var reverted []Migration
+	err = db.withMigrationLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(all) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := all[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+			}); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			helpers.Logger.Infof("Reverted migration %d_%s", migration.Version, migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// MigrationStatus mengembalikan semua migrasi beserta waktu diterapkan, nil jika belum
+func (db *Database) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	err = db.withMigrationLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range all {
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+			if row, ok := done[migration.Version]; ok {
+				status.AppliedAt = &row.AppliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withMigrationLock menjalankan fn di satu koneksi yang memegang advisory lock. Lock berlaku
+// per sesi, sehingga lock dan migrasi harus memakai koneksi yang sama.
+func (db *Database) withMigrationLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return db.DB.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer func() {
+			if err := conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				helpers.Logger.Error("Failed to release migration lock: ", err)
+			}
+		}()
+
+		if err := conn.Exec(`CREATE TABLE IF NOT EXISTS "schema_migrations" (
+			"version" bigint PRIMARY KEY,
+			"name" varchar(255) NOT NULL,
+			"applied_at" timestamptz NOT NULL
+		)`).Error; err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+func appliedMigrations(conn *gorm.DB) (map[int64]SchemaMigration, error) {
+	var rows []SchemaMigration
+	if err := conn.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
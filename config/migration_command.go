@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: migrate up | migrate down [steps] | migrate status"
+
+// RunMigrateCommand menjalankan subcommand migrate dari argumen setelah kata "migrate" dan
+// menulis hasilnya ke out. Dipakai oleh server dan toyctl.
+func (db *Database) RunMigrateCommand(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "Database is up to date")
+		}
+		for _, migration := range applied {
+			fmt.Fprintf(out, "Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			parsed, err := strconv.Atoi(args[1])
+			if err != nil || parsed <= 0 {
+				return fmt.Errorf("invalid steps %q, %s", args[1], migrateUsage)
+			}
+			steps = parsed
+		}
+
+		reverted, err := db.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "No migration to revert")
+		}
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+
+	case "status":
+		statuses, err := db.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,283 @@
+package config
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/migrations"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		fsys         fstest.MapFS
+		wantVersions []int64
+		wantErr      bool
+	}{
+		{
+			name: "diurutkan berdasarkan versi",
+			fsys: fstest.MapFS{
+				"0002_b.up.sql":   {Data: []byte("SELECT 2")},
+				"0002_b.down.sql": {Data: []byte("SELECT 2")},
+				"0001_a.up.sql":   {Data: []byte("SELECT 1")},
+				"0001_a.down.sql": {Data: []byte("SELECT 1")},
+				"README.md":       {Data: []byte("bukan migrasi")},
+			},
+			wantVersions: []int64{1, 2},
+		},
+		{
+			name: "file down tidak ada",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "versi dipakai dua nama",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql":   {Data: []byte("SELECT 1")},
+				"0001_b.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "versi nol",
+			fsys: fstest.MapFS{
+				"0000_a.up.sql":   {Data: []byte("SELECT 1")},
+				"0000_a.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadMigrations(tt.fsys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.wantVersions) {
+				t.Fatalf("got %d migrations, want %d", len(got), len(tt.wantVersions))
+			}
+			for i, migration := range got {
+				if migration.Version != tt.wantVersions[i] {
+					t.Errorf("migration %d version = %d, want %d", i, migration.Version, tt.wantVersions[i])
+				}
+			}
+		})
+	}
+}
+
+// Migrasi yang di-embed harus tetap bisa dibaca, dan hanya skema awal yang dicatat tanpa dijalankan
+func TestLoadMigrations_Embedded(t *testing.T) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 2 || all[0].Version != baselineVersion {
+		t.Fatalf("embedded migrations start at %v, want baseline %d followed by later migrations", all, baselineVersion)
+	}
+}
+
+// testDatabase membuat schema terpisah di database TEST_DATABASE_URL sehingga setiap kasus mulai
+// dari database kosong. Test dilewati jika variabel tersebut tidak diisi.
+func testDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	gormConfig := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	if err := admin.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+		dsn = u.String()
+	} else {
+		dsn += " search_path=" + schema
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), gormConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec(fmt.Sprintf(`DROP SCHEMA "%s" CASCADE`, schema))
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return &Database{db}
+}
+
+func TestDatabase_MigrateUp(t *testing.T) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// setup menyiapkan database sebelum MigrateUp, mensimulasikan database lama
+		setup func(t *testing.T, db *Database)
+		// wantApplied adalah jumlah migrasi yang dijalankan, selain yang dicatat sebagai baseline
+		wantApplied int
+		// wantVerified menandakan user lama dianggap sudah memverifikasi email
+		wantVerified bool
+	}{
+		{
+			name:        "database kosong",
+			setup:       func(t *testing.T, db *Database) {},
+			wantApplied: len(all),
+		},
+		{
+			name: "skema awal dari AutoMigrate",
+			setup: func(t *testing.T, db *Database) {
+				if err := db.Exec(all[0].Up).Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantApplied:  len(all) - 1,
+			wantVerified: true,
+		},
+		{
+			name: "sebagian perubahan sudah dibuat AutoMigrate",
+			setup: func(t *testing.T, db *Database) {
+				if err := db.Exec(all[0].Up).Error; err != nil {
+					t.Fatal(err)
+				}
+				if err := db.Exec(`ALTER TABLE "users" DROP CONSTRAINT "chk_users_role"`).Error; err != nil {
+					t.Fatal(err)
+				}
+				if err := db.AutoMigrate(&entity.User{}, &entity.Wishlist{}, &entity.UserToken{}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantApplied: len(all) - 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDatabase(t)
+			ctx := context.Background()
+
+			tt.setup(t, db)
+
+			userID := uuid.Must(uuid.NewV7())
+			if db.Migrator().HasTable("users") {
+				if err := db.Exec(`INSERT INTO "users" ("id", "created_at", "email", "username", "password", "full_name") VALUES (?, ?, ?, ?, ?, ?)`,
+					userID, time.Now(), "old@test.com", "old", "hash", "Old User").Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			applied, err := db.MigrateUp(ctx)
+			if err != nil {
+				t.Fatalf("MigrateUp() error = %v", err)
+			}
+			if len(applied) != tt.wantApplied {
+				t.Errorf("applied %d migrations, want %d", len(applied), tt.wantApplied)
+			}
+
+			tables := []string{"vouchers", "voucher_redemptions", "plans", "subscriptions", "waitlist_entries",
+				"wishlists", "rental_fulfilments", "delivery_routes", "roles", "permissions", "api_keys",
+				"user_identities", "user_mfa", "audit_events", "signing_keys", "bundles"}
+			for _, table := range tables {
+				if !db.Migrator().HasTable(table) {
+					t.Errorf("table %s does not exist", table)
+				}
+			}
+
+			columns := map[string][]string{
+				"users":        {"email_verified_at", "erasure_requested_at", "erased_at"},
+				"rentals":      {"voucher_id", "subscription_id", "discount_amount", "delivery_fee"},
+				"rental_items": {"bundle_id"},
+				"payments":     {"subscription_id"},
+				"user_tokens":  {"family_id", "is_used", "mfa_verified", "impersonator_id", "last_seen_at"},
+			}
+			for table, names := range columns {
+				for _, column := range names {
+					if !db.Migrator().HasColumn(table, column) {
+						t.Errorf("column %s.%s does not exist", table, column)
+					}
+				}
+			}
+
+			if db.Migrator().HasConstraint("users", "chk_users_role") {
+				t.Error("chk_users_role still exists")
+			}
+
+			var verified int64
+			if err := db.Table("users").Where("id = ? AND email_verified_at IS NOT NULL", userID).Count(&verified).Error; err != nil {
+				t.Fatal(err)
+			}
+			if (verified == 1) != tt.wantVerified {
+				t.Errorf("existing user verified = %v, want %v", verified == 1, tt.wantVerified)
+			}
+
+			// Migrasi yang sudah tercatat tidak dijalankan ulang
+			applied, err = db.MigrateUp(ctx)
+			if err != nil || len(applied) != 0 {
+				t.Errorf("second MigrateUp() applied %d migrations, error = %v", len(applied), err)
+			}
+		})
+	}
+}
+
+func TestDatabase_MigrateDown(t *testing.T) {
+	db := testDatabase(t)
+	ctx := context.Background()
+
+	all, err := db.MigrateUp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := db.MigrateDown(ctx, len(all))
+	if err != nil {
+		t.Fatalf("MigrateDown() error = %v", err)
+	}
+	if len(reverted) != len(all) {
+		t.Errorf("reverted %d migrations, want %d", len(reverted), len(all))
+	}
+	if db.Migrator().HasTable("users") || db.Migrator().HasTable("vouchers") {
+		t.Error("tables still exist after reverting every migration")
+	}
+
+	if _, err := db.MigrateUp(ctx); err != nil {
+		t.Fatalf("MigrateUp() after MigrateDown() error = %v", err)
+	}
+}
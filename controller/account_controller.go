@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type IAccountController interface {
+	ForgotPassword(c *gin.Context)
+	ResetPassword(c *gin.Context)
+	VerifyEmail(c *gin.Context)
+	ResendVerification(c *gin.Context)
+}
+
+type AccountController struct {
+	accountSvc service.IAccountService
+}
+
+func NewAccountController(accountSvc service.IAccountService) IAccountController {
+	return &AccountController{
+		accountSvc: accountSvc,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Send a single use password reset link to the email if it is registered
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.ForgotPasswordRequest true "Forgot password"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/auth/password/forgot [post]
+func (a *AccountController) ForgotPassword(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := a.accountSvc.RequestPasswordReset(c.Request.Context(), reqBody.Email); err != nil {
+		logger.Error("Failed to request password reset: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "If the email is registered, a password reset link has been sent")
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a password reset token, all active sessions are revoked
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.ResetPasswordRequest true "Reset password"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/auth/password/reset [post]
+func (a *AccountController) ResetPassword(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := entity.ValidatePassword(reqBody.Password); err != nil {
+		logger.Error("Failed to validate password: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := a.accountSvc.ResetPassword(c.Request.Context(), reqBody); err != nil {
+		logger.Error("Failed to reset password: ", err)
+		if errors.Is(err, entity.ErrActionTokenInvalid) {
+			response.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success reset password")
+}
+
+// VerifyEmail godoc
+// @Summary Verify email
+// @Description Verify email address using the token sent by email
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body entity.VerifyEmailRequest true "Verify email"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/auth/email/verify [post]
+func (a *AccountController) VerifyEmail(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := a.accountSvc.VerifyEmail(c.Request.Context(), reqBody.Token); err != nil {
+		logger.Error("Failed to verify email: ", err)
+		if errors.Is(err, entity.ErrActionTokenInvalid) {
+			response.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success verify email")
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new email verification link to the logged in user
+// @Tags users
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/auth/email/resend [post]
+func (a *AccountController) ResendVerification(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	if err := a.accountSvc.SendEmailVerification(c.Request.Context(), claimsData.UserID.String()); err != nil {
+		logger.Error("Failed to send verification email: ", err)
+		if errors.Is(err, entity.ErrEmailAlreadyVerified) {
+			response.ResponseError(c, http.StatusConflict, err.Error())
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to send verification email")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success send verification email")
+}
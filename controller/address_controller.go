@@ -0,0 +1,227 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IAddressController interface {
+	FindMine(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+}
+
+type AddressController struct {
+	addressSvc service.IAddressService
+}
+
+func NewAddressController(addressSvc service.IAddressService) IAddressController {
+	return &AddressController{
+		addressSvc: addressSvc,
+	}
+}
+
+// FindMine godoc
+// @Summary Get my addresses
+// @Description Get saved addresses of the logged in user, the default address comes first
+// @Tags Address
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.Address
+// @Router /user/addresses [get]
+func (a *AddressController) FindMine(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := a.addressSvc.FindByUserID(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find addresses: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find addresses")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get addresses")
+}
+
+// Insert godoc
+// @Summary Add address
+// @Description Save a new address, the first address becomes the default
+// @Tags Address
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param address body entity.AddressRequest true "Address"
+// @Success 200 {object} entity.Address
+// @Router /user/addresses [post]
+func (a *AddressController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.AddressRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate address: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := a.addressSvc.Create(c.Request.Context(), claimsData.UserID, reqBody)
+	if err != nil {
+		logger.Error("Failed to insert address: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to insert address")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, address, nil, "Success insert address")
+}
+
+// UpdateById godoc
+// @Summary Update address
+// @Description Update a saved address. Set is_default to make it the default address.
+// @Tags Address
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Address ID"
+// @Param address body entity.AddressRequest true "Address"
+// @Success 200 {object} entity.Address
+// @Router /user/addresses/{id} [put]
+func (a *AddressController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.AddressRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate address: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	address, err := a.addressSvc.Update(c.Request.Context(), claimsData.UserID, id, reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("address with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Address not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update address by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to update address")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, address, nil, "Success update address")
+}
+
+// DeleteById godoc
+// @Summary Delete address
+// @Description Delete a saved address. Rentals keep their own copy of the delivery address.
+// @Tags Address
+// @Security ApiCookieAuth
+// @Param id path string true "Address ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/addresses/{id} [delete]
+func (a *AddressController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := a.addressSvc.Delete(c.Request.Context(), claimsData.UserID.String(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("address with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Address not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to delete address by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to delete address")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete address")
+}
@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type IAPIKeyController interface {
+	FindAll(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+}
+
+type APIKeyController struct {
+	apiKeySvc service.IAPIKeyService
+}
+
+func NewAPIKeyController(apiKeySvc service.IAPIKeyService) IAPIKeyController {
+	return &APIKeyController{
+		apiKeySvc: apiKeySvc,
+	}
+}
+
+// FindAll godoc
+// @Summary List API keys
+// @Description Get API keys of partner integrations, the key itself is never returned
+// @Tags APIKey
+// @Security ApiCookieAuth
+// @Produce json
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.APIKey
+// @Router /admin/api-keys [get]
+func (a *APIKeyController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := a.apiKeySvc.FindAll(c.Request.Context(), limitInt, offset)
+	if err != nil {
+		logger.Error("Failed to find API keys: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find API keys")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get API keys")
+}
+
+// Insert godoc
+// @Summary Create API key
+// @Description Create a scoped API key for a partner. The key is only shown in this response.
+// @Tags APIKey
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.APIKeyRequest true "API key"
+// @Success 200 {object} entity.APIKeyCreatedResponse
+// @Router /admin/api-keys [post]
+func (a *APIKeyController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.APIKeyRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate API key: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := a.apiKeySvc.Create(c.Request.Context(), reqBody, claimsData.UserID)
+	if err != nil {
+		logger.Error("Failed to create API key: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success create API key")
+}
+
+// UpdateById godoc
+// @Summary Update API key
+// @Description Update name, scopes, rate limit and expiry of an API key
+// @Tags APIKey
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param request body entity.APIKeyRequest true "API key"
+// @Success 200 {object} entity.APIKey
+// @Router /admin/api-keys/{id} [put]
+func (a *APIKeyController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.APIKeyRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate API key: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	data, err := a.apiKeySvc.UpdateById(c.Request.Context(), id, reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to update API key %s: %v", id, err))
+		respondAPIKeyError(c, err, "Failed to update API key")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success update API key")
+}
+
+// DeleteById godoc
+// @Summary Revoke API key
+// @Description Revoke an API key, requests using it are rejected immediately
+// @Tags APIKey
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/api-keys/{id} [delete]
+func (a *APIKeyController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	if err := a.apiKeySvc.Revoke(c.Request.Context(), id); err != nil {
+		logger.Error(fmt.Errorf("failed to revoke API key %s: %v", id, err))
+		respondAPIKeyError(c, err, "Failed to revoke API key")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success revoke API key")
+}
+
+func respondAPIKeyError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrAPIKeyNotFound):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrAPIKeyRevoked):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"net/http"
+	"time"
+)
+
+type IAuditController interface {
+	FindAll(c *gin.Context)
+}
+
+type AuditController struct {
+	auditSvc service.IAuditService
+}
+
+func NewAuditController(auditSvc service.IAuditService) IAuditController {
+	return &AuditController{
+		auditSvc: auditSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary List audit events
+// @Description Get audit events of admin and financial actions, newest first
+// @Tags Audit
+// @Security ApiCookieAuth
+// @Produce json
+// @Param actor_id query string false "Actor user ID"
+// @Param action query string false "Action, for example toy.update"
+// @Param entity_type query string false "Entity type, for example toy"
+// @Param entity_id query string false "Entity ID"
+// @Param request_id query string false "Request ID"
+// @Param from query string false "Occurred from (RFC3339 or YYYY-MM-DD)"
+// @Param to query string false "Occurred until (RFC3339 or YYYY-MM-DD)"
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.AuditEvent
+// @Router /admin/audit [get]
+func (a *AuditController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	filter := entity.AuditEventFilter{
+		ActorID:    c.Query("actor_id"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+	}
+
+	if filter.ActorID != "" {
+		if _, err := uuid.FromString(filter.ActorID); err != nil {
+			logger.Error("Invalid actor id: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Invalid actor_id")
+			return
+		}
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseAuditTime(value)
+		if err != nil {
+			logger.Error("Invalid from time: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Invalid from, use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		filter.From = &from
+	}
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseAuditTime(value)
+		if err != nil {
+			logger.Error("Invalid to time: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Invalid to, use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		if dateOnly {
+			to = to.Add(24*time.Hour - time.Nanosecond)
+		}
+		filter.To = &to
+	}
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := a.auditSvc.FindAll(c.Request.Context(), filter, limitInt, offset)
+	if err != nil {
+		logger.Error("Failed to find audit events: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find audit events")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get audit events")
+}
+
+// parseAuditTime menerima waktu RFC3339 atau tanggal saja, dateOnly menandakan format tanggal
+func parseAuditTime(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, false, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", value)
+	return parsed, true, err
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"final-project/entity"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// refreshTokenCookiePath membatasi cookie refresh token hanya dikirim ke endpoint refresh
+const refreshTokenCookiePath = "/api/user/auth/refresh"
+
+// authModeHeader dipakai aplikasi mobile dan partner untuk meminta token di body response
+// dengan nilai "bearer", karena mereka tidak bisa memakai cookie
+const authModeHeader = "X-Auth-Mode"
+
+// CookieOptions mengatur atribut cookie auth. Domain kosong membuat cookie hanya berlaku
+// untuk host yang melayani request.
+type CookieOptions struct {
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// setAuthCookies menyimpan token sesi dan token CSRF baru. Token CSRF berumur sama dengan
+// refresh token dan tidak HttpOnly agar frontend bisa mengirimnya di header X-CSRF-Token.
+func (o CookieOptions) setAuthCookies(c *gin.Context, userToken entity.UserToken) error {
+	csrfToken, err := helpers.GenerateCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	refreshMaxAge := int(time.Until(userToken.RefreshTokenExpiresAt).Seconds())
+	c.SetSameSite(o.SameSite)
+	c.SetCookie("access_token", userToken.AccessToken, int(time.Until(userToken.AccessTokenExpiresAt).Seconds()), "/", o.Domain, o.Secure, true)
+	c.SetCookie("refresh_token", userToken.RefreshToken, refreshMaxAge, refreshTokenCookiePath, o.Domain, o.Secure, true)
+	c.SetCookie(helpers.CSRFCookieName, csrfToken, refreshMaxAge, "/", o.Domain, o.Secure, false)
+	return nil
+}
+
+func (o CookieOptions) clearAuthCookies(c *gin.Context) {
+	c.SetSameSite(o.SameSite)
+	c.SetCookie("access_token", "", -1, "/", o.Domain, o.Secure, true)
+	c.SetCookie("refresh_token", "", -1, refreshTokenCookiePath, o.Domain, o.Secure, true)
+	c.SetCookie(helpers.CSRFCookieName, "", -1, "/", o.Domain, o.Secure, false)
+}
+
+// respondSession mengirim token sesi sebagai cookie untuk browser, atau di body response untuk client bearer
+func (o CookieOptions) respondSession(c *gin.Context, userToken entity.UserToken, user *entity.User, bearer bool, message string) {
+	if bearer {
+		response.ResponseSuccess(c, http.StatusOK, entity.AuthTokenResponse{
+			TokenType:             "Bearer",
+			AccessToken:           userToken.AccessToken,
+			AccessTokenExpiresAt:  userToken.AccessTokenExpiresAt,
+			RefreshToken:          userToken.RefreshToken,
+			RefreshTokenExpiresAt: userToken.RefreshTokenExpiresAt,
+			User:                  user,
+		}, nil, message)
+		return
+	}
+
+	if err := o.setAuthCookies(c, userToken); err != nil {
+		helpers.Logger.Error("Failed to set auth cookies: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
+	if user == nil {
+		response.ResponseSuccess(c, http.StatusOK, nil, nil, message)
+		return
+	}
+	response.ResponseSuccess(c, http.StatusOK, user, nil, message)
+}
+
+// wantsBearerTokens mengembalikan true jika client meminta token dikirim di body response
+func wantsBearerTokens(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader(authModeHeader), "bearer")
+}
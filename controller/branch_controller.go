@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IBranchController interface {
+	FindActive(c *gin.Context)
+	FindAll(c *gin.Context)
+	FinById(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+}
+
+type BranchController struct {
+	branchSvc service.IBranchService
+}
+
+func NewBranchController(branchSvc service.IBranchService) IBranchController {
+	return &BranchController{
+		branchSvc: branchSvc,
+	}
+}
+
+// FindActive godoc
+// @Summary Get active branches
+// @Description Get branches that accept pickup and delivery orders
+// @Tags Branch
+// @Produce json
+// @Success 200 {array} entity.Branch
+// @Router /branches [get]
+func (b *BranchController) FindActive(c *gin.Context) {
+	var logger = helpers.Logger
+
+	data, err := b.branchSvc.FindActive(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to find active branches: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find branches")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get branches")
+}
+
+// FindAll godoc
+// @Summary Get all branches
+// @Description Get all branches including inactive ones
+// @Tags Branch
+// @Security ApiCookieAuth
+// @Produce json
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.Branch
+// @Router /admin/branches [get]
+func (b *BranchController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := b.branchSvc.FindAll(c.Request.Context(), limitInt, offset)
+	if err != nil {
+		logger.Error("Failed to find all branches: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find all branches")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get all branches")
+}
+
+// FinById godoc
+// @Summary Get branch by id
+// @Description Get branch by id
+// @Tags Branch
+// @Produce json
+// @Param id path string true "Branch ID"
+// @Success 200 {object} entity.Branch
+// @Router /branches/{id} [get]
+func (b *BranchController) FinById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	data, err := b.branchSvc.FindById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("branch with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Branch not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to find branch by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get branch")
+}
+
+// Insert godoc
+// @Summary Insert branch
+// @Description Insert branch
+// @Tags Branch
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param branch body entity.Branch true "Branch"
+// @Success 200 {object} entity.Branch
+// @Router /admin/branches [post]
+func (b *BranchController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.Branch
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate branch: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := b.branchSvc.Insert(c.Request.Context(), &reqBody)
+	if err != nil {
+		logger.Error("Failed to insert branch: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, reqBody, nil, "Success insert branch")
+}
+
+// UpdateById godoc
+// @Summary Update branch by id
+// @Description Update branch by id, set is_active to false to stop accepting new orders
+// @Tags Branch
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Branch ID"
+// @Param branch body entity.Branch true "Branch"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/branches/{id} [put]
+func (b *BranchController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.Branch
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate branch: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := b.branchSvc.UpdateById(c.Request.Context(), id, &reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("branch with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Branch not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update branch by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success update branch")
+}
+
+// DeleteById godoc
+// @Summary Delete branch by id
+// @Description Delete branch by id
+// @Tags Branch
+// @Security ApiCookieAuth
+// @Param id path string true "Branch ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/branches/{id} [delete]
+func (b *BranchController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := b.branchSvc.DeleteById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("branch with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Branch not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to delete branch by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete branch")
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IBundleController interface {
+	FindAll(c *gin.Context)
+	FindById(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+}
+
+type BundleController struct {
+	bundleSvc service.IBundleService
+}
+
+func NewBundleController(bundleSvc service.IBundleService) IBundleController {
+	return &BundleController{
+		bundleSvc: bundleSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary Get all bundles
+// @Description Get all toy bundles with their components and availability
+// @Tags Bundle
+// @Produce json
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.Bundle
+// @Router /bundle [get]
+func (b *BundleController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := b.bundleSvc.FindAll(c.Request.Context(), limitInt, offset)
+	if err != nil {
+		logger.Error("Failed to find all bundles: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find all bundles")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get all bundles")
+}
+
+// FindById godoc
+// @Summary Get bundle by id
+// @Description Get a toy bundle with its components and availability
+// @Tags Bundle
+// @Produce json
+// @Param id path string true "Bundle ID"
+// @Success 200 {object} entity.Bundle
+// @Router /bundle/{id} [get]
+func (b *BundleController) FindById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	data, err := b.bundleSvc.FindById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("bundle with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Bundle not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to find bundle by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get bundle")
+}
+
+// Insert godoc
+// @Summary Insert bundle
+// @Description Create a toy bundle composed of toys with quantities and a bundle price
+// @Tags Bundle
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param bundle body entity.Bundle true "Bundle"
+// @Success 200 {object} entity.Bundle
+// @Router /bundle [post]
+func (b *BundleController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.Bundle
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate bundle: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := b.bundleSvc.Insert(c.Request.Context(), &reqBody)
+	if err != nil {
+		logger.Error("Failed to insert bundle: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, reqBody, nil, "Success insert bundle")
+}
+
+// UpdateById godoc
+// @Summary Update bundle by id
+// @Description Update a toy bundle and replace its components
+// @Tags Bundle
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Bundle ID"
+// @Param bundle body entity.Bundle true "Bundle"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /bundle/{id} [put]
+func (b *BundleController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.Bundle
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate bundle: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := b.bundleSvc.UpdateById(c.Request.Context(), id, &reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("bundle with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Bundle not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update bundle by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success update bundle")
+}
+
+// DeleteById godoc
+// @Summary Delete bundle by id
+// @Description Delete a toy bundle
+// @Tags Bundle
+// @Security ApiCookieAuth
+// @Param id path string true "Bundle ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /bundle/{id} [delete]
+func (b *BundleController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := b.bundleSvc.DeleteById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("bundle with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Bundle not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to delete bundle by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete bundle")
+}
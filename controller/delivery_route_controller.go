@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IDeliveryRouteController interface {
+	Plan(c *gin.Context)
+	FindByDate(c *gin.Context)
+	UpdateStop(c *gin.Context)
+}
+
+type DeliveryRouteController struct {
+	routeSvc service.IDeliveryRouteService
+}
+
+func NewDeliveryRouteController(routeSvc service.IDeliveryRouteService) IDeliveryRouteController {
+	return &DeliveryRouteController{
+		routeSvc: routeSvc,
+	}
+}
+
+// Plan godoc
+// @Summary Plan delivery routes
+// @Description Group the day's unplanned drop-offs and return collections of a branch into driver routes. Stops are ordered per delivery slot with nearest neighbour, optionally improved with 2-opt (default).
+// @Tags Delivery
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.PlanDeliveryRoutesRequest true "Plan routes"
+// @Success 201 {array} entity.DeliveryRoute
+// @Router /admin/delivery/routes [post]
+func (d *DeliveryRouteController) Plan(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.PlanDeliveryRoutesRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate delivery route plan: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	routes, err := d.routeSvc.Plan(c.Request.Context(), reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to plan delivery routes of branch %s on %s: %v", reqBody.BranchID, reqBody.Date, err))
+		respondDeliveryRouteError(c, err, "Failed to plan delivery routes")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusCreated, routes, nil, "Success to plan delivery routes")
+}
+
+// FindByDate godoc
+// @Summary Get delivery routes
+// @Description Get the driver routes of a branch on a date with their ordered stops
+// @Tags Delivery
+// @Security ApiCookieAuth
+// @Produce json
+// @Param branch_id query string true "Branch ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {array} entity.DeliveryRoute
+// @Router /admin/delivery/routes [get]
+func (d *DeliveryRouteController) FindByDate(c *gin.Context) {
+	var logger = helpers.Logger
+
+	branchID := c.Query("branch_id")
+	if branchID == "" {
+		logger.Error("Branch id is required")
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrBranchRequired.Error())
+		return
+	}
+
+	routes, err := d.routeSvc.FindByBranchAndDate(c.Request.Context(), branchID, c.Query("date"))
+	if err != nil {
+		logger.Error("Failed to find delivery routes: ", err)
+		respondDeliveryRouteError(c, err, "Failed to find delivery routes")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, routes, nil, "Success get delivery routes")
+}
+
+// UpdateStop godoc
+// @Summary Update delivery stop status
+// @Description Record the outcome of a stop. A delivered drop-off activates the rental, a collected stop marks the toys as picked up for return processing at the branch.
+// @Tags Delivery
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Stop ID"
+// @Param request body entity.UpdateDeliveryStopRequest true "Stop status"
+// @Success 200 {object} entity.DeliveryStop
+// @Router /admin/delivery/stops/{id} [put]
+func (d *DeliveryRouteController) UpdateStop(c *gin.Context) {
+	var logger = helpers.Logger
+
+	id := c.Param("id")
+
+	var reqBody entity.UpdateDeliveryStopRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate delivery stop: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	stop, err := d.routeSvc.UpdateStop(c.Request.Context(), id, reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to update delivery stop %s: %v", id, err))
+		respondDeliveryRouteError(c, err, "Failed to update delivery stop")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, stop, nil, "Success to update delivery stop")
+}
+
+func respondDeliveryRouteError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.ResponseError(c, http.StatusNotFound, "Delivery stop not found")
+	case errors.Is(err, entity.ErrBranchUnavailable),
+		errors.Is(err, entity.ErrNoDeliveryJobs):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrDeliveryStopCompleted):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrDeliveryRouteDateInvalid),
+		errors.Is(err, entity.ErrDeliveryStopStatusWrong),
+		errors.Is(err, entity.ErrDeliveryFailureReason):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, fallback)
+	}
+}
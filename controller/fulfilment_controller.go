@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type IFulfilmentController interface {
+	Quote(c *gin.Context)
+	Slots(c *gin.Context)
+}
+
+type FulfilmentController struct {
+	fulfilmentSvc service.IFulfilmentService
+}
+
+func NewFulfilmentController(fulfilmentSvc service.IFulfilmentService) IFulfilmentController {
+	return &FulfilmentController{
+		fulfilmentSvc: fulfilmentSvc,
+	}
+}
+
+// Quote godoc
+// @Summary Get delivery fee
+// @Description Calculate the delivery fee to a saved address from the chosen branch, or from the nearest active branch
+// @Tags Fulfilment
+// @Security ApiCookieAuth
+// @Produce json
+// @Param address_id query string true "Address ID"
+// @Param branch_id query string false "Branch ID"
+// @Success 200 {object} entity.DeliveryQuote
+// @Router /fulfilment/quote [get]
+func (f *FulfilmentController) Quote(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	addressID := c.Query("address_id")
+	if addressID == "" {
+		logger.Error("Address id is required")
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrAddressRequired.Error())
+		return
+	}
+
+	quote, err := f.fulfilmentSvc.Quote(c.Request.Context(), claimsData.UserID.String(), addressID, c.Query("branch_id"))
+	if err != nil {
+		logger.Error("Failed to quote delivery fee: ", err)
+		respondFulfilmentError(c, err, "Failed to quote delivery fee")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, quote, nil, "Success get delivery fee")
+}
+
+// Slots godoc
+// @Summary Get delivery slots
+// @Description Get delivery and return pickup slots of a branch on a date with their remaining capacity
+// @Tags Fulfilment
+// @Produce json
+// @Param branch_id query string true "Branch ID"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {array} entity.DeliverySlot
+// @Router /fulfilment/slots [get]
+func (f *FulfilmentController) Slots(c *gin.Context) {
+	var logger = helpers.Logger
+
+	branchID := c.Query("branch_id")
+	if branchID == "" {
+		logger.Error("Branch id is required")
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrBranchRequired.Error())
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		logger.Error("Invalid date: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Invalid date, use YYYY-MM-DD")
+		return
+	}
+
+	slots, err := f.fulfilmentSvc.AvailableSlots(c.Request.Context(), branchID, date)
+	if err != nil {
+		logger.Error("Failed to find delivery slots: ", err)
+		respondFulfilmentError(c, err, "Failed to find delivery slots")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, slots, nil, "Success get delivery slots")
+}
+
+func respondFulfilmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, entity.ErrBranchUnavailable),
+		errors.Is(err, entity.ErrAddressNotFound):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrDeliverySlotFull):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrFulfilmentMethodInvalid),
+		errors.Is(err, entity.ErrBranchRequired),
+		errors.Is(err, entity.ErrAddressRequired),
+		errors.Is(err, entity.ErrOutsideDeliveryArea),
+		errors.Is(err, entity.ErrDeliverySlotRequired),
+		errors.Is(err, entity.ErrDeliverySlotInvalid),
+		errors.Is(err, entity.ErrReturnPickupSlotInvalid),
+		errors.Is(err, entity.ErrReturnPickupNotAvailable):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, fallback)
+	}
+}
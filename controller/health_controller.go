@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"final-project/utils/helpers"
+	"final-project/utils/lifecycle"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type IHealthController interface {
+	Live(c *gin.Context)
+	Ready(c *gin.Context)
+}
+
+type HealthController struct {
+	lc *lifecycle.Lifecycle
+	db *gorm.DB
+}
+
+func NewHealthController(lc *lifecycle.Lifecycle, db *gorm.DB) IHealthController {
+	return &HealthController{
+		lc: lc,
+		db: db,
+	}
+}
+
+// Live godoc
+// @Summary Liveness probe
+// @Description Returns 200 while the process is running, also during shutdown
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.APISuccessResponse
+// @Router /health/live [get]
+func (h *HealthController) Live(c *gin.Context) {
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Alive")
+}
+
+// Ready godoc
+// @Summary Readiness probe
+// @Description Returns 503 once shutdown has started or when the database cannot be reached, so the load balancer stops sending traffic
+// @Tags Health
+// @Produce json
+// @Success 200 {object} response.APISuccessResponse
+// @Failure 503 {object} response.APIErrorResponse
+// @Router /health/ready [get]
+func (h *HealthController) Ready(c *gin.Context) {
+	var logger = helpers.Logger
+
+	if !h.lc.Ready() {
+		response.ResponseError(c, http.StatusServiceUnavailable, "Not ready")
+		return
+	}
+
+	sqlDB, err := h.db.DB()
+	if err == nil {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		logger.Error("Readiness check failed to reach database: ", err)
+		response.ResponseError(c, http.StatusServiceUnavailable, "Database unavailable")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Ready")
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IImpersonationController interface {
+	Start(c *gin.Context)
+}
+
+type ImpersonationController struct {
+	impersonationSvc service.IImpersonationService
+}
+
+func NewImpersonationController(impersonationSvc service.IImpersonationService) IImpersonationController {
+	return &ImpersonationController{
+		impersonationSvc: impersonationSvc,
+	}
+}
+
+// Start godoc
+// @Summary Impersonate user
+// @Description Issue a short-lived bearer token to see the app as the user. The token cannot be refreshed, destructive account actions are rejected and every request is written to the audit log.
+// @Tags users
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 201 {object} entity.ImpersonationResponse
+// @Router /admin/users/{id}/impersonate [post]
+func (ic *ImpersonationController) Start(c *gin.Context) {
+	var log = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		log.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		log.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	// Impersonation tidak boleh berantai
+	if claimsData.IsImpersonation() {
+		log.Error("Nested impersonation is not allowed")
+		response.ResponseError(c, http.StatusForbidden, entity.ErrImpersonationRestricted.Error())
+		return
+	}
+
+	id := c.Param("id")
+	data, err := ic.impersonationSvc.Start(c.Request.Context(), claimsData.UserID, id, sessionClient(c))
+	if err != nil {
+		log.Error(fmt.Errorf("failed to impersonate user %s: %v", id, err))
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.ResponseError(c, http.StatusNotFound, "User not found")
+		case errors.Is(err, entity.ErrImpersonateSelf):
+			response.ResponseError(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrImpersonationNotAllowed), errors.Is(err, entity.ErrAccountInactive):
+			response.ResponseError(c, http.StatusForbidden, err.Error())
+		default:
+			response.ResponseError(c, http.StatusInternalServerError, "Failed to impersonate user")
+		}
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusCreated, data, nil, "Success to impersonate user")
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"final-project/utils/helpers"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type IJWKSController interface {
+	FindAll(c *gin.Context)
+}
+
+type JWKSController struct {
+	jwtHelper helpers.JWTHelper
+}
+
+func NewJWKSController(jwtHelper helpers.JWTHelper) IJWKSController {
+	return &JWKSController{
+		jwtHelper: jwtHelper,
+	}
+}
+
+// FindAll godoc
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying tokens issued by this service, including keys that are about to be used
+// @Tags JWKS
+// @Produce json
+// @Success 200 {object} helpers.JWKS
+// @Router /.well-known/jwks.json [get]
+func (j *JWKSController) FindAll(c *gin.Context) {
+	// Response mengikuti format JWKS standar (tanpa pembungkus response) agar bisa dibaca library JWT
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, j.jwtHelper.JWKS())
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type IMFAController interface {
+	Enroll(c *gin.Context)
+	Confirm(c *gin.Context)
+	RegenerateRecoveryCodes(c *gin.Context)
+	Disable(c *gin.Context)
+}
+
+type MFAController struct {
+	mfaSvc service.IMFAService
+}
+
+func NewMFAController(mfaSvc service.IMFAService) IMFAController {
+	return &MFAController{
+		mfaSvc: mfaSvc,
+	}
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth provisioning URI to be shown as QR code
+// @Tags MFA
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} entity.MFAEnrollmentResponse
+// @Router /user/mfa/enroll [post]
+func (m *MFAController) Enroll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := m.mfaSvc.Enroll(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to enroll two-factor: ", err)
+		respondMFAError(c, err, "Failed to enroll two-factor")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Scan the provisioning URI and confirm with a code")
+}
+
+// Confirm godoc
+// @Summary Confirm two-factor enrollment
+// @Description Activate two-factor with a code from the authenticator app, recovery codes are returned once
+// @Tags MFA
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "Code"
+// @Success 200 {object} entity.MFARecoveryCodesResponse
+// @Router /user/mfa/confirm [post]
+func (m *MFAController) Confirm(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.MFACodeRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	codes, err := m.mfaSvc.Confirm(c.Request.Context(), claimsData.UserID.String(), reqBody.Code)
+	if err != nil {
+		logger.Error("Failed to confirm two-factor: ", err)
+		respondMFAError(c, err, "Failed to confirm two-factor")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, entity.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil, "Two-factor enabled, please login again to start a verified session")
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes, requires a valid TOTP or recovery code
+// @Tags MFA
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "Code"
+// @Success 200 {object} entity.MFARecoveryCodesResponse
+// @Router /user/mfa/recovery-codes [post]
+func (m *MFAController) RegenerateRecoveryCodes(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.MFACodeRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	codes, err := m.mfaSvc.RegenerateRecoveryCodes(c.Request.Context(), claimsData.UserID.String(), reqBody.Code)
+	if err != nil {
+		logger.Error("Failed to regenerate recovery codes: ", err)
+		respondMFAError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, entity.MFARecoveryCodesResponse{RecoveryCodes: codes}, nil, "Success regenerate recovery codes")
+}
+
+// Disable godoc
+// @Summary Disable two-factor
+// @Description Disable two-factor authentication, not allowed for roles where it is enforced
+// @Tags MFA
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.MFACodeRequest true "Code"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/mfa [delete]
+func (m *MFAController) Disable(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.MFACodeRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := m.mfaSvc.Disable(c.Request.Context(), claimsData.UserID.String(), reqBody.Code); err != nil {
+		logger.Error("Failed to disable two-factor: ", err)
+		respondMFAError(c, err, "Failed to disable two-factor")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success disable two-factor")
+}
+
+func respondMFAError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrMFAInvalidCode):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrMFANotEnrolled):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrMFAAlreadyEnabled):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrMFARequiredForRole):
+		response.ResponseError(c, http.StatusForbidden, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
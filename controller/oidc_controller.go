@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/oidc"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// oidcStateCookie mengikat state ke browser yang memulai login, agar callback dari browser lain
+// (misalnya link yang dikirim penyerang) ditolak
+const (
+	oidcStateCookie     = "oidc_state"
+	oidcStateCookiePath = "/api/user/auth/oidc"
+)
+
+type IOIDCController interface {
+	Providers(c *gin.Context)
+	Login(c *gin.Context)
+	Callback(c *gin.Context)
+	FindIdentities(c *gin.Context)
+	Link(c *gin.Context)
+	Unlink(c *gin.Context)
+}
+
+type OIDCController struct {
+	oidcSvc  service.IOIDCService
+	cookies  CookieOptions
+	stateTTL int
+}
+
+func NewOIDCController(oidcSvc service.IOIDCService, cookies CookieOptions, stateTTLSeconds int) IOIDCController {
+	return &OIDCController{
+		oidcSvc:  oidcSvc,
+		cookies:  cookies,
+		stateTTL: stateTTLSeconds,
+	}
+}
+
+// Providers godoc
+// @Summary List login providers
+// @Description Get the configured OpenID Connect providers
+// @Tags OIDC
+// @Produce json
+// @Success 200 {array} entity.OIDCProviderResponse
+// @Router /user/auth/oidc/providers [get]
+func (o *OIDCController) Providers(c *gin.Context) {
+	providers := make([]entity.OIDCProviderResponse, 0)
+	for _, name := range o.oidcSvc.Providers() {
+		providers = append(providers, entity.OIDCProviderResponse{
+			Name:     name,
+			LoginURL: fmt.Sprintf("/api/user/auth/oidc/%s/login", name),
+		})
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, providers, nil, "Success get login providers")
+}
+
+// Login godoc
+// @Summary Login with provider
+// @Description Redirect to the OpenID Connect provider using authorization code flow with PKCE
+// @Tags OIDC
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Router /user/auth/oidc/{provider}/login [get]
+func (o *OIDCController) Login(c *gin.Context) {
+	var logger = helpers.Logger
+
+	authURL, state, err := o.oidcSvc.StartLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		logger.Error("Failed to start provider login: ", err)
+		respondOIDCError(c, err, "Failed to start provider login")
+		return
+	}
+
+	o.setStateCookie(c, state)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback godoc
+// @Summary Provider callback
+// @Description Complete provider login or identity linking. Login responds like /user/auth/login.
+// @Tags OIDC
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} entity.User
+// @Router /user/auth/oidc/{provider}/callback [get]
+func (o *OIDCController) Callback(c *gin.Context) {
+	var logger = helpers.Logger
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		logger.Errorf("Provider returned error: %s %s", providerErr, c.Query("error_description"))
+		o.clearStateCookie(c)
+		response.ResponseError(c, http.StatusBadRequest, "Provider login was cancelled or failed")
+		return
+	}
+
+	state := c.Query("state")
+	code := c.Query("code")
+	cookieState, err := c.Cookie(oidcStateCookie)
+	if err != nil || state == "" || code == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		logger.Error("Provider callback state does not match")
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrOIDCStateInvalid.Error())
+		return
+	}
+	o.clearStateCookie(c)
+
+	result, err := o.oidcSvc.HandleCallback(c.Request.Context(), c.Param("provider"), code, state, sessionClient(c))
+	if err != nil {
+		logger.Error("Failed to handle provider callback: ", err)
+		respondOIDCError(c, err, "Failed to login with provider")
+		return
+	}
+
+	if result.Flow == entity.OIDCFlowLink {
+		response.ResponseSuccess(c, http.StatusOK, result.Identity, nil, "Success link identity")
+		return
+	}
+
+	loginResult := result.LoginResult
+	if loginResult.MFARequired {
+		response.ResponseSuccess(c, http.StatusOK, entity.MFAPendingResponse{
+			MFARequired: true,
+			MFAToken:    loginResult.MFAToken,
+			ExpiresAt:   loginResult.MFATokenExpiresAt,
+		}, nil, "Two-factor authentication required")
+		return
+	}
+
+	loginResult.User.Password = ""
+	o.cookies.respondSession(c, loginResult.UserToken, &loginResult.User, false, "Success to login")
+}
+
+// FindIdentities godoc
+// @Summary List linked identities
+// @Description Get provider identities linked to the logged in user
+// @Tags OIDC
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.UserIdentity
+// @Router /user/identities [get]
+func (o *OIDCController) FindIdentities(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := o.oidcSvc.FindIdentities(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find identities: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find identities")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get identities")
+}
+
+// Link godoc
+// @Summary Link identity
+// @Description Redirect to the provider to link its account to the logged in user
+// @Tags OIDC
+// @Security ApiCookieAuth
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Router /user/identities/{provider}/link [get]
+func (o *OIDCController) Link(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	authURL, state, err := o.oidcSvc.StartLink(c.Request.Context(), c.Param("provider"), claimsData.UserID)
+	if err != nil {
+		logger.Error("Failed to start identity linking: ", err)
+		respondOIDCError(c, err, "Failed to start identity linking")
+		return
+	}
+
+	o.setStateCookie(c, state)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Unlink godoc
+// @Summary Unlink identity
+// @Description Remove the link between the logged in user and a provider account
+// @Tags OIDC
+// @Security ApiCookieAuth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/identities/{provider} [delete]
+func (o *OIDCController) Unlink(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	provider := c.Param("provider")
+	if err := o.oidcSvc.Unlink(c.Request.Context(), claimsData.UserID.String(), provider); err != nil {
+		logger.Error(fmt.Errorf("failed to unlink identity %s: %v", provider, err))
+		respondOIDCError(c, err, "Failed to unlink identity")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success unlink identity")
+}
+
+func (o *OIDCController) setStateCookie(c *gin.Context, state string) {
+	// SameSite Lax tetap mengirim cookie pada redirect GET dari halaman provider
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, o.stateTTL, oidcStateCookiePath, o.cookies.Domain, o.cookies.Secure, true)
+}
+
+func (o *OIDCController) clearStateCookie(c *gin.Context) {
+	c.SetCookie(oidcStateCookie, "", -1, oidcStateCookiePath, o.cookies.Domain, o.cookies.Secure, true)
+}
+
+func respondOIDCError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrOIDCProviderNotFound), errors.Is(err, entity.ErrIdentityNotFound):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrOIDCStateInvalid),
+		errors.Is(err, oidc.ErrInvalidIDToken),
+		errors.Is(err, oidc.ErrNonceMismatch):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrOIDCEmailNotVerified), errors.Is(err, entity.ErrAccountInactive):
+		response.ResponseError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, entity.ErrIdentityAlreadyLinked),
+		errors.Is(err, entity.ErrIdentityProviderLinked),
+		errors.Is(err, entity.ErrIdentityLastLoginMethod):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.ResponseError(c, http.StatusNotFound, "User not found")
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IPlanController interface {
+	FindAll(c *gin.Context)
+	FindById(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+}
+
+type PlanController struct {
+	planSvc service.IPlanService
+}
+
+func NewPlanController(planSvc service.IPlanService) IPlanController {
+	return &PlanController{
+		planSvc: planSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary Get subscription plans
+// @Description Get all active membership subscription plans
+// @Tags Plan
+// @Produce json
+// @Success 200 {array} entity.Plan
+// @Router /plans [get]
+func (p *PlanController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	data, err := p.planSvc.FindAllActive(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to find all plans: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find all plans")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get all plans")
+}
+
+// FindById godoc
+// @Summary Get subscription plan by id
+// @Description Get subscription plan by id
+// @Tags Plan
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Success 200 {object} entity.Plan
+// @Router /plans/{id} [get]
+func (p *PlanController) FindById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	data, err := p.planSvc.FindById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("plan with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Plan not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to find plan by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get plan")
+}
+
+// Insert godoc
+// @Summary Insert subscription plan
+// @Description Create a membership subscription plan
+// @Tags Plan
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param plan body entity.Plan true "Plan"
+// @Success 200 {object} entity.Plan
+// @Router /admin/plans [post]
+func (p *PlanController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.Plan
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate plan: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := p.planSvc.Insert(c.Request.Context(), &reqBody)
+	if err != nil {
+		logger.Error("Failed to insert plan: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, reqBody, nil, "Success insert plan")
+}
+
+// UpdateById godoc
+// @Summary Update subscription plan by id
+// @Description Update subscription plan by id
+// @Tags Plan
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param plan body entity.Plan true "Plan"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/plans/{id} [put]
+func (p *PlanController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.Plan
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate plan: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := p.planSvc.UpdateById(c.Request.Context(), id, &reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("plan with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Plan not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update plan by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success update plan")
+}
+
+// DeleteById godoc
+// @Summary Delete subscription plan by id
+// @Description Delete subscription plan by id
+// @Tags Plan
+// @Security ApiCookieAuth
+// @Param id path string true "Plan ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/plans/{id} [delete]
+func (p *PlanController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := p.planSvc.DeleteById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("plan with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Plan not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to delete plan by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete plan")
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type IPrivacyController interface {
+	Export(c *gin.Context)
+	CancelErasure(c *gin.Context)
+}
+
+type PrivacyController struct {
+	privacySvc service.IPrivacyService
+}
+
+func NewPrivacyController(privacySvc service.IPrivacyService) IPrivacyController {
+	return &PrivacyController{
+		privacySvc: privacySvc,
+	}
+}
+
+// Export godoc
+// @Summary Export my data
+// @Description Download a ZIP of JSON files with profile, rentals, payments, subscriptions, sessions and other personal data
+// @Tags Privacy
+// @Security ApiCookieAuth
+// @Produce application/zip
+// @Success 200 {file} file
+// @Router /user/me/export [get]
+func (p *PrivacyController) Export(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := p.privacySvc.Export(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to export data of user %s: %v", claimsData.UserID, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.ResponseError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to export personal data")
+		return
+	}
+
+	filename := fmt.Sprintf("personal-data-%s.zip", time.Now().Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", data)
+}
+
+// CancelErasure godoc
+// @Summary Cancel account deletion
+// @Description Cancel a scheduled account deletion while the grace period has not ended
+// @Tags Privacy
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/me/erasure [delete]
+func (p *PrivacyController) CancelErasure(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	if err := p.privacySvc.CancelErasure(c.Request.Context(), claimsData.UserID.String()); err != nil {
+		logger.Error(fmt.Errorf("failed to cancel erasure of user %s: %v", claimsData.UserID, err))
+		respondErasureError(c, err, "Failed to cancel account deletion")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Account deletion cancelled")
+}
+
+func respondErasureError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrErasureNotRequested):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrErasureAlreadyRequested),
+		errors.Is(err, entity.ErrErasureOpenRentals),
+		errors.Is(err, entity.ErrErasureActiveSubscription),
+		errors.Is(err, entity.ErrErasureWaitlistHold):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
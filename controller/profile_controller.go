@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IProfileController interface {
+	FindMe(c *gin.Context)
+	UpdateMe(c *gin.Context)
+	ChangePassword(c *gin.Context)
+}
+
+type ProfileController struct {
+	accountSvc service.IAccountService
+}
+
+func NewProfileController(accountSvc service.IAccountService) IProfileController {
+	return &ProfileController{
+		accountSvc: accountSvc,
+	}
+}
+
+// FindMe godoc
+// @Summary Get my profile
+// @Description Get the profile of the logged in user
+// @Tags users
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} entity.ProfileResponse
+// @Router /user/me [get]
+func (p *ProfileController) FindMe(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	user, err := p.accountSvc.FindProfile(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to find profile of user %s: %v", claimsData.UserID, err))
+		respondProfileError(c, err, "Failed to find profile")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, user.ToProfileResponse(), nil, "Success to find profile")
+}
+
+// UpdateMe godoc
+// @Summary Update my profile
+// @Description Update the editable fields of the logged in user, omitted fields are left unchanged. Changing the email requires verifying the new address.
+// @Tags users
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.UpdateProfileRequest true "Profile"
+// @Success 200 {object} entity.ProfileResponse
+// @Router /user/me [patch]
+func (p *ProfileController) UpdateMe(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate profile: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	user, err := p.accountSvc.UpdateProfile(c.Request.Context(), claimsData.UserID.String(), reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to update profile of user %s: %v", claimsData.UserID, err))
+		respondProfileError(c, err, "Failed to update profile")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, user.ToProfileResponse(), nil, "Success to update profile")
+}
+
+// ChangePassword godoc
+// @Summary Change my password
+// @Description Change the password of the logged in user, all other sessions are revoked
+// @Tags users
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.ChangePasswordRequest true "Change password"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/me/password [post]
+func (p *ProfileController) ChangePassword(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := entity.ValidatePassword(reqBody.NewPassword); err != nil {
+		logger.Error("Failed to validate password: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := p.accountSvc.ChangePassword(c.Request.Context(), claimsData.UserID.String(), c.GetString("session_id"), reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to change password of user %s: %v", claimsData.UserID, err))
+		respondProfileError(c, err, "Failed to change password")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success to change password")
+}
+
+func respondProfileError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		response.ResponseError(c, http.StatusNotFound, "User not found")
+	case errors.Is(err, entity.ErrEmailTaken),
+		errors.Is(err, entity.ErrUsernameTaken):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrCurrentPasswordInvalid),
+		errors.Is(err, entity.ErrPasswordUnchanged):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, fallback)
+	}
+}
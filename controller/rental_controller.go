@@ -139,7 +139,11 @@ func (r *RentalController) Insert(c *gin.Context) {
 	rental, err := r.RentalSvc.CreateRental(c.Request.Context(), reqBody)
 	if err != nil {
 		logger.Error("Failed to insert rental: ", err)
-		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		if errors.Is(err, entity.ErrEmailNotVerified) {
+			response.ResponseError(c, http.StatusForbidden, err.Error())
+			return
+		}
+		respondFulfilmentError(c, err, err.Error())
 		return
 	}
 
@@ -0,0 +1,280 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IRoleController interface {
+	FindAll(c *gin.Context)
+	FindPermissions(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+	AssignToUser(c *gin.Context)
+}
+
+type RoleController struct {
+	rbacSvc service.IRBACService
+}
+
+func NewRoleController(rbacSvc service.IRBACService) IRoleController {
+	return &RoleController{
+		rbacSvc: rbacSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary List roles
+// @Description Get all roles with their permissions
+// @Tags Role
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.Role
+// @Router /admin/roles [get]
+func (r *RoleController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	data, err := r.rbacSvc.FindAllRoles(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to find roles: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find roles")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get roles")
+}
+
+// FindPermissions godoc
+// @Summary List permissions
+// @Description Get all permissions that can be assigned to a role
+// @Tags Role
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.Permission
+// @Router /admin/permissions [get]
+func (r *RoleController) FindPermissions(c *gin.Context) {
+	var logger = helpers.Logger
+
+	data, err := r.rbacSvc.FindAllPermissions(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to find permissions: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find permissions")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get permissions")
+}
+
+// Insert godoc
+// @Summary Create role
+// @Description Create a custom role with a set of permissions. Only permissions held by the caller's own role can be granted.
+// @Tags Role
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param role body entity.RoleRequest true "Role"
+// @Success 200 {object} entity.Role
+// @Router /admin/roles [post]
+func (r *RoleController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.RoleRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate role: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	role, err := r.rbacSvc.CreateRole(c.Request.Context(), claimsData.Role, reqBody)
+	if err != nil {
+		logger.Error("Failed to create role: ", err)
+		respondRoleError(c, err, "Failed to create role")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, role, nil, "Success create role")
+}
+
+// UpdateById godoc
+// @Summary Update role
+// @Description Update role description and permissions
+// @Tags Role
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Role ID"
+// @Param role body entity.RoleRequest true "Role"
+// @Success 200 {object} entity.Role
+// @Router /admin/roles/{id} [put]
+func (r *RoleController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.RoleRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := reqBody.Validate(); err != nil {
+		logger.Error("Failed to validate role: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	role, err := r.rbacSvc.UpdateRole(c.Request.Context(), claimsData.Role, id, reqBody)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to update role %s: %v", id, err))
+		respondRoleError(c, err, "Failed to update role")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, role, nil, "Success update role")
+}
+
+// DeleteById godoc
+// @Summary Delete role
+// @Description Delete a custom role that is not assigned to any user
+// @Tags Role
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "Role ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/roles/{id} [delete]
+func (r *RoleController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	if err := r.rbacSvc.DeleteRole(c.Request.Context(), id); err != nil {
+		logger.Error(fmt.Errorf("failed to delete role %s: %v", id, err))
+		respondRoleError(c, err, "Failed to delete role")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete role")
+}
+
+// AssignToUser godoc
+// @Summary Assign role to user
+// @Description Change the role of a user, the user's sessions are revoked. Callers cannot change their own role, assign a role with permissions they do not hold, change the role of a user holding permissions they do not hold, or demote the last admin.
+// @Tags Role
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body entity.AssignRoleRequest true "Role"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/users/{id}/role [put]
+func (r *RoleController) AssignToUser(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.AssignRoleRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	if err := r.rbacSvc.AssignRole(c.Request.Context(), claimsData.UserID.String(), claimsData.Role, id, reqBody.Role); err != nil {
+		logger.Error(fmt.Errorf("failed to assign role to user %s: %v", id, err))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.ResponseError(c, http.StatusNotFound, "User not found")
+			return
+		}
+		respondRoleError(c, err, "Failed to assign role")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success assign role")
+}
+
+func respondRoleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, entity.ErrRoleNotFound):
+		response.ResponseError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, entity.ErrRoleAlreadyExists), errors.Is(err, entity.ErrRoleInUse), errors.Is(err, entity.ErrLastAdmin):
+		response.ResponseError(c, http.StatusConflict, err.Error())
+	case errors.Is(err, entity.ErrSystemRoleReadOnly),
+		errors.Is(err, entity.ErrPermissionNotHeld),
+		errors.Is(err, entity.ErrRoleSelfAssign):
+		response.ResponseError(c, http.StatusForbidden, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"net/http"
+)
+
+type ISessionController interface {
+	FindMine(c *gin.Context)
+	DeleteById(c *gin.Context)
+	DeleteAll(c *gin.Context)
+	FindByUserId(c *gin.Context)
+	DeleteByUserId(c *gin.Context)
+}
+
+type SessionController struct {
+	userTokenSvc service.ITokenService
+	cookies      CookieOptions
+}
+
+func NewSessionController(userTokenSvc service.ITokenService, cookies CookieOptions) ISessionController {
+	return &SessionController{
+		userTokenSvc: userTokenSvc,
+		cookies:      cookies,
+	}
+}
+
+// FindMine godoc
+// @Summary List my sessions
+// @Description Get active sessions of the logged in user with device, IP and last seen
+// @Tags Session
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.SessionResponse
+// @Router /user/sessions [get]
+func (s *SessionController) FindMine(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	tokens, err := s.userTokenSvc.FindSessionsByUserID(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find sessions: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find sessions")
+		return
+	}
+
+	currentSessionID := uuid.FromStringOrNil(c.GetString("session_id"))
+	response.ResponseSuccess(c, http.StatusOK, toSessionResponses(tokens, currentSessionID), nil, "Success get sessions")
+}
+
+// DeleteById godoc
+// @Summary Revoke a session
+// @Description Revoke one of the logged in user's sessions
+// @Tags Session
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/sessions/{id} [delete]
+func (s *SessionController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if _, err := uuid.FromString(id); err != nil {
+		logger.Error("Invalid session id: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Invalid session id")
+		return
+	}
+
+	err := s.userTokenSvc.RevokeSession(c.Request.Context(), claimsData.UserID.String(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			logger.Error(fmt.Errorf("session %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Session not found")
+			return
+		}
+
+		logger.Error("Failed to revoke session: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	if id == c.GetString("session_id") {
+		s.cookies.clearAuthCookies(c)
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success revoke session")
+}
+
+// DeleteAll godoc
+// @Summary Revoke other sessions
+// @Description Revoke all sessions of the logged in user except the current one
+// @Tags Session
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/sessions [delete]
+func (s *SessionController) DeleteAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	err := s.userTokenSvc.RevokeAllSessions(c.Request.Context(), claimsData.UserID.String(), c.GetString("session_id"))
+	if err != nil {
+		logger.Error("Failed to revoke sessions: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success revoke other sessions")
+}
+
+// FindByUserId godoc
+// @Summary List user sessions
+// @Description Get active sessions of a user
+// @Tags Session
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} entity.SessionResponse
+// @Router /admin/users/{id}/sessions [get]
+func (s *SessionController) FindByUserId(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if _, err := uuid.FromString(id); err != nil {
+		logger.Error("Invalid user id: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	tokens, err := s.userTokenSvc.FindSessionsByUserID(c.Request.Context(), id)
+	if err != nil {
+		logger.Error("Failed to find sessions: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find sessions")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, toSessionResponses(tokens, uuid.Nil), nil, "Success get sessions")
+}
+
+// DeleteByUserId godoc
+// @Summary Force logout a user
+// @Description Revoke all sessions of a user
+// @Tags Session
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/users/{id}/sessions [delete]
+func (s *SessionController) DeleteByUserId(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if _, err := uuid.FromString(id); err != nil {
+		logger.Error("Invalid user id: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	if err := s.userTokenSvc.RevokeAllSessions(c.Request.Context(), id, ""); err != nil {
+		logger.Error("Failed to force logout user: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to force logout user")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success force logout user")
+}
+
+func toSessionResponses(tokens []entity.UserToken, currentSessionID uuid.UUID) []entity.SessionResponse {
+	sessions := make([]entity.SessionResponse, 0, len(tokens))
+	for i := range tokens {
+		sessions = append(sessions, tokens[i].ToSessionResponse(currentSessionID))
+	}
+	return sessions
+}
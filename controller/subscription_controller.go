@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type ISubscriptionController interface {
+	FindMine(c *gin.Context)
+	Subscribe(c *gin.Context)
+	Cancel(c *gin.Context)
+	UpdatePaymentStatus(c *gin.Context)
+}
+
+type SubscriptionController struct {
+	subscriptionSvc service.ISubscriptionService
+}
+
+func NewSubscriptionController(subscriptionSvc service.ISubscriptionService) ISubscriptionController {
+	return &SubscriptionController{
+		subscriptionSvc: subscriptionSvc,
+	}
+}
+
+// FindMine godoc
+// @Summary Get my subscription
+// @Description Get current subscription of the logged in user with plan usage
+// @Tags Subscription
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} entity.SubscriptionUsage
+// @Router /user/subscription [get]
+func (s *SubscriptionController) FindMine(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := s.subscriptionSvc.GetUsage(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		if errors.Is(err, entity.ErrNoActiveSubscription) {
+			logger.Error(fmt.Errorf("user %s has no subscription", claimsData.UserID))
+			response.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		logger.Error("Failed to find subscription: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get subscription")
+}
+
+// Subscribe godoc
+// @Summary Subscribe to a plan
+// @Description Start a monthly membership subscription. The subscription stays pending until the first bill is paid, and is cancelled when that payment fails
+// @Tags Subscription
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.SubscribeRequest true "Subscribe"
+// @Success 200 {object} entity.Subscription
+// @Router /user/subscription [post]
+func (s *SubscriptionController) Subscribe(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.SubscribeRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	subscription, err := s.subscriptionSvc.Subscribe(c.Request.Context(), claimsData.UserID, reqBody)
+	if err != nil {
+		logger.Error("Failed to subscribe: ", err)
+		if errors.Is(err, entity.ErrPlanNotAvailable) || errors.Is(err, entity.ErrSubscriptionAlreadyActive) {
+			response.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, subscription, nil, "Success subscribe")
+}
+
+// Cancel godoc
+// @Summary Cancel my subscription
+// @Description Cancel subscription renewal at the end of the current period
+// @Tags Subscription
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {object} entity.Subscription
+// @Router /user/subscription [delete]
+func (s *SubscriptionController) Cancel(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	subscription, err := s.subscriptionSvc.Cancel(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		if errors.Is(err, entity.ErrNoActiveSubscription) {
+			logger.Error(fmt.Errorf("user %s has no subscription", claimsData.UserID))
+			response.ResponseError(c, http.StatusNotFound, err.Error())
+			return
+		}
+
+		logger.Error("Failed to cancel subscription: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, subscription, nil, "Success cancel subscription")
+}
+
+// UpdatePaymentStatus godoc
+// @Summary Update payment status
+// @Description Record the transaction status of a payment, settling a subscription bill reactivates a past due subscription
+// @Tags Subscription
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Payment ID"
+// @Param request body entity.UpdatePaymentStatusRequest true "Payment status"
+// @Success 200 {object} entity.Payment
+// @Router /admin/payments/{id}/status [put]
+func (s *SubscriptionController) UpdatePaymentStatus(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.UpdatePaymentStatusRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	payment, err := s.subscriptionSvc.UpdatePaymentStatus(c.Request.Context(), id, reqBody)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("payment with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Payment not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update payment %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, payment, nil, "Success update payment status")
+}
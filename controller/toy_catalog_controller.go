@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"encoding/csv"
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"final-project/utils/spreadsheet"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// maxToyImportSize adalah ukuran maksimal file import katalog
+const maxToyImportSize = 10 << 20
+
+type IToyCatalogController interface {
+	Import(c *gin.Context)
+	Export(c *gin.Context)
+}
+
+type ToyCatalogController struct {
+	catalogSvc service.IToyCatalogService
+}
+
+func NewToyCatalogController(catalogSvc service.IToyCatalogService) IToyCatalogController {
+	return &ToyCatalogController{
+		catalogSvc: catalogSvc,
+	}
+}
+
+// Import godoc
+// @Summary Import toy catalogue
+// @Description Import toys from a CSV or XLSX file with the columns name, description, age_recommendation, condition, rental_price, late_fee_per_day, replacement_price, stock and categories (names separated by ";"). Runs as a dry run unless dry_run=false. In atomic mode nothing is saved when any row is invalid, in row mode every valid row is saved.
+// @Tags Toy
+// @Security ApiCookieAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Only validate the rows (default true)"
+// @Param mode query string false "atomic (default) or row"
+// @Success 200 {object} entity.ToyImportResult
+// @Router /admin/toy/import [post]
+func (t *ToyCatalogController) Import(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var req entity.ToyImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Error("Failed to bind query: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind query")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		logger.Error("Failed to validate toy import: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		logger.Error("Failed to read import file: ", err)
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrToyImportFileRequired.Error())
+		return
+	}
+
+	if fileHeader.Size > maxToyImportSize {
+		logger.Error(fmt.Errorf("toy import file of %d bytes is too large", fileHeader.Size))
+		response.ResponseError(c, http.StatusRequestEntityTooLarge, entity.ErrToyImportTooLarge.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logger.Error("Failed to open import file: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to open import file")
+		return
+	}
+	defer file.Close()
+
+	rows, err := t.catalogSvc.Parse(fileHeader.Filename, file, fileHeader.Size)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to parse toy import file %s: %v", fileHeader.Filename, err))
+		respondToyCatalogError(c, err, "Failed to read import file")
+		return
+	}
+
+	result, err := t.catalogSvc.Import(c.Request.Context(), rows, req)
+	if err != nil {
+		logger.Error("Failed to import toys: ", err)
+		respondToyCatalogError(c, err, "Failed to import toys")
+		return
+	}
+
+	message := "Success to import toys"
+	switch {
+	case result.DryRun:
+		message = "Dry run finished, no toys saved"
+	case result.Mode == entity.ToyImportModeAtomic && result.Failed > 0:
+		message = "No toys imported because some rows are invalid"
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, result, nil, message)
+}
+
+// Export godoc
+// @Summary Export toy catalogue
+// @Description Download the whole catalogue with categories and image URLs. The file uses the import columns, so it can be edited and imported again.
+// @Tags Toy
+// @Security ApiCookieAuth
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default) or xlsx"
+// @Success 200 {file} file
+// @Router /admin/toy/export [get]
+func (t *ToyCatalogController) Export(c *gin.Context) {
+	var logger = helpers.Logger
+
+	format := c.DefaultQuery("format", entity.ToyCatalogFormatCSV)
+
+	var contentType string
+	switch format {
+	case entity.ToyCatalogFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case entity.ToyCatalogFormatXLSX:
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		response.ResponseError(c, http.StatusBadRequest, entity.ErrToyCatalogFormat.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("toy-catalogue-%s.%s", time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// Status sudah terkirim saat baris pertama ditulis, sehingga error di tengah export hanya bisa dicatat
+	if err := t.catalogSvc.Export(c.Request.Context(), format, c.Writer); err != nil {
+		logger.Error("Failed to export toy catalogue: ", err)
+	}
+}
+
+func respondToyCatalogError(c *gin.Context, err error, message string) {
+	var csvErr *csv.ParseError
+	switch {
+	case errors.As(err, &csvErr):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, entity.ErrToyImportEmpty),
+		errors.Is(err, entity.ErrToyImportMissingColumn),
+		errors.Is(err, entity.ErrToyCatalogFormat),
+		errors.Is(err, spreadsheet.ErrInvalidXLSX):
+		response.ResponseError(c, http.StatusBadRequest, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, message)
+	}
+}
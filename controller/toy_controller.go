@@ -2,6 +2,7 @@ package controller
 
 import (
 	"errors"
+	"final-project/entity"
 	"final-project/service"
 	"final-project/utils/helpers"
 	"final-project/utils/response"
@@ -17,6 +18,7 @@ type IToyController interface {
 	Insert(c *gin.Context)
 	UpdateById(c *gin.Context)
 	DeleteById(c *gin.Context)
+	UpdateStock(c *gin.Context)
 }
 
 type ToyController struct {
@@ -148,3 +150,47 @@ func (t ToyController) DeleteById(c *gin.Context) {
 	//TODO implement me
 	panic("implement me")
 }
+
+// UpdateStock godoc
+// @Summary Update toy stock
+// @Description Update stock of a toy, customers waiting for the toy are notified when stock is raised
+// @Tags Toy
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Toy ID"
+// @Param request body entity.UpdateToyStockRequest true "Stock"
+// @Success 200 {object} entity.Toy
+// @Router /toy/{id}/stock [put]
+func (t ToyController) UpdateStock(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.UpdateToyStockRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	data, err := t.toySvc.UpdateStock(c.Request.Context(), id, reqBody.Stock)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("toy with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Toy not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update stock of toy %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success update toy stock")
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
+	"strconv"
 )
 
 type IUserController interface {
@@ -19,21 +20,33 @@ type IUserController interface {
 	UpdateById(c *gin.Context)
 	DeleteById(c *gin.Context)
 	Login(c *gin.Context)
+	VerifyMFA(c *gin.Context)
+	Refresh(c *gin.Context)
 	Logout(c *gin.Context)
+	Unlock(c *gin.Context)
 }
 
 type UserController struct {
 	userService      service.IUserService
 	userTokenService service.ITokenService
+	accountService   service.IAccountService
+	privacyService   service.IPrivacyService
+	cookies          CookieOptions
 }
 
 func NewUserController(
 	userSvc service.IUserService,
 	userTokenSvc service.ITokenService,
+	accountSvc service.IAccountService,
+	privacySvc service.IPrivacyService,
+	cookies CookieOptions,
 ) IUserController {
 	return &UserController{
 		userService:      userSvc,
 		userTokenService: userTokenSvc,
+		accountService:   accountSvc,
+		privacyService:   privacySvc,
+		cookies:          cookies,
 	}
 }
 
@@ -141,6 +154,11 @@ func (uc *UserController) Insert(c *gin.Context) {
 		return
 	}
 
+	// Gagal mengirim email tidak membatalkan registrasi, user bisa meminta kirim ulang
+	if err := uc.accountService.SendEmailVerification(c.Request.Context(), user.ID.String()); err != nil {
+		log.Error("Failed to send verification email: ", err)
+	}
+
 	user.Password = ""
 
 	response.ResponseSuccess(c, http.StatusOK, user, nil, "Success to insert user")
@@ -148,13 +166,13 @@ func (uc *UserController) Insert(c *gin.Context) {
 
 // UpdateUser godoc
 // @Summary      Update a user
-// @Description  Update a user
+// @Description  Update a user. Deprecated, use PATCH /user/me which accepts the same fields.
 // @Tags         users
 // @Security ApiCookieAuth
 // @Produce      json
 // @Param        id   path      string  true  "User ID"
-// @Param        user  body     entity.User  true  "User"
-// @Success      200  {object}  entity.User
+// @Param        user  body     entity.UpdateProfileRequest  true  "User"
+// @Success      200  {object}  entity.ProfileResponse
 // @Router       /user/auth/{id} [put]
 func (uc *UserController) UpdateById(c *gin.Context) {
 	var log = helpers.Logger
@@ -186,44 +204,37 @@ func (uc *UserController) UpdateById(c *gin.Context) {
 		return
 	}
 
-	var user entity.User
-	if err := c.ShouldBindJSON(&user); err != nil {
+	var reqBody entity.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
 		log.Error("Failed to bind JSON: ", err)
 		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
 		return
 	}
 
-	if err := user.Validate(false); err != nil {
+	if err := reqBody.Validate(); err != nil {
 		log.Error("Failed to validate user: ", err)
 		response.ResponseError(c, http.StatusBadRequest, err)
 		return
 	}
 
-	err := uc.userService.UpdateById(c.Request.Context(), id, &user)
+	user, err := uc.accountService.UpdateProfile(c.Request.Context(), id, reqBody)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error(fmt.Errorf("user with id %s not found", id))
-			response.ResponseError(c, http.StatusNotFound, "User not found")
-			return
-		}
-
 		log.Error(fmt.Errorf("failed to update user by id %s: %v", id, err))
-		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		respondProfileError(c, err, "Failed to update user by id")
+		return
 	}
 
-	user.Password = ""
-
-	response.ResponseSuccess(c, http.StatusOK, user, nil, "Success to update user by id")
+	response.ResponseSuccess(c, http.StatusOK, user.ToProfileResponse(), nil, "Success to update user by id")
 }
 
 // DeleteUser godoc
 // @Summary      Delete a user
-// @Description  Delete a user
+// @Description  Schedule the account for erasure. Personal data is anonymised after the grace period, rentals and payments are kept for accounting.
 // @Tags         users
 // @Security ApiCookieAuth
 // @Produce      json
 // @Param        id   path      string  true  "User ID"
-// @Success      200  {object}  entity.User
+// @Success      202  {object}  entity.ErasureResponse
 // @Router       /user/auth/{id} [delete]
 func (uc *UserController) DeleteById(c *gin.Context) {
 	var log = helpers.Logger
@@ -255,7 +266,7 @@ func (uc *UserController) DeleteById(c *gin.Context) {
 		return
 	}
 
-	err := uc.userService.DeleteById(c.Request.Context(), id)
+	data, err := uc.privacyService.RequestErasure(c.Request.Context(), id, c.GetString("session_id"))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Error(fmt.Errorf("user with id %s not found", id))
@@ -264,10 +275,11 @@ func (uc *UserController) DeleteById(c *gin.Context) {
 		}
 
 		log.Error(fmt.Errorf("failed to delete user by id %s: %v", id, err))
-		response.ResponseError(c, http.StatusInternalServerError, "Failed to delete user by id")
+		respondErasureError(c, err, "Failed to delete user by id")
+		return
 	}
 
-	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success to delete user by id")
+	response.ResponseSuccess(c, http.StatusAccepted, data, nil, "Account is scheduled for deletion")
 }
 
 // Login godoc
@@ -276,6 +288,7 @@ func (uc *UserController) DeleteById(c *gin.Context) {
 // @Tags         users
 // @Produce      json
 // @Param        user  body      entity.UserLoginRequest  true  "User"
+// @Param        X-Auth-Mode  header  string  false  "Set to bearer to receive tokens in the response body"
 // @Success      200  {object}  entity.User
 // @Router       /user/auth/login [post]
 func (uc *UserController) Login(c *gin.Context) {
@@ -288,17 +301,97 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
-	user, userToken, err := uc.userService.Login(c.Request.Context(), userLoginRequest.Email, userLoginRequest.Password)
+	result, err := uc.userService.Login(c.Request.Context(), userLoginRequest.Email, userLoginRequest.Password, sessionClient(c))
 	if err != nil {
 		log.Error("Failed to login: ", err)
-		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		respondLoginError(c, err)
 		return
 	}
 
-	c.SetCookie("access_token", userToken.AccessToken, 24*60*60, "/", "localhost", false, true)
-	user.Password = ""
+	if result.MFARequired {
+		response.ResponseSuccess(c, http.StatusOK, entity.MFAPendingResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAToken,
+			ExpiresAt:   result.MFATokenExpiresAt,
+		}, nil, "Two-factor authentication required")
+		return
+	}
+
+	result.User.Password = ""
+	uc.cookies.respondSession(c, result.UserToken, &result.User, wantsBearerTokens(c), "Success to login")
+}
+
+// VerifyMFA godoc
+// @Summary      Verify two-factor login
+// @Description  Exchange the mfa_token returned by login and a TOTP or recovery code for a session
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.MFALoginRequest  true  "MFA login"
+// @Param        X-Auth-Mode  header  string  false  "Set to bearer to receive tokens in the response body"
+// @Success      200  {object}  entity.User
+// @Router       /user/auth/mfa [post]
+func (uc *UserController) VerifyMFA(c *gin.Context) {
+	var log = helpers.Logger
+
+	var reqBody entity.MFALoginRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		log.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	result, err := uc.userService.CompleteMFALogin(c.Request.Context(), reqBody, sessionClient(c))
+	if err != nil {
+		log.Error("Failed to verify two-factor login: ", err)
+		respondLoginError(c, err)
+		return
+	}
+
+	result.User.Password = ""
+	uc.cookies.respondSession(c, result.UserToken, &result.User, wantsBearerTokens(c), "Success to login")
+}
+
+// Refresh godoc
+// @Summary      Refresh token
+// @Description  Rotate access and refresh token using the refresh_token cookie, or the refresh_token in the body for bearer clients
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      entity.RefreshTokenRequest  false  "Refresh token for bearer clients"
+// @Success      200  {object}  response.APISuccessResponse
+// @Router       /user/auth/refresh [post]
+func (uc *UserController) Refresh(c *gin.Context) {
+	var log = helpers.Logger
 
-	response.ResponseSuccess(c, http.StatusOK, user, nil, "Success to login")
+	// Client bearer mengirim refresh token di body dan menerima token baru di body juga
+	bearer := wantsBearerTokens(c)
+	refreshToken, err := c.Cookie("refresh_token")
+	if err != nil || refreshToken == "" {
+		var reqBody entity.RefreshTokenRequest
+		if bindErr := c.ShouldBindJSON(&reqBody); bindErr == nil && reqBody.RefreshToken != "" {
+			refreshToken = reqBody.RefreshToken
+			bearer = true
+		}
+	}
+
+	if refreshToken == "" {
+		log.Error("Refresh token not found: ", err)
+		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userToken, err := uc.userTokenService.RefreshToken(c.Request.Context(), refreshToken, sessionClient(c))
+	if err != nil {
+		log.Error("Failed to refresh token: ", err)
+		if !bearer {
+			uc.cookies.clearAuthCookies(c)
+		}
+		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	uc.cookies.respondSession(c, userToken, nil, bearer, "Success to refresh token")
 }
 
 // Logout godoc
@@ -326,8 +419,69 @@ func (uc *UserController) Logout(c *gin.Context) {
 		return
 	}
 
-	c.SetCookie("access_token", "", -1, "/", "localhost", false, true)
-	c.SetCookie("refresh_token", "", -1, "/", "localhost", false, true)
+	// Sesi impersonation memakai bearer token, cookie di browser adalah sesi admin sendiri
+	if claims, ok := c.Get("claims"); !ok || !claims.(*helpers.ClaimsToken).IsImpersonation() {
+		uc.cookies.clearAuthCookies(c)
+	}
 
 	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success to logout")
 }
+
+// Unlock godoc
+// @Summary      Unlock user
+// @Description  Clear failed login attempts and lift the lockout of a user
+// @Tags         users
+// @Security ApiCookieAuth
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.APISuccessResponse
+// @Router       /admin/users/{id}/unlock [post]
+func (uc *UserController) Unlock(c *gin.Context) {
+	var log = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		log.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := uc.userService.Unlock(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Error(fmt.Errorf("user with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "User not found")
+			return
+		}
+
+		log.Error(fmt.Errorf("failed to unlock user %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to unlock user")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success to unlock user")
+}
+
+func respondLoginError(c *gin.Context, err error) {
+	var throttledErr *entity.LoginThrottledError
+	switch {
+	case errors.As(err, &throttledErr):
+		c.Header("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds())+1))
+		response.ResponseError(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, entity.ErrInvalidCredentials),
+		errors.Is(err, entity.ErrMFAInvalidCode),
+		errors.Is(err, entity.ErrMFATokenInvalid):
+		response.ResponseError(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, entity.ErrAccountInactive):
+		response.ResponseError(c, http.StatusForbidden, err.Error())
+	default:
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to login")
+	}
+}
+
+func sessionClient(c *gin.Context) entity.SessionClient {
+	return entity.SessionClient{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
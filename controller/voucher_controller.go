@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type IVoucherController interface {
+	FindAll(c *gin.Context)
+	FindById(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateById(c *gin.Context)
+	DeleteById(c *gin.Context)
+	FindRedemptions(c *gin.Context)
+	Report(c *gin.Context)
+}
+
+type VoucherController struct {
+	voucherSvc service.IVoucherService
+}
+
+func NewVoucherController(voucherSvc service.IVoucherService) IVoucherController {
+	return &VoucherController{
+		voucherSvc: voucherSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary Get all vouchers
+// @Description Get all vouchers with their eligible categories and toys
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Produce json
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.Voucher
+// @Router /admin/vouchers [get]
+func (v *VoucherController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := v.voucherSvc.FindAll(c.Request.Context(), limitInt, offset)
+	if err != nil {
+		logger.Error("Failed to find all vouchers: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find all vouchers")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get all vouchers")
+}
+
+// FindById godoc
+// @Summary Get voucher by id
+// @Description Get voucher by id
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "Voucher ID"
+// @Success 200 {object} entity.Voucher
+// @Router /admin/vouchers/{id} [get]
+func (v *VoucherController) FindById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	data, err := v.voucherSvc.FindById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("voucher with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Voucher not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to find voucher by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get voucher")
+}
+
+// Insert godoc
+// @Summary Insert voucher
+// @Description Create a percentage or fixed discount voucher
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param voucher body entity.VoucherRequest true "Voucher"
+// @Success 200 {object} entity.Voucher
+// @Router /admin/vouchers [post]
+func (v *VoucherController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var reqBody entity.VoucherRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	voucher := reqBody.ToVoucher()
+	if err := voucher.Validate(); err != nil {
+		logger.Error("Failed to validate voucher: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := v.voucherSvc.Insert(c.Request.Context(), &voucher)
+	if err != nil {
+		logger.Error("Failed to insert voucher: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, voucher, nil, "Success insert voucher")
+}
+
+// UpdateById godoc
+// @Summary Update voucher by id
+// @Description Update voucher by id
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Voucher ID"
+// @Param voucher body entity.VoucherRequest true "Voucher"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/vouchers/{id} [put]
+func (v *VoucherController) UpdateById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.VoucherRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	voucher := reqBody.ToVoucher()
+	if err := voucher.Validate(); err != nil {
+		logger.Error("Failed to validate voucher: ", err)
+		response.ResponseError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	err := v.voucherSvc.UpdateById(c.Request.Context(), id, &voucher)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("voucher with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Voucher not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update voucher by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success update voucher")
+}
+
+// DeleteById godoc
+// @Summary Delete voucher by id
+// @Description Delete voucher by id
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Param id path string true "Voucher ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /admin/vouchers/{id} [delete]
+func (v *VoucherController) DeleteById(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := v.voucherSvc.DeleteById(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("voucher with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Voucher not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to delete voucher by id %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success delete voucher")
+}
+
+// FindRedemptions godoc
+// @Summary Get voucher redemptions
+// @Description Get redemption history of a voucher
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "Voucher ID"
+// @Param page query string false "Page"
+// @Param limit query string false "Limit"
+// @Success 200 {array} entity.VoucherRedemption
+// @Router /admin/vouchers/{id}/redemptions [get]
+func (v *VoucherController) FindRedemptions(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var page = c.DefaultQuery("page", "1")
+	var pageInt = helpers.ParseToInt(page)
+
+	var limit = c.DefaultQuery("limit", "10")
+	var limitInt = helpers.ParseToInt(limit)
+
+	var offset = (pageInt - 1) * limitInt
+
+	data, totalData, err := v.voucherSvc.FindRedemptions(c.Request.Context(), id, limitInt, offset)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to find redemptions of voucher %s: %v", id, err))
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find voucher redemptions")
+		return
+	}
+
+	metaData := response.Page{
+		Limit:     limitInt,
+		Total:     int(totalData),
+		Page:      pageInt,
+		TotalPage: int(totalData) / limitInt,
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, metaData, "Success get voucher redemptions")
+}
+
+// Report godoc
+// @Summary Voucher redemption report
+// @Description Summary of usage, unique users and total discount per voucher
+// @Tags Voucher
+// @Security ApiCookieAuth
+// @Produce json
+// @Param from query string false "Redeemed from (YYYY-MM-DD)"
+// @Param to query string false "Redeemed until (YYYY-MM-DD)"
+// @Success 200 {array} entity.VoucherReport
+// @Router /admin/vouchers/report [get]
+func (v *VoucherController) Report(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			logger.Error("Invalid from date: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Invalid from date, use YYYY-MM-DD")
+			return
+		}
+		from = &date
+	}
+	if value := c.Query("to"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			logger.Error("Invalid to date: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Invalid to date, use YYYY-MM-DD")
+			return
+		}
+		endOfDay := date.Add(24*time.Hour - time.Nanosecond)
+		to = &endOfDay
+	}
+
+	data, err := v.voucherSvc.Report(c.Request.Context(), from, to)
+	if err != nil {
+		logger.Error("Failed to build voucher report: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to build voucher report")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get voucher report")
+}
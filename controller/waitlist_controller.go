@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IWaitlistController interface {
+	FindAll(c *gin.Context)
+	FindByToyId(c *gin.Context)
+	Insert(c *gin.Context)
+	Confirm(c *gin.Context)
+	Cancel(c *gin.Context)
+}
+
+type WaitlistController struct {
+	waitlistSvc service.IWaitlistService
+}
+
+func NewWaitlistController(waitlistSvc service.IWaitlistService) IWaitlistController {
+	return &WaitlistController{
+		waitlistSvc: waitlistSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary Get my waitlist
+// @Description Get waitlist entries of the logged in user
+// @Tags Waitlist
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.WaitlistEntry
+// @Router /rental/waitlist [get]
+func (w *WaitlistController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := w.waitlistSvc.FindByUserID(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find waitlist: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find waitlist")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get waitlist")
+}
+
+// FindByToyId godoc
+// @Summary Get waitlist queue of a toy
+// @Description Get waiting and held waitlist entries of a toy in queue order
+// @Tags Waitlist
+// @Security ApiCookieAuth
+// @Produce json
+// @Param toy_id path string true "Toy ID"
+// @Success 200 {array} entity.WaitlistEntry
+// @Router /rental/waitlist/toy/{toy_id} [get]
+func (w *WaitlistController) FindByToyId(c *gin.Context) {
+	var logger = helpers.Logger
+
+	var toyId = c.Param("toy_id")
+	if toyId == "" {
+		logger.Error("Toy id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Toy id is required")
+		return
+	}
+
+	data, err := w.waitlistSvc.FindByToyID(c.Request.Context(), toyId)
+	if err != nil {
+		logger.Error(fmt.Errorf("failed to find waitlist of toy %s: %v", toyId, err))
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find waitlist")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get waitlist")
+}
+
+// Insert godoc
+// @Summary Join waitlist
+// @Description Join the waitlist queue of an unavailable toy for a date range
+// @Tags Waitlist
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param request body entity.JoinWaitlistRequest true "Waitlist"
+// @Success 200 {object} entity.WaitlistEntry
+// @Router /rental/waitlist [post]
+func (w *WaitlistController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	entry, err := w.waitlistSvc.JoinWaitlist(c.Request.Context(), claimsData.UserID, reqBody)
+	if err != nil {
+		logger.Error("Failed to join waitlist: ", err)
+		if errors.Is(err, entity.ErrWaitlistStockAvailable) || errors.Is(err, entity.ErrInvalidReturnDate) {
+			response.ResponseError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, entity.ErrWaitlistAlreadyJoined) {
+			response.ResponseError(c, http.StatusConflict, err.Error())
+			return
+		}
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, entry, nil, "Success join waitlist")
+}
+
+// Confirm godoc
+// @Summary Confirm waitlist hold
+// @Description Confirm a held waitlist entry into a pending rental
+// @Tags Waitlist
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Waitlist ID"
+// @Param request body entity.ConfirmWaitlistRequest false "Confirm"
+// @Success 200 {object} entity.Rental
+// @Router /rental/waitlist/{id}/confirm [post]
+func (w *WaitlistController) Confirm(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	var reqBody entity.ConfirmWaitlistRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			logger.Error("Failed to bind JSON: ", err)
+			response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+			return
+		}
+	}
+
+	rental, err := w.waitlistSvc.ConfirmHold(c.Request.Context(), claimsData.UserID.String(), id, reqBody)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			logger.Error(fmt.Errorf("waitlist with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Waitlist not found")
+		case errors.Is(err, entity.ErrWaitlistNotHeld),
+			errors.Is(err, entity.ErrWaitlistAlreadyClosed),
+			errors.Is(err, entity.ErrWaitlistHoldExpired):
+			logger.Error(fmt.Errorf("failed to confirm waitlist %s: %v", id, err))
+			response.ResponseError(c, http.StatusConflict, err.Error())
+		case errors.Is(err, entity.ErrEmailNotVerified):
+			logger.Error(fmt.Errorf("failed to confirm waitlist %s: %v", id, err))
+			response.ResponseError(c, http.StatusForbidden, err.Error())
+		default:
+			logger.Error(fmt.Errorf("failed to confirm waitlist %s: %v", id, err))
+			respondFulfilmentError(c, err, err.Error())
+		}
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, rental, nil, "Success confirm waitlist")
+}
+
+// Cancel godoc
+// @Summary Cancel waitlist
+// @Description Leave the waitlist queue, a held unit is passed to the next customer
+// @Tags Waitlist
+// @Security ApiCookieAuth
+// @Produce json
+// @Param id path string true "Waitlist ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /rental/waitlist/{id} [delete]
+func (w *WaitlistController) Cancel(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var id = c.Param("id")
+	if id == "" {
+		logger.Error("Id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Id is required")
+		return
+	}
+
+	err := w.waitlistSvc.CancelEntry(c.Request.Context(), claimsData.UserID.String(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			logger.Error(fmt.Errorf("waitlist with id %s not found", id))
+			response.ResponseError(c, http.StatusNotFound, "Waitlist not found")
+		case errors.Is(err, entity.ErrWaitlistAlreadyClosed):
+			logger.Error(fmt.Errorf("failed to cancel waitlist %s: %v", id, err))
+			response.ResponseError(c, http.StatusConflict, err.Error())
+		default:
+			logger.Error(fmt.Errorf("failed to cancel waitlist %s: %v", id, err))
+			response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success cancel waitlist")
+}
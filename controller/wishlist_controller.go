@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"errors"
+	"final-project/entity"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"final-project/utils/response"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type IWishlistController interface {
+	FindAll(c *gin.Context)
+	Insert(c *gin.Context)
+	UpdateNotify(c *gin.Context)
+	DeleteByToyId(c *gin.Context)
+}
+
+type WishlistController struct {
+	wishlistSvc service.IWishlistService
+}
+
+func NewWishlistController(wishlistSvc service.IWishlistService) IWishlistController {
+	return &WishlistController{
+		wishlistSvc: wishlistSvc,
+	}
+}
+
+// FindAll godoc
+// @Summary Get my wishlist
+// @Description Get wishlist of the logged in user
+// @Tags Wishlist
+// @Security ApiCookieAuth
+// @Produce json
+// @Success 200 {array} entity.Wishlist
+// @Router /user/wishlist [get]
+func (w *WishlistController) FindAll(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	data, err := w.wishlistSvc.FindByUserID(c.Request.Context(), claimsData.UserID.String())
+	if err != nil {
+		logger.Error("Failed to find wishlist: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, "Failed to find wishlist")
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, data, nil, "Success get wishlist")
+}
+
+// Insert godoc
+// @Summary Add toy to wishlist
+// @Description Add a toy to wishlist and optionally subscribe to back in stock notification
+// @Tags Wishlist
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param wishlist body entity.AddWishlistRequest true "Wishlist"
+// @Success 200 {object} entity.Wishlist
+// @Router /user/wishlist [post]
+func (w *WishlistController) Insert(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var reqBody entity.AddWishlistRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	wishlist, err := w.wishlistSvc.AddToWishlist(c.Request.Context(), claimsData.UserID, reqBody)
+	if err != nil {
+		logger.Error("Failed to add toy to wishlist: ", err)
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, wishlist, nil, "Success add toy to wishlist")
+}
+
+// UpdateNotify godoc
+// @Summary Update back in stock notification
+// @Description Subscribe or unsubscribe back in stock notification for a wishlist toy
+// @Tags Wishlist
+// @Security ApiCookieAuth
+// @Accept json
+// @Produce json
+// @Param toy_id path string true "Toy ID"
+// @Param request body entity.UpdateWishlistNotifyRequest true "Notify me"
+// @Success 200 {object} entity.Wishlist
+// @Router /user/wishlist/{toy_id}/notify [put]
+func (w *WishlistController) UpdateNotify(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var toyId = c.Param("toy_id")
+	if toyId == "" {
+		logger.Error("Toy id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Toy id is required")
+		return
+	}
+
+	var reqBody entity.UpdateWishlistNotifyRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		logger.Error("Failed to bind JSON: ", err)
+		response.ResponseError(c, http.StatusBadRequest, "Failed to bind JSON")
+		return
+	}
+
+	wishlist, err := w.wishlistSvc.UpdateNotifyMe(c.Request.Context(), claimsData.UserID.String(), toyId, reqBody.NotifyMe)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("wishlist for toy %s not found", toyId))
+			response.ResponseError(c, http.StatusNotFound, "Wishlist not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to update wishlist for toy %s: %v", toyId, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, wishlist, nil, "Success update wishlist notification")
+}
+
+// DeleteByToyId godoc
+// @Summary Remove toy from wishlist
+// @Description Remove a toy from wishlist of the logged in user
+// @Tags Wishlist
+// @Security ApiCookieAuth
+// @Produce json
+// @Param toy_id path string true "Toy ID"
+// @Success 200 {object} response.APISuccessResponse
+// @Router /user/wishlist/{toy_id} [delete]
+func (w *WishlistController) DeleteByToyId(c *gin.Context) {
+	var logger = helpers.Logger
+
+	claims, exists := c.Get("claims")
+	if !exists {
+		logger.Error("Claims not found in context")
+		response.ResponseError(c, http.StatusUnauthorized, "Claims not found in context")
+		return
+	}
+
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !ok {
+		logger.Error("Invalid claims type")
+		response.ResponseError(c, http.StatusUnauthorized, "Invalid claims type")
+		return
+	}
+
+	var toyId = c.Param("toy_id")
+	if toyId == "" {
+		logger.Error("Toy id is required")
+		response.ResponseError(c, http.StatusBadRequest, "Toy id is required")
+		return
+	}
+
+	err := w.wishlistSvc.RemoveFromWishlist(c.Request.Context(), claimsData.UserID.String(), toyId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error(fmt.Errorf("wishlist for toy %s not found", toyId))
+			response.ResponseError(c, http.StatusNotFound, "Wishlist not found")
+			return
+		}
+
+		logger.Error(fmt.Errorf("failed to remove toy %s from wishlist: %v", toyId, err))
+		response.ResponseError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.ResponseSuccess(c, http.StatusOK, nil, nil, "Success remove toy from wishlist")
+}
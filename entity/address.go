@@ -0,0 +1,106 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+	"regexp"
+)
+
+// Address adalah alamat tersimpan milik user, dipakai sebagai tujuan pengiriman rental
+type Address struct {
+	BaseEntity
+	UserID        uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Label         string    `gorm:"size:50;not null" json:"label"`
+	RecipientName string    `gorm:"size:255;not null" json:"recipient_name"`
+	PhoneNumber   string    `gorm:"size:20;not null" json:"phone_number"`
+	Street        string    `gorm:"type:text;not null" json:"street"`
+	City          string    `gorm:"size:100;not null" json:"city"`
+	PostalCode    string    `gorm:"size:10" json:"postal_code"`
+	Latitude      float64   `gorm:"not null" json:"latitude"`
+	Longitude     float64   `gorm:"not null" json:"longitude"`
+	Notes         string    `gorm:"type:text" json:"notes"`
+	IsDefault     bool      `gorm:"not null;default:false" json:"is_default"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (*Address) TableName() string {
+	return "addresses"
+}
+
+// FullAddress menggabungkan alamat menjadi satu baris untuk disalin ke data pengiriman
+func (a *Address) FullAddress() string {
+	address := a.Street + ", " + a.City
+	if a.PostalCode != "" {
+		address += " " + a.PostalCode
+	}
+	return address
+}
+
+type AddressRequest struct {
+	Label         string  `json:"label"`
+	RecipientName string  `json:"recipient_name"`
+	PhoneNumber   string  `json:"phone_number"`
+	Street        string  `json:"street"`
+	City          string  `json:"city"`
+	PostalCode    string  `json:"postal_code"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	Notes         string  `json:"notes"`
+	IsDefault     bool    `json:"is_default"`
+}
+
+func (r *AddressRequest) Validate() []string {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Label,
+			validation.Required.Error("Label alamat wajib diisi"),
+			validation.RuneLength(1, 50).Error("Label alamat maksimal 50 karakter"),
+		),
+		validation.Field(&r.RecipientName,
+			validation.Required.Error("Nama penerima wajib diisi"),
+			validation.RuneLength(2, 255).Error("Nama penerima harus antara 2-255 karakter"),
+		),
+		validation.Field(&r.PhoneNumber,
+			validation.Required.Error("Nomor telepon penerima wajib diisi"),
+			validation.RuneLength(7, 20).Error("Nomor telepon harus antara 7-20 karakter"),
+			validation.Match(regexp.MustCompile(`^[0-9+\-\s]*$`)).Error("Nomor telepon hanya boleh berisi angka, +, - dan spasi"),
+		),
+		validation.Field(&r.Street,
+			validation.Required.Error("Alamat wajib diisi"),
+			validation.RuneLength(5, 1000).Error("Alamat harus antara 5-1000 karakter"),
+		),
+		validation.Field(&r.City,
+			validation.Required.Error("Kota wajib diisi"),
+			validation.RuneLength(2, 100).Error("Kota harus antara 2-100 karakter"),
+		),
+		validation.Field(&r.PostalCode,
+			validation.Match(regexp.MustCompile(`^[0-9]{5}$`)).Error("Kode pos harus 5 digit angka"),
+		),
+		validation.Field(&r.Latitude,
+			validation.Min(-90.0).Error("Latitude harus antara -90 dan 90"),
+			validation.Max(90.0).Error("Latitude harus antara -90 dan 90"),
+		),
+		validation.Field(&r.Longitude,
+			validation.Min(-180.0).Error("Longitude harus antara -180 dan 180"),
+			validation.Max(180.0).Error("Longitude harus antara -180 dan 180"),
+		),
+		validation.Field(&r.Notes,
+			validation.RuneLength(0, 500).Error("Catatan alamat maksimal 500 karakter"),
+		),
+	)
+
+	return validationMessages(err)
+}
+
+// Apply menyalin isi request ke alamat. UserID dan status default diatur oleh service.
+func (r *AddressRequest) Apply(address *Address) {
+	address.Label = r.Label
+	address.RecipientName = r.RecipientName
+	address.PhoneNumber = r.PhoneNumber
+	address.Street = r.Street
+	address.City = r.City
+	address.PostalCode = r.PostalCode
+	address.Latitude = r.Latitude
+	address.Longitude = r.Longitude
+	address.Notes = r.Notes
+}
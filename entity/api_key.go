@@ -0,0 +1,122 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+)
+
+// APIKeyPrefix ditambahkan di depan setiap API key agar mudah dikenali, misalnya oleh secret scanner
+const APIKeyPrefix = "trk_"
+
+var (
+	ErrAPIKeyNotFound    = errors.New("API key tidak ditemukan")
+	ErrAPIKeyInvalid     = errors.New("API key tidak valid, sudah dicabut, atau kadaluarsa")
+	ErrAPIKeyRevoked     = errors.New("API key sudah dicabut")
+	ErrAPIKeyRateLimited = errors.New("batas request API key terlampaui")
+	ErrAPIKeyScopeDenied = errors.New("scope tidak dapat diberikan ke API key")
+)
+
+// APIKeyScopes adalah permission yang boleh diberikan ke API key partner.
+// Permission untuk mengelola user, role, dan API key sengaja tidak termasuk.
+var APIKeyScopes = map[string]bool{
+	PermissionVoucherRead:  true,
+	PermissionToyManage:    true,
+	PermissionToyStock:     true,
+	PermissionRentalRead:   true,
+	PermissionRentalReturn: true,
+	PermissionWaitlistRead: true,
+}
+
+// APIKey dipakai integrasi server-to-server. Key hanya ditampilkan sekali saat dibuat,
+// yang disimpan adalah hash SHA-256 dan beberapa karakter awal untuk identifikasi.
+type APIKey struct {
+	BaseEntity
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	KeyPrefix  string     `gorm:"size:20;not null" json:"key_prefix"`
+	KeyHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     []string   `gorm:"type:text;serializer:json" json:"scopes"`
+	RateLimit  int        `gorm:"not null;default:0" json:"rate_limit"`
+	CreatedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `gorm:"type:varchar(45)" json:"last_used_ip"`
+}
+
+func (*APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive mengembalikan true jika key belum dicabut dan belum kadaluarsa
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	RateLimit int        `json:"rate_limit"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *APIKeyRequest) Validate() []string {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Name,
+			validation.Required.Error("Nama API key wajib diisi"),
+			validation.RuneLength(3, 100).Error("Nama API key harus antara 3-100 karakter"),
+		),
+		validation.Field(&r.Scopes,
+			validation.Required.Error("Scope wajib diisi"),
+			validation.Each(validation.By(func(value interface{}) error {
+				scope, _ := value.(string)
+				if !APIKeyScopes[scope] {
+					return ErrAPIKeyScopeDenied
+				}
+				return nil
+			})),
+		),
+		validation.Field(&r.RateLimit,
+			validation.Min(0).Error("Rate limit tidak boleh negatif"),
+			validation.Max(100000).Error("Rate limit maksimal 100000 request per menit"),
+		),
+		validation.Field(&r.ExpiresAt,
+			validation.By(func(value interface{}) error {
+				expiresAt, _ := value.(*time.Time)
+				if expiresAt != nil && !expiresAt.After(time.Now()) {
+					return errors.New("Tanggal kadaluarsa harus di masa depan")
+				}
+				return nil
+			}),
+		),
+	)
+
+	return validationMessages(err)
+}
+
+// APIKeyCreatedResponse berisi key dalam bentuk asli, hanya dikembalikan sekali saat key dibuat
+type APIKeyCreatedResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyUsage berisi status rate limit key pada window saat ini
+type APIKeyUsage struct {
+	Limit     int
+	Remaining int
+	ResetIn   time.Duration
+}
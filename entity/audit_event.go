@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	AuditActionImpersonationStart   = "impersonation.start"
+	AuditActionImpersonationRequest = "impersonation.request"
+
+	AuditOperationCreate = "create"
+	AuditOperationUpdate = "update"
+	AuditOperationDelete = "delete"
+)
+
+// AuditedEntities memetakan tabel yang setiap perubahannya dicatat ke audit log ke nama entity
+// yang dipakai di kolom entity_type dan action, misalnya "toy.update"
+var AuditedEntities = map[string]string{
+	"users":         "user",
+	"roles":         "role",
+	"api_keys":      "api_key",
+	"categories":    "toy_category",
+	"toys":          "toy",
+	"toy_images":    "toy_image",
+	"branches":      "branch",
+	"bundles":       "bundle",
+	"bundle_items":  "bundle_item",
+	"plans":         "plan",
+	"subscriptions": "subscription",
+	"payments":      "payment",
+	"vouchers":      "voucher",
+	"rentals":       "rental",
+	"rental_items":  "rental_item",
+}
+
+// AuditRedactedColumns tidak pernah disimpan isinya di audit log, hanya ditandai jika berubah
+var AuditRedactedColumns = map[string]bool{
+	"password": true,
+	"key_hash": true,
+}
+
+// AuditEvent mencatat aksi yang perlu bisa ditelusuri, seperti perubahan data inti atau request
+// yang dilakukan admin atas nama user lain. ActorID adalah user yang sebenarnya melakukan aksi.
+type AuditEvent struct {
+	BaseEntity
+	ActorID            *uuid.UUID             `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	ImpersonatedUserID *uuid.UUID             `gorm:"type:uuid;index" json:"impersonated_user_id,omitempty"`
+	APIKeyID           *uuid.UUID             `gorm:"type:uuid;index" json:"api_key_id,omitempty"`
+	Action             string                 `gorm:"size:100;not null;index" json:"action"`
+	EntityType         string                 `gorm:"size:50;index:idx_audit_event_entity" json:"entity_type,omitempty"`
+	EntityID           string                 `gorm:"size:100;index:idx_audit_event_entity" json:"entity_id,omitempty"`
+	Before             map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"before,omitempty"`
+	After              map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"after,omitempty"`
+	Method             string                 `gorm:"size:10" json:"method,omitempty"`
+	Path               string                 `gorm:"type:text" json:"path,omitempty"`
+	StatusCode         int                    `json:"status_code,omitempty"`
+	IPAddress          string                 `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent          string                 `gorm:"type:text" json:"user_agent"`
+	RequestID          string                 `gorm:"size:64;index" json:"request_id,omitempty"`
+	OccurredAt         time.Time              `gorm:"not null;index" json:"occurred_at"`
+}
+
+func (*AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditEventFilter berisi filter pencarian audit log, field kosong diabaikan
+type AuditEventFilter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
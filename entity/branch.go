@@ -0,0 +1,57 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"regexp"
+)
+
+// Branch adalah cabang tempat pelanggan mengambil mainan, sekaligus titik asal pengiriman
+type Branch struct {
+	BaseEntity
+	Name         string  `gorm:"size:100;not null" json:"name"`
+	Address      string  `gorm:"type:text;not null" json:"address"`
+	City         string  `gorm:"size:100;not null" json:"city"`
+	PhoneNumber  string  `gorm:"size:20" json:"phone_number"`
+	Latitude     float64 `gorm:"not null" json:"latitude"`
+	Longitude    float64 `gorm:"not null" json:"longitude"`
+	OpeningHours string  `gorm:"size:255" json:"opening_hours"`
+	IsActive     bool    `gorm:"not null;default:true" json:"is_active"`
+}
+
+func (*Branch) TableName() string {
+	return "branches"
+}
+
+func (b *Branch) Validate() []string {
+	err := validation.ValidateStruct(b,
+		validation.Field(&b.Name,
+			validation.Required.Error("Nama cabang wajib diisi"),
+			validation.RuneLength(3, 100).Error("Nama cabang harus antara 3-100 karakter"),
+		),
+		validation.Field(&b.Address,
+			validation.Required.Error("Alamat cabang wajib diisi"),
+			validation.RuneLength(5, 1000).Error("Alamat cabang harus antara 5-1000 karakter"),
+		),
+		validation.Field(&b.City,
+			validation.Required.Error("Kota wajib diisi"),
+			validation.RuneLength(2, 100).Error("Kota harus antara 2-100 karakter"),
+		),
+		validation.Field(&b.PhoneNumber,
+			validation.RuneLength(7, 20).Error("Nomor telepon harus antara 7-20 karakter"),
+			validation.Match(regexp.MustCompile(`^[0-9+\-\s]*$`)).Error("Nomor telepon hanya boleh berisi angka, +, - dan spasi"),
+		),
+		validation.Field(&b.Latitude,
+			validation.Min(-90.0).Error("Latitude harus antara -90 dan 90"),
+			validation.Max(90.0).Error("Latitude harus antara -90 dan 90"),
+		),
+		validation.Field(&b.Longitude,
+			validation.Min(-180.0).Error("Longitude harus antara -180 dan 180"),
+			validation.Max(180.0).Error("Longitude harus antara -180 dan 180"),
+		),
+		validation.Field(&b.OpeningHours,
+			validation.RuneLength(0, 255).Error("Jam buka maksimal 255 karakter"),
+		),
+	)
+
+	return validationMessages(err)
+}
@@ -0,0 +1,105 @@
+package entity
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+)
+
+type Bundle struct {
+	BaseEntity
+	Name           string  `gorm:"size:255;not null" json:"name"`
+	Description    string  `gorm:"type:text" json:"description"`
+	Price          float64 `gorm:"type:decimal(10,2);not null" json:"price"`
+	IsAvailable    bool    `gorm:"default:true" json:"is_available"`
+	AvailableStock int     `gorm:"-" json:"available_stock"`
+
+	Items []BundleItem `gorm:"foreignKey:BundleID" json:"items"`
+}
+
+func (*Bundle) TableName() string {
+	return "bundles"
+}
+
+// CalculateAvailability menghitung jumlah bundle yang bisa disewa berdasarkan stok komponen paling sedikit
+func (b *Bundle) CalculateAvailability() int {
+	if !b.IsAvailable || len(b.Items) == 0 {
+		return 0
+	}
+
+	available := -1
+	for _, item := range b.Items {
+		if item.Quantity <= 0 || !item.Toy.IsAvailable {
+			return 0
+		}
+
+		componentAvailable := item.Toy.Stock / item.Quantity
+		if available == -1 || componentAvailable < available {
+			available = componentAvailable
+		}
+	}
+
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+func (b *Bundle) Validate() []string {
+	err := validation.ValidateStruct(b,
+		validation.Field(&b.Name,
+			validation.Required.Error("Nama bundle wajib diisi"),
+			validation.RuneLength(3, 255).Error("Nama bundle harus antara 3-255 karakter"),
+		),
+		validation.Field(&b.Description,
+			validation.When(b.Description != "", validation.RuneLength(10, 5000).Error("Deskripsi harus antara 10-5000 karakter")),
+		),
+		validation.Field(&b.Price,
+			validation.Required.Error("Harga bundle wajib diisi"),
+			validation.Min(0.0).Error("Harga bundle tidak boleh negatif"),
+		),
+		validation.Field(&b.Items,
+			validation.Required.Error("Komponen bundle wajib diisi"),
+			validation.Each(validation.By(func(value interface{}) error {
+				item, _ := value.(BundleItem)
+				return validation.ValidateStruct(&item,
+					validation.Field(&item.ToyID,
+						validation.Required.Error("ID mainan wajib diisi"),
+					),
+					validation.Field(&item.Quantity,
+						validation.Required.Error("Jumlah wajib diisi"),
+						validation.Min(1).Error("Jumlah minimal 1"),
+					),
+				)
+			})),
+		),
+	)
+
+	if err == nil {
+		return nil
+	}
+
+	var errorMessages []string
+	if validationErrors, ok := err.(validation.Errors); ok {
+		for _, fieldErr := range validationErrors {
+			errorMessages = append(errorMessages, fieldErr.Error())
+		}
+	} else {
+		errorMessages = append(errorMessages, err.Error())
+	}
+
+	return errorMessages
+}
+
+type BundleItem struct {
+	BaseEntity
+	BundleID uuid.UUID `gorm:"type:uuid;not null;index" json:"bundle_id"`
+	ToyID    uuid.UUID `gorm:"type:uuid;not null" json:"toy_id"`
+	Quantity int       `gorm:"not null;default:1" json:"quantity"`
+
+	Bundle Bundle `gorm:"foreignKey:BundleID" json:"-"`
+	Toy    Toy    `gorm:"foreignKey:ToyID" json:"toy"`
+}
+
+func (*BundleItem) TableName() string {
+	return "bundle_items"
+}
@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+	"time"
+)
+
+const (
+	DeliveryStopDropOff    = "drop_off"
+	DeliveryStopCollection = "collection"
+)
+
+const (
+	DeliveryStopStatusPending   = "pending"
+	DeliveryStopStatusDelivered = "delivered"
+	DeliveryStopStatusCollected = "collected"
+	DeliveryStopStatusFailed    = "failed"
+)
+
+const (
+	DeliveryRouteStatusPlanned   = "planned"
+	DeliveryRouteStatusCompleted = "completed"
+)
+
+const (
+	RouteAlgorithmNearestNeighbour = "nearest_neighbour"
+	RouteAlgorithmTwoOpt           = "two_opt"
+)
+
+var (
+	ErrNoDeliveryJobs           = errors.New("tidak ada pengiriman atau penjemputan yang perlu direncanakan pada tanggal tersebut")
+	ErrDeliveryStopCompleted    = errors.New("status titik pengiriman sudah final dan tidak dapat diubah")
+	ErrDeliveryStopStatusWrong  = errors.New("status tidak sesuai dengan jenis titik, pengiriman memakai delivered dan penjemputan memakai collected")
+	ErrDeliveryFailureReason    = errors.New("alasan gagal wajib diisi")
+	ErrDeliveryRouteDateInvalid = errors.New("tanggal rute harus berformat YYYY-MM-DD")
+)
+
+// DeliveryRoute adalah manifest satu driver untuk satu cabang pada satu hari. RouteDate adalah
+// tengah malam di zona waktu pengiriman, urutan titik disimpan di DeliveryStop.Sequence dan rute
+// dimulai serta diakhiri di cabang.
+type DeliveryRoute struct {
+	BaseEntity
+	BranchID   uuid.UUID `gorm:"type:uuid;not null;index:idx_delivery_routes_branch_date" json:"branch_id"`
+	RouteDate  time.Time `gorm:"not null;index:idx_delivery_routes_branch_date" json:"route_date"`
+	Number     int       `gorm:"not null" json:"number"`
+	Algorithm  string    `gorm:"size:30;not null" json:"algorithm"`
+	DistanceKm float64   `gorm:"type:decimal(8,2);default:0" json:"distance_km"`
+	Status     string    `gorm:"size:20;not null;default:planned;check:status IN ('planned', 'completed')" json:"status"`
+
+	Branch Branch         `gorm:"foreignKey:BranchID" json:"branch,omitempty"`
+	Stops  []DeliveryStop `gorm:"foreignKey:RouteID" json:"stops,omitempty"`
+}
+
+func (*DeliveryRoute) TableName() string {
+	return "delivery_routes"
+}
+
+// DeliveryStop adalah satu pengantaran atau penjemputan di dalam rute. Alamat dan jadwal
+// disalin dari RentalFulfilment agar manifest tetap utuh untuk driver.
+type DeliveryStop struct {
+	BaseEntity
+	RouteID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"route_id"`
+	RentalID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"rental_id"`
+	Type           string     `gorm:"size:20;not null;check:type IN ('drop_off', 'collection')" json:"type"`
+	Sequence       int        `gorm:"not null" json:"sequence"`
+	Address        string     `gorm:"type:text" json:"address"`
+	RecipientName  string     `gorm:"size:255" json:"recipient_name"`
+	RecipientPhone string     `gorm:"size:20" json:"recipient_phone"`
+	AddressNotes   string     `gorm:"type:text" json:"address_notes,omitempty"`
+	Latitude       float64    `gorm:"not null" json:"latitude"`
+	Longitude      float64    `gorm:"not null" json:"longitude"`
+	SlotStart      *time.Time `json:"slot_start,omitempty"`
+	SlotEnd        *time.Time `json:"slot_end,omitempty"`
+	Status         string     `gorm:"size:20;not null;default:pending;check:status IN ('pending', 'delivered', 'collected', 'failed')" json:"status"`
+	FailureReason  string     `gorm:"type:text" json:"failure_reason,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+func (*DeliveryStop) TableName() string {
+	return "delivery_stops"
+}
+
+// IsFinal menandakan driver sudah melaporkan hasil titik ini
+func (s *DeliveryStop) IsFinal() bool {
+	return s.Status != DeliveryStopStatusPending
+}
+
+// DeliveryJob adalah pengantaran atau penjemputan yang belum masuk rute mana pun
+type DeliveryJob struct {
+	RentalID       uuid.UUID
+	Type           string
+	Address        string
+	RecipientName  string
+	RecipientPhone string
+	AddressNotes   string
+	Latitude       float64
+	Longitude      float64
+	SlotStart      *time.Time
+	SlotEnd        *time.Time
+}
+
+type PlanDeliveryRoutesRequest struct {
+	BranchID  uuid.UUID `json:"branch_id" binding:"required"`
+	Date      string    `json:"date" binding:"required"`
+	Drivers   int       `json:"drivers"`
+	Algorithm string    `json:"algorithm"`
+}
+
+func (r *PlanDeliveryRoutesRequest) Validate() []string {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Date,
+			validation.Required.Error("Tanggal rute wajib diisi"),
+			validation.Date("2006-01-02").Error(ErrDeliveryRouteDateInvalid.Error()),
+		),
+		validation.Field(&r.Drivers,
+			validation.Min(0).Error("Jumlah driver harus antara 1-20"),
+			validation.Max(20).Error("Jumlah driver harus antara 1-20"),
+		),
+		validation.Field(&r.Algorithm,
+			validation.In(RouteAlgorithmNearestNeighbour, RouteAlgorithmTwoOpt).Error("Algoritma harus nearest_neighbour atau two_opt"),
+		),
+	)
+
+	return validationMessages(err)
+}
+
+type UpdateDeliveryStopRequest struct {
+	Status        string `json:"status" binding:"required"`
+	FailureReason string `json:"failure_reason"`
+}
+
+func (r *UpdateDeliveryStopRequest) Validate() []string {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Status,
+			validation.Required.Error("Status wajib diisi"),
+			validation.In(DeliveryStopStatusDelivered, DeliveryStopStatusCollected, DeliveryStopStatusFailed).Error("Status harus delivered, collected, atau failed"),
+		),
+		validation.Field(&r.FailureReason,
+			validation.RuneLength(0, 500).Error("Alasan gagal maksimal 500 karakter"),
+		),
+	)
+
+	return validationMessages(err)
+}
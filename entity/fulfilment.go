@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofrs/uuid/v5"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FulfilmentMethodPickup   = "pickup"
+	FulfilmentMethodDelivery = "delivery"
+)
+
+var (
+	ErrFulfilmentMethodInvalid  = errors.New("metode pengambilan harus pickup atau delivery")
+	ErrBranchRequired           = errors.New("cabang pengambilan wajib dipilih")
+	ErrBranchUnavailable        = errors.New("cabang tidak ditemukan atau sedang tidak aktif")
+	ErrAddressRequired          = errors.New("alamat pengiriman wajib dipilih")
+	ErrAddressNotFound          = errors.New("alamat tidak ditemukan")
+	ErrOutsideDeliveryArea      = errors.New("alamat berada di luar jangkauan pengiriman")
+	ErrDeliverySlotRequired     = errors.New("jadwal pengiriman wajib dipilih")
+	ErrDeliverySlotInvalid      = errors.New("jadwal pengiriman tidak tersedia, pilih salah satu slot pada tanggal rental")
+	ErrReturnPickupSlotInvalid  = errors.New("jadwal penjemputan tidak tersedia, pilih salah satu slot pada tanggal pengembalian")
+	ErrDeliverySlotFull         = errors.New("slot yang dipilih sudah penuh, pilih slot lain")
+	ErrReturnPickupNotAvailable = errors.New("penjemputan pengembalian hanya tersedia untuk rental yang dikirim")
+)
+
+// RentalFulfilment mencatat bagaimana mainan sampai ke pelanggan dan kembali ke cabang. Alamat
+// pengiriman disalin saat checkout agar tidak berubah jika alamat di address book diedit.
+type RentalFulfilment struct {
+	BaseEntity
+	RentalID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"rental_id"`
+	Method          string     `gorm:"size:20;not null;check:method IN ('pickup', 'delivery')" json:"method"`
+	BranchID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"branch_id"`
+	AddressID       *uuid.UUID `gorm:"type:uuid" json:"address_id,omitempty"`
+	DeliveryAddress string     `gorm:"type:text" json:"delivery_address,omitempty"`
+	RecipientName   string     `gorm:"size:255" json:"recipient_name,omitempty"`
+	RecipientPhone  string     `gorm:"size:20" json:"recipient_phone,omitempty"`
+	AddressNotes    string     `gorm:"type:text" json:"address_notes,omitempty"`
+	Latitude        *float64   `json:"latitude,omitempty"`
+	Longitude       *float64   `json:"longitude,omitempty"`
+	DistanceKm      float64    `gorm:"type:decimal(8,2);default:0" json:"distance_km,omitempty"`
+	// Slot disimpan sebagai waktu mulai dan selesai, kapasitas dihitung per cabang per waktu mulai
+	DeliverySlotStart     *time.Time `gorm:"index" json:"delivery_slot_start,omitempty"`
+	DeliverySlotEnd       *time.Time `json:"delivery_slot_end,omitempty"`
+	ReturnPickupSlotStart *time.Time `gorm:"index" json:"return_pickup_slot_start,omitempty"`
+	ReturnPickupSlotEnd   *time.Time `json:"return_pickup_slot_end,omitempty"`
+	// Diisi dari rute pengiriman saat driver melaporkan titik selesai
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CollectedAt *time.Time `json:"collected_at,omitempty"`
+	// Kapasitas per slot saat checkout, diperiksa ulang di dalam transaksi penyimpanan rental
+	SlotCapacity int `gorm:"-" json:"-"`
+
+	Branch Branch `gorm:"foreignKey:BranchID" json:"branch,omitempty"`
+}
+
+func (*RentalFulfilment) TableName() string {
+	return "rental_fulfilments"
+}
+
+// CreateFulfilmentRequest dikirim bersama checkout. DeliverySlot dan ReturnPickupSlot adalah
+// waktu mulai salah satu slot yang dikembalikan endpoint slot pengiriman.
+type CreateFulfilmentRequest struct {
+	Method           string     `json:"method"`
+	BranchID         *uuid.UUID `json:"branch_id"`
+	AddressID        *uuid.UUID `json:"address_id"`
+	DeliverySlot     *time.Time `json:"delivery_slot"`
+	ReturnPickupSlot *time.Time `json:"return_pickup_slot"`
+}
+
+// DeliveryZone adalah tarif pengiriman untuk jarak sampai MaxDistanceKm dari cabang
+type DeliveryZone struct {
+	MaxDistanceKm float64 `json:"max_distance_km"`
+	Fee           float64 `json:"fee"`
+}
+
+// ParseDeliveryZone membaca zona dengan format "<jarak maksimal km>:<tarif>", misalnya "5:10000"
+func ParseDeliveryZone(value string) (DeliveryZone, error) {
+	distance, fee, ok := strings.Cut(value, ":")
+	if !ok {
+		return DeliveryZone{}, fmt.Errorf("zona pengiriman %q harus berformat jarak_km:tarif", value)
+	}
+
+	maxDistance, err := strconv.ParseFloat(strings.TrimSpace(distance), 64)
+	if err != nil || maxDistance <= 0 {
+		return DeliveryZone{}, fmt.Errorf("jarak zona pengiriman %q tidak valid", value)
+	}
+
+	amount, err := strconv.ParseFloat(strings.TrimSpace(fee), 64)
+	if err != nil || amount < 0 {
+		return DeliveryZone{}, fmt.Errorf("tarif zona pengiriman %q tidak valid", value)
+	}
+
+	return DeliveryZone{MaxDistanceKm: maxDistance, Fee: amount}, nil
+}
+
+// DeliverySlotWindow adalah jendela waktu harian, Start dan End dihitung dari tengah malam
+type DeliverySlotWindow struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseDeliverySlotWindow membaca slot dengan format "HH:MM-HH:MM", misalnya "09:00-12:00"
+func ParseDeliverySlotWindow(value string) (DeliverySlotWindow, error) {
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return DeliverySlotWindow{}, fmt.Errorf("slot pengiriman %q harus berformat HH:MM-HH:MM", value)
+	}
+
+	parse := func(clock string) (time.Duration, error) {
+		parsed, err := time.Parse("15:04", strings.TrimSpace(clock))
+		if err != nil {
+			return 0, fmt.Errorf("jam slot pengiriman %q tidak valid", value)
+		}
+		return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+	}
+
+	window := DeliverySlotWindow{}
+	var err error
+	if window.Start, err = parse(start); err != nil {
+		return DeliverySlotWindow{}, err
+	}
+	if window.End, err = parse(end); err != nil {
+		return DeliverySlotWindow{}, err
+	}
+	if window.End <= window.Start {
+		return DeliverySlotWindow{}, fmt.Errorf("slot pengiriman %q harus berakhir setelah waktu mulai", value)
+	}
+
+	return window, nil
+}
+
+// DeliverySlot adalah slot pada tanggal tertentu beserta sisa kapasitasnya
+type DeliverySlot struct {
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Remaining int       `json:"remaining"`
+	Available bool      `json:"available"`
+}
+
+// DeliveryQuote adalah perkiraan biaya pengiriman ke sebuah alamat. Tarif berlaku per
+// perjalanan, penjemputan saat pengembalian dikenakan tarif yang sama.
+type DeliveryQuote struct {
+	BranchID        uuid.UUID `json:"branch_id"`
+	BranchName      string    `json:"branch_name"`
+	DistanceKm      float64   `json:"distance_km"`
+	DeliveryFee     float64   `json:"delivery_fee"`
+	ReturnPickupFee float64   `json:"return_pickup_fee"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrImpersonateSelf         = errors.New("tidak dapat melakukan impersonate terhadap akun sendiri")
+	ErrImpersonationNotAllowed = errors.New("user dengan akses admin tidak dapat di-impersonate")
+	ErrImpersonationRestricted = errors.New("aksi ini tidak dapat dilakukan selama impersonation")
+)
+
+// ImpersonationResponse berisi access token untuk melihat aplikasi sebagai user lain. Token hanya
+// dikirim lewat header Authorization agar tidak menimpa cookie sesi admin, dan tidak bisa di-refresh.
+type ImpersonationResponse struct {
+	TokenType      string    `json:"token_type"`
+	AccessToken    string    `json:"access_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	ImpersonatorID uuid.UUID `json:"impersonator_id"`
+	User           User      `json:"user"`
+}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	LoginAttemptScopeAccount = "account"
+	LoginAttemptScopeIP      = "ip"
+)
+
+var (
+	ErrInvalidCredentials   = errors.New("email/username atau password salah")
+	ErrAccountInactive      = errors.New("akun tidak aktif, silakan hubungi admin")
+	ErrTooManyLoginAttempts = errors.New("terlalu banyak percobaan login gagal")
+)
+
+// LoginAttempt mencatat percobaan login gagal per akun atau per alamat IP.
+// Key untuk scope account adalah ID user, atau identifier yang dipakai jika user tidak ditemukan,
+// sehingga akun yang tidak ada diperlakukan sama dengan akun yang ada.
+type LoginAttempt struct {
+	BaseEntity
+	Scope        string     `gorm:"size:20;not null;uniqueIndex:idx_login_attempt_scope_key;check:scope IN ('account', 'ip')" json:"scope"`
+	Key          string     `gorm:"size:255;not null;uniqueIndex:idx_login_attempt_scope_key" json:"key"`
+	FailedCount  int        `gorm:"not null;default:0" json:"failed_count"`
+	LastFailedAt time.Time  `gorm:"not null" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
+
+func (*LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// LoginThrottledError dikembalikan saat login ditolak karena delay atau lockout masih berlaku
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, silakan coba lagi dalam %d detik", ErrTooManyLoginAttempts.Error(), int(e.RetryAfter.Seconds())+1)
+}
+
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrTooManyLoginAttempts
+}
@@ -7,10 +7,11 @@ import (
 )
 
 const (
-	PaymentTypeRental    = "rental"
-	PaymentTypeLateFee   = "late_fee"
-	PaymentTypeDamageFee = "damage_fee"
-	PaymentTypeCombined  = "combined"
+	PaymentTypeRental       = "rental"
+	PaymentTypeLateFee      = "late_fee"
+	PaymentTypeDamageFee    = "damage_fee"
+	PaymentTypeCombined     = "combined"
+	PaymentTypeSubscription = "subscription"
 )
 
 const (
@@ -27,9 +28,10 @@ const (
 
 type Payment struct {
 	BaseEntity
-	RentalID          uuid.UUID  `gorm:"type:uuid;not null" json:"rental_id"`
-	TransactionID     string     `gorm:"size:100;uniqueIndex" json:"transaction_id"`
-	PaymentType       string     `gorm:"size:50;not null;check:payment_type IN ('rental', 'late_fee', 'damage_fee', 'combined')" json:"payment_type"`
+	RentalID          *uuid.UUID `gorm:"type:uuid" json:"rental_id,omitempty"`
+	SubscriptionID    *uuid.UUID `gorm:"type:uuid;index" json:"subscription_id,omitempty"`
+	TransactionID     *string    `gorm:"size:100;uniqueIndex" json:"transaction_id,omitempty"`
+	PaymentType       string     `gorm:"size:50;not null;check:payment_type IN ('rental', 'late_fee', 'damage_fee', 'combined', 'subscription')" json:"payment_type"`
 	GrossAmount       float64    `gorm:"type:decimal(10,2);not null" json:"gross_amount"`
 	SnapToken         string     `gorm:"type:text" json:"snap_token"`
 	SnapURL           string     `gorm:"type:text" json:"snap_url"`
@@ -40,7 +42,22 @@ type Payment struct {
 	VANumber          string     `gorm:"size:100" json:"va_number"`
 	FraudStatus       string     `gorm:"size:50" json:"fraud_status"`
 
-	Rental Rental `gorm:"foreignKey:RentalID" json:"-"`
+	Rental       Rental       `gorm:"foreignKey:RentalID" json:"-"`
+	Subscription Subscription `gorm:"foreignKey:SubscriptionID" json:"-"`
+}
+
+// IsSettled menandakan pembayaran sudah diterima
+func (p *Payment) IsSettled() bool {
+	return p.TransactionStatus == TransactionStatusSettlement || p.TransactionStatus == TransactionStatusCapture
+}
+
+// IsFailed menandakan pembayaran ditolak, dibatalkan, atau kadaluarsa sehingga tidak akan lunas
+func (p *Payment) IsFailed() bool {
+	switch p.TransactionStatus {
+	case TransactionStatusDeny, TransactionStatusCancel, TransactionStatusExpire, TransactionStatusFailure:
+		return true
+	}
+	return false
 }
 
 func (*Payment) TableName() string {
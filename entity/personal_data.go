@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrErasureAlreadyRequested   = errors.New("penghapusan akun sudah dijadwalkan")
+	ErrErasureNotRequested       = errors.New("tidak ada penghapusan akun yang dijadwalkan")
+	ErrErasureOpenRentals        = errors.New("akun masih memiliki rental yang belum selesai")
+	ErrErasureActiveSubscription = errors.New("batalkan langganan terlebih dahulu sebelum menghapus akun")
+	ErrErasureWaitlistHold       = errors.New("akun masih memiliki hold waitlist yang belum dikonfirmasi")
+)
+
+// ErasedUserName menggantikan nama user yang datanya sudah dianonimkan
+const ErasedUserName = "Deleted user"
+
+// PersonalDataExport berisi seluruh data pribadi user, setiap field ditulis sebagai file JSON terpisah
+type PersonalDataExport struct {
+	Profile            User
+	Addresses          []Address
+	Rentals            []Rental
+	Payments           []Payment
+	Subscriptions      []Subscription
+	Sessions           []SessionResponse
+	Identities         []UserIdentity
+	Wishlist           []Wishlist
+	Waitlist           []WaitlistEntry
+	VoucherRedemptions []VoucherRedemption
+}
+
+// ErasureResponse menjelaskan kapan data akun akan dianonimkan. Sebelum ErasesAt user masih bisa
+// login dan membatalkan penghapusan.
+type ErasureResponse struct {
+	RequestedAt time.Time `json:"requested_at"`
+	ErasesAt    time.Time `json:"erases_at"`
+}
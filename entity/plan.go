@@ -0,0 +1,77 @@
+package entity
+
+import validation "github.com/go-ozzo/ozzo-validation/v4"
+
+type Plan struct {
+	BaseEntity
+	Name              string  `gorm:"size:100;not null" json:"name"`
+	Description       string  `gorm:"type:text" json:"description"`
+	MonthlyFee        float64 `gorm:"type:decimal(10,2);not null" json:"monthly_fee"`
+	MaxConcurrentToys int     `gorm:"not null" json:"max_concurrent_toys"`
+	SwapsPerMonth     int     `gorm:"not null" json:"swaps_per_month"`
+	MaxToyValue       float64 `gorm:"type:decimal(10,2);not null;default:0" json:"max_toy_value"`
+	IsActive          bool    `gorm:"default:true" json:"is_active"`
+}
+
+func (*Plan) TableName() string {
+	return "plans"
+}
+
+// AllowsToy memeriksa apakah nilai penggantian mainan masih dalam tier paket (0 berarti tanpa batas)
+func (p *Plan) AllowsToy(toy Toy) bool {
+	return p.MaxToyValue <= 0 || toy.ReplacementPrice <= p.MaxToyValue
+}
+
+// CheckLimits memeriksa batas mainan bersamaan dan jatah tukar bulanan untuk rental baru
+// berisi requestedToys unit
+func (p *Plan) CheckLimits(activeToys int, requestedToys int, swapsUsed int) error {
+	if activeToys+requestedToys > p.MaxConcurrentToys {
+		return ErrPlanConcurrentLimitReached
+	}
+	if swapsUsed >= p.SwapsPerMonth {
+		return ErrPlanSwapLimitReached
+	}
+	return nil
+}
+
+func (p *Plan) Validate() []string {
+	err := validation.ValidateStruct(p,
+		validation.Field(&p.Name,
+			validation.Required.Error("Nama paket wajib diisi"),
+			validation.RuneLength(3, 100).Error("Nama paket harus antara 3-100 karakter"),
+		),
+		validation.Field(&p.Description,
+			validation.When(p.Description != "", validation.RuneLength(10, 5000).Error("Deskripsi harus antara 10-5000 karakter")),
+		),
+		validation.Field(&p.MonthlyFee,
+			validation.Required.Error("Biaya bulanan wajib diisi"),
+			validation.Min(0.0).Error("Biaya bulanan tidak boleh negatif"),
+		),
+		validation.Field(&p.MaxConcurrentToys,
+			validation.Required.Error("Jumlah mainan bersamaan wajib diisi"),
+			validation.Min(1).Error("Jumlah mainan bersamaan minimal 1"),
+		),
+		validation.Field(&p.SwapsPerMonth,
+			validation.Required.Error("Jumlah tukar per bulan wajib diisi"),
+			validation.Min(1).Error("Jumlah tukar per bulan minimal 1"),
+		),
+		validation.Field(&p.MaxToyValue,
+			validation.Min(0.0).Error("Nilai maksimal mainan tidak boleh negatif"),
+		),
+	)
+
+	if err == nil {
+		return nil
+	}
+
+	var errorMessages []string
+	if validationErrors, ok := err.(validation.Errors); ok {
+		for _, fieldErr := range validationErrors {
+			errorMessages = append(errorMessages, fieldErr.Error())
+		}
+	} else {
+		errorMessages = append(errorMessages, err.Error())
+	}
+
+	return errorMessages
+}
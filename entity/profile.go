@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/gofrs/uuid/v5"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrEmailTaken             = errors.New("email sudah terdaftar")
+	ErrUsernameTaken          = errors.New("username sudah terdaftar")
+	ErrProfileNoChanges       = errors.New("tidak ada data profil yang diubah")
+	ErrCurrentPasswordInvalid = errors.New("password saat ini salah")
+	ErrPasswordUnchanged      = errors.New("password baru harus berbeda dari password saat ini")
+)
+
+// UpdateProfileRequest hanya berisi field yang boleh diubah sendiri oleh user. Field yang tidak
+// dikirim dibiarkan, role, status aktif, dan status verifikasi tidak bisa diubah lewat sini.
+type UpdateProfileRequest struct {
+	Email       *string `json:"email"`
+	Username    *string `json:"username"`
+	FullName    *string `json:"full_name"`
+	PhoneNumber *string `json:"phone_number"`
+	Address     *string `json:"address"`
+}
+
+func (r *UpdateProfileRequest) Validate() []string {
+	if r.Email == nil && r.Username == nil && r.FullName == nil && r.PhoneNumber == nil && r.Address == nil {
+		return []string{ErrProfileNoChanges.Error()}
+	}
+
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Email,
+			validation.When(r.Email != nil,
+				validation.Required.Error("Email wajib diisi"),
+				validation.RuneLength(5, 255).Error("Email harus antara 5-255 karakter"),
+				is.Email.Error("Format email tidak valid"),
+			),
+		),
+		validation.Field(&r.Username,
+			validation.When(r.Username != nil,
+				validation.Required.Error("Username wajib diisi"),
+				validation.RuneLength(3, 100).Error("Username harus antara 3-100 karakter"),
+			),
+		),
+		validation.Field(&r.FullName,
+			validation.When(r.FullName != nil,
+				validation.Required.Error("Nama lengkap wajib diisi"),
+				validation.RuneLength(2, 255).Error("Nama lengkap harus antara 2-255 karakter"),
+			),
+		),
+		validation.Field(&r.PhoneNumber,
+			validation.RuneLength(7, 20).Error("Nomor telepon harus antara 7-20 karakter"),
+			validation.Match(regexp.MustCompile(`^[0-9+\-\s]*$`)).Error("Nomor telepon hanya boleh berisi angka, +, - dan spasi"),
+		),
+		validation.Field(&r.Address,
+			validation.RuneLength(5, 1000).Error("Alamat harus antara 5-1000 karakter"),
+		),
+	)
+
+	return validationMessages(err)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// ProfileResponse adalah data akun yang ditampilkan ke pemiliknya sendiri
+type ProfileResponse struct {
+	ID                 uuid.UUID  `json:"id"`
+	Email              string     `json:"email"`
+	Username           string     `json:"username"`
+	FullName           string     `json:"full_name"`
+	PhoneNumber        string     `json:"phone_number"`
+	Address            string     `json:"address"`
+	Role               string     `json:"role"`
+	EmailVerified      bool       `json:"email_verified"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	ErasureRequestedAt *time.Time `json:"erasure_requested_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+func (u *User) ToProfileResponse() ProfileResponse {
+	return ProfileResponse{
+		ID:                 u.ID,
+		Email:              u.Email,
+		Username:           u.Username,
+		FullName:           u.FullName,
+		PhoneNumber:        u.PhoneNumber,
+		Address:            u.Address,
+		Role:               u.Role,
+		EmailVerified:      u.IsEmailVerified(),
+		EmailVerifiedAt:    u.EmailVerifiedAt,
+		ErasureRequestedAt: u.ErasureRequestedAt,
+		CreatedAt:          u.CreatedAt,
+	}
+}
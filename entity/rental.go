@@ -39,21 +39,31 @@ type Rental struct {
 	ExpectedReturnDate time.Time  `gorm:"not null" json:"expected_return_date,omitempty"`
 	ActualReturnDate   *time.Time `json:"actual_return_date,omitempty"`
 	TotalRentalPrice   float64    `gorm:"type:decimal(10,2);not null" json:"total_rental_price,omitempty"`
+	VoucherID          *uuid.UUID `gorm:"type:uuid" json:"voucher_id,omitempty"`
+	SubscriptionID     *uuid.UUID `gorm:"type:uuid;index" json:"subscription_id,omitempty"`
+	DiscountAmount     float64    `gorm:"type:decimal(10,2);default:0" json:"discount_amount,omitempty"`
 	LateFee            float64    `gorm:"type:decimal(10,2)" json:"late_fee,omitempty"`
 	DamageFee          float64    `gorm:"type:decimal(10,2)" json:"damage_fee,omitempty"`
+	DeliveryFee        float64    `gorm:"type:decimal(10,2);default:0" json:"delivery_fee,omitempty"`
 	TotalAmount        float64    `gorm:"-" json:"total_amount,omitempty"`
 	PaymentStatus      string     `gorm:"size:50;not null;default:unpaid;check:payment_status IN ('unpaid', 'pending', 'paid', 'expired', 'failed', 'refunded', 'partially_paid')" json:"payment_status,omitempty"`
 	Notes              string     `gorm:"type:text" json:"notes,omitempty"`
 
-	User        User         `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
-	RentalItems []RentalItem `gorm:"foreignKey:RentalID" json:"rental_items,omitempty"`
-	Payments    []Payment    `gorm:"foreignKey:RentalID" json:"payments,omitempty" swaggerignore:"true"`
+	User        User              `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
+	RentalItems []RentalItem      `gorm:"foreignKey:RentalID" json:"rental_items,omitempty"`
+	Fulfilment  *RentalFulfilment `gorm:"foreignKey:RentalID" json:"fulfilment,omitempty"`
+	Payments    []Payment         `gorm:"foreignKey:RentalID" json:"payments,omitempty" swaggerignore:"true"`
 }
 
 func (*Rental) TableName() string {
 	return "rentals"
 }
 
+// CalculateTotalAmount menghitung total tagihan rental: harga sewa setelah diskon ditambah ongkos kirim dan denda
+func (r *Rental) CalculateTotalAmount() float64 {
+	return r.TotalRentalPrice - r.DiscountAmount + r.DeliveryFee + r.LateFee + r.DamageFee
+}
+
 func (r *Rental) BeforeCreate(tx *gorm.DB) error {
 	if err := r.BaseEntity.BeforeCreate(tx); err != nil {
 		return err
@@ -161,11 +171,15 @@ func (r *Rental) BeforeCreate(tx *gorm.DB) error {
 //}
 
 type CreateRentalRequest struct {
-	UserID             uuid.UUID                 `json:"user_id"`
-	RentalDate         time.Time                 `json:"rental_date"`
-	ExpectedReturnDate time.Time                 `json:"expected_return_date"`
-	Items              []CreateRentalItemRequest `json:"items"`
-	Notes              string                    `json:"notes"`
+	UserID             uuid.UUID                   `json:"user_id"`
+	RentalDate         time.Time                   `json:"rental_date"`
+	ExpectedReturnDate time.Time                   `json:"expected_return_date"`
+	Items              []CreateRentalItemRequest   `json:"items"`
+	Bundles            []CreateRentalBundleRequest `json:"bundles"`
+	VoucherCode        string                      `json:"voucher_code"`
+	UseSubscription    bool                        `json:"use_subscription"`
+	Fulfilment         *CreateFulfilmentRequest    `json:"fulfilment"`
+	Notes              string                      `json:"notes"`
 }
 
 type CreateRentalItemRequest struct {
@@ -174,6 +188,12 @@ type CreateRentalItemRequest struct {
 	ConditionBefore string    `json:"condition_before"`
 }
 
+type CreateRentalBundleRequest struct {
+	BundleID        uuid.UUID `json:"bundle_id"`
+	Quantity        int       `json:"quantity"`
+	ConditionBefore string    `json:"condition_before"`
+}
+
 type ReturnRentalRequest struct {
 	ActualReturnDate time.Time                 `json:"actual_return_date" binding:"required"`
 	Items            []ReturnRentalItemRequest `json:"items" binding:"required"`
@@ -185,3 +205,14 @@ type ReturnRentalItemRequest struct {
 	ConditionAfter    string    `json:"condition_after" binding:"required"`
 	DamageDescription string    `json:"damage_description"`
 }
+
+// RentalFeeAdjustment adalah selisih denda sebelum dan sesudah dihitung ulang
+type RentalFeeAdjustment struct {
+	RentalID       uuid.UUID `json:"rental_id"`
+	OldLateFee     float64   `json:"old_late_fee"`
+	NewLateFee     float64   `json:"new_late_fee"`
+	OldDamageFee   float64   `json:"old_damage_fee"`
+	NewDamageFee   float64   `json:"new_damage_fee"`
+	OldTotalAmount float64   `json:"old_total_amount"`
+	NewTotalAmount float64   `json:"new_total_amount"`
+}
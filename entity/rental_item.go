@@ -14,15 +14,16 @@ const (
 
 type RentalItem struct {
 	BaseEntity
-	RentalID          uuid.UUID `gorm:"type:uuid;not null" json:"rental_id"`
-	ToyID             uuid.UUID `gorm:"type:uuid;not null" json:"toy_id"`
-	Quantity          int       `gorm:"not null;default:1" json:"quantity"`
-	PricePerUnit      float64   `gorm:"type:decimal(10,2);not null" json:"price_per_unit"`
-	ConditionBefore   string    `gorm:"size:50;not null;check:condition_before IN ('new', 'excellent', 'good', 'fair', 'poor')" json:"condition_before"`
-	ConditionAfter    string    `gorm:"size:50;check:condition_after IN ('new', 'excellent', 'good', 'fair', 'poor', 'damaged', 'lost')" json:"condition_after"`
-	DamageDescription string    `gorm:"type:text" json:"damage_description"`
-	DamageFee         float64   `gorm:"type:decimal(10,2)" json:"damage_fee"`
-	Status            string    `gorm:"size:50;not null;default:rented;check:status IN ('rented', 'returned', 'damaged', 'lost')" json:"status"`
+	RentalID          uuid.UUID  `gorm:"type:uuid;not null" json:"rental_id"`
+	ToyID             uuid.UUID  `gorm:"type:uuid;not null" json:"toy_id"`
+	BundleID          *uuid.UUID `gorm:"type:uuid;index" json:"bundle_id,omitempty"`
+	Quantity          int        `gorm:"not null;default:1" json:"quantity"`
+	PricePerUnit      float64    `gorm:"type:decimal(10,2);not null" json:"price_per_unit"`
+	ConditionBefore   string     `gorm:"size:50;not null;check:condition_before IN ('new', 'excellent', 'good', 'fair', 'poor')" json:"condition_before"`
+	ConditionAfter    string     `gorm:"size:50;check:condition_after IN ('new', 'excellent', 'good', 'fair', 'poor', 'damaged', 'lost')" json:"condition_after"`
+	DamageDescription string     `gorm:"type:text" json:"damage_description"`
+	DamageFee         float64    `gorm:"type:decimal(10,2)" json:"damage_fee"`
+	Status            string     `gorm:"size:50;not null;default:rented;check:status IN ('rented', 'returned', 'damaged', 'lost')" json:"status"`
 
 	Rental Rental `gorm:"foreignKey:RentalID" json:"-"`
 	Toy    Toy    `gorm:"foreignKey:ToyID" json:"toy"`
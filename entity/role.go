@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"errors"
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+const (
+	PermissionUserRead        = "user.read"
+	PermissionUserManage      = "user.manage"
+	PermissionUserImpersonate = "user.impersonate"
+	PermissionRoleManage      = "role.manage"
+	PermissionPlanManage      = "plan.manage"
+	PermissionPaymentManage   = "payment.manage"
+	PermissionVoucherRead     = "voucher.read"
+	PermissionVoucherManage   = "voucher.manage"
+	PermissionToyManage       = "toy.manage"
+	PermissionToyStock        = "toy.stock"
+	PermissionRentalRead      = "rental.read"
+	PermissionRentalReturn    = "rental.return"
+	PermissionWaitlistRead    = "waitlist.read"
+	PermissionAPIKeyManage    = "api_key.manage"
+	PermissionAuditRead       = "audit.read"
+	PermissionBranchManage    = "branch.manage"
+	PermissionDeliveryManage  = "delivery.manage"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role tidak ditemukan")
+	ErrRoleAlreadyExists  = errors.New("nama role sudah digunakan")
+	ErrSystemRoleReadOnly = errors.New("role bawaan sistem tidak dapat diubah atau dihapus")
+	ErrRoleInUse          = errors.New("role masih dipakai oleh user")
+	ErrUnknownPermission  = errors.New("permission tidak dikenal")
+	ErrPermissionNotHeld  = errors.New("tidak dapat memberikan permission yang tidak Anda miliki")
+	ErrRoleSelfAssign     = errors.New("tidak dapat mengubah role akun sendiri")
+	ErrLastAdmin          = errors.New("tidak dapat mengubah role admin terakhir")
+)
+
+// DefaultPermissions adalah daftar permission yang dikenal aplikasi beserta deskripsinya
+var DefaultPermissions = map[string]string{
+	PermissionUserRead:        "Melihat data user dan sesi",
+	PermissionUserManage:      "Mengelola sesi, lockout, dan role user",
+	PermissionUserImpersonate: "Melihat aplikasi sebagai user lain untuk keperluan support",
+	PermissionRoleManage:      "Mengelola role dan permission",
+	PermissionPlanManage:      "Mengelola paket langganan",
+	PermissionPaymentManage:   "Mengubah status pembayaran",
+	PermissionVoucherRead:     "Melihat voucher dan laporannya",
+	PermissionVoucherManage:   "Mengelola voucher",
+	PermissionToyManage:       "Mengelola mainan, kategori, gambar, bundle, dan harga",
+	PermissionToyStock:        "Mengubah stok mainan",
+	PermissionRentalRead:      "Melihat data rental",
+	PermissionRentalReturn:    "Memproses pengembalian rental",
+	PermissionWaitlistRead:    "Melihat antrian waitlist",
+	PermissionAPIKeyManage:    "Mengelola API key partner",
+	PermissionAuditRead:       "Melihat audit log perubahan data",
+	PermissionBranchManage:    "Mengelola cabang pengambilan dan pengiriman",
+	PermissionDeliveryManage:  "Merencanakan rute driver dan memperbarui status pengiriman",
+}
+
+// DefaultRolePermissions berisi permission awal untuk role bawaan. Admin selalu mendapat semua permission.
+var DefaultRolePermissions = map[string][]string{
+	RoleStaff: {
+		PermissionRentalRead,
+		PermissionRentalReturn,
+		PermissionToyStock,
+		PermissionWaitlistRead,
+		PermissionDeliveryManage,
+	},
+	RoleCustomer: {},
+}
+
+type Permission struct {
+	BaseEntity
+	Code        string `gorm:"size:100;not null;uniqueIndex" json:"code"`
+	Description string `gorm:"type:text" json:"description"`
+}
+
+func (*Permission) TableName() string {
+	return "permissions"
+}
+
+type Role struct {
+	BaseEntity
+	Name        string `gorm:"size:50;not null;uniqueIndex" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	IsSystem    bool   `gorm:"default:false" json:"is_system"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+}
+
+func (*Role) TableName() string {
+	return "roles"
+}
+
+// PermissionCodes mengembalikan kode permission yang dimiliki role
+func (r *Role) PermissionCodes() []string {
+	codes := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		codes = append(codes, permission.Code)
+	}
+	return codes
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+func (r *RoleRequest) Validate() []string {
+	err := validation.ValidateStruct(r,
+		validation.Field(&r.Name,
+			validation.Required.Error("Nama role wajib diisi"),
+			validation.RuneLength(3, 50).Error("Nama role harus antara 3-50 karakter"),
+			validation.Match(regexp.MustCompile(`^[a-z][a-z0-9_]*$`)).Error("Nama role hanya boleh berisi huruf kecil, angka, dan underscore"),
+		),
+		validation.Field(&r.Permissions,
+			validation.Each(validation.By(func(value interface{}) error {
+				code, _ := value.(string)
+				if _, ok := DefaultPermissions[code]; !ok {
+					return ErrUnknownPermission
+				}
+				return nil
+			})),
+		),
+	)
+
+	return validationMessages(err)
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+)
+
+// SigningKey adalah key JWT asimetris. Key mulai dipakai untuk signing pada ActivatesAt,
+// berhenti dipakai saat RetiredAt karena digantikan key baru, dan tetap dipakai untuk
+// verifikasi sampai ExpiresAt ketika semua token yang ditandatanganinya sudah kadaluarsa.
+type SigningKey struct {
+	BaseEntity
+	Kid         string     `gorm:"size:64;not null;uniqueIndex" json:"kid"`
+	Algorithm   string     `gorm:"size:20;not null" json:"algorithm"`
+	PrivateKey  string     `gorm:"type:text;not null" json:"-"`
+	ActivatesAt time.Time  `gorm:"not null;index" json:"activates_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at,omitempty"`
+}
+
+func (*SigningKey) TableName() string {
+	return "signing_keys"
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	SubscriptionStatusPending   = "pending"
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusPastDue   = "past_due"
+	SubscriptionStatusCancelled = "cancelled"
+)
+
+var (
+	ErrNoActiveSubscription       = errors.New("anda tidak memiliki langganan aktif")
+	ErrSubscriptionAlreadyActive  = errors.New("anda sudah memiliki langganan aktif")
+	ErrPlanNotAvailable           = errors.New("paket langganan tidak tersedia")
+	ErrPlanConcurrentLimitReached = errors.New("jumlah mainan yang sedang disewa melebihi batas paket langganan")
+	ErrPlanSwapLimitReached       = errors.New("jatah tukar mainan bulan ini sudah habis")
+	ErrPlanToyValueExceeded       = errors.New("nilai mainan melebihi tier paket langganan")
+	ErrSubscriptionWithVoucher    = errors.New("voucher tidak dapat digunakan untuk rental langganan")
+)
+
+type Subscription struct {
+	BaseEntity
+	UserID             uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PlanID             uuid.UUID `gorm:"type:uuid;not null" json:"plan_id"`
+	Status             string    `gorm:"size:20;not null;default:active;check:status IN ('pending', 'active', 'past_due', 'cancelled')" json:"status"`
+	CurrentPeriodStart time.Time `gorm:"not null" json:"current_period_start"`
+	CurrentPeriodEnd   time.Time `gorm:"not null" json:"current_period_end"`
+	CancelAtPeriodEnd  bool      `gorm:"default:false" json:"cancel_at_period_end"`
+
+	User     User      `gorm:"foreignKey:UserID" json:"-"`
+	Plan     Plan      `gorm:"foreignKey:PlanID" json:"plan"`
+	Payments []Payment `gorm:"foreignKey:SubscriptionID" json:"-"`
+}
+
+func (*Subscription) TableName() string {
+	return "subscriptions"
+}
+
+type SubscribeRequest struct {
+	PlanID uuid.UUID `json:"plan_id" binding:"required"`
+}
+
+type SubscriptionUsage struct {
+	Subscription      Subscription `json:"subscription"`
+	ActiveToys        int          `json:"active_toys"`
+	MaxConcurrentToys int          `json:"max_concurrent_toys"`
+	SwapsUsed         int          `json:"swaps_used"`
+	SwapsPerMonth     int          `json:"swaps_per_month"`
+	SwapsRemaining    int          `json:"swaps_remaining"`
+	MaxToyValue       float64      `json:"max_toy_value"`
+	LatestPayment     *Payment     `json:"latest_payment,omitempty"`
+}
+
+type UpdatePaymentStatusRequest struct {
+	TransactionStatus string `json:"transaction_status" binding:"required,oneof=pending capture settlement deny cancel expire failure refund partial_refund"`
+	PaymentMethod     string `json:"payment_method"`
+	TransactionID     string `json:"transaction_id"`
+}
@@ -89,3 +89,7 @@ func (t *Toy) Validate() []string {
 
 	return errorMessages
 }
+
+type UpdateToyStockRequest struct {
+	Stock int `json:"stock" binding:"min=0"`
+}
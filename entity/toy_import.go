@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"errors"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	ToyCatalogFormatCSV  = "csv"
+	ToyCatalogFormatXLSX = "xlsx"
+)
+
+const (
+	// ToyImportModeAtomic menyimpan semua baris dalam satu transaksi, atau tidak sama sekali jika ada baris yang tidak valid
+	ToyImportModeAtomic = "atomic"
+	// ToyImportModeRow menyimpan baris yang valid satu per satu dan melewati baris yang tidak valid
+	ToyImportModeRow = "row"
+)
+
+var (
+	ErrToyImportEmpty         = errors.New("file import tidak berisi baris mainan")
+	ErrToyImportMissingColumn = errors.New("kolom wajib tidak ditemukan di header file import")
+	ErrToyImportFileRequired  = errors.New("file import wajib diunggah")
+	ErrToyImportTooLarge      = errors.New("ukuran file import melebihi batas")
+	ErrToyCatalogFormat       = errors.New("format file harus csv atau xlsx")
+)
+
+// ToyImportColumns adalah urutan kolom katalog untuk import dan export. Kolom categories berisi
+// nama kategori yang dipisahkan titik koma.
+var ToyImportColumns = []string{
+	"name",
+	"description",
+	"age_recommendation",
+	"condition",
+	"rental_price",
+	"late_fee_per_day",
+	"replacement_price",
+	"stock",
+	"categories",
+}
+
+// ToyExportColumns adalah kolom export katalog. Kolom id dan image_urls diabaikan saat file
+// diimpor kembali.
+var ToyExportColumns = append(append([]string{"id"}, ToyImportColumns...), "image_urls")
+
+// ToyImportRequest adalah opsi import dari query string. Default-nya dry run agar hasil validasi
+// bisa diperiksa sebelum data disimpan.
+type ToyImportRequest struct {
+	DryRun bool   `form:"dry_run,default=true"`
+	Mode   string `form:"mode,default=atomic"`
+}
+
+func (r *ToyImportRequest) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Mode,
+			validation.Required.Error("Mode import wajib diisi"),
+			validation.In(ToyImportModeAtomic, ToyImportModeRow).Error("Mode import harus atomic atau row"),
+		),
+	)
+}
+
+// ToyImportRow adalah satu baris file import. Row adalah nomor baris di file termasuk header,
+// Errors berisi kesalahan format yang ditemukan saat membaca file.
+type ToyImportRow struct {
+	Row        int
+	Toy        Toy
+	Categories []string
+	Errors     []string
+}
+
+type ToyImportRowResult struct {
+	Row    int        `json:"row"`
+	Name   string     `json:"name"`
+	ToyID  *uuid.UUID `json:"toy_id,omitempty"`
+	Errors []string   `json:"errors,omitempty"`
+}
+
+type ToyImportResult struct {
+	DryRun   bool                 `json:"dry_run"`
+	Mode     string               `json:"mode"`
+	Total    int                  `json:"total"`
+	Valid    int                  `json:"valid"`
+	Imported int                  `json:"imported"`
+	Failed   int                  `json:"failed"`
+	Rows     []ToyImportRowResult `json:"rows"`
+}
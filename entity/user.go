@@ -5,10 +5,12 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	_ "github.com/gofrs/uuid/v5"
 	"regexp"
+	"time"
 )
 
 const (
 	RoleAdmin    = "admin"
+	RoleStaff    = "staff"
 	RoleCustomer = "customer"
 )
 
@@ -21,7 +23,13 @@ type User struct {
 	PhoneNumber string `gorm:"size:20" json:"phone_number"`
 	Address     string `gorm:"type:text" json:"address"`
 	IsActive    bool   `gorm:"default:true" json:"is_active"`
-	Role        string `gorm:"size:20;not null;default:customer;check:role IN ('admin', 'customer')" json:"role"`
+	Role        string `gorm:"size:50;not null;default:customer;index" json:"role"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// ErasureRequestedAt diisi saat user meminta akunnya dihapus, data pribadi dianonimkan
+	// setelah masa tenggang berakhir dan ErasedAt diisi
+	ErasureRequestedAt *time.Time `gorm:"index" json:"erasure_requested_at,omitempty"`
+	ErasedAt           *time.Time `json:"erased_at,omitempty"`
 
 	Rentals    []Rental    `gorm:"foreignKey:UserID" json:"-"`
 	UserTokens []UserToken `gorm:"foreignKey:UserID" json:"-"`
@@ -31,13 +39,11 @@ func (*User) TableName() string {
 	return "users"
 }
 
-func (u *User) Validate(passValidate bool) []string {
-	var (
-		hasUppercase = regexp.MustCompile(`[A-Z]`)
-		hasSymbol    = regexp.MustCompile(`[!@#~$%^&*()+|_{}:<>?,./;'[\]\\=\-]`)
-		hasNumber    = regexp.MustCompile(`[0-9]`)
-	)
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
 
+func (u *User) Validate(passValidate bool) []string {
 	err := validation.ValidateStruct(u,
 		validation.Field(&u.Email,
 			validation.Required.Error("Email wajib diisi"),
@@ -49,13 +55,7 @@ func (u *User) Validate(passValidate bool) []string {
 			validation.RuneLength(3, 100).Error("Username harus antara 3-100 karakter"),
 		),
 		validation.Field(&u.Password,
-			validation.When(passValidate,
-				validation.Required.Error("Password wajib diisi"),
-				validation.RuneLength(8, 255).Error("Password harus antara 8-255 karakter"),
-				validation.Match(hasUppercase).Error("Password harus mengandung huruf kapital"),
-				validation.Match(hasSymbol).Error("Password harus mengandung simbol (misal @, #, !, dll)"),
-				validation.Match(hasNumber).Error("Password harus mengandung angka"),
-			),
+			validation.When(passValidate, passwordRules()...),
 		),
 		validation.Field(&u.FullName,
 			validation.Required.Error("Nama lengkap wajib diisi"),
@@ -70,6 +70,31 @@ func (u *User) Validate(passValidate bool) []string {
 		),
 	)
 
+	return validationMessages(err)
+}
+
+// ValidatePassword memvalidasi password baru dengan aturan yang sama seperti saat registrasi
+func ValidatePassword(password string) []string {
+	return validationMessages(validation.Validate(password, passwordRules()...))
+}
+
+func passwordRules() []validation.Rule {
+	var (
+		hasUppercase = regexp.MustCompile(`[A-Z]`)
+		hasSymbol    = regexp.MustCompile(`[!@#~$%^&*()+|_{}:<>?,./;'[\]\\=\-]`)
+		hasNumber    = regexp.MustCompile(`[0-9]`)
+	)
+
+	return []validation.Rule{
+		validation.Required.Error("Password wajib diisi"),
+		validation.RuneLength(8, 255).Error("Password harus antara 8-255 karakter"),
+		validation.Match(hasUppercase).Error("Password harus mengandung huruf kapital"),
+		validation.Match(hasSymbol).Error("Password harus mengandung simbol (misal @, #, !, dll)"),
+		validation.Match(hasNumber).Error("Password harus mengandung angka"),
+	}
+}
+
+func validationMessages(err error) []string {
 	if err == nil {
 		return nil
 	}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	ActionTokenPasswordReset     = "password_reset"
+	ActionTokenEmailVerification = "email_verification"
+)
+
+var (
+	ErrActionTokenInvalid   = errors.New("token tidak valid atau sudah kadaluarsa")
+	ErrEmailAlreadyVerified = errors.New("email sudah terverifikasi")
+	ErrEmailNotVerified     = errors.New("email belum terverifikasi, silakan verifikasi email terlebih dahulu")
+)
+
+// UserActionToken menyimpan token sekali pakai untuk reset password dan verifikasi email.
+// Token asli hanya dikirim lewat email, yang disimpan hanya hash SHA-256-nya.
+type UserActionToken struct {
+	BaseEntity
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:30;not null;check:purpose IN ('password_reset', 'email_verification')" json:"purpose"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (*UserActionToken) TableName() string {
+	return "user_action_tokens"
+}
+
+func (t *UserActionToken) IsUsable() bool {
+	return t.UsedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
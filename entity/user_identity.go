@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	OIDCFlowLogin = "login"
+	OIDCFlowLink  = "link"
+)
+
+var (
+	ErrOIDCProviderNotFound    = errors.New("provider login tidak dikenal")
+	ErrOIDCStateInvalid        = errors.New("sesi login provider tidak valid atau sudah kadaluarsa")
+	ErrOIDCEmailNotVerified    = errors.New("email dari provider belum terverifikasi")
+	ErrIdentityAlreadyLinked   = errors.New("akun provider sudah terhubung ke user lain")
+	ErrIdentityProviderLinked  = errors.New("user sudah menghubungkan akun dari provider ini")
+	ErrIdentityNotFound        = errors.New("akun provider tidak terhubung")
+	ErrIdentityLastLoginMethod = errors.New("tidak dapat memutus satu-satunya metode login, atur password terlebih dahulu")
+)
+
+// UserIdentity menghubungkan user dengan akun di provider OIDC. Subject adalah ID user
+// di provider yang tidak berubah, berbeda dengan email.
+type UserIdentity struct {
+	BaseEntity
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_identity_user_provider" json:"user_id"`
+	Provider string    `gorm:"size:50;not null;uniqueIndex:idx_user_identity_provider_subject;uniqueIndex:idx_user_identity_user_provider" json:"provider"`
+	Subject  string    `gorm:"size:255;not null;uniqueIndex:idx_user_identity_provider_subject" json:"subject"`
+	Email    string    `gorm:"size:255" json:"email"`
+	LinkedAt time.Time `gorm:"not null" json:"linked_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (*UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCState menyimpan state, nonce, dan code verifier PKCE selama user berada di halaman provider.
+// Hanya hash dari state yang disimpan dan state hanya bisa dipakai sekali.
+type OIDCState struct {
+	BaseEntity
+	StateHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Provider     string     `gorm:"size:50;not null" json:"provider"`
+	Flow         string     `gorm:"size:20;not null;check:flow IN ('login', 'link')" json:"flow"`
+	Nonce        string     `gorm:"size:100;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:100;not null" json:"-"`
+	UserID       *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+}
+
+func (*OIDCState) TableName() string {
+	return "oidc_states"
+}
+
+type OIDCProviderResponse struct {
+	Name     string `json:"name"`
+	LoginURL string `json:"login_url"`
+}
+
+// OIDCCallbackResult berisi hasil callback provider, LoginResult untuk flow login
+// dan Identity untuk flow link
+type OIDCCallbackResult struct {
+	Flow        string
+	LoginResult LoginResult
+	Identity    UserIdentity
+}
@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+var (
+	ErrMFAInvalidCode      = errors.New("kode verifikasi tidak valid")
+	ErrMFANotEnrolled      = errors.New("two-factor authentication belum diaktifkan")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication sudah aktif")
+	ErrMFARequiredForRole  = errors.New("two-factor authentication wajib untuk role ini dan tidak dapat dinonaktifkan")
+	ErrMFATokenInvalid     = errors.New("sesi verifikasi two-factor tidak valid atau sudah kadaluarsa")
+	ErrMFAEnrollmentNeeded = errors.New("two-factor authentication wajib diaktifkan untuk mengakses fitur ini")
+)
+
+// UserMFA menyimpan secret TOTP milik user. ConfirmedAt kosong berarti enrollment belum selesai.
+type UserMFA struct {
+	BaseEntity
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	Secret       string     `gorm:"size:64;not null" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (*UserMFA) TableName() string {
+	return "user_mfa"
+}
+
+func (m *UserMFA) IsEnabled() bool {
+	return m.ConfirmedAt != nil
+}
+
+// MFARecoveryCode adalah kode cadangan sekali pakai, hanya hash-nya yang disimpan
+type MFARecoveryCode struct {
+	BaseEntity
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+func (*MFARecoveryCode) TableName() string {
+	return "mfa_recovery_codes"
+}
+
+type MFAEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// LoginResult adalah hasil login. Jika MFARequired bernilai true, UserToken kosong dan
+// client harus menukar MFAToken beserta kode TOTP ke endpoint verifikasi MFA.
+type LoginResult struct {
+	User              User
+	UserToken         UserToken
+	MFARequired       bool
+	MFAToken          string
+	MFATokenExpiresAt time.Time
+}
+
+type MFAPendingResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
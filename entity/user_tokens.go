@@ -8,12 +8,21 @@ import (
 
 type UserToken struct {
 	BaseEntity
-	UserID                uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
-	AccessToken           string    `gorm:"type:text;not null" json:"access_token"`
-	RefreshToken          string    `gorm:"type:text;not null" json:"refresh_token"`
-	AccessTokenExpiresAt  time.Time `gorm:"not null" json:"access_token_expires_at"`
-	RefreshTokenExpiresAt time.Time `gorm:"not null" json:"refresh_token_expires_at"`
-	IsBlocked             bool      `gorm:"default:false" json:"is_blocked"`
+	UserID                uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	FamilyID              *uuid.UUID `gorm:"type:uuid;index" json:"family_id,omitempty"`
+	AccessToken           string     `gorm:"type:text;not null" json:"access_token"`
+	RefreshToken          string     `gorm:"type:text;not null" json:"refresh_token"`
+	AccessTokenExpiresAt  time.Time  `gorm:"not null" json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time  `gorm:"not null" json:"refresh_token_expires_at"`
+	IsBlocked             bool       `gorm:"default:false" json:"is_blocked"`
+	IsUsed                bool       `gorm:"default:false" json:"is_used"`
+	MFAVerified           bool       `gorm:"default:false" json:"mfa_verified"`
+	UsedAt                *time.Time `json:"used_at,omitempty"`
+	UserAgent             string     `gorm:"type:text" json:"user_agent"`
+	IPAddress             string     `gorm:"type:varchar(45)" json:"ip_address"`
+	LastSeenAt            *time.Time `json:"last_seen_at,omitempty"`
+	// ImpersonatorID diisi untuk sesi yang dibuat admin agar bisa melihat aplikasi sebagai user ini
+	ImpersonatorID *uuid.UUID `gorm:"type:uuid;index" json:"impersonator_id,omitempty"`
 
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
@@ -29,3 +38,60 @@ func (t *UserToken) IsAccessTokenExpired() bool {
 func (t *UserToken) IsRefreshTokenExpired() bool {
 	return time.Now().After(t.RefreshTokenExpiresAt)
 }
+
+func (t *UserToken) IsImpersonation() bool {
+	return t.ImpersonatorID != nil
+}
+
+// SessionID mengembalikan ID family token, yang tetap sama selama sesi dirotasi
+func (t *UserToken) SessionID() uuid.UUID {
+	if t.FamilyID != nil {
+		return *t.FamilyID
+	}
+	return t.ID
+}
+
+func (t *UserToken) ToSessionResponse(currentSessionID uuid.UUID) SessionResponse {
+	lastSeenAt := t.CreatedAt
+	if t.LastSeenAt != nil {
+		lastSeenAt = *t.LastSeenAt
+	}
+
+	return SessionResponse{
+		ID:         t.SessionID(),
+		UserAgent:  t.UserAgent,
+		IPAddress:  t.IPAddress,
+		LastSeenAt: lastSeenAt,
+		ExpiresAt:  t.RefreshTokenExpiresAt,
+		IsCurrent:  t.SessionID() == currentSessionID,
+	}
+}
+
+// SessionClient berisi informasi perangkat yang membuat atau merotasi sesi
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
+
+// AuthTokenResponse dikirim ke client yang memakai header Authorization, seperti aplikasi mobile
+type AuthTokenResponse struct {
+	TokenType             string    `json:"token_type"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+	User                  *User     `json:"user,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,259 @@
+package entity
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+var (
+	ErrVoucherNotFound          = errors.New("voucher tidak ditemukan")
+	ErrVoucherInactive          = errors.New("voucher tidak aktif")
+	ErrVoucherNotStarted        = errors.New("voucher belum berlaku")
+	ErrVoucherExpired           = errors.New("voucher sudah kadaluarsa")
+	ErrVoucherMinSpend          = errors.New("total rental belum memenuhi minimum pembelanjaan voucher")
+	ErrVoucherNotEligible       = errors.New("voucher tidak berlaku untuk mainan yang dipilih")
+	ErrVoucherUsageLimitReached = errors.New("kuota penggunaan voucher sudah habis")
+	ErrVoucherUserLimitReached  = errors.New("batas penggunaan voucher untuk akun Anda sudah tercapai")
+)
+
+type Voucher struct {
+	BaseEntity
+	Code          string    `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Description   string    `gorm:"type:text" json:"description"`
+	DiscountType  string    `gorm:"size:20;not null;check:discount_type IN ('percentage', 'fixed')" json:"discount_type"`
+	DiscountValue float64   `gorm:"type:decimal(10,2);not null" json:"discount_value"`
+	MaxDiscount   float64   `gorm:"type:decimal(10,2);default:0" json:"max_discount"`
+	MinSpend      float64   `gorm:"type:decimal(10,2);default:0" json:"min_spend"`
+	ValidFrom     time.Time `gorm:"not null" json:"valid_from"`
+	ValidUntil    time.Time `gorm:"not null" json:"valid_until"`
+	UsageLimit    int       `gorm:"not null;default:0" json:"usage_limit"`
+	PerUserLimit  int       `gorm:"not null;default:0" json:"per_user_limit"`
+	UsedCount     int       `gorm:"not null;default:0" json:"used_count"`
+	IsActive      bool      `gorm:"default:true" json:"is_active"`
+
+	Categories  []ToyCategory       `gorm:"many2many:voucher_categories" json:"categories,omitempty"`
+	Toys        []Toy               `gorm:"many2many:voucher_toys" json:"toys,omitempty"`
+	Redemptions []VoucherRedemption `gorm:"foreignKey:VoucherID" json:"-"`
+}
+
+func (*Voucher) TableName() string {
+	return "vouchers"
+}
+
+// NormalizeCode menyeragamkan format kode voucher
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CheckValidity memeriksa status aktif dan periode berlaku voucher
+func (v *Voucher) CheckValidity(now time.Time) error {
+	if !v.IsActive {
+		return ErrVoucherInactive
+	}
+	if now.Before(v.ValidFrom) {
+		return ErrVoucherNotStarted
+	}
+	if now.After(v.ValidUntil) {
+		return ErrVoucherExpired
+	}
+	if v.UsageLimit > 0 && v.UsedCount >= v.UsageLimit {
+		return ErrVoucherUsageLimitReached
+	}
+	return nil
+}
+
+// CheckUserLimit memeriksa batas pemakaian per user terhadap jumlah redemption user tersebut
+func (v *Voucher) CheckUserLimit(used int64) error {
+	if v.PerUserLimit > 0 && used >= int64(v.PerUserLimit) {
+		return ErrVoucherUserLimitReached
+	}
+	return nil
+}
+
+// HasEligibilityRules menandakan voucher hanya berlaku untuk kategori atau mainan tertentu
+func (v *Voucher) HasEligibilityRules() bool {
+	return len(v.Categories) > 0 || len(v.Toys) > 0
+}
+
+// IsToyEligible memeriksa apakah mainan termasuk dalam kategori atau daftar mainan voucher
+func (v *Voucher) IsToyEligible(toy Toy) bool {
+	if !v.HasEligibilityRules() {
+		return true
+	}
+
+	for _, t := range v.Toys {
+		if t.ID == toy.ID {
+			return true
+		}
+	}
+
+	for _, category := range v.Categories {
+		for _, toyCategory := range toy.Categories {
+			if category.ID == toyCategory.ID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// CalculateDiscount menghitung potongan dari subtotal item yang memenuhi syarat
+func (v *Voucher) CalculateDiscount(eligibleSubtotal float64) float64 {
+	var discount float64
+	switch v.DiscountType {
+	case DiscountTypePercentage:
+		discount = eligibleSubtotal * v.DiscountValue / 100
+		if v.MaxDiscount > 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	case DiscountTypeFixed:
+		discount = v.DiscountValue
+	}
+
+	if discount > eligibleSubtotal {
+		discount = eligibleSubtotal
+	}
+
+	return math.Round(discount*100) / 100
+}
+
+func (v *Voucher) Validate() []string {
+	err := validation.ValidateStruct(v,
+		validation.Field(&v.Code,
+			validation.Required.Error("Kode voucher wajib diisi"),
+			validation.RuneLength(3, 50).Error("Kode voucher harus antara 3-50 karakter"),
+		),
+		validation.Field(&v.DiscountType,
+			validation.Required.Error("Tipe diskon wajib diisi"),
+			validation.In(DiscountTypePercentage, DiscountTypeFixed).
+				Error("Tipe diskon harus salah satu dari: percentage atau fixed"),
+		),
+		validation.Field(&v.DiscountValue,
+			validation.Required.Error("Nilai diskon wajib diisi"),
+			validation.Min(0.0).Error("Nilai diskon tidak boleh negatif"),
+			validation.When(v.DiscountType == DiscountTypePercentage,
+				validation.Max(100.0).Error("Diskon persentase maksimal 100")),
+		),
+		validation.Field(&v.MaxDiscount,
+			validation.Min(0.0).Error("Maksimal diskon tidak boleh negatif"),
+		),
+		validation.Field(&v.MinSpend,
+			validation.Min(0.0).Error("Minimum pembelanjaan tidak boleh negatif"),
+		),
+		validation.Field(&v.ValidFrom,
+			validation.Required.Error("Tanggal mulai berlaku wajib diisi"),
+		),
+		validation.Field(&v.ValidUntil,
+			validation.Required.Error("Tanggal akhir berlaku wajib diisi"),
+			validation.By(func(value interface{}) error {
+				date, _ := value.(time.Time)
+				if !date.After(v.ValidFrom) {
+					return errors.New("Tanggal akhir berlaku harus setelah tanggal mulai")
+				}
+				return nil
+			}),
+		),
+		validation.Field(&v.UsageLimit,
+			validation.Min(0).Error("Batas penggunaan tidak boleh negatif"),
+		),
+		validation.Field(&v.PerUserLimit,
+			validation.Min(0).Error("Batas penggunaan per pengguna tidak boleh negatif"),
+		),
+	)
+
+	if err == nil {
+		return nil
+	}
+
+	var errorMessages []string
+	if validationErrors, ok := err.(validation.Errors); ok {
+		for _, fieldErr := range validationErrors {
+			errorMessages = append(errorMessages, fieldErr.Error())
+		}
+	} else {
+		errorMessages = append(errorMessages, err.Error())
+	}
+
+	return errorMessages
+}
+
+type VoucherRedemption struct {
+	BaseEntity
+	VoucherID      uuid.UUID `gorm:"type:uuid;not null;index" json:"voucher_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	RentalID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"rental_id"`
+	DiscountAmount float64   `gorm:"type:decimal(10,2);not null" json:"discount_amount"`
+	RedeemedAt     time.Time `gorm:"not null" json:"redeemed_at"`
+
+	Voucher Voucher `gorm:"foreignKey:VoucherID" json:"-"`
+	User    User    `gorm:"foreignKey:UserID" json:"-"`
+	Rental  Rental  `gorm:"foreignKey:RentalID" json:"-"`
+}
+
+func (*VoucherRedemption) TableName() string {
+	return "voucher_redemptions"
+}
+
+type VoucherRequest struct {
+	Code          string      `json:"code"`
+	Description   string      `json:"description"`
+	DiscountType  string      `json:"discount_type"`
+	DiscountValue float64     `json:"discount_value"`
+	MaxDiscount   float64     `json:"max_discount"`
+	MinSpend      float64     `json:"min_spend"`
+	ValidFrom     time.Time   `json:"valid_from"`
+	ValidUntil    time.Time   `json:"valid_until"`
+	UsageLimit    int         `json:"usage_limit"`
+	PerUserLimit  int         `json:"per_user_limit"`
+	IsActive      *bool       `json:"is_active"`
+	CategoryIDs   []uuid.UUID `json:"category_ids"`
+	ToyIDs        []uuid.UUID `json:"toy_ids"`
+}
+
+// ToVoucher memetakan request ke entity voucher, kategori dan mainan hanya diisi ID-nya
+func (r VoucherRequest) ToVoucher() Voucher {
+	voucher := Voucher{
+		Code:          NormalizeCode(r.Code),
+		Description:   r.Description,
+		DiscountType:  r.DiscountType,
+		DiscountValue: r.DiscountValue,
+		MaxDiscount:   r.MaxDiscount,
+		MinSpend:      r.MinSpend,
+		ValidFrom:     r.ValidFrom,
+		ValidUntil:    r.ValidUntil,
+		UsageLimit:    r.UsageLimit,
+		PerUserLimit:  r.PerUserLimit,
+		IsActive:      r.IsActive == nil || *r.IsActive,
+	}
+
+	for _, id := range r.CategoryIDs {
+		voucher.Categories = append(voucher.Categories, ToyCategory{BaseEntity: BaseEntity{ID: id}})
+	}
+	for _, id := range r.ToyIDs {
+		voucher.Toys = append(voucher.Toys, Toy{BaseEntity: BaseEntity{ID: id}})
+	}
+
+	return voucher
+}
+
+type VoucherReport struct {
+	VoucherID      uuid.UUID  `json:"voucher_id"`
+	Code           string     `json:"code"`
+	UsageLimit     int        `json:"usage_limit"`
+	UsedCount      int        `json:"used_count"`
+	UniqueUsers    int        `json:"unique_users"`
+	TotalDiscount  float64    `json:"total_discount"`
+	TotalRevenue   float64    `json:"total_revenue"`
+	LastRedeemedAt *time.Time `json:"last_redeemed_at,omitempty"`
+}
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusHeld      = "held"
+	WaitlistStatusConfirmed = "confirmed"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+var (
+	ErrWaitlistHoldExpired    = errors.New("masa hold waitlist telah berakhir")
+	ErrWaitlistNotHeld        = errors.New("waitlist belum mendapatkan hold")
+	ErrWaitlistAlreadyClosed  = errors.New("waitlist sudah dikonfirmasi, kadaluarsa, atau dibatalkan")
+	ErrWaitlistStockAvailable = errors.New("stok mainan masih tersedia, silakan langsung membuat rental")
+	ErrWaitlistAlreadyJoined  = errors.New("anda sudah berada di waitlist mainan ini")
+)
+
+type WaitlistEntry struct {
+	BaseEntity
+	UserID             uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	ToyID              uuid.UUID  `gorm:"type:uuid;not null;index" json:"toy_id"`
+	Quantity           int        `gorm:"not null;default:1" json:"quantity"`
+	RentalDate         time.Time  `gorm:"not null" json:"rental_date"`
+	ExpectedReturnDate time.Time  `gorm:"not null" json:"expected_return_date"`
+	Status             string     `gorm:"size:50;not null;default:waiting;check:status IN ('waiting', 'held', 'confirmed', 'expired', 'cancelled')" json:"status"`
+	HeldAt             *time.Time `json:"held_at,omitempty"`
+	HoldExpiresAt      *time.Time `json:"hold_expires_at,omitempty"`
+	RentalID           *uuid.UUID `gorm:"type:uuid" json:"rental_id,omitempty"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+	Toy  Toy  `gorm:"foreignKey:ToyID" json:"toy,omitempty"`
+}
+
+func (*WaitlistEntry) TableName() string {
+	return "waitlist_entries"
+}
+
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) error {
+	if err := w.BaseEntity.BeforeCreate(tx); err != nil {
+		return err
+	}
+
+	if !w.ExpectedReturnDate.After(w.RentalDate) {
+		return ErrInvalidReturnDate
+	}
+
+	return nil
+}
+
+func (w *WaitlistEntry) IsHoldExpired() bool {
+	return w.HoldExpiresAt != nil && time.Now().After(*w.HoldExpiresAt)
+}
+
+type JoinWaitlistRequest struct {
+	ToyID              uuid.UUID `json:"toy_id" binding:"required"`
+	Quantity           int       `json:"quantity" binding:"required,min=1"`
+	RentalDate         time.Time `json:"rental_date" binding:"required"`
+	ExpectedReturnDate time.Time `json:"expected_return_date" binding:"required"`
+}
+
+type ConfirmWaitlistRequest struct {
+	Fulfilment *CreateFulfilmentRequest `json:"fulfilment"`
+	Notes      string                   `json:"notes"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type Wishlist struct {
+	BaseEntity
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_toy" json:"user_id"`
+	ToyID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_user_toy" json:"toy_id"`
+	NotifyMe       bool       `gorm:"default:false" json:"notify_me"`
+	LastNotifiedAt *time.Time `json:"last_notified_at,omitempty"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+	Toy  Toy  `gorm:"foreignKey:ToyID" json:"toy"`
+}
+
+func (*Wishlist) TableName() string {
+	return "wishlists"
+}
+
+type AddWishlistRequest struct {
+	ToyID    uuid.UUID `json:"toy_id" binding:"required"`
+	NotifyMe bool      `json:"notify_me"`
+}
+
+type UpdateWishlistNotifyRequest struct {
+	NotifyMe bool `json:"notify_me"`
+}
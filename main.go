@@ -1,14 +1,20 @@
 package main
 
 import (
-	"errors"
+	"context"
 	"final-project/config"
 	_ "final-project/docs"
+	"final-project/repository"
 	"final-project/utils/helpers"
+	"final-project/utils/lifecycle"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	// Database zona waktu ikut di-embed agar DELIVERY_TIMEZONE bisa dimuat di image tanpa tzdata
+	_ "time/tzdata"
 )
 
 // @title           ToyRental API
@@ -23,6 +29,15 @@ import (
 // @securityDefinitions.cookie ApiCookieAuth
 // @in cookie
 // @name access_tokend
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @securityDefinitions.apikey PartnerApiKey
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey CsrfToken
+// @in header
+// @name X-CSRF-Token
 func main() {
 	// Load konfigurasi
 	cfg := config.LoadConfig()
@@ -31,39 +46,96 @@ func main() {
 	helpers.SetupLogger(cfg.IsProd)
 	log := helpers.Logger
 
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Inisialisasi database
 	db := config.NewDatabase(cfg)
 
-	// Auto migrate
-	if err := db.AutoMigrate(); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// Subcommand migrate up|down|status dijalankan lalu keluar tanpa menyalakan server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := db.RunMigrateCommand(context.Background(), os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Failed to run migration: %v", err)
+		}
+		db.CloseConnection()
+		return
+	}
+
+	// Terapkan migrasi yang belum berjalan, dilindungi advisory lock jika beberapa replika start bersamaan
+	if cfg.DBAutoMigrate {
+		if _, err := db.MigrateUp(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// Seed role dan permission bawaan
+	if err := db.SeedRoles(); err != nil {
+		log.Fatalf("Failed to seed roles: %v", err)
+	}
+
+	// Catat perubahan data inti ke audit log
+	if err := repository.RegisterAuditCallbacks(db.DB); err != nil {
+		log.Fatalf("Failed to register audit callbacks: %v", err)
 	}
 
+	// Komponen dihentikan terbalik dari urutan registrasi: HTTP server, worker, lalu database
+	lc := lifecycle.New()
+	lc.OnStop("database", func(context.Context) error {
+		return db.CloseConnection()
+	})
+
 	// Setup routes
-	r := setupRoutes(cfg, db.DB)
+	r := setupRoutes(cfg, db.DB, lc)
 	srv := &http.Server{
 		Addr:    ":" + cfg.ServerPort,
 		Handler: r,
 	}
 
-	// Buat channel untuk menangkap signal interupsi
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		log.Fatalf("Failed to listen on port %s: %v", cfg.ServerPort, err)
+	}
+
+	// Shutdown menunggu request yang sedang berjalan, misalnya checkout, selesai. Jika batas waktu
+	// habis koneksi yang tersisa diputus paksa.
+	lc.OnStop("http server", func(ctx context.Context) error {
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+			return err
+		}
+		return nil
+	})
+
+	// Tangkap signal interupsi. Signal kedua menghentikan proses tanpa menunggu shutdown selesai.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Jalankan server di goroutine terpisah
+	serverErr := make(chan error, 1)
 	go func() {
 		log.Printf("Server running on port %s", cfg.ServerPort)
-		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-			log.Fatalf("Failed to run server: %v", err)
-		}
+		serverErr <- srv.Serve(listener)
 	}()
+	lc.SetReady(true)
 
 	// Tunggu signal untuk shutdown
-	<-quit
-	log.Println("Shutting down server...")
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down server...")
+	case err := <-serverErr:
+		log.Errorf("Server stopped unexpectedly: %v", err)
+	}
+	stop()
 
-	// Tutup koneksi database
-	db.CloseConnection()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeout)*time.Second)
+	defer cancel()
+
+	if err := lc.Shutdown(shutdownCtx, time.Duration(cfg.ShutdownDrainDelay)*time.Second); err != nil {
+		log.Errorf("Server exited with errors: %v", err)
+		cancel()
+		os.Exit(1)
+	}
 
 	log.Println("Server exited properly")
 }
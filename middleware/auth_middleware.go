@@ -7,130 +7,303 @@ import (
 	"final-project/utils/helpers"
 	"final-project/utils/response"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type AuthMiddleware struct {
 	jwtHelper    helpers.JWTHelper
 	userTokenSvc service.ITokenService
+	mfaSvc       service.IMFAService
+	rbacSvc      service.IRBACService
+	apiKeySvc    service.IAPIKeyService
+	auditSvc     service.IAuditService
 }
 
-func NewAuthMiddleware(jwtHelper helpers.JWTHelper, userTokenSvc service.ITokenService) *AuthMiddleware {
+func NewAuthMiddleware(
+	jwtHelper helpers.JWTHelper,
+	userTokenSvc service.ITokenService,
+	mfaSvc service.IMFAService,
+	rbacSvc service.IRBACService,
+	apiKeySvc service.IAPIKeyService,
+	auditSvc service.IAuditService,
+) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtHelper:    jwtHelper,
 		userTokenSvc: userTokenSvc,
+		mfaSvc:       mfaSvc,
+		rbacSvc:      rbacSvc,
+		apiKeySvc:    apiKeySvc,
+		auditSvc:     auditSvc,
 	}
 }
 
 func (m *AuthMiddleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var log = helpers.Logger
-		accessToken, err := c.Request.Cookie("access_token")
-		if err != nil {
-			c.Abort()
-			log.Error("Failed to get access token: ", err)
-			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		if !m.authenticate(c, true) {
 			return
 		}
 
-		claims, err := m.jwtHelper.ValidateAccessToken(accessToken.Value)
-		if err != nil {
-			c.Abort()
-			log.Error("Failed to validate access token: ", err)
-			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		m.next(c)
+	}
+}
+
+// MFASetupMiddleware sama seperti AuthMiddleware tetapi tidak mewajibkan two-factor,
+// dipakai untuk endpoint enrollment dan logout agar user dengan role wajib MFA tetap bisa memakainya
+func (m *AuthMiddleware) MFASetupMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.authenticate(c, false) {
 			return
 		}
 
-		c.Set("claims", claims)
-		c.Set("access_token", accessToken.Value)
-
-		c.Next()
+		m.next(c)
 	}
 }
 
-func (m *AuthMiddleware) AdminMiddleware() gin.HandlerFunc {
+// DenyImpersonation menolak aksi destruktif, seperti menghapus akun atau mengganti password,
+// jika request dilakukan admin atas nama user
+func (m *AuthMiddleware) DenyImpersonation() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var log = helpers.Logger
 
-		accessToken, err := c.Request.Cookie("access_token")
-		if err != nil {
+		claims, exists := c.Get("claims")
+		if claimsData, ok := claims.(*helpers.ClaimsToken); exists && ok && claimsData.IsImpersonation() {
 			c.Abort()
-			log.Error("Failed to get access token: ", err)
-			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+			log.Errorf("Admin %s tried %s %s while impersonating user %s", claimsData.ImpersonatorID, c.Request.Method, c.FullPath(), claimsData.UserID)
+			response.ResponseError(c, http.StatusForbidden, entity.ErrImpersonationRestricted.Error())
 			return
 		}
 
-		claims, err := m.jwtHelper.ValidateAccessToken(accessToken.Value)
+		c.Next()
+	}
+}
+
+// RequirePermission memastikan role user, atau scope API key partner, memiliki permission yang diminta.
+// Autentikasi dilakukan di sini jika belum dijalankan oleh middleware sebelumnya.
+func (m *AuthMiddleware) RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var log = helpers.Logger
+
+		authenticated := false
+		if _, exists := c.Get("claims"); !exists {
+			if rawKey := apiKeyFromRequest(c); rawKey != "" {
+				m.requireAPIKeyScope(c, rawKey, permission)
+				return
+			}
+
+			if !m.authenticate(c, true) {
+				return
+			}
+			authenticated = true
+		}
+
+		claims := c.MustGet("claims").(*helpers.ClaimsToken)
+		allowed, err := m.rbacSvc.HasPermission(c.Request.Context(), claims.Role, permission)
 		if err != nil {
 			c.Abort()
-			log.Error("Failed to validate access token: ", err)
-			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+			log.Error("Failed to check permission: ", err)
+			response.ResponseError(c, http.StatusInternalServerError, "Failed to check permission")
 			return
 		}
 
-		if claims.Role != entity.RoleAdmin {
+		if !allowed {
 			c.Abort()
-			log.Error("Unauthorized")
-			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+			log.Errorf("Role %s does not have permission %s", claims.Role, permission)
+			response.ResponseError(c, http.StatusForbidden, "Forbidden")
+			if authenticated {
+				m.auditImpersonation(c)
+			}
 			return
 		}
 
-		c.Set("claims", claims)
-		c.Set("access_token", accessToken.Value)
-
+		// Request yang sudah diautentikasi middleware sebelumnya dicatat oleh middleware tersebut
+		if authenticated {
+			m.next(c)
+			return
+		}
 		c.Next()
 	}
 }
 
-func (m *AuthMiddleware) RefreshTokenMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var log = helpers.Logger
-		accessToken, err := c.Request.Cookie("access_token")
-		if err != nil {
-			c.Abort()
-			log.Error("Failed to get access token: ", err)
+// requireAPIKeyScope mengautentikasi request dengan API key dan memastikan key memiliki scope yang diminta
+func (m *AuthMiddleware) requireAPIKeyScope(c *gin.Context, rawKey string, permission string) {
+	var log = helpers.Logger
+
+	key, usage, err := m.apiKeySvc.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+	if usage.Limit > 0 {
+		c.Header("X-RateLimit-Limit", strconv.Itoa(usage.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(usage.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(int(usage.ResetIn.Seconds())+1))
+	}
+	if err != nil {
+		c.Abort()
+		log.Error("Failed to authenticate API key: ", err)
+		switch {
+		case errors.Is(err, entity.ErrAPIKeyRateLimited):
+			c.Header("Retry-After", strconv.Itoa(int(usage.ResetIn.Seconds())+1))
+			response.ResponseError(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, entity.ErrAPIKeyInvalid):
 			response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
-			return
+		default:
+			response.ResponseError(c, http.StatusInternalServerError, "Failed to authenticate API key")
 		}
+		return
+	}
 
-		claims, err := m.jwtHelper.ValidateAccessToken(accessToken.Value)
-		if err == nil {
-			c.Set("claims", claims)
-			c.Set("access_token", accessToken.Value)
-
-			c.Next()
-		}
+	if !key.HasScope(permission) {
+		c.Abort()
+		log.Errorf("API key %s does not have scope %s", key.ID, permission)
+		response.ResponseError(c, http.StatusForbidden, "Forbidden")
+		return
+	}
 
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			tokenClaims, err := m.jwtHelper.ExtractTokenClaims(accessToken.Value)
-			if err != nil {
-				c.Abort()
-				log.Error("Failed to extract token claims: ", err)
-				response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
-				return
-			}
+	c.Set("api_key", key)
+	setAuditActor(c, func(actor *helpers.AuditActor) {
+		actor.APIKeyID = &key.ID
+	})
+	c.Next()
+}
 
-			userToken, err := m.userTokenSvc.RefreshToken(c.Request.Context(), accessToken.Value, *tokenClaims)
-			if err != nil {
-				c.Abort()
-				log.Error("Failed to refresh token: ", err)
-				response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
-				return
-			}
+// authenticate memvalidasi access token dari header Authorization atau cookie beserta sesinya,
+// lalu menyimpan claims ke context. Request dihentikan dan false dikembalikan jika gagal.
+func (m *AuthMiddleware) authenticate(c *gin.Context, enforceMFA bool) bool {
+	var log = helpers.Logger
 
-			c.SetCookie("access_token", userToken.AccessToken, 24*60*60, "/", "localhost", false, true)
-			claims, _ = m.jwtHelper.ValidateAccessToken(userToken.AccessToken)
+	accessToken, err := accessTokenFromRequest(c)
+	if err != nil {
+		c.Abort()
+		log.Error("Failed to get access token: ", err)
+		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
 
-			c.Set("claims", claims)
-			c.Set("access_token", userToken.AccessToken)
+	claims, err := m.jwtHelper.ValidateAccessToken(accessToken)
+	if err != nil {
+		c.Abort()
+		log.Error("Failed to validate access token: ", err)
+		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
 
-			c.Next()
-			return
-		}
+	userToken, err := m.validateSession(c, accessToken, claims)
+	if err != nil {
+		c.Abort()
+		log.Error("Failed to validate session: ", err)
+		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
 
+	// Token impersonation hanya berlaku untuk sesi impersonation yang dibuat oleh admin yang sama
+	if claims.IsImpersonation() != userToken.IsImpersonation() ||
+		(claims.IsImpersonation() && *claims.ImpersonatorID != *userToken.ImpersonatorID) {
 		c.Abort()
-		log.Error("Failed to validate access token: ", err)
+		log.Error("Impersonation claims do not match session")
 		response.ResponseError(c, http.StatusUnauthorized, "Unauthorized")
+		return false
+	}
+
+	if enforceMFA && m.mfaSvc.IsEnforced(claims.Role) && !userToken.MFAVerified {
+		c.Abort()
+		log.Error("Two-factor authentication required for role ", claims.Role)
+		response.ResponseError(c, http.StatusForbidden, entity.ErrMFAEnrollmentNeeded.Error())
+		return false
+	}
+
+	c.Set("claims", claims)
+	c.Set("access_token", accessToken)
+	c.Set("session_id", userToken.SessionID().String())
+	setAuditActor(c, func(actor *helpers.AuditActor) {
+		actor.UserID = &claims.UserID
+		actor.ImpersonatorID = claims.ImpersonatorID
+	})
+	return true
+}
+
+// setAuditActor melengkapi data pelaku di context request agar perubahan data tercatat atas namanya
+func setAuditActor(c *gin.Context, fill func(actor *helpers.AuditActor)) {
+	actor, ok := helpers.AuditActorFromContext(c.Request.Context())
+	if !ok {
+		actor = helpers.AuditActor{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	}
+	fill(&actor)
+	c.Request = c.Request.WithContext(helpers.WithAuditActor(c.Request.Context(), actor))
+}
+
+// next melanjutkan request ke handler berikutnya, lalu mencatat request ke audit log
+// jika dilakukan dalam sesi impersonation
+func (m *AuthMiddleware) next(c *gin.Context) {
+	c.Next()
+	m.auditImpersonation(c)
+}
+
+func (m *AuthMiddleware) auditImpersonation(c *gin.Context) {
+	claims, exists := c.Get("claims")
+	claimsData, ok := claims.(*helpers.ClaimsToken)
+	if !exists || !ok || !claimsData.IsImpersonation() {
+		return
+	}
+
+	if err := m.auditSvc.Record(c.Request.Context(), &entity.AuditEvent{
+		ActorID:            claimsData.ImpersonatorID,
+		ImpersonatedUserID: &claimsData.UserID,
+		Action:             entity.AuditActionImpersonationRequest,
+		EntityType:         "user",
+		EntityID:           claimsData.UserID.String(),
+		Method:             c.Request.Method,
+		Path:               c.Request.URL.RequestURI(),
+		StatusCode:         c.Writer.Status(),
+		IPAddress:          c.ClientIP(),
+		UserAgent:          c.Request.UserAgent(),
+		RequestID:          c.GetString("request_id"),
+	}); err != nil {
+		helpers.Logger.Error("Failed to record impersonation audit event: ", err)
+	}
+}
+
+// validateSession memastikan token yang valid secara signature juga masih tercatat aktif di user_tokens
+func (m *AuthMiddleware) validateSession(c *gin.Context, accessToken string, claims *helpers.ClaimsToken) (entity.UserToken, error) {
+	userToken, err := m.userTokenSvc.ValidateSession(c.Request.Context(), accessToken)
+	if err != nil {
+		return entity.UserToken{}, err
+	}
+
+	if userToken.UserID != claims.UserID {
+		return entity.UserToken{}, service.ErrSessionRevoked
+	}
+
+	return userToken, nil
+}
+
+// accessTokenFromRequest mengambil access token dari header "Authorization: Bearer" untuk aplikasi
+// mobile dan partner, atau dari cookie access_token untuk browser
+func accessTokenFromRequest(c *gin.Context) (string, error) {
+	if token, ok := bearerToken(c); ok {
+		if token == "" || strings.HasPrefix(token, entity.APIKeyPrefix) {
+			return "", errors.New("invalid bearer token")
+		}
+		return token, nil
+	}
+
+	return c.Cookie("access_token")
+}
+
+// apiKeyFromRequest mengambil API key dari header X-API-Key atau dari bearer token berawalan prefix API key
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	if token, ok := bearerToken(c); ok && strings.HasPrefix(token, entity.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
 	}
+	return strings.TrimSpace(header[len("Bearer "):]), true
 }
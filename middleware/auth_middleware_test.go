@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/service"
+	"final-project/utils/helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeAPIKeyRepo struct {
+	repository.IAPIKeyRepository
+	keys map[string]entity.APIKey
+}
+
+func (r *fakeAPIKeyRepo) FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	key, ok := r.keys[keyHash]
+	if !ok {
+		return key, gorm.ErrRecordNotFound
+	}
+	return key, nil
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(ctx context.Context, id string, ip string, interval time.Duration) error {
+	return nil
+}
+
+func TestAuthMiddleware_RequirePermission_APIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const rawKey = entity.APIKeyPrefix + "rahasia-integrasi"
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		modify         func(key *entity.APIKey)
+		header         string
+		value          string
+		requests       int
+		wantStatus     int
+		wantRetryAfter bool
+	}{
+		{
+			name:       "key dengan scope yang diminta",
+			header:     "X-API-Key",
+			value:      rawKey,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "key lewat bearer token",
+			header:     "Authorization",
+			value:      "Bearer " + rawKey,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "key tanpa scope yang diminta",
+			modify: func(key *entity.APIKey) {
+				key.Scopes = []string{entity.PermissionWaitlistRead}
+			},
+			header:     "X-API-Key",
+			value:      rawKey,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "key tidak dikenal",
+			header:     "X-API-Key",
+			value:      entity.APIKeyPrefix + "tidak-ada",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "key tanpa prefix",
+			header:     "X-API-Key",
+			value:      "rahasia-integrasi",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "key sudah dicabut",
+			modify: func(key *entity.APIKey) {
+				key.RevokedAt = &past
+			},
+			header:     "X-API-Key",
+			value:      rawKey,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "key kadaluarsa",
+			modify: func(key *entity.APIKey) {
+				key.ExpiresAt = &past
+			},
+			header:     "X-API-Key",
+			value:      rawKey,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "melebihi rate limit",
+			modify: func(key *entity.APIKey) {
+				key.RateLimit = 2
+			},
+			header:         "X-API-Key",
+			value:          rawKey,
+			requests:       3,
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := entity.APIKey{
+				BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+				Name:       "gudang",
+				KeyHash:    helpers.HashToken(rawKey),
+				Scopes:     []string{entity.PermissionRentalRead},
+			}
+			if tt.modify != nil {
+				tt.modify(&key)
+			}
+
+			apiKeySvc := service.NewAPIKeyService(&fakeAPIKeyRepo{keys: map[string]entity.APIKey{key.KeyHash: key}}, 60)
+			authMiddleware := NewAuthMiddleware(helpers.JWTHelper{}, nil, nil, nil, apiKeySvc, nil)
+
+			var gotKey entity.APIKey
+			router := gin.New()
+			router.GET("/rentals", authMiddleware.RequirePermission(entity.PermissionRentalRead), func(c *gin.Context) {
+				gotKey = c.MustGet("api_key").(entity.APIKey)
+				c.Status(http.StatusOK)
+			})
+
+			requests := tt.requests
+			if requests == 0 {
+				requests = 1
+			}
+			var rec *httptest.ResponseRecorder
+			for i := 0; i < requests; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/rentals", nil)
+				req.Header.Set(tt.header, tt.value)
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotRetryAfter := rec.Header().Get("Retry-After") != ""; gotRetryAfter != tt.wantRetryAfter {
+				t.Errorf("Retry-After present = %v, want %v", gotRetryAfter, tt.wantRetryAfter)
+			}
+			if tt.wantStatus == http.StatusOK && gotKey.ID != key.ID {
+				t.Errorf("api key in context = %s, want %s", gotKey.ID, key.ID)
+			}
+		})
+	}
+}
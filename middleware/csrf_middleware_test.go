@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"final-project/utils/helpers"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCSRFMiddleware_Protect(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const csrfToken = "token-csrf"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		cookies    map[string]string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "request GET tidak diperiksa",
+			method:     http.MethodGet,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "cookie auth tanpa header csrf ditolak",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt", helpers.CSRFCookieName: csrfToken},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "refresh token cookie tanpa header csrf ditolak",
+			method:     http.MethodPost,
+			path:       "/auth/refresh",
+			cookies:    map[string]string{"refresh_token": "jwt", helpers.CSRFCookieName: csrfToken},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "header csrf sama dengan cookie",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt", helpers.CSRFCookieName: csrfToken},
+			headers:    map[string]string{helpers.CSRFHeaderName: csrfToken},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "header csrf berbeda dengan cookie ditolak",
+			method:     http.MethodDelete,
+			path:       "/rentals/1",
+			cookies:    map[string]string{"access_token": "jwt", helpers.CSRFCookieName: csrfToken},
+			headers:    map[string]string{helpers.CSRFHeaderName: "token-lain"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "cookie csrf tidak ada ditolak",
+			method:     http.MethodPut,
+			path:       "/profile",
+			cookies:    map[string]string{"access_token": "jwt"},
+			headers:    map[string]string{helpers.CSRFHeaderName: csrfToken},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "bearer token tidak diperiksa",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt"},
+			headers:    map[string]string{"Authorization": "Bearer jwt"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "header X-API-Key tidak diperiksa",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt"},
+			headers:    map[string]string{"X-API-Key": "trk_key"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "header Authorization Basic tetap diperiksa",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt"},
+			headers:    map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "bearer token kosong tetap diperiksa",
+			method:     http.MethodPost,
+			path:       "/rentals",
+			cookies:    map[string]string{"access_token": "jwt"},
+			headers:    map[string]string{"Authorization": "Bearer "},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "path yang dikecualikan tidak diperiksa",
+			method:     http.MethodPost,
+			path:       "/payments/webhook",
+			cookies:    map[string]string{"access_token": "jwt"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "request tanpa cookie auth tidak diperiksa",
+			method:     http.MethodPost,
+			path:       "/auth/login",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(NewCSRFMiddleware([]string{"/payments/webhook"}).Protect())
+			router.Any("/*path", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			for name, value := range tt.cookies {
+				req.AddCookie(&http.Cookie{Name: name, Value: value})
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
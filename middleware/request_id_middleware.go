@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"final-project/utils/helpers"
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid/v5"
+	"regexp"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID membatasi request ID dari client agar aman disimpan dan ditulis ke log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID memakai header X-Request-ID dari proxy atau membuat ID baru, lalu menyimpannya
+// bersama IP dan user agent ke context untuk dicatat di audit log
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.Must(uuid.NewV7()).String()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(helpers.WithAuditActor(c.Request.Context(), helpers.AuditActor{
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: requestID,
+		}))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,12 @@
+-- Menghapus skema awal dengan urutan terbalik dari pembuatan tabel
+
+DROP TABLE IF EXISTS "user_tokens";
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "rental_items";
+DROP TABLE IF EXISTS "rentals";
+DROP TABLE IF EXISTS "image_toys";
+DROP TABLE IF EXISTS "toy_images";
+DROP TABLE IF EXISTS "toy_categories";
+DROP TABLE IF EXISTS "toys";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,163 @@
+-- Skema awal, sama dengan hasil AutoMigrate sebelum migrasi berversi diperkenalkan
+-- termasuk check constraint yang dideklarasikan di tag entity. Database lama dicatat langsung
+-- di versi ini tanpa menjalankannya, perubahan setelahnya ada di migrasi berikutnya.
+
+CREATE TABLE "users" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "email" varchar(255) NOT NULL,
+    "username" varchar(100) NOT NULL,
+    "password" varchar(255) NOT NULL,
+    "full_name" varchar(255) NOT NULL,
+    "phone_number" varchar(20),
+    "address" text,
+    "is_active" boolean DEFAULT true,
+    "role" varchar(20) NOT NULL DEFAULT 'customer',
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_users_role" CHECK (role IN ('admin', 'customer'))
+);
+CREATE INDEX "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE "categories" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE "toys" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "age_recommendation" varchar(50),
+    "condition" varchar(50) NOT NULL,
+    "rental_price" decimal(10,2) NOT NULL,
+    "late_fee_per_day" decimal(10,2) NOT NULL,
+    "replacement_price" decimal(10,2) NOT NULL,
+    "is_available" boolean DEFAULT true,
+    "stock" bigint NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_toys_condition" CHECK (condition IN ('new', 'excellent', 'good', 'fair', 'poor'))
+);
+CREATE INDEX "idx_toys_deleted_at" ON "toys" ("deleted_at");
+
+CREATE TABLE "toy_categories" (
+    "toy_id" uuid,
+    "toy_category_id" uuid,
+    PRIMARY KEY ("toy_id","toy_category_id"),
+    CONSTRAINT "fk_toy_categories_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id"),
+    CONSTRAINT "fk_toy_categories_toy_category" FOREIGN KEY ("toy_category_id") REFERENCES "categories"("id")
+);
+
+CREATE TABLE "toy_images" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "image_url" varchar(255) NOT NULL,
+    "is_primary" boolean DEFAULT false,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_toy_images_deleted_at" ON "toy_images" ("deleted_at");
+
+CREATE TABLE "image_toys" (
+    "toy_image_id" uuid,
+    "toy_id" uuid,
+    PRIMARY KEY ("toy_image_id","toy_id"),
+    CONSTRAINT "fk_image_toys_toy_image" FOREIGN KEY ("toy_image_id") REFERENCES "toy_images"("id"),
+    CONSTRAINT "fk_image_toys_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id")
+);
+
+CREATE TABLE "rentals" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "status" varchar(50) NOT NULL,
+    "rental_date" timestamptz NOT NULL,
+    "expected_return_date" timestamptz NOT NULL,
+    "actual_return_date" timestamptz,
+    "total_rental_price" decimal(10,2) NOT NULL,
+    "late_fee" decimal(10,2),
+    "damage_fee" decimal(10,2),
+    "payment_status" varchar(50) NOT NULL DEFAULT 'unpaid',
+    "notes" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_rentals" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "chk_rentals_payment_status" CHECK (payment_status IN ('unpaid', 'pending', 'paid', 'expired', 'failed', 'refunded', 'partially_paid')),
+    CONSTRAINT "chk_rentals_status" CHECK (status IN ('pending', 'active', 'completed', 'overdue', 'cancelled'))
+);
+CREATE INDEX "idx_rentals_deleted_at" ON "rentals" ("deleted_at");
+
+CREATE TABLE "rental_items" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "rental_id" uuid NOT NULL,
+    "toy_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 1,
+    "price_per_unit" decimal(10,2) NOT NULL,
+    "condition_before" varchar(50) NOT NULL,
+    "condition_after" varchar(50),
+    "damage_description" text,
+    "damage_fee" decimal(10,2),
+    "status" varchar(50) NOT NULL DEFAULT 'rented',
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_rentals_rental_items" FOREIGN KEY ("rental_id") REFERENCES "rentals"("id"),
+    CONSTRAINT "fk_toys_rental_items" FOREIGN KEY ("toy_id") REFERENCES "toys"("id"),
+    CONSTRAINT "chk_rental_items_condition_before" CHECK (condition_before IN ('new', 'excellent', 'good', 'fair', 'poor')),
+    CONSTRAINT "chk_rental_items_condition_after" CHECK (condition_after IN ('new', 'excellent', 'good', 'fair', 'poor', 'damaged', 'lost')),
+    CONSTRAINT "chk_rental_items_status" CHECK (status IN ('rented', 'returned', 'damaged', 'lost'))
+);
+CREATE INDEX "idx_rental_items_deleted_at" ON "rental_items" ("deleted_at");
+
+CREATE TABLE "payments" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "rental_id" uuid NOT NULL,
+    "transaction_id" varchar(100),
+    "payment_type" varchar(50) NOT NULL,
+    "gross_amount" decimal(10,2) NOT NULL,
+    "snap_token" text,
+    "snap_url" text,
+    "expiry_time" timestamptz,
+    "transaction_time" timestamptz,
+    "transaction_status" varchar(50),
+    "payment_method" varchar(50),
+    "va_number" varchar(100),
+    "fraud_status" varchar(50),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_rentals_payments" FOREIGN KEY ("rental_id") REFERENCES "rentals"("id"),
+    CONSTRAINT "chk_payments_payment_type" CHECK (payment_type IN ('rental', 'late_fee', 'damage_fee', 'combined'))
+);
+CREATE UNIQUE INDEX "idx_payments_transaction_id" ON "payments" ("transaction_id");
+CREATE INDEX "idx_payments_deleted_at" ON "payments" ("deleted_at");
+
+CREATE TABLE "user_tokens" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "access_token" text NOT NULL,
+    "refresh_token" text NOT NULL,
+    "access_token_expires_at" timestamptz NOT NULL,
+    "refresh_token_expires_at" timestamptz NOT NULL,
+    "is_blocked" boolean DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_user_tokens" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX "idx_user_tokens_deleted_at" ON "user_tokens" ("deleted_at");
@@ -0,0 +1,60 @@
+-- Mengembalikan skema ke kondisi awal. Pembayaran langganan dihapus karena rental_id kembali
+-- wajib, dan role selain admin dan customer dikembalikan menjadi customer.
+
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "impersonator_id";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "last_seen_at";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "ip_address";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "user_agent";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "used_at";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "mfa_verified";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "is_used";
+ALTER TABLE "user_tokens" DROP COLUMN IF EXISTS "family_id";
+
+DELETE FROM "payments" WHERE "rental_id" IS NULL;
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "subscription_id";
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "chk_payments_payment_type";
+ALTER TABLE "payments" ADD CONSTRAINT "chk_payments_payment_type" CHECK (payment_type IN ('rental', 'late_fee', 'damage_fee', 'combined'));
+ALTER TABLE "payments" ALTER COLUMN "rental_id" SET NOT NULL;
+
+ALTER TABLE "rental_items" DROP COLUMN IF EXISTS "bundle_id";
+
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "delivery_fee";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "discount_amount";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "subscription_id";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "voucher_id";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "erased_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "erasure_requested_at";
+DROP INDEX IF EXISTS "idx_users_role";
+UPDATE "users" SET "role" = 'customer' WHERE "role" NOT IN ('admin', 'customer');
+ALTER TABLE "users" ALTER COLUMN "role" TYPE varchar(20);
+ALTER TABLE "users" ADD CONSTRAINT "chk_users_role" CHECK (role IN ('admin', 'customer'));
+
+DROP TABLE IF EXISTS "delivery_stops";
+DROP TABLE IF EXISTS "delivery_routes";
+DROP TABLE IF EXISTS "rental_fulfilments";
+DROP TABLE IF EXISTS "addresses";
+DROP TABLE IF EXISTS "branches";
+DROP TABLE IF EXISTS "voucher_redemptions";
+DROP TABLE IF EXISTS "voucher_categories";
+DROP TABLE IF EXISTS "voucher_toys";
+DROP TABLE IF EXISTS "vouchers";
+DROP TABLE IF EXISTS "waitlist_entries";
+DROP TABLE IF EXISTS "wishlists";
+DROP TABLE IF EXISTS "audit_events";
+DROP TABLE IF EXISTS "api_keys";
+DROP TABLE IF EXISTS "role_permissions";
+DROP TABLE IF EXISTS "roles";
+DROP TABLE IF EXISTS "permissions";
+DROP TABLE IF EXISTS "mfa_recovery_codes";
+DROP TABLE IF EXISTS "user_mfa";
+DROP TABLE IF EXISTS "login_attempts";
+DROP TABLE IF EXISTS "oidc_states";
+DROP TABLE IF EXISTS "user_identities";
+DROP TABLE IF EXISTS "user_action_tokens";
+DROP TABLE IF EXISTS "signing_keys";
+DROP TABLE IF EXISTS "subscriptions";
+DROP TABLE IF EXISTS "plans";
+DROP TABLE IF EXISTS "bundle_items";
+DROP TABLE IF EXISTS "bundles";
@@ -0,0 +1,537 @@
+-- Tabel dan kolom yang ditambahkan setelah skema awal. Database lama mungkin sudah menjalankan
+-- AutoMigrate untuk sebagian perubahan ini, sehingga setiap perintah harus aman dijalankan ulang.
+
+CREATE TABLE IF NOT EXISTS "bundles" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(255) NOT NULL,
+    "description" text,
+    "price" decimal(10,2) NOT NULL,
+    "is_available" boolean DEFAULT true,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_bundles_deleted_at" ON "bundles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "bundle_items" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "bundle_id" uuid NOT NULL,
+    "toy_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 1,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_bundle_items_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id"),
+    CONSTRAINT "fk_bundles_items" FOREIGN KEY ("bundle_id") REFERENCES "bundles"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_bundle_items_bundle_id" ON "bundle_items" ("bundle_id");
+CREATE INDEX IF NOT EXISTS "idx_bundle_items_deleted_at" ON "bundle_items" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "plans" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "monthly_fee" decimal(10,2) NOT NULL,
+    "max_concurrent_toys" bigint NOT NULL,
+    "swaps_per_month" bigint NOT NULL,
+    "max_toy_value" decimal(10,2) NOT NULL DEFAULT 0,
+    "is_active" boolean DEFAULT true,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_plans_deleted_at" ON "plans" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "subscriptions" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "plan_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'active',
+    "current_period_start" timestamptz NOT NULL,
+    "current_period_end" timestamptz NOT NULL,
+    "cancel_at_period_end" boolean DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_subscriptions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_subscriptions_plan" FOREIGN KEY ("plan_id") REFERENCES "plans"("id"),
+    CONSTRAINT "chk_subscriptions_status" CHECK (status IN ('active', 'past_due', 'cancelled'))
+);
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_user_id" ON "subscriptions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_subscriptions_deleted_at" ON "subscriptions" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "signing_keys" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "kid" varchar(64) NOT NULL,
+    "algorithm" varchar(20) NOT NULL,
+    "private_key" text NOT NULL,
+    "activates_at" timestamptz NOT NULL,
+    "retired_at" timestamptz,
+    "expires_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_expires_at" ON "signing_keys" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_activates_at" ON "signing_keys" ("activates_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_signing_keys_kid" ON "signing_keys" ("kid");
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_deleted_at" ON "signing_keys" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "user_action_tokens" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "purpose" varchar(30) NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_action_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "chk_user_action_tokens_purpose" CHECK (purpose IN ('password_reset', 'email_verification'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_action_tokens_token_hash" ON "user_action_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_user_action_tokens_user_id" ON "user_action_tokens" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_user_action_tokens_deleted_at" ON "user_action_tokens" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(255),
+    "linked_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identity_provider_subject" ON "user_identities" ("provider","subject");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identity_user_provider" ON "user_identities" ("user_id","provider");
+CREATE INDEX IF NOT EXISTS "idx_user_identities_deleted_at" ON "user_identities" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "oidc_states" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "state_hash" varchar(64) NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "flow" varchar(20) NOT NULL,
+    "nonce" varchar(100) NOT NULL,
+    "code_verifier" varchar(100) NOT NULL,
+    "user_id" uuid,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_oidc_states_flow" CHECK (flow IN ('login', 'link'))
+);
+CREATE INDEX IF NOT EXISTS "idx_oidc_states_expires_at" ON "oidc_states" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_oidc_states_state_hash" ON "oidc_states" ("state_hash");
+CREATE INDEX IF NOT EXISTS "idx_oidc_states_deleted_at" ON "oidc_states" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "scope" varchar(20) NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "failed_count" bigint NOT NULL DEFAULT 0,
+    "last_failed_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_login_attempts_scope" CHECK (scope IN ('account', 'ip'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_attempt_scope_key" ON "login_attempts" ("scope","key");
+CREATE INDEX IF NOT EXISTS "idx_login_attempts_deleted_at" ON "login_attempts" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "user_mfa" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "secret" varchar(64) NOT NULL,
+    "confirmed_at" timestamptz,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_mfa_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_mfa_deleted_at" ON "user_mfa" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_mfa_user_id" ON "user_mfa" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "mfa_recovery_codes" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_mfa_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_code_hash" ON "mfa_recovery_codes" ("code_hash");
+CREATE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_user_id" ON "mfa_recovery_codes" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_mfa_recovery_codes_deleted_at" ON "mfa_recovery_codes" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "permissions" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "code" varchar(100) NOT NULL,
+    "description" text,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_permissions_code" ON "permissions" ("code");
+CREATE INDEX IF NOT EXISTS "idx_permissions_deleted_at" ON "permissions" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(50) NOT NULL,
+    "description" text,
+    "is_system" boolean DEFAULT false,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+CREATE INDEX IF NOT EXISTS "idx_roles_deleted_at" ON "roles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role_id" uuid,
+    "permission_id" uuid,
+    PRIMARY KEY ("role_id","permission_id"),
+    CONSTRAINT "fk_role_permissions_role" FOREIGN KEY ("role_id") REFERENCES "roles"("id"),
+    CONSTRAINT "fk_role_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "permissions"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "key_prefix" varchar(20) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "scopes" text,
+    "rate_limit" bigint NOT NULL DEFAULT 0,
+    "created_by" uuid NOT NULL,
+    "expires_at" timestamptz,
+    "revoked_at" timestamptz,
+    "last_used_at" timestamptz,
+    "last_used_ip" varchar(45),
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_deleted_at" ON "api_keys" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "audit_events" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "actor_id" uuid,
+    "impersonated_user_id" uuid,
+    "api_key_id" uuid,
+    "action" varchar(100) NOT NULL,
+    "entity_type" varchar(50),
+    "entity_id" varchar(100),
+    "before" jsonb,
+    "after" jsonb,
+    "method" varchar(10),
+    "path" text,
+    "status_code" bigint,
+    "ip_address" varchar(45),
+    "user_agent" text,
+    "request_id" varchar(64),
+    "occurred_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_events_action" ON "audit_events" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_api_key_id" ON "audit_events" ("api_key_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_impersonated_user_id" ON "audit_events" ("impersonated_user_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_actor_id" ON "audit_events" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_deleted_at" ON "audit_events" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_occurred_at" ON "audit_events" ("occurred_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_events_request_id" ON "audit_events" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_event_entity" ON "audit_events" ("entity_type","entity_id");
+
+CREATE TABLE IF NOT EXISTS "wishlists" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "toy_id" uuid NOT NULL,
+    "notify_me" boolean DEFAULT false,
+    "last_notified_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_wishlists_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_wishlists_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_wishlist_user_toy" ON "wishlists" ("user_id","toy_id");
+CREATE INDEX IF NOT EXISTS "idx_wishlists_deleted_at" ON "wishlists" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "waitlist_entries" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "toy_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 1,
+    "rental_date" timestamptz NOT NULL,
+    "expected_return_date" timestamptz NOT NULL,
+    "status" varchar(50) NOT NULL DEFAULT 'waiting',
+    "held_at" timestamptz,
+    "hold_expires_at" timestamptz,
+    "rental_id" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_waitlist_entries_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_waitlist_entries_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id"),
+    CONSTRAINT "chk_waitlist_entries_status" CHECK (status IN ('waiting', 'held', 'confirmed', 'expired', 'cancelled'))
+);
+CREATE INDEX IF NOT EXISTS "idx_waitlist_entries_toy_id" ON "waitlist_entries" ("toy_id");
+CREATE INDEX IF NOT EXISTS "idx_waitlist_entries_user_id" ON "waitlist_entries" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_waitlist_entries_deleted_at" ON "waitlist_entries" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "vouchers" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "code" varchar(50) NOT NULL,
+    "description" text,
+    "discount_type" varchar(20) NOT NULL,
+    "discount_value" decimal(10,2) NOT NULL,
+    "max_discount" decimal(10,2) DEFAULT 0,
+    "min_spend" decimal(10,2) DEFAULT 0,
+    "valid_from" timestamptz NOT NULL,
+    "valid_until" timestamptz NOT NULL,
+    "usage_limit" bigint NOT NULL DEFAULT 0,
+    "per_user_limit" bigint NOT NULL DEFAULT 0,
+    "used_count" bigint NOT NULL DEFAULT 0,
+    "is_active" boolean DEFAULT true,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_vouchers_discount_type" CHECK (discount_type IN ('percentage', 'fixed'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_vouchers_code" ON "vouchers" ("code");
+CREATE INDEX IF NOT EXISTS "idx_vouchers_deleted_at" ON "vouchers" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "voucher_toys" (
+    "voucher_id" uuid,
+    "toy_id" uuid,
+    PRIMARY KEY ("voucher_id","toy_id"),
+    CONSTRAINT "fk_voucher_toys_voucher" FOREIGN KEY ("voucher_id") REFERENCES "vouchers"("id"),
+    CONSTRAINT "fk_voucher_toys_toy" FOREIGN KEY ("toy_id") REFERENCES "toys"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "voucher_categories" (
+    "voucher_id" uuid,
+    "toy_category_id" uuid,
+    PRIMARY KEY ("voucher_id","toy_category_id"),
+    CONSTRAINT "fk_voucher_categories_toy_category" FOREIGN KEY ("toy_category_id") REFERENCES "categories"("id"),
+    CONSTRAINT "fk_voucher_categories_voucher" FOREIGN KEY ("voucher_id") REFERENCES "vouchers"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "voucher_redemptions" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "voucher_id" uuid NOT NULL,
+    "user_id" uuid NOT NULL,
+    "rental_id" uuid NOT NULL,
+    "discount_amount" decimal(10,2) NOT NULL,
+    "redeemed_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_voucher_redemptions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_voucher_redemptions_rental" FOREIGN KEY ("rental_id") REFERENCES "rentals"("id"),
+    CONSTRAINT "fk_vouchers_redemptions" FOREIGN KEY ("voucher_id") REFERENCES "vouchers"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_voucher_redemptions_user_id" ON "voucher_redemptions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_voucher_redemptions_voucher_id" ON "voucher_redemptions" ("voucher_id");
+CREATE INDEX IF NOT EXISTS "idx_voucher_redemptions_deleted_at" ON "voucher_redemptions" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_voucher_redemptions_rental_id" ON "voucher_redemptions" ("rental_id");
+
+CREATE TABLE IF NOT EXISTS "branches" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "address" text NOT NULL,
+    "city" varchar(100) NOT NULL,
+    "phone_number" varchar(20),
+    "latitude" decimal NOT NULL,
+    "longitude" decimal NOT NULL,
+    "opening_hours" varchar(255),
+    "is_active" boolean NOT NULL DEFAULT true,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_branches_deleted_at" ON "branches" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "addresses" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "user_id" uuid NOT NULL,
+    "label" varchar(50) NOT NULL,
+    "recipient_name" varchar(255) NOT NULL,
+    "phone_number" varchar(20) NOT NULL,
+    "street" text NOT NULL,
+    "city" varchar(100) NOT NULL,
+    "postal_code" varchar(10),
+    "latitude" decimal NOT NULL,
+    "longitude" decimal NOT NULL,
+    "notes" text,
+    "is_default" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_addresses_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_addresses_user_id" ON "addresses" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_addresses_deleted_at" ON "addresses" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "rental_fulfilments" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "rental_id" uuid NOT NULL,
+    "method" varchar(20) NOT NULL,
+    "branch_id" uuid NOT NULL,
+    "address_id" uuid,
+    "delivery_address" text,
+    "recipient_name" varchar(255),
+    "recipient_phone" varchar(20),
+    "address_notes" text,
+    "latitude" decimal,
+    "longitude" decimal,
+    "distance_km" decimal(8,2) DEFAULT 0,
+    "delivery_slot_start" timestamptz,
+    "delivery_slot_end" timestamptz,
+    "return_pickup_slot_start" timestamptz,
+    "return_pickup_slot_end" timestamptz,
+    "delivered_at" timestamptz,
+    "collected_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_rental_fulfilments_branch" FOREIGN KEY ("branch_id") REFERENCES "branches"("id"),
+    CONSTRAINT "fk_rentals_fulfilment" FOREIGN KEY ("rental_id") REFERENCES "rentals"("id"),
+    CONSTRAINT "chk_rental_fulfilments_method" CHECK (method IN ('pickup', 'delivery'))
+);
+CREATE INDEX IF NOT EXISTS "idx_rental_fulfilments_return_pickup_slot_start" ON "rental_fulfilments" ("return_pickup_slot_start");
+CREATE INDEX IF NOT EXISTS "idx_rental_fulfilments_delivery_slot_start" ON "rental_fulfilments" ("delivery_slot_start");
+CREATE INDEX IF NOT EXISTS "idx_rental_fulfilments_branch_id" ON "rental_fulfilments" ("branch_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_rental_fulfilments_rental_id" ON "rental_fulfilments" ("rental_id");
+CREATE INDEX IF NOT EXISTS "idx_rental_fulfilments_deleted_at" ON "rental_fulfilments" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "delivery_routes" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "branch_id" uuid NOT NULL,
+    "route_date" timestamptz NOT NULL,
+    "number" bigint NOT NULL,
+    "algorithm" varchar(30) NOT NULL,
+    "distance_km" decimal(8,2) DEFAULT 0,
+    "status" varchar(20) NOT NULL DEFAULT 'planned',
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_delivery_routes_branch" FOREIGN KEY ("branch_id") REFERENCES "branches"("id"),
+    CONSTRAINT "chk_delivery_routes_status" CHECK (status IN ('planned', 'completed'))
+);
+CREATE INDEX IF NOT EXISTS "idx_delivery_routes_deleted_at" ON "delivery_routes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_delivery_routes_branch_date" ON "delivery_routes" ("branch_id","route_date");
+
+CREATE TABLE IF NOT EXISTS "delivery_stops" (
+    "id" uuid,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "route_id" uuid NOT NULL,
+    "rental_id" uuid NOT NULL,
+    "type" varchar(20) NOT NULL,
+    "sequence" bigint NOT NULL,
+    "address" text,
+    "recipient_name" varchar(255),
+    "recipient_phone" varchar(20),
+    "address_notes" text,
+    "latitude" decimal NOT NULL,
+    "longitude" decimal NOT NULL,
+    "slot_start" timestamptz,
+    "slot_end" timestamptz,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "failure_reason" text,
+    "completed_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_delivery_routes_stops" FOREIGN KEY ("route_id") REFERENCES "delivery_routes"("id"),
+    CONSTRAINT "chk_delivery_stops_status" CHECK (status IN ('pending', 'delivered', 'collected', 'failed')),
+    CONSTRAINT "chk_delivery_stops_type" CHECK (type IN ('drop_off', 'collection'))
+);
+CREATE INDEX IF NOT EXISTS "idx_delivery_stops_rental_id" ON "delivery_stops" ("rental_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_stops_route_id" ON "delivery_stops" ("route_id");
+CREATE INDEX IF NOT EXISTS "idx_delivery_stops_deleted_at" ON "delivery_stops" ("deleted_at");
+
+-- Role dikelola lewat tabel roles, check constraint lama hanya mengizinkan admin dan customer
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "chk_users_role";
+ALTER TABLE "users" ALTER COLUMN "role" TYPE varchar(50);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "erasure_requested_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "erased_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_users_erasure_requested_at" ON "users" ("erasure_requested_at");
+CREATE INDEX IF NOT EXISTS "idx_users_role" ON "users" ("role");
+
+-- User yang terdaftar sebelum verifikasi email diperkenalkan dianggap sudah terverifikasi
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'email_verified_at'
+    ) THEN
+        ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+        UPDATE "users" SET "email_verified_at" = "created_at";
+    END IF;
+END $$;
+
+ALTER TABLE "rentals" ADD COLUMN IF NOT EXISTS "voucher_id" uuid;
+ALTER TABLE "rentals" ADD COLUMN IF NOT EXISTS "subscription_id" uuid;
+ALTER TABLE "rentals" ADD COLUMN IF NOT EXISTS "discount_amount" decimal(10,2) DEFAULT 0;
+ALTER TABLE "rentals" ADD COLUMN IF NOT EXISTS "delivery_fee" decimal(10,2) DEFAULT 0;
+CREATE INDEX IF NOT EXISTS "idx_rentals_subscription_id" ON "rentals" ("subscription_id");
+
+ALTER TABLE "rental_items" ADD COLUMN IF NOT EXISTS "bundle_id" uuid;
+CREATE INDEX IF NOT EXISTS "idx_rental_items_bundle_id" ON "rental_items" ("bundle_id");
+
+-- Pembayaran langganan tidak terkait dengan rental
+ALTER TABLE "payments" ALTER COLUMN "rental_id" DROP NOT NULL;
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "subscription_id" uuid;
+CREATE INDEX IF NOT EXISTS "idx_payments_subscription_id" ON "payments" ("subscription_id");
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "chk_payments_payment_type";
+ALTER TABLE "payments" ADD CONSTRAINT "chk_payments_payment_type" CHECK (payment_type IN ('rental', 'late_fee', 'damage_fee', 'combined', 'subscription'));
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'fk_subscriptions_payments') THEN
+        ALTER TABLE "payments" ADD CONSTRAINT "fk_subscriptions_payments" FOREIGN KEY ("subscription_id") REFERENCES "subscriptions"("id");
+    END IF;
+END $$;
+
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "family_id" uuid;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "is_used" boolean DEFAULT false;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "mfa_verified" boolean DEFAULT false;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "used_at" timestamptz;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "user_agent" text;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "ip_address" varchar(45);
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "last_seen_at" timestamptz;
+ALTER TABLE "user_tokens" ADD COLUMN IF NOT EXISTS "impersonator_id" uuid;
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_impersonator_id" ON "user_tokens" ("impersonator_id");
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_family_id" ON "user_tokens" ("family_id");
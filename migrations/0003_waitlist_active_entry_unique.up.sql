@@ -0,0 +1,15 @@
+-- Satu user hanya boleh memiliki satu entry aktif per mainan. Entry duplikat yang sudah ada
+-- dibatalkan terlebih dahulu, entry paling awal dipertahankan agar posisi antriannya tidak berubah.
+
+UPDATE "waitlist_entries" SET "status" = 'cancelled'
+WHERE "status" = 'waiting' AND "deleted_at" IS NULL AND EXISTS (
+    SELECT 1 FROM "waitlist_entries" AS "earlier"
+    WHERE "earlier"."user_id" = "waitlist_entries"."user_id"
+      AND "earlier"."toy_id" = "waitlist_entries"."toy_id"
+      AND "earlier"."status" IN ('waiting', 'held')
+      AND "earlier"."deleted_at" IS NULL
+      AND ("earlier"."status" = 'held' OR ("earlier"."created_at", "earlier"."id") < ("waitlist_entries"."created_at", "waitlist_entries"."id"))
+);
+
+CREATE UNIQUE INDEX "idx_waitlist_active_user_toy" ON "waitlist_entries" ("user_id","toy_id")
+WHERE "status" IN ('waiting', 'held') AND "deleted_at" IS NULL;
@@ -0,0 +1,3 @@
+UPDATE "subscriptions" SET "status" = 'cancelled' WHERE "status" = 'pending';
+ALTER TABLE "subscriptions" DROP CONSTRAINT IF EXISTS "chk_subscriptions_status";
+ALTER TABLE "subscriptions" ADD CONSTRAINT "chk_subscriptions_status" CHECK (status IN ('active', 'past_due', 'cancelled'));
@@ -0,0 +1,4 @@
+-- Langganan baru berstatus pending sampai pembayaran pertamanya lunas
+
+ALTER TABLE "subscriptions" DROP CONSTRAINT IF EXISTS "chk_subscriptions_status";
+ALTER TABLE "subscriptions" ADD CONSTRAINT "chk_subscriptions_status" CHECK (status IN ('pending', 'active', 'past_due', 'cancelled'));
@@ -0,0 +1,8 @@
+// Package migrations berisi file SQL berversi yang di-embed ke binary. Setiap versi terdiri dari
+// <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql, versi tidak boleh diubah setelah dirilis.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IAddressRepository interface {
+	IBaseRepository[entity.Address]
+	FindByUserID(ctx context.Context, userID string) ([]entity.Address, error)
+	FindByUserAndId(ctx context.Context, userID string, id string) (entity.Address, error)
+	Create(ctx context.Context, address *entity.Address) error
+	Update(ctx context.Context, address *entity.Address) error
+	DeleteByUserAndId(ctx context.Context, userID string, id string) error
+}
+
+type AddressRepository struct {
+	BaseRepository[entity.Address]
+}
+
+func NewAddressRepository(db *gorm.DB) IAddressRepository {
+	return &AddressRepository{
+		BaseRepository: BaseRepository[entity.Address]{DB: db},
+	}
+}
+
+func (r *AddressRepository) FindByUserID(ctx context.Context, userID string) ([]entity.Address, error) {
+	var entities []entity.Address
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("is_default DESC, created_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *AddressRepository) FindByUserAndId(ctx context.Context, userID string, id string) (entity.Address, error) {
+	var model entity.Address
+	if err := r.DB.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// Create menyimpan alamat baru. Alamat pertama milik user otomatis menjadi alamat default.
+func (r *AddressRepository) Create(ctx context.Context, address *entity.Address) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&entity.Address{}).Where("user_id = ?", address.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(address).Error
+	})
+}
+
+// Update memakai map agar field yang dikosongkan ikut tersimpan. Alamat default tidak bisa
+// dilepas langsung, user harus memilih alamat lain sebagai default.
+func (r *AddressRepository) Update(ctx context.Context, address *entity.Address) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		fields := map[string]interface{}{
+			"label":          address.Label,
+			"recipient_name": address.RecipientName,
+			"phone_number":   address.PhoneNumber,
+			"street":         address.Street,
+			"city":           address.City,
+			"postal_code":    address.PostalCode,
+			"latitude":       address.Latitude,
+			"longitude":      address.Longitude,
+			"notes":          address.Notes,
+		}
+
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, address); err != nil {
+				return err
+			}
+			fields["is_default"] = true
+		}
+
+		result := tx.Model(&entity.Address{}).
+			Where("id = ? AND user_id = ?", address.ID, address.UserID).
+			Updates(fields)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// DeleteByUserAndId menghapus alamat. Jika yang dihapus alamat default, alamat tertua yang
+// tersisa menjadi default.
+func (r *AddressRepository) DeleteByUserAndId(ctx context.Context, userID string, id string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var address entity.Address
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next entity.Address
+		if err := tx.Where("user_id = ?", userID).Order("created_at ASC").First(&next).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+func clearDefaultAddress(tx *gorm.DB, address *entity.Address) error {
+	return tx.Model(&entity.Address{}).
+		Where("user_id = ? AND id <> ? AND is_default = ?", address.UserID, address.ID, true).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IAPIKeyRepository interface {
+	IBaseRepository[entity.APIKey]
+	FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error)
+	UpdateSettings(ctx context.Context, key *entity.APIKey) error
+	Revoke(ctx context.Context, id string) error
+	TouchLastUsed(ctx context.Context, id string, ip string, interval time.Duration) error
+}
+
+type APIKeyRepository struct {
+	BaseRepository[entity.APIKey]
+}
+
+func NewAPIKeyRepository(db *gorm.DB) IAPIKeyRepository {
+	return &APIKeyRepository{
+		BaseRepository: BaseRepository[entity.APIKey]{DB: db},
+	}
+}
+
+func (r *APIKeyRepository) FindAll(ctx context.Context, limit int, offset int) ([]entity.APIKey, int64, error) {
+	var entities []entity.APIKey
+	if err := r.DB.WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var totalData int64
+	if err := r.DB.WithContext(ctx).Model(&entity.APIKey{}).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+	return entities, totalData, nil
+}
+
+func (r *APIKeyRepository) FindByHash(ctx context.Context, keyHash string) (entity.APIKey, error) {
+	var model entity.APIKey
+	if err := r.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// UpdateSettings menyimpan nama, scope, rate limit, dan tanggal kadaluarsa termasuk nilai kosong
+func (r *APIKeyRepository) UpdateSettings(ctx context.Context, key *entity.APIKey) error {
+	return r.DB.WithContext(ctx).
+		Model(key).
+		Select("name", "scopes", "rate_limit", "expires_at").
+		Updates(key).Error
+}
+
+// Revoke mencabut key, mengembalikan ErrAPIKeyRevoked jika key sudah dicabut sebelumnya
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string) error {
+	result := r.DB.WithContext(ctx).
+		Model(&entity.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrAPIKeyRevoked
+	}
+	return nil
+}
+
+// TouchLastUsed mencatat waktu dan IP pemakaian terakhir. Update hanya dilakukan jika catatan
+// sebelumnya lebih lama dari interval, agar setiap request tidak selalu menulis ke database.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, ip string, interval time.Duration) error {
+	now := time.Now()
+	return r.DB.WithContext(ctx).
+		Model(&entity.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-interval)).
+		Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+}
@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"final-project/entity"
+	"final-project/utils/helpers"
+	"fmt"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"time"
+)
+
+const (
+	auditBeforeKey = "audit:before"
+	// auditMaxRows membatasi jumlah baris yang dicatat dari satu update atau delete massal
+	auditMaxRows = 500
+	auditMasked  = "[REDACTED]"
+)
+
+// RegisterAuditCallbacks mencatat setiap create, update, dan delete pada tabel di entity.AuditedEntities
+// ke audit_events. Callback berjalan di transaksi yang sama dengan perubahan datanya, sehingga
+// perubahan dibatalkan jika audit log gagal disimpan. Pelaku diambil dari helpers.AuditActor di context.
+func RegisterAuditCallbacks(db *gorm.DB) error {
+	callback := db.Callback()
+
+	if err := callback.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_create", auditAfterCreate); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:begin_transaction").Before("gorm:update").
+		Register("audit:before_update", auditSnapshot); err != nil {
+		return err
+	}
+	if err := callback.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_update", auditAfterUpdate); err != nil {
+		return err
+	}
+	if err := callback.Delete().After("gorm:begin_transaction").Before("gorm:delete").
+		Register("audit:before_delete", auditSnapshot); err != nil {
+		return err
+	}
+	return callback.Delete().After("gorm:delete").Before("gorm:commit_or_rollback_transaction").
+		Register("audit:after_delete", auditAfterDelete)
+}
+
+func auditEntityType(db *gorm.DB) (string, bool) {
+	if db.Error != nil || db.DryRun || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return "", false
+	}
+	entityType, ok := entity.AuditedEntities[db.Statement.Table]
+	return entityType, ok
+}
+
+// auditSnapshot menyimpan isi baris yang akan diubah atau dihapus sebelum query dijalankan
+func auditSnapshot(db *gorm.DB) {
+	if _, ok := auditEntityType(db); !ok {
+		return
+	}
+
+	conditions := auditConditions(db)
+	if len(conditions) == 0 {
+		return
+	}
+
+	rows, err := findAuditRows(db, conditions)
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("gagal membaca data untuk audit log: %w", err))
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+func auditAfterCreate(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	ids := auditPrimaryKeys(db)
+	if len(ids) == 0 {
+		return
+	}
+
+	rows, err := findAuditRows(db, []clause.Expression{auditPrimaryKeyIn(db, ids)})
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("gagal membaca data untuk audit log: %w", err))
+		return
+	}
+
+	for _, row := range rows {
+		recordAuditEvent(db, entityType, entity.AuditOperationCreate, row, nil, maskAuditRow(row))
+	}
+}
+
+func auditAfterUpdate(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	before := auditBeforeRows(db)
+	if len(before) == 0 {
+		return
+	}
+
+	primaryKey := db.Statement.Schema.PrioritizedPrimaryField.DBName
+	ids := make([]interface{}, 0, len(before))
+	for _, row := range before {
+		ids = append(ids, row[primaryKey])
+	}
+
+	after, err := findAuditRows(db, []clause.Expression{auditPrimaryKeyIn(db, ids)})
+	if err != nil {
+		_ = db.AddError(fmt.Errorf("gagal membaca data untuk audit log: %w", err))
+		return
+	}
+
+	afterByID := make(map[interface{}]map[string]interface{}, len(after))
+	for _, row := range after {
+		afterByID[row[primaryKey]] = row
+	}
+
+	for _, oldRow := range before {
+		newRow, ok := afterByID[oldRow[primaryKey]]
+		if !ok {
+			continue
+		}
+
+		oldValues, newValues := diffAuditRows(oldRow, newRow)
+		if len(newValues) == 0 {
+			continue
+		}
+		recordAuditEvent(db, entityType, entity.AuditOperationUpdate, oldRow, oldValues, newValues)
+	}
+}
+
+func auditAfterDelete(db *gorm.DB) {
+	entityType, ok := auditEntityType(db)
+	if !ok || db.Statement.RowsAffected == 0 {
+		return
+	}
+
+	for _, row := range auditBeforeRows(db) {
+		recordAuditEvent(db, entityType, entity.AuditOperationDelete, row, maskAuditRow(row), nil)
+	}
+}
+
+func recordAuditEvent(db *gorm.DB, entityType string, operation string, row map[string]interface{}, before map[string]interface{}, after map[string]interface{}) {
+	event := entity.AuditEvent{
+		Action:     entityType + "." + operation,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(row[db.Statement.Schema.PrioritizedPrimaryField.DBName]),
+		Before:     before,
+		After:      after,
+		OccurredAt: time.Now(),
+	}
+
+	if actor, ok := helpers.AuditActorFromContext(db.Statement.Context); ok {
+		event.ActorID = actor.UserID
+		if actor.ImpersonatorID != nil {
+			event.ActorID = actor.ImpersonatorID
+			event.ImpersonatedUserID = actor.UserID
+		}
+		event.APIKeyID = actor.APIKeyID
+		event.IPAddress = actor.IPAddress
+		event.UserAgent = actor.UserAgent
+		event.RequestID = actor.RequestID
+	}
+
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&event).Error; err != nil {
+		_ = db.AddError(fmt.Errorf("gagal menyimpan audit log: %w", err))
+	}
+}
+
+// auditConditions menyusun kondisi WHERE dari statement yang sedang berjalan, ditambah primary key
+// dari model dan filter soft delete yang baru ditambahkan gorm saat query dibangun
+func auditConditions(db *gorm.DB) []clause.Expression {
+	var conditions []clause.Expression
+	if where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where); ok {
+		conditions = append(conditions, where.Exprs...)
+	}
+
+	if ids := auditPrimaryKeys(db); len(ids) > 0 {
+		conditions = append(conditions, auditPrimaryKeyIn(db, ids))
+	}
+
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	if deletedAt := db.Statement.Schema.LookUpField("DeletedAt"); deletedAt != nil && !db.Statement.Unscoped {
+		conditions = append(conditions, clause.Eq{Column: clause.Column{Name: deletedAt.DBName}, Value: nil})
+	}
+	return conditions
+}
+
+// auditPrimaryKeys mengambil primary key yang sudah terisi dari struct atau slice pada statement
+func auditPrimaryKeys(db *gorm.DB) []interface{} {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	value := reflect.Indirect(db.Statement.ReflectValue)
+
+	var ids []interface{}
+	switch value.Kind() {
+	case reflect.Struct:
+		if id, zero := field.ValueOf(db.Statement.Context, value); !zero {
+			ids = append(ids, id)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := reflect.Indirect(value.Index(i))
+			if item.Kind() != reflect.Struct {
+				continue
+			}
+			if id, zero := field.ValueOf(db.Statement.Context, item); !zero {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func auditPrimaryKeyIn(db *gorm.DB, ids []interface{}) clause.Expression {
+	return clause.IN{Column: clause.Column{Name: db.Statement.Schema.PrioritizedPrimaryField.DBName}, Values: ids}
+}
+
+// findAuditRows membaca baris mentah dengan koneksi yang sama, termasuk transaksi yang sedang berjalan
+func findAuditRows(db *gorm.DB, conditions []clause.Expression) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	err := db.Session(&gorm.Session{NewDB: true}).
+		Table(db.Statement.Table).
+		Clauses(clause.Where{Exprs: conditions}).
+		Limit(auditMaxRows).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		for column, value := range row {
+			row[column] = normalizeAuditValue(value)
+		}
+	}
+	return rows, nil
+}
+
+func auditBeforeRows(db *gorm.DB) []map[string]interface{} {
+	value, ok := db.InstanceGet(auditBeforeKey)
+	if !ok {
+		return nil
+	}
+	rows, _ := value.([]map[string]interface{})
+	return rows
+}
+
+// diffAuditRows mengembalikan kolom yang berubah saja, updated_at diabaikan karena selalu berubah
+func diffAuditRows(oldRow map[string]interface{}, newRow map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	before := make(map[string]interface{})
+	after := make(map[string]interface{})
+	for column, newValue := range newRow {
+		oldValue := oldRow[column]
+		if column == "updated_at" || reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+
+		if entity.AuditRedactedColumns[column] {
+			oldValue, newValue = auditMasked, auditMasked
+		}
+		before[column] = oldValue
+		after[column] = newValue
+	}
+	return before, after
+}
+
+func maskAuditRow(row map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(row))
+	for column, value := range row {
+		if entity.AuditRedactedColumns[column] {
+			value = auditMasked
+		}
+		masked[column] = value
+	}
+	return masked
+}
+
+// normalizeAuditValue mengubah nilai dari driver ke bentuk yang bisa dibandingkan dan disimpan sebagai JSON
+func normalizeAuditValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case [16]byte:
+		return uuid.UUID(v).String()
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
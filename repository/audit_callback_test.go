@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"final-project/entity"
+	"final-project/utils/helpers"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordedQuery adalah satu statement yang diterima fakeConn beserta argumennya
+type recordedQuery struct {
+	sql  string
+	args []driver.NamedValue
+}
+
+// fakeConn mencatat setiap statement tanpa database. SELECT dijawab berurutan dari results,
+// statement lain dianggap mengenai satu baris.
+type fakeConn struct {
+	queries []recordedQuery
+	results []fakeRows
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (c *fakeConn) Connect(ctx context.Context) (driver.Conn, error) { return c, nil }
+func (c *fakeConn) Driver() driver.Driver                            { return nil }
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error)        { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                                     { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.queries = append(c.queries, recordedQuery{sql: "BEGIN"})
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.queries = append(c.queries, recordedQuery{sql: "COMMIT"})
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.queries = append(c.queries, recordedQuery{sql: "ROLLBACK"})
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.queries = append(c.queries, recordedQuery{sql: query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.queries = append(c.queries, recordedQuery{sql: query, args: args})
+	if !strings.HasPrefix(query, "SELECT") || len(c.results) == 0 {
+		return &fakeRows{}, nil
+	}
+	rows := c.results[0]
+	c.results = c.results[1:]
+	return &rows, nil
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// auditTestDB membuat koneksi gorm di atas fakeConn dengan callback audit terpasang
+func auditTestDB(t *testing.T, results ...fakeRows) (*gorm.DB, *fakeConn) {
+	t.Helper()
+
+	conn := &fakeConn{results: results}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(conn)}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterAuditCallbacks(db); err != nil {
+		t.Fatal(err)
+	}
+	return db, conn
+}
+
+// insertedColumns memetakan kolom INSERT ke argumennya
+func insertedColumns(query recordedQuery) map[string]driver.Value {
+	start := strings.Index(query.sql, "(")
+	end := strings.Index(query.sql, ")")
+	values := make(map[string]driver.Value)
+	for i, column := range strings.Split(query.sql[start+1:end], ",") {
+		if i < len(query.args) {
+			values[strings.Trim(column, `"`)] = query.args[i].Value
+		}
+	}
+	return values
+}
+
+func TestRegisterAuditCallbacks(t *testing.T) {
+	categoryID := uuid.Must(uuid.NewV7())
+	actorID := uuid.Must(uuid.NewV7())
+	row := func(name string) fakeRows {
+		return fakeRows{
+			columns: []string{"id", "name", "description"},
+			values:  [][]driver.Value{{categoryID.String(), name, "Mainan edukasi"}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		results    []fakeRows
+		run        func(db *gorm.DB) error
+		withActor  bool
+		wantAction string
+		wantBefore bool
+		wantAfter  bool
+	}{
+		{
+			name:    "create dicatat dengan pelaku dari context",
+			results: []fakeRows{row("Puzzle")},
+			run: func(db *gorm.DB) error {
+				return db.Create(&entity.ToyCategory{Name: "Puzzle"}).Error
+			},
+			withActor:  true,
+			wantAction: "toy_category.create",
+			wantAfter:  true,
+		},
+		{
+			name:    "update dicatat dengan nilai sebelum dan sesudah",
+			results: []fakeRows{row("Puzzle"), row("Puzzle Kayu")},
+			run: func(db *gorm.DB) error {
+				return db.Model(&entity.ToyCategory{BaseEntity: entity.BaseEntity{ID: categoryID}}).Update("name", "Puzzle Kayu").Error
+			},
+			withActor:  true,
+			wantAction: "toy_category.update",
+			wantBefore: true,
+			wantAfter:  true,
+		},
+		{
+			name:    "delete dicatat dengan isi baris sebelum dihapus",
+			results: []fakeRows{row("Puzzle")},
+			run: func(db *gorm.DB) error {
+				return db.Delete(&entity.ToyCategory{BaseEntity: entity.BaseEntity{ID: categoryID}}).Error
+			},
+			withActor:  true,
+			wantAction: "toy_category.delete",
+			wantBefore: true,
+		},
+		{
+			name:    "proses tanpa pelaku tetap dicatat",
+			results: []fakeRows{row("Puzzle")},
+			run: func(db *gorm.DB) error {
+				return db.Delete(&entity.ToyCategory{BaseEntity: entity.BaseEntity{ID: categoryID}}).Error
+			},
+			wantAction: "toy_category.delete",
+			wantBefore: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, conn := auditTestDB(t, tt.results...)
+
+			ctx := context.Background()
+			if tt.withActor {
+				ctx = helpers.WithAuditActor(ctx, helpers.AuditActor{UserID: &actorID, IPAddress: "10.0.0.1", RequestID: "req-1"})
+			}
+			if err := tt.run(db.WithContext(ctx)); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+
+			auditAt, beginAt, commitAt := -1, -1, -1
+			for i, query := range conn.queries {
+				switch {
+				case strings.HasPrefix(query.sql, `INSERT INTO "audit_events"`):
+					if auditAt != -1 {
+						t.Fatal("more than one audit event written")
+					}
+					auditAt = i
+				case query.sql == "BEGIN":
+					beginAt = i
+				case query.sql == "COMMIT":
+					commitAt = i
+				}
+			}
+			if auditAt == -1 {
+				t.Fatalf("no audit event written, queries: %v", conn.queries)
+			}
+			if beginAt == -1 || beginAt > auditAt || commitAt < auditAt {
+				t.Error("audit event was not written inside the transaction of the change")
+			}
+
+			values := insertedColumns(conn.queries[auditAt])
+			if values["action"] != tt.wantAction {
+				t.Errorf("action = %v, want %s", values["action"], tt.wantAction)
+			}
+			if values["entity_id"] != categoryID.String() {
+				t.Errorf("entity id = %v, want %s", values["entity_id"], categoryID)
+			}
+
+			wantActor := driver.Value(nil)
+			if tt.withActor {
+				wantActor = actorID.String()
+			}
+			if values["actor_id"] != wantActor {
+				t.Errorf("actor id = %v, want %v", values["actor_id"], wantActor)
+			}
+			if tt.withActor && (values["ip_address"] != "10.0.0.1" || values["request_id"] != "req-1") {
+				t.Errorf("ip address = %v, request id = %v, want values from the context", values["ip_address"], values["request_id"])
+			}
+
+			if hasBefore := values["before"] != nil; hasBefore != tt.wantBefore {
+				t.Errorf("before = %v, want present %v", values["before"], tt.wantBefore)
+			}
+			if hasAfter := values["after"] != nil; hasAfter != tt.wantAfter {
+				t.Errorf("after = %v, want present %v", values["after"], tt.wantAfter)
+			}
+			if tt.wantAction == "toy_category.update" {
+				if after, _ := values["after"].(string); !strings.Contains(after, "Puzzle Kayu") || strings.Contains(after, "Mainan edukasi") {
+					t.Errorf("after = %v, want only the changed name", values["after"])
+				}
+			}
+		})
+	}
+}
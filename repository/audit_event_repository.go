@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IAuditEventRepository interface {
+	IBaseRepository[entity.AuditEvent]
+	FindFiltered(ctx context.Context, filter entity.AuditEventFilter, limit int, offset int) ([]entity.AuditEvent, int64, error)
+}
+
+type AuditEventRepository struct {
+	BaseRepository[entity.AuditEvent]
+}
+
+func NewAuditEventRepository(db *gorm.DB) IAuditEventRepository {
+	return &AuditEventRepository{
+		BaseRepository: BaseRepository[entity.AuditEvent]{DB: db},
+	}
+}
+
+// FindFiltered mengembalikan audit event terbaru lebih dulu sesuai filter yang diisi
+func (r *AuditEventRepository) FindFiltered(ctx context.Context, filter entity.AuditEventFilter, limit int, offset int) ([]entity.AuditEvent, int64, error) {
+	query := r.DB.WithContext(ctx).Model(&entity.AuditEvent{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("occurred_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("occurred_at <= ?", *filter.To)
+	}
+
+	var totalData int64
+	if err := query.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []entity.AuditEvent
+	if err := query.Order("occurred_at DESC").Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, totalData, nil
+}
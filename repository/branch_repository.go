@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IBranchRepository interface {
+	IBaseRepository[entity.Branch]
+	FindActive(ctx context.Context) ([]entity.Branch, error)
+	FindActiveById(ctx context.Context, id string) (entity.Branch, error)
+}
+
+type BranchRepository struct {
+	BaseRepository[entity.Branch]
+}
+
+func NewBranchRepository(db *gorm.DB) IBranchRepository {
+	return &BranchRepository{
+		BaseRepository: BaseRepository[entity.Branch]{DB: db},
+	}
+}
+
+// UpdateById memakai map agar cabang bisa dinonaktifkan
+func (r *BranchRepository) UpdateById(ctx context.Context, id string, branch *entity.Branch) error {
+	result := r.DB.WithContext(ctx).Model(&entity.Branch{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"name":          branch.Name,
+		"address":       branch.Address,
+		"city":          branch.City,
+		"phone_number":  branch.PhoneNumber,
+		"latitude":      branch.Latitude,
+		"longitude":     branch.Longitude,
+		"opening_hours": branch.OpeningHours,
+		"is_active":     branch.IsActive,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *BranchRepository) FindActive(ctx context.Context) ([]entity.Branch, error) {
+	var entities []entity.Branch
+	if err := r.DB.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("name ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *BranchRepository) FindActiveById(ctx context.Context, id string) (entity.Branch, error) {
+	var model entity.Branch
+	if err := r.DB.WithContext(ctx).
+		Where("id = ? AND is_active = ?", id, true).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type IBundleRepository interface {
+	IBaseRepository[entity.Bundle]
+}
+
+type BundleRepository struct {
+	BaseRepository[entity.Bundle]
+}
+
+func NewBundleRepository(db *gorm.DB) IBundleRepository {
+	return &BundleRepository{
+		BaseRepository: BaseRepository[entity.Bundle]{DB: db},
+	}
+}
+
+func (r *BundleRepository) FindAll(ctx context.Context, limit int, offset int) ([]entity.Bundle, int64, error) {
+	var entities []entity.Bundle
+	if err := r.DB.WithContext(ctx).
+		Preload("Items.Toy").
+		Limit(limit).Offset(offset).
+		Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var totalData int64
+	if err := r.DB.WithContext(ctx).Model(new(entity.Bundle)).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+	return entities, totalData, nil
+}
+
+func (r *BundleRepository) FindById(ctx context.Context, id string) (entity.Bundle, error) {
+	var model entity.Bundle
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).
+		Preload("Items.Toy").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// UpdateById memperbarui data bundle dan mengganti seluruh komponennya
+func (r *BundleRepository) UpdateById(ctx context.Context, id string, model *entity.Bundle) error {
+	bundleID, err := uuid.FromString(id)
+	if err != nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Bundle{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":         model.Name,
+			"description":  model.Description,
+			"price":        model.Price,
+			"is_available": model.IsAvailable,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Unscoped().Where("bundle_id = ?", id).Delete(&entity.BundleItem{}).Error; err != nil {
+			return err
+		}
+
+		for i := range model.Items {
+			model.Items[i].BundleID = bundleID
+			if err := tx.Create(&model.Items[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *BundleRepository) DeleteById(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", id).Delete(&entity.BundleItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Bundle{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IDeliveryRouteRepository interface {
+	FindJobs(ctx context.Context, branchID string, dayStart time.Time, dayEnd time.Time) ([]entity.DeliveryJob, error)
+	CreateRoutes(ctx context.Context, routes []entity.DeliveryRoute) error
+	FindByBranchAndDate(ctx context.Context, branchID string, routeDate time.Time) ([]entity.DeliveryRoute, error)
+	FindStopById(ctx context.Context, id string) (entity.DeliveryStop, error)
+	CompleteStop(ctx context.Context, stop *entity.DeliveryStop) error
+}
+
+type DeliveryRouteRepository struct {
+	DB *gorm.DB
+}
+
+func NewDeliveryRouteRepository(db *gorm.DB) IDeliveryRouteRepository {
+	return &DeliveryRouteRepository{DB: db}
+}
+
+// FindJobs mengambil rental dengan metode delivery di sebuah cabang yang diantar (RentalDate) atau
+// dijemput (ExpectedReturnDate) pada [dayStart, dayEnd). Rental yang sudah punya titik di rute lain
+// dilewati, kecuali titik tersebut gagal sehingga perlu dijadwalkan ulang.
+func (r *DeliveryRouteRepository) FindJobs(ctx context.Context, branchID string, dayStart time.Time, dayEnd time.Time) ([]entity.DeliveryJob, error) {
+	var jobs []entity.DeliveryJob
+
+	for _, kind := range []struct {
+		stopType string
+		dateCol  string
+		statuses []string
+		extra    string
+	}{
+		{
+			stopType: entity.DeliveryStopDropOff,
+			dateCol:  "rentals.rental_date",
+			statuses: []string{entity.RentalStatusPending},
+			extra:    "rental_fulfilments.delivered_at IS NULL",
+		},
+		{
+			stopType: entity.DeliveryStopCollection,
+			dateCol:  "rentals.expected_return_date",
+			statuses: []string{entity.RentalStatusActive, entity.RentalStatusOverdue},
+			extra:    "rental_fulfilments.return_pickup_slot_start IS NOT NULL AND rental_fulfilments.collected_at IS NULL",
+		},
+	} {
+		planned := r.DB.Model(&entity.DeliveryStop{}).Select("rental_id").
+			Where("type = ? AND status <> ?", kind.stopType, entity.DeliveryStopStatusFailed)
+
+		var fulfilments []entity.RentalFulfilment
+		if err := r.DB.WithContext(ctx).
+			Joins("JOIN rentals ON rentals.id = rental_fulfilments.rental_id AND rentals.deleted_at IS NULL").
+			Where("rental_fulfilments.branch_id = ? AND rental_fulfilments.method = ?", branchID, entity.FulfilmentMethodDelivery).
+			Where("rental_fulfilments.latitude IS NOT NULL AND rental_fulfilments.longitude IS NOT NULL").
+			Where(kind.dateCol+" >= ? AND "+kind.dateCol+" < ?", dayStart, dayEnd).
+			Where("rentals.status IN ?", kind.statuses).
+			Where(kind.extra).
+			Where("rental_fulfilments.rental_id NOT IN (?)", planned).
+			Find(&fulfilments).Error; err != nil {
+			return nil, err
+		}
+
+		for _, fulfilment := range fulfilments {
+			job := entity.DeliveryJob{
+				RentalID:       fulfilment.RentalID,
+				Type:           kind.stopType,
+				Address:        fulfilment.DeliveryAddress,
+				RecipientName:  fulfilment.RecipientName,
+				RecipientPhone: fulfilment.RecipientPhone,
+				AddressNotes:   fulfilment.AddressNotes,
+				Latitude:       *fulfilment.Latitude,
+				Longitude:      *fulfilment.Longitude,
+				SlotStart:      fulfilment.DeliverySlotStart,
+				SlotEnd:        fulfilment.DeliverySlotEnd,
+			}
+			if kind.stopType == entity.DeliveryStopCollection {
+				job.SlotStart = fulfilment.ReturnPickupSlotStart
+				job.SlotEnd = fulfilment.ReturnPickupSlotEnd
+			}
+			jobs = append(jobs, job)
+		}
+	}
+
+	return jobs, nil
+}
+
+func (r *DeliveryRouteRepository) CreateRoutes(ctx context.Context, routes []entity.DeliveryRoute) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range routes {
+			if err := tx.Omit("Branch").Create(&routes[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *DeliveryRouteRepository) FindByBranchAndDate(ctx context.Context, branchID string, routeDate time.Time) ([]entity.DeliveryRoute, error) {
+	var routes []entity.DeliveryRoute
+	if err := r.DB.WithContext(ctx).
+		Where("branch_id = ? AND route_date = ?", branchID, routeDate).
+		Preload("Stops", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sequence ASC")
+		}).
+		Order("number ASC").
+		Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (r *DeliveryRouteRepository) FindStopById(ctx context.Context, id string) (entity.DeliveryStop, error) {
+	var stop entity.DeliveryStop
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&stop).Error; err != nil {
+		return stop, err
+	}
+	return stop, nil
+}
+
+// CompleteStop menyimpan hasil titik dan meneruskannya ke rental. Mainan yang sudah diantar
+// membuat rental aktif, penjemputan hanya dicatat karena pengembalian tetap diproses di cabang
+// setelah kondisi mainan diperiksa. Rute selesai jika tidak ada lagi titik yang pending.
+func (r *DeliveryRouteRepository) CompleteStop(ctx context.Context, stop *entity.DeliveryStop) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.DeliveryStop{}).
+			Where("id = ? AND status = ?", stop.ID, entity.DeliveryStopStatusPending).
+			Updates(map[string]interface{}{
+				"status":         stop.Status,
+				"failure_reason": stop.FailureReason,
+				"completed_at":   stop.CompletedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrDeliveryStopCompleted
+		}
+
+		switch stop.Status {
+		case entity.DeliveryStopStatusDelivered:
+			if err := tx.Model(&entity.Rental{}).
+				Where("id = ? AND status = ?", stop.RentalID, entity.RentalStatusPending).
+				Update("status", entity.RentalStatusActive).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&entity.RentalFulfilment{}).
+				Where("rental_id = ?", stop.RentalID).
+				Update("delivered_at", stop.CompletedAt).Error; err != nil {
+				return err
+			}
+		case entity.DeliveryStopStatusCollected:
+			if err := tx.Model(&entity.RentalFulfilment{}).
+				Where("rental_id = ?", stop.RentalID).
+				Update("collected_at", stop.CompletedAt).Error; err != nil {
+				return err
+			}
+		}
+
+		var pending int64
+		if err := tx.Model(&entity.DeliveryStop{}).
+			Where("route_id = ? AND status = ?", stop.RouteID, entity.DeliveryStopStatusPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending == 0 {
+			return tx.Model(&entity.DeliveryRoute{}).
+				Where("id = ?", stop.RouteID).
+				Update("status", entity.DeliveryRouteStatusCompleted).Error
+		}
+		return nil
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+type IFulfilmentRepository interface {
+	CountSlotBookings(ctx context.Context, branchID string, slotStarts []time.Time) (map[time.Time]int, error)
+}
+
+type FulfilmentRepository struct {
+	DB *gorm.DB
+}
+
+func NewFulfilmentRepository(db *gorm.DB) IFulfilmentRepository {
+	return &FulfilmentRepository{DB: db}
+}
+
+// CountSlotBookings menghitung pengiriman dan penjemputan per slot di sebuah cabang. Rental yang
+// dibatalkan tidak lagi memakai kapasitas.
+func (r *FulfilmentRepository) CountSlotBookings(ctx context.Context, branchID string, slotStarts []time.Time) (map[time.Time]int, error) {
+	return countSlotBookings(r.DB.WithContext(ctx), branchID, slotStarts)
+}
+
+func countSlotBookings(db *gorm.DB, branchID string, slotStarts []time.Time) (map[time.Time]int, error) {
+	counts := make(map[time.Time]int, len(slotStarts))
+	if len(slotStarts) == 0 {
+		return counts, nil
+	}
+
+	activeRentals := db.Session(&gorm.Session{NewDB: true}).Model(&entity.Rental{}).Select("id").Where("status <> ?", entity.RentalStatusCancelled)
+
+	for _, column := range []string{"delivery_slot_start", "return_pickup_slot_start"} {
+		var rows []struct {
+			SlotStart time.Time
+			Total     int
+		}
+		if err := db.Model(&entity.RentalFulfilment{}).
+			Select(column+" AS slot_start, COUNT(*) AS total").
+			Where("branch_id = ? AND "+column+" IN ? AND rental_id IN (?)", branchID, slotStarts, activeRentals).
+			Group(column).
+			Find(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			counts[row.SlotStart.UTC()] += row.Total
+		}
+	}
+
+	return counts, nil
+}
+
+// reserveDeliverySlots memastikan slot pengiriman dan penjemputan rental masih memiliki kapasitas.
+// Setiap slot dikunci dengan advisory lock sampai transaksi selesai, diurutkan berdasarkan waktu
+// agar checkout yang memakai slot yang sama tidak saling menunggu, sehingga dua checkout bersamaan
+// tidak bisa mengambil sisa kapasitas yang sama.
+func reserveDeliverySlots(tx *gorm.DB, fulfilment *entity.RentalFulfilment) error {
+	var starts []time.Time
+	for _, start := range []*time.Time{fulfilment.DeliverySlotStart, fulfilment.ReturnPickupSlotStart} {
+		if start != nil {
+			starts = append(starts, start.UTC())
+		}
+	}
+	if len(starts) == 0 {
+		return nil
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	for _, start := range starts {
+		key := fmt.Sprintf("delivery_slot:%s:%d", fulfilment.BranchID, start.Unix())
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+			return err
+		}
+	}
+
+	counts, err := countSlotBookings(tx, fulfilment.BranchID.String(), starts)
+	if err != nil {
+		return err
+	}
+	for _, start := range starts {
+		if counts[start] >= fulfilment.SlotCapacity {
+			return entity.ErrDeliverySlotFull
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ILoginAttemptRepository interface {
+	Find(ctx context.Context, scope string, key string) (entity.LoginAttempt, error)
+	RecordFailure(ctx context.Context, scope string, key string, now time.Time, window time.Duration) (entity.LoginAttempt, error)
+	Lock(ctx context.Context, scope string, key string, until time.Time) error
+	Reset(ctx context.Context, scope string, key string) error
+}
+
+type LoginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) ILoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+func (r *LoginAttemptRepository) Find(ctx context.Context, scope string, key string) (entity.LoginAttempt, error) {
+	var model entity.LoginAttempt
+	if err := r.DB.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// RecordFailure menambah hitungan gagal secara atomik. Hitungan dimulai ulang jika kegagalan
+// terakhir sudah lebih lama dari window dan lockout sebelumnya sudah berakhir.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, scope string, key string, now time.Time, window time.Duration) (entity.LoginAttempt, error) {
+	model := entity.LoginAttempt{
+		Scope:        scope,
+		Key:          key,
+		FailedCount:  1,
+		LastFailedAt: now,
+	}
+
+	staleBefore := now.Add(-window)
+	err := r.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}, {Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failed_count": gorm.Expr(
+				"CASE WHEN login_attempts.last_failed_at < ? AND (login_attempts.locked_until IS NULL OR login_attempts.locked_until < ?) THEN 1 ELSE login_attempts.failed_count + 1 END",
+				staleBefore, now,
+			),
+			"locked_until": gorm.Expr(
+				"CASE WHEN login_attempts.locked_until < ? THEN NULL ELSE login_attempts.locked_until END",
+				now,
+			),
+			"last_failed_at": now,
+			"updated_at":     now,
+		}),
+	}).Create(&model).Error
+	if err != nil {
+		return model, err
+	}
+
+	return r.Find(ctx, scope, key)
+}
+
+func (r *LoginAttemptRepository) Lock(ctx context.Context, scope string, key string, until time.Time) error {
+	return r.DB.WithContext(ctx).Model(&entity.LoginAttempt{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Update("locked_until", until).Error
+}
+
+func (r *LoginAttemptRepository) Reset(ctx context.Context, scope string, key string) error {
+	return r.DB.WithContext(ctx).Unscoped().
+		Where("scope = ? AND key = ?", scope, key).
+		Delete(&entity.LoginAttempt{}).Error
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IMFARepository interface {
+	FindByUserID(ctx context.Context, userID string) (entity.UserMFA, error)
+	SaveEnrollment(ctx context.Context, mfa *entity.UserMFA) error
+	Confirm(ctx context.Context, mfa *entity.UserMFA, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, mfa *entity.UserMFA, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	DeleteByUserID(ctx context.Context, userID string) error
+}
+
+type MFARepository struct {
+	DB *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) IMFARepository {
+	return &MFARepository{DB: db}
+}
+
+func (r *MFARepository) FindByUserID(ctx context.Context, userID string) (entity.UserMFA, error) {
+	var model entity.UserMFA
+	if err := r.DB.WithContext(ctx).Where("user_id = ?", userID).First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// SaveEnrollment mengganti enrollment yang belum dikonfirmasi dengan secret baru
+func (r *MFARepository) SaveEnrollment(ctx context.Context, mfa *entity.UserMFA) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().
+			Where("user_id = ? AND confirmed_at IS NULL", mfa.UserID).
+			Delete(&entity.UserMFA{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(mfa).Error
+	})
+}
+
+// Confirm mengaktifkan MFA dan menyimpan recovery code baru dalam satu transaksi
+func (r *MFARepository) Confirm(ctx context.Context, mfa *entity.UserMFA, step int64, codeHashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.UserMFA{}).
+			Where("id = ? AND confirmed_at IS NULL", mfa.ID).
+			Updates(map[string]interface{}{
+				"confirmed_at":   now,
+				"last_used_step": step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrMFAAlreadyEnabled
+		}
+
+		if err := replaceRecoveryCodes(tx, mfa.UserID.String(), codeHashes); err != nil {
+			return err
+		}
+
+		mfa.ConfirmedAt = &now
+		mfa.LastUsedStep = step
+		return nil
+	})
+}
+
+// UseStep mencatat time step TOTP yang dipakai. Kode pada step yang sama atau lebih lama ditolak
+// sehingga kode yang sudah dipakai tidak bisa diputar ulang.
+func (r *MFARepository) UseStep(ctx context.Context, mfa *entity.UserMFA, step int64) (bool, error) {
+	result := r.DB.WithContext(ctx).Model(&entity.UserMFA{}).
+		Where("id = ? AND last_used_step < ?", mfa.ID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	result := r.DB.WithContext(ctx).Model(&entity.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+func (r *MFARepository) DeleteByUserID(ctx context.Context, userID string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.UserMFA{}).Error
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codeHashes []string) error {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	ownerID, err := uuid.FromString(userID)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]entity.MFARecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, entity.MFARecoveryCode{UserID: ownerID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
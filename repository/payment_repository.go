@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IPaymentRepository interface {
+	IBaseRepository[entity.Payment]
+	FindLatestBySubscriptionID(ctx context.Context, subscriptionID string) (entity.Payment, error)
+	UpdateStatus(ctx context.Context, payment *entity.Payment) error
+}
+
+type PaymentRepository struct {
+	BaseRepository[entity.Payment]
+}
+
+func NewPaymentRepository(db *gorm.DB) IPaymentRepository {
+	return &PaymentRepository{
+		BaseRepository: BaseRepository[entity.Payment]{DB: db},
+	}
+}
+
+func (r *PaymentRepository) FindLatestBySubscriptionID(ctx context.Context, subscriptionID string) (entity.Payment, error) {
+	var model entity.Payment
+	if err := r.DB.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, payment *entity.Payment) error {
+	return r.DB.WithContext(ctx).Model(payment).
+		Select("transaction_status", "transaction_time", "payment_method", "transaction_id").
+		Updates(payment).Error
+}
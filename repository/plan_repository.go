@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IPlanRepository interface {
+	IBaseRepository[entity.Plan]
+	FindAllActive(ctx context.Context) ([]entity.Plan, error)
+}
+
+type PlanRepository struct {
+	BaseRepository[entity.Plan]
+}
+
+func NewPlanRepository(db *gorm.DB) IPlanRepository {
+	return &PlanRepository{
+		BaseRepository: BaseRepository[entity.Plan]{DB: db},
+	}
+}
+
+func (r *PlanRepository) FindAllActive(ctx context.Context) ([]entity.Plan, error) {
+	var entities []entity.Plan
+	if err := r.DB.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("monthly_fee ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *PlanRepository) UpdateById(ctx context.Context, id string, model *entity.Plan) error {
+	result := r.DB.WithContext(ctx).Model(&entity.Plan{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"name":                model.Name,
+		"description":         model.Description,
+		"monthly_fee":         model.MonthlyFee,
+		"max_concurrent_toys": model.MaxConcurrentToys,
+		"swaps_per_month":     model.SwapsPerMonth,
+		"max_toy_value":       model.MaxToyValue,
+		"is_active":           model.IsActive,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
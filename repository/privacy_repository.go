@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+type IPrivacyRepository interface {
+	FindExportData(ctx context.Context, userID string) (entity.PersonalDataExport, error)
+	CheckErasable(ctx context.Context, userID string) error
+	RequestErasure(ctx context.Context, userID string, requestedAt time.Time) error
+	CancelErasure(ctx context.Context, userID string) error
+	FindDueErasures(ctx context.Context, requestedBefore time.Time) ([]entity.User, error)
+	Anonymize(ctx context.Context, userID string, erasedAt time.Time) error
+}
+
+type PrivacyRepository struct {
+	DB *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) IPrivacyRepository {
+	return &PrivacyRepository{DB: db}
+}
+
+// FindExportData mengumpulkan data yang tersimpan atas nama user. Token sesi tidak ikut diekspor,
+// hanya informasi perangkat dan waktu aktifnya.
+func (r *PrivacyRepository) FindExportData(ctx context.Context, userID string) (entity.PersonalDataExport, error) {
+	var data entity.PersonalDataExport
+	db := r.DB.WithContext(ctx)
+
+	if err := db.Where("id = ?", userID).First(&data.Profile).Error; err != nil {
+		return data, err
+	}
+	data.Profile.Password = ""
+
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Addresses).Error; err != nil {
+		return data, err
+	}
+
+	if err := db.Where("user_id = ?", userID).Preload("RentalItems").Preload("Fulfilment").Order("created_at ASC").Find(&data.Rentals).Error; err != nil {
+		return data, err
+	}
+
+	if err := db.Where("user_id = ?", userID).Preload("Plan").Order("created_at ASC").Find(&data.Subscriptions).Error; err != nil {
+		return data, err
+	}
+
+	if err := db.
+		Where("rental_id IN (?)", db.Model(&entity.Rental{}).Select("id").Where("user_id = ?", userID)).
+		Or("subscription_id IN (?)", db.Model(&entity.Subscription{}).Select("id").Where("user_id = ?", userID)).
+		Order("created_at ASC").
+		Find(&data.Payments).Error; err != nil {
+		return data, err
+	}
+
+	var tokens []entity.UserToken
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&tokens).Error; err != nil {
+		return data, err
+	}
+	data.Sessions = make([]entity.SessionResponse, 0, len(tokens))
+	for _, token := range tokens {
+		data.Sessions = append(data.Sessions, token.ToSessionResponse(token.SessionID()))
+	}
+
+	if err := db.Where("user_id = ?", userID).Order("linked_at ASC").Find(&data.Identities).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Wishlist).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&data.Waitlist).Error; err != nil {
+		return data, err
+	}
+	if err := db.Where("user_id = ?", userID).Order("redeemed_at ASC").Find(&data.VoucherRedemptions).Error; err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+// CheckErasable memastikan tidak ada transaksi yang masih berjalan, karena rental dan pembayaran
+// yang belum selesai masih membutuhkan data kontak user
+func (r *PrivacyRepository) CheckErasable(ctx context.Context, userID string) error {
+	db := r.DB.WithContext(ctx)
+
+	var count int64
+	if err := db.Model(&entity.Rental{}).
+		Where("user_id = ? AND status IN ?", userID, []string{entity.RentalStatusPending, entity.RentalStatusActive, entity.RentalStatusOverdue}).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return entity.ErrErasureOpenRentals
+	}
+
+	if err := db.Model(&entity.Subscription{}).
+		Where("user_id = ? AND status IN ? AND cancel_at_period_end = ?", userID,
+			[]string{entity.SubscriptionStatusPending, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}, false).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return entity.ErrErasureActiveSubscription
+	}
+
+	if err := db.Model(&entity.WaitlistEntry{}).
+		Where("user_id = ? AND status = ?", userID, entity.WaitlistStatusHeld).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return entity.ErrErasureWaitlistHold
+	}
+
+	return nil
+}
+
+func (r *PrivacyRepository) RequestErasure(ctx context.Context, userID string, requestedAt time.Time) error {
+	result := r.DB.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND erasure_requested_at IS NULL", userID).
+		Update("erasure_requested_at", requestedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrErasureAlreadyRequested
+	}
+	return nil
+}
+
+func (r *PrivacyRepository) CancelErasure(ctx context.Context, userID string) error {
+	result := r.DB.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND erasure_requested_at IS NOT NULL AND erased_at IS NULL", userID).
+		Update("erasure_requested_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrErasureNotRequested
+	}
+	return nil
+}
+
+func (r *PrivacyRepository) FindDueErasures(ctx context.Context, requestedBefore time.Time) ([]entity.User, error) {
+	var users []entity.User
+	if err := r.DB.WithContext(ctx).
+		Where("erasure_requested_at <= ? AND erased_at IS NULL", requestedBefore).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Anonymize menghapus data pribadi user secara permanen. Rental, pembayaran, langganan, dan
+// redemption voucher tetap disimpan untuk pembukuan, tetapi tidak lagi terhubung ke data yang
+// bisa mengidentifikasi orangnya.
+func (r *PrivacyRepository) Anonymize(ctx context.Context, userID string, erasedAt time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		placeholder := strings.ReplaceAll(userID, "-", "")
+		result := tx.Model(&entity.User{}).
+			Where("id = ? AND erasure_requested_at IS NOT NULL AND erased_at IS NULL", userID).
+			Updates(map[string]interface{}{
+				"email":             fmt.Sprintf("erased+%s@invalid", placeholder),
+				"username":          "erased_" + placeholder,
+				"password":          "",
+				"full_name":         entity.ErasedUserName,
+				"phone_number":      "",
+				"address":           "",
+				"is_active":         false,
+				"email_verified_at": nil,
+				"erased_at":         erasedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrErasureNotRequested
+		}
+
+		// Data yang hanya berguna selama akun aktif dihapus permanen
+		for _, model := range []interface{}{
+			&entity.UserToken{},
+			&entity.UserActionToken{},
+			&entity.UserIdentity{},
+			&entity.UserMFA{},
+			&entity.MFARecoveryCode{},
+			&entity.Wishlist{},
+			&entity.Address{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Alamat pengiriman yang disalin ke rental ikut dihapus, cabang dan jadwalnya tetap disimpan
+		if err := tx.Model(&entity.RentalFulfilment{}).
+			Where("rental_id IN (?)", tx.Model(&entity.Rental{}).Select("id").Where("user_id = ?", userID)).
+			Updates(map[string]interface{}{
+				"address_id":       nil,
+				"delivery_address": "",
+				"recipient_name":   "",
+				"recipient_phone":  "",
+				"address_notes":    "",
+				"latitude":         nil,
+				"longitude":        nil,
+			}).Error; err != nil {
+			return err
+		}
+
+		// Manifest driver yang sudah lewat juga menyimpan salinan alamat
+		if err := tx.Model(&entity.DeliveryStop{}).
+			Where("rental_id IN (?)", tx.Model(&entity.Rental{}).Select("id").Where("user_id = ?", userID)).
+			Updates(map[string]interface{}{
+				"address":         "",
+				"recipient_name":  "",
+				"recipient_phone": "",
+				"address_notes":   "",
+				"latitude":        0,
+				"longitude":       0,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&entity.WaitlistEntry{}).
+			Where("user_id = ? AND status = ?", userID, entity.WaitlistStatusWaiting).
+			Update("status", entity.WaitlistStatusCancelled).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().
+			Where("scope = ? AND key = ?", entity.LoginAttemptScopeAccount, userID).
+			Delete(&entity.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		// Audit log menyimpan isi data user sebelum diubah, termasuk perubahan di transaksi ini
+		if err := tx.Model(&entity.AuditEvent{}).
+			Where("entity_type = ? AND entity_id = ?", entity.AuditedEntities["users"], userID).
+			Updates(map[string]interface{}{"before": nil, "after": nil}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.AuditEvent{}).
+			Where("actor_id = ? OR impersonated_user_id = ?", userID, userID).
+			Updates(map[string]interface{}{"ip_address": "", "user_agent": ""}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", userID).Delete(&entity.User{}).Error
+	})
+}
@@ -3,7 +3,10 @@ package repository
 import (
 	"context"
 	"final-project/entity"
+	"github.com/gofrs/uuid/v5"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 type IRentalRepository interface {
@@ -11,6 +14,8 @@ type IRentalRepository interface {
 	UpdateToyStock(ctx context.Context, toyID string, quantity int) error
 	ReturnRental(ctx context.Context, rental *entity.Rental) error
 	UpdateRentalItem(ctx context.Context, rentalItem *entity.RentalItem) error
+	FindReturned(ctx context.Context) ([]entity.Rental, error)
+	UpdateFees(ctx context.Context, rental *entity.Rental) error
 }
 
 type RentalRepository struct {
@@ -27,6 +32,7 @@ func (r *RentalRepository) FindById(ctx context.Context, id string) (entity.Rent
 	var model entity.Rental
 	if err := r.DB.WithContext(ctx).Where("id = ?", id).
 		Preload("RentalItems").
+		Preload("Fulfilment.Branch").
 		First(&model).Error; err != nil {
 		return model, err
 	}
@@ -35,28 +41,18 @@ func (r *RentalRepository) FindById(ctx context.Context, id string) (entity.Rent
 
 func (r *RentalRepository) Insert(ctx context.Context, model *entity.Rental) error {
 	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		if err := tx.Omit("RentalItems").Create(model).Error; err != nil {
-			return err
-		}
-
-		for i := range model.RentalItems {
-			model.RentalItems[i].RentalID = model.ID
-			if err := tx.Create(&model.RentalItems[i]).Error; err != nil {
-				return err
-			}
-
-			if err := tx.Model(&entity.Toy{}).Where("id = ?", model.RentalItems[i].ToyID).
-				UpdateColumn("stock", gorm.Expr("stock - ?", model.RentalItems[i].Quantity)).Error; err != nil {
-				return err
-			}
-		}
-		return nil
+		return insertRental(tx, model, nil)
 	})
 }
 
 func (r *RentalRepository) UpdateToyStock(ctx context.Context, toyID string, quantity int) error {
-	return r.DB.WithContext(ctx).Model(&entity.Toy{}).Where("id = ?", toyID).
-		UpdateColumn("stock", gorm.Expr("stock - ?", quantity)).Error
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Toy{}).Where("id = ?", toyID).
+			UpdateColumn("stock", gorm.Expr("stock - ?", quantity)).Error; err != nil {
+			return err
+		}
+		return resetWishlistNotifications(tx, toyID)
+	})
 }
 
 func (r *RentalRepository) ReturnRental(ctx context.Context, rental *entity.Rental) error {
@@ -90,3 +86,128 @@ func (r *RentalRepository) UpdateRentalItem(ctx context.Context, rentalItem *ent
 		return nil
 	})
 }
+
+// FindReturned mengambil rental yang sudah dikembalikan beserta itemnya
+func (r *RentalRepository) FindReturned(ctx context.Context) ([]entity.Rental, error) {
+	var entities []entity.Rental
+	if err := r.DB.WithContext(ctx).
+		Where("actual_return_date IS NOT NULL").
+		Preload("RentalItems").
+		Order("actual_return_date ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *RentalRepository) UpdateFees(ctx context.Context, rental *entity.Rental) error {
+	return r.DB.WithContext(ctx).Model(rental).
+		Select("late_fee", "damage_fee", "total_amount").
+		Updates(rental).Error
+}
+
+// insertRental menyimpan rental beserta itemnya, memeriksa batas paket langganan dan kapasitas
+// slot pengiriman, mencatat pemakaian voucher, dan memotong stok. reserved berisi jumlah unit per mainan yang sudah dipotong
+// sebelumnya (misalnya hold waitlist) sehingga tidak dipotong dua kali.
+func insertRental(tx *gorm.DB, model *entity.Rental, reserved map[uuid.UUID]int) error {
+	if model.SubscriptionID != nil {
+		if err := checkSubscriptionLimits(tx, model); err != nil {
+			return err
+		}
+	}
+
+	if model.Fulfilment != nil && model.Fulfilment.Method == entity.FulfilmentMethodDelivery {
+		if err := reserveDeliverySlots(tx, model.Fulfilment); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Omit("RentalItems").Create(model).Error; err != nil {
+		return err
+	}
+
+	if model.VoucherID != nil {
+		if err := redeemVoucher(tx, model); err != nil {
+			return err
+		}
+	}
+
+	remaining := make(map[uuid.UUID]int, len(reserved))
+	for toyID, quantity := range reserved {
+		remaining[toyID] = quantity
+	}
+
+	for i := range model.RentalItems {
+		item := &model.RentalItems[i]
+		item.RentalID = model.ID
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+
+		quantity := item.Quantity
+		if held := min(remaining[item.ToyID], quantity); held > 0 {
+			remaining[item.ToyID] -= held
+			quantity -= held
+		}
+		if quantity == 0 {
+			continue
+		}
+
+		// Stok dicek ulang di dalam transaksi agar checkout yang bersamaan atau hold waitlist
+		// tidak membuat stok negatif
+		result := tx.Model(&entity.Toy{}).
+			Where("id = ? AND stock >= ?", item.ToyID, quantity).
+			UpdateColumn("stock", gorm.Expr("stock - ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		if err := resetWishlistNotifications(tx, item.ToyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redeemVoucher menambah jumlah pemakaian voucher dan mencatat redemption untuk rental. Baris
+// voucher dikunci sampai transaksi selesai sehingga kuota total dan batas per user tidak bisa
+// terlewati oleh checkout yang berjalan bersamaan.
+func redeemVoucher(tx *gorm.DB, rental *entity.Rental) error {
+	var voucher entity.Voucher
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", *rental.VoucherID).
+		First(&voucher).Error; err != nil {
+		return err
+	}
+
+	if voucher.UsageLimit > 0 && voucher.UsedCount >= voucher.UsageLimit {
+		return entity.ErrVoucherUsageLimitReached
+	}
+
+	if voucher.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&entity.VoucherRedemption{}).
+			Where("voucher_id = ? AND user_id = ?", voucher.ID, rental.UserID).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if err := voucher.CheckUserLimit(used); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&entity.Voucher{}).Where("id = ?", voucher.ID).
+		UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(&entity.VoucherRedemption{
+		VoucherID:      voucher.ID,
+		UserID:         rental.UserID,
+		RentalID:       rental.ID,
+		DiscountAmount: rental.DiscountAmount,
+		RedeemedAt:     time.Now(),
+	}).Error
+}
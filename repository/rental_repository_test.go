@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder mencatat SQL yang dihasilkan gorm dalam mode DryRun
+type sqlRecorder struct {
+	logger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// dryRunDB membuat koneksi gorm yang tidak pernah menghubungi database, query hanya dicatat
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+
+	recorder := &sqlRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost", PreferSimpleProtocol: true}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// DryRun tidak menjalankan query, setiap update dianggap mengenai satu baris
+	if err := db.Callback().Update().After("gorm:update").Register("test:rows_affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 1
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return db, recorder
+}
+
+var stockDecrementPattern = regexp.MustCompile(`UPDATE "toys" SET "stock"=stock - (\d+) WHERE \(id = '([0-9a-f-]+)' AND stock >= (\d+)\)`)
+
+func TestInsertRental_StockDecrement(t *testing.T) {
+	toyA := uuid.Must(uuid.NewV7())
+	toyB := uuid.Must(uuid.NewV7())
+	bundleID := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name     string
+		items    []entity.RentalItem
+		reserved map[uuid.UUID]int
+		want     map[uuid.UUID]string
+	}{
+		{
+			name: "checkout biasa memotong seluruh unit",
+			items: []entity.RentalItem{
+				{ToyID: toyA, Quantity: 2},
+				{ToyID: toyB, Quantity: 1},
+			},
+			want: map[uuid.UUID]string{toyA: "2", toyB: "1"},
+		},
+		{
+			name:     "unit hold waitlist tidak dipotong lagi",
+			items:    []entity.RentalItem{{ToyID: toyA, Quantity: 2}},
+			reserved: map[uuid.UUID]int{toyA: 2},
+			want:     map[uuid.UUID]string{},
+		},
+		{
+			name: "hanya unit di luar hold yang dipotong",
+			items: []entity.RentalItem{
+				{ToyID: toyA, Quantity: 2},
+				{ToyID: toyA, Quantity: 3, BundleID: &bundleID},
+			},
+			reserved: map[uuid.UUID]int{toyA: 4},
+			want:     map[uuid.UUID]string{toyA: "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := dryRunDB(t)
+
+			now := time.Now()
+			rental := &entity.Rental{
+				UserID:             uuid.Must(uuid.NewV7()),
+				Status:             "pending",
+				RentalDate:         now,
+				ExpectedReturnDate: now.AddDate(0, 0, 3),
+				PaymentStatus:      "unpaid",
+				RentalItems:        tt.items,
+			}
+			if err := insertRental(db, rental, tt.reserved); err != nil {
+				t.Fatalf("insertRental() error = %v", err)
+			}
+
+			got := make(map[uuid.UUID]string)
+			for _, statement := range recorder.statements {
+				match := stockDecrementPattern.FindStringSubmatch(statement)
+				if match == nil {
+					continue
+				}
+				toyID := uuid.FromStringOrNil(match[2])
+				if match[3] != match[1] {
+					t.Errorf("stock of toy %s decremented by %s but guarded with stock >= %s", toyID, match[1], match[3])
+				}
+				if _, ok := got[toyID]; ok {
+					t.Errorf("stock of toy %s decremented more than once", toyID)
+				}
+				got[toyID] = match[1]
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("decremented %v, want %v\n%s", got, tt.want, strings.Join(recorder.statements, "\n"))
+			}
+			for toyID, quantity := range tt.want {
+				if got[toyID] != quantity {
+					t.Errorf("toy %s decremented by %s, want %s", toyID, got[toyID], quantity)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertRental_InsufficientStock(t *testing.T) {
+	db, _ := dryRunDB(t)
+	// Update yang tidak mengenai baris berarti stok sudah diambil checkout atau hold lain
+	if err := db.Callback().Update().Replace("test:rows_affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 0
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	rental := &entity.Rental{
+		UserID:             uuid.Must(uuid.NewV7()),
+		Status:             "pending",
+		RentalDate:         now,
+		ExpectedReturnDate: now.AddDate(0, 0, 3),
+		PaymentStatus:      "unpaid",
+		RentalItems:        []entity.RentalItem{{ToyID: uuid.Must(uuid.NewV7()), Quantity: 2}},
+	}
+	if err := insertRental(db, rental, nil); !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("insertRental() error = %v, want %v", err, ErrInsufficientStock)
+	}
+}
+
+// Baris voucher harus dikunci sebelum kuota dan batas per user diperiksa, lalu kuota dinaikkan
+// dan redemption dicatat di transaksi yang sama
+func TestRedeemVoucher_LocksVoucherBeforeCheckingLimits(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	voucherID := uuid.Must(uuid.NewV7())
+	rental := &entity.Rental{
+		BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+		UserID:     uuid.Must(uuid.NewV7()),
+		VoucherID:  &voucherID,
+	}
+	if err := redeemVoucher(db, rental); err != nil {
+		t.Fatalf("redeemVoucher() error = %v", err)
+	}
+
+	wantOrder := []string{
+		`SELECT * FROM "vouchers" WHERE id = `,
+		`UPDATE "vouchers" SET "used_count"=used_count + 1`,
+		`INSERT INTO "voucher_redemptions"`,
+	}
+	next := 0
+	for _, statement := range recorder.statements {
+		if next < len(wantOrder) && strings.HasPrefix(statement, wantOrder[next]) {
+			if next == 0 && !strings.HasSuffix(statement, "FOR UPDATE") {
+				t.Errorf("voucher is read without a row lock: %s", statement)
+			}
+			next++
+		}
+	}
+	if next != len(wantOrder) {
+		t.Errorf("statements out of order, matched %d of %d:\n%s", next, len(wantOrder), strings.Join(recorder.statements, "\n"))
+	}
+}
+
+// Langganan harus dikunci dan batas paket diperiksa sebelum rental disimpan sehingga dua checkout
+// bersamaan tidak bisa melewati batas bulanan
+func TestInsertRental_LocksSubscriptionBeforeInsert(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	subscriptionID := uuid.Must(uuid.NewV7())
+	rental := &entity.Rental{
+		UserID:         uuid.Must(uuid.NewV7()),
+		SubscriptionID: &subscriptionID,
+		RentalItems:    []entity.RentalItem{{ToyID: uuid.Must(uuid.NewV7()), Quantity: 1}},
+	}
+	// DryRun tidak mengembalikan baris sehingga status langganan kosong dan rental ditolak
+	if err := insertRental(db, rental, nil); !errors.Is(err, entity.ErrNoActiveSubscription) {
+		t.Fatalf("insertRental() error = %v, want %v", err, entity.ErrNoActiveSubscription)
+	}
+
+	if len(recorder.statements) == 0 || !strings.HasPrefix(recorder.statements[0], `SELECT * FROM "subscriptions" WHERE id = `) ||
+		!strings.HasSuffix(recorder.statements[0], "FOR UPDATE") {
+		t.Fatalf("subscription is not locked first:\n%s", strings.Join(recorder.statements, "\n"))
+	}
+	for _, statement := range recorder.statements {
+		if strings.HasPrefix(statement, `INSERT INTO "rentals"`) {
+			t.Errorf("rental inserted before plan limits were checked: %s", statement)
+		}
+	}
+}
+
+var advisoryLockPattern = regexp.MustCompile(`SELECT pg_advisory_xact_lock\(hashtext\('delivery_slot:[0-9a-f-]+:(\d+)'\)\)`)
+
+func TestInsertRental_ReservesDeliverySlots(t *testing.T) {
+	delivery := time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)
+	returnPickup := delivery.AddDate(0, 0, 3)
+
+	tests := []struct {
+		name      string
+		method    string
+		capacity  int
+		wantLocks []time.Time
+		wantErr   error
+	}{
+		{
+			name:      "slot pengiriman dan penjemputan dikunci berurutan",
+			method:    entity.FulfilmentMethodDelivery,
+			capacity:  1,
+			wantLocks: []time.Time{delivery, returnPickup},
+		},
+		{
+			name:      "slot penuh ditolak sebelum rental disimpan",
+			method:    entity.FulfilmentMethodDelivery,
+			wantLocks: []time.Time{delivery, returnPickup},
+			wantErr:   entity.ErrDeliverySlotFull,
+		},
+		{
+			name:   "ambil di cabang tidak memakai slot",
+			method: entity.FulfilmentMethodPickup,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := dryRunDB(t)
+
+			fulfilment := &entity.RentalFulfilment{
+				Method:       tt.method,
+				BranchID:     uuid.Must(uuid.NewV7()),
+				SlotCapacity: tt.capacity,
+			}
+			if tt.method == entity.FulfilmentMethodDelivery {
+				// Urutan sengaja dibalik, kunci tetap diambil dari slot paling awal
+				fulfilment.DeliverySlotStart = &returnPickup
+				fulfilment.ReturnPickupSlotStart = &delivery
+			}
+			rental := &entity.Rental{
+				UserID:             uuid.Must(uuid.NewV7()),
+				Status:             "pending",
+				RentalDate:         delivery,
+				ExpectedReturnDate: returnPickup,
+				PaymentStatus:      "unpaid",
+				Fulfilment:         fulfilment,
+				RentalItems:        []entity.RentalItem{{ToyID: uuid.Must(uuid.NewV7()), Quantity: 1}},
+			}
+			if err := insertRental(db, rental, nil); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("insertRental() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var locks []time.Time
+			inserted := false
+			for _, statement := range recorder.statements {
+				if match := advisoryLockPattern.FindStringSubmatch(statement); match != nil {
+					if inserted {
+						t.Errorf("slot locked after the rental was inserted: %s", statement)
+					}
+					unix, _ := strconv.ParseInt(match[1], 10, 64)
+					locks = append(locks, time.Unix(unix, 0).UTC())
+				}
+				if strings.HasPrefix(statement, `INSERT INTO "rentals"`) {
+					inserted = true
+				}
+			}
+
+			if len(locks) != len(tt.wantLocks) {
+				t.Fatalf("locked slots %v, want %v\n%s", locks, tt.wantLocks, strings.Join(recorder.statements, "\n"))
+			}
+			for i := range tt.wantLocks {
+				if !locks[i].Equal(tt.wantLocks[i]) {
+					t.Errorf("lock %d on slot %s, want %s", i, locks[i], tt.wantLocks[i])
+				}
+			}
+			if inserted != (tt.wantErr == nil) {
+				t.Errorf("rental inserted = %v, want %v", inserted, tt.wantErr == nil)
+			}
+		})
+	}
+}
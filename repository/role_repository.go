@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+)
+
+type IRoleRepository interface {
+	IBaseRepository[entity.Role]
+	FindAllWithPermissions(ctx context.Context) ([]entity.Role, error)
+	FindByName(ctx context.Context, name string) (entity.Role, error)
+	FindPermissions(ctx context.Context) ([]entity.Permission, error)
+	FindPermissionsByCodes(ctx context.Context, codes []string) ([]entity.Permission, error)
+	InsertWithPermissions(ctx context.Context, role *entity.Role) error
+	UpdateWithPermissions(ctx context.Context, role *entity.Role) error
+	CountUsersByRole(ctx context.Context, name string) (int64, error)
+	FindRoleNameByUserID(ctx context.Context, userID string) (string, error)
+	AssignToUser(ctx context.Context, userID string, roleName string) error
+}
+
+type RoleRepository struct {
+	BaseRepository[entity.Role]
+}
+
+func NewRoleRepository(db *gorm.DB) IRoleRepository {
+	return &RoleRepository{
+		BaseRepository: BaseRepository[entity.Role]{DB: db},
+	}
+}
+
+func (r *RoleRepository) FindAllWithPermissions(ctx context.Context) ([]entity.Role, error) {
+	var entities []entity.Role
+	if err := r.DB.WithContext(ctx).
+		Preload("Permissions").
+		Order("name ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *RoleRepository) FindById(ctx context.Context, id string) (entity.Role, error) {
+	var model entity.Role
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).Preload("Permissions").First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (entity.Role, error) {
+	var model entity.Role
+	if err := r.DB.WithContext(ctx).Where("name = ?", name).Preload("Permissions").First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *RoleRepository) FindPermissions(ctx context.Context) ([]entity.Permission, error) {
+	var entities []entity.Permission
+	if err := r.DB.WithContext(ctx).Order("code ASC").Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *RoleRepository) FindPermissionsByCodes(ctx context.Context, codes []string) ([]entity.Permission, error) {
+	var entities []entity.Permission
+	if len(codes) == 0 {
+		return entities, nil
+	}
+	if err := r.DB.WithContext(ctx).Where("code IN ?", codes).Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// InsertWithPermissions menyimpan role dan relasi ke permission yang sudah ada tanpa membuat data permission baru
+func (r *RoleRepository) InsertWithPermissions(ctx context.Context, role *entity.Role) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Create(role).Error; err != nil {
+			return err
+		}
+		return savePermissions(tx, role)
+	})
+}
+
+func (r *RoleRepository) UpdateWithPermissions(ctx context.Context, role *entity.Role) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Role{}).Where("id = ?", role.ID).Updates(map[string]interface{}{
+			"name":        role.Name,
+			"description": role.Description,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		return savePermissions(tx, role)
+	})
+}
+
+func (r *RoleRepository) CountUsersByRole(ctx context.Context, name string) (int64, error) {
+	var total int64
+	if err := r.DB.WithContext(ctx).Model(&entity.User{}).Where("role = ?", name).Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// FindRoleNameByUserID mengembalikan nama role user saat ini
+func (r *RoleRepository) FindRoleNameByUserID(ctx context.Context, userID string) (string, error) {
+	var user entity.User
+	if err := r.DB.WithContext(ctx).Select("role").Where("id = ?", userID).First(&user).Error; err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
+func (r *RoleRepository) AssignToUser(ctx context.Context, userID string, roleName string) error {
+	result := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", userID).Update("role", roleName)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func savePermissions(tx *gorm.DB, role *entity.Role) error {
+	for _, permission := range role.Permissions {
+		if err := tx.Exec("INSERT INTO role_permissions (role_id, permission_id) VALUES (?, ?)",
+			role.ID, permission.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteById menghapus role secara permanen agar namanya bisa dipakai lagi
+func (r *RoleRepository) DeleteById(ctx context.Context, id string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE role_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&entity.Role{}, "id = ?", id).Error
+	})
+}
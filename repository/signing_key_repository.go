@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+// signingKeyLockID adalah ID advisory lock postgres agar hanya satu instance yang merotasi key
+const signingKeyLockID = 727001
+
+type ISigningKeyRepository interface {
+	IBaseRepository[entity.SigningKey]
+	FindUsable(ctx context.Context, now time.Time) ([]entity.SigningKey, error)
+	Rotate(ctx context.Context, algorithm string, decide func(latest *entity.SigningKey) (*entity.SigningKey, *time.Time)) (bool, error)
+}
+
+type SigningKeyRepository struct {
+	BaseRepository[entity.SigningKey]
+}
+
+func NewSigningKeyRepository(db *gorm.DB) ISigningKeyRepository {
+	return &SigningKeyRepository{
+		BaseRepository: BaseRepository[entity.SigningKey]{DB: db},
+	}
+}
+
+// FindUsable mengembalikan key yang masih bisa dipakai untuk verifikasi, urut dari yang paling lama aktif
+func (r *SigningKeyRepository) FindUsable(ctx context.Context, now time.Time) ([]entity.SigningKey, error) {
+	var entities []entity.SigningKey
+	if err := r.DB.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("activates_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Rotate menjalankan keputusan rotasi di dalam advisory lock. decide menerima key terbaru untuk
+// algoritma tersebut (nil jika belum ada) dan mengembalikan key baru beserta waktu kadaluarsa
+// key yang digantikan, atau nil jika rotasi belum diperlukan. Key yang sudah kadaluarsa sekalian dihapus.
+func (r *SigningKeyRepository) Rotate(ctx context.Context, algorithm string, decide func(latest *entity.SigningKey) (*entity.SigningKey, *time.Time)) (bool, error) {
+	rotated := false
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", signingKeyLockID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().
+			Where("expires_at IS NOT NULL AND expires_at < ?", time.Now()).
+			Delete(&entity.SigningKey{}).Error; err != nil {
+			return err
+		}
+
+		var latest *entity.SigningKey
+		var model entity.SigningKey
+		err := tx.Where("algorithm = ?", algorithm).Order("activates_at DESC").First(&model).Error
+		if err == nil {
+			latest = &model
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		newKey, oldExpiresAt := decide(latest)
+		if newKey == nil {
+			return nil
+		}
+
+		// Semua key yang masih dipakai signing digantikan, termasuk key algoritma lain
+		// jika algoritma signing baru saja diganti
+		if err := tx.Model(&entity.SigningKey{}).
+			Where("retired_at IS NULL").
+			Updates(map[string]interface{}{
+				"retired_at": newKey.ActivatesAt,
+				"expires_at": oldExpiresAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(newKey).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
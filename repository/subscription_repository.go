@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ISubscriptionRepository interface {
+	IBaseRepository[entity.Subscription]
+	FindCurrentByUserID(ctx context.Context, userID string) (entity.Subscription, error)
+	FindDueForRenewal(ctx context.Context, now time.Time) ([]entity.Subscription, error)
+	CountActiveToys(ctx context.Context, subscriptionID string) (int, error)
+	CountRentalsSince(ctx context.Context, subscriptionID string, since time.Time) (int, error)
+	Subscribe(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error
+	Renew(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error
+	Activate(ctx context.Context, subscription *entity.Subscription) error
+	UpdateStatus(ctx context.Context, id string, status string) error
+	UpdateCancelAtPeriodEnd(ctx context.Context, id string, cancel bool) error
+}
+
+type SubscriptionRepository struct {
+	BaseRepository[entity.Subscription]
+}
+
+func NewSubscriptionRepository(db *gorm.DB) ISubscriptionRepository {
+	return &SubscriptionRepository{
+		BaseRepository: BaseRepository[entity.Subscription]{DB: db},
+	}
+}
+
+// FindCurrentByUserID mengambil langganan pengguna yang belum dibatalkan, termasuk yang masih
+// menunggu pembayaran pertama
+func (r *SubscriptionRepository) FindCurrentByUserID(ctx context.Context, userID string) (entity.Subscription, error) {
+	var model entity.Subscription
+	if err := r.DB.WithContext(ctx).
+		Preload("Plan").
+		Where("user_id = ? AND status IN ?", userID, []string{entity.SubscriptionStatusPending, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}).
+		Order("created_at DESC").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// FindDueForRenewal mengambil langganan yang periodenya sudah berakhir, termasuk langganan yang
+// pembayaran pertamanya tidak pernah lunas
+func (r *SubscriptionRepository) FindDueForRenewal(ctx context.Context, now time.Time) ([]entity.Subscription, error) {
+	var entities []entity.Subscription
+	if err := r.DB.WithContext(ctx).
+		Preload("Plan").
+		Where("status IN ? AND current_period_end <= ?", []string{entity.SubscriptionStatusPending, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue}, now).
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// CountActiveToys menghitung unit mainan yang masih dipegang pelanggan melalui langganan
+func (r *SubscriptionRepository) CountActiveToys(ctx context.Context, subscriptionID string) (int, error) {
+	return countSubscriptionActiveToys(r.DB.WithContext(ctx), subscriptionID)
+}
+
+// CountRentalsSince menghitung rental langganan (tukar mainan) yang dibuat sejak awal periode
+func (r *SubscriptionRepository) CountRentalsSince(ctx context.Context, subscriptionID string, since time.Time) (int, error) {
+	return countSubscriptionRentalsSince(r.DB.WithContext(ctx), subscriptionID, since)
+}
+
+func (r *SubscriptionRepository) Subscribe(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Plan").Create(subscription).Error; err != nil {
+			return err
+		}
+
+		payment.SubscriptionID = &subscription.ID
+		return tx.Create(payment).Error
+	})
+}
+
+// Renew memajukan periode langganan dan mencatat tagihan periode berikutnya
+func (r *SubscriptionRepository) Renew(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Subscription{}).Where("id = ?", subscription.ID).
+			Updates(map[string]interface{}{
+				"status":               subscription.Status,
+				"current_period_start": subscription.CurrentPeriodStart,
+				"current_period_end":   subscription.CurrentPeriodEnd,
+			}).Error; err != nil {
+			return err
+		}
+
+		payment.SubscriptionID = &subscription.ID
+		return tx.Create(payment).Error
+	})
+}
+
+// Activate mengaktifkan langganan yang pembayaran pertamanya sudah lunas, periode dimulai saat aktivasi
+func (r *SubscriptionRepository) Activate(ctx context.Context, subscription *entity.Subscription) error {
+	result := r.DB.WithContext(ctx).Model(&entity.Subscription{}).
+		Where("id = ? AND status = ?", subscription.ID, entity.SubscriptionStatusPending).
+		Updates(map[string]interface{}{
+			"status":               entity.SubscriptionStatusActive,
+			"current_period_start": subscription.CurrentPeriodStart,
+			"current_period_end":   subscription.CurrentPeriodEnd,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	subscription.Status = entity.SubscriptionStatusActive
+	return nil
+}
+
+func (r *SubscriptionRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	return r.DB.WithContext(ctx).Model(&entity.Subscription{}).Where("id = ?", id).
+		Update("status", status).Error
+}
+
+func (r *SubscriptionRepository) UpdateCancelAtPeriodEnd(ctx context.Context, id string, cancel bool) error {
+	return r.DB.WithContext(ctx).Model(&entity.Subscription{}).Where("id = ?", id).
+		Update("cancel_at_period_end", cancel).Error
+}
+
+func countSubscriptionActiveToys(db *gorm.DB, subscriptionID interface{}) (int, error) {
+	var total int64
+	if err := db.Table("rental_items").
+		Joins("JOIN rentals ON rentals.id = rental_items.rental_id AND rentals.deleted_at IS NULL").
+		Where("rentals.subscription_id = ? AND rental_items.status = ? AND rental_items.deleted_at IS NULL",
+			subscriptionID, entity.RentalItemStatusRented).
+		Where("rentals.status IN ?", []string{entity.RentalStatusPending, entity.RentalStatusActive, entity.RentalStatusOverdue}).
+		Select("COALESCE(SUM(rental_items.quantity), 0)").
+		Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+func countSubscriptionRentalsSince(db *gorm.DB, subscriptionID interface{}, since time.Time) (int, error) {
+	var total int64
+	if err := db.Model(&entity.Rental{}).
+		Where("subscription_id = ? AND created_at >= ? AND status <> ?", subscriptionID, since, entity.RentalStatusCancelled).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return int(total), nil
+}
+
+// checkSubscriptionLimits mengunci baris langganan lalu memeriksa ulang batas paket untuk rental
+// yang akan disimpan. Rental langganan lain milik pelanggan yang sama menunggu sampai transaksi
+// ini selesai, sehingga checkout bersamaan tidak bisa melewati batas paket.
+func checkSubscriptionLimits(tx *gorm.DB, rental *entity.Rental) error {
+	var subscription entity.Subscription
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", *rental.SubscriptionID).
+		First(&subscription).Error; err != nil {
+		return err
+	}
+	if subscription.Status != entity.SubscriptionStatusActive {
+		return entity.ErrNoActiveSubscription
+	}
+
+	var plan entity.Plan
+	if err := tx.Where("id = ?", subscription.PlanID).First(&plan).Error; err != nil {
+		return err
+	}
+
+	activeToys, err := countSubscriptionActiveToys(tx, subscription.ID)
+	if err != nil {
+		return err
+	}
+	swapsUsed, err := countSubscriptionRentalsSince(tx, subscription.ID, subscription.CurrentPeriodStart)
+	if err != nil {
+		return err
+	}
+
+	requestedToys := 0
+	for _, item := range rental.RentalItems {
+		requestedToys += item.Quantity
+	}
+	return plan.CheckLimits(activeToys, requestedToys, swapsUsed)
+}
@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"context"
 	"final-project/entity"
 	"gorm.io/gorm"
+	"strings"
 )
 
 type IToyCategoryRepository interface {
 	IBaseRepository[entity.ToyCategory]
+	FindByNames(ctx context.Context, names []string) ([]entity.ToyCategory, error)
 }
 
 type ToyCategoryRepository struct {
@@ -18,3 +21,24 @@ func NewToyCategoryRepository(db *gorm.DB) IToyCategoryRepository {
 		BaseRepository: &BaseRepository[entity.ToyCategory]{DB: db},
 	}
 }
+
+// FindByNames mencari kategori berdasarkan nama tanpa membedakan huruf besar kecil
+func (r *ToyCategoryRepository) FindByNames(ctx context.Context, names []string) ([]entity.ToyCategory, error) {
+	var entities []entity.ToyCategory
+	if len(names) == 0 {
+		return entities, nil
+	}
+
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+
+	if err := r.DB.WithContext(ctx).
+		Where("LOWER(name) IN ?", lowered).
+		Order("created_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
@@ -8,6 +8,10 @@ import (
 
 type IToyRepository interface {
 	IBaseRepository[entity.Toy]
+	UpdateStock(ctx context.Context, id string, stock int) error
+	FindByIdsWithCategories(ctx context.Context, ids []string) ([]entity.Toy, error)
+	InsertMany(ctx context.Context, toys []entity.Toy) error
+	FindInBatches(ctx context.Context, batchSize int, fn func(toys []entity.Toy) error) error
 }
 
 type ToyRepository struct {
@@ -34,6 +38,28 @@ func (r *ToyRepository) Update(ctx context.Context, toy *entity.Toy) error {
 	}).Error
 }
 
+func (r *ToyRepository) UpdateStock(ctx context.Context, id string, stock int) error {
+	result := r.DB.WithContext(ctx).Model(&entity.Toy{}).Where("id = ?", id).UpdateColumn("stock", stock)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return resetWishlistNotifications(r.DB.WithContext(ctx), id)
+}
+
+func (r *ToyRepository) FindByIdsWithCategories(ctx context.Context, ids []string) ([]entity.Toy, error) {
+	var entities []entity.Toy
+	if err := r.DB.WithContext(ctx).
+		Preload("Categories").
+		Where("id IN ?", ids).
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
 func (r *ToyRepository) FindAll(ctx context.Context, limit int, offset int) ([]entity.Toy, int64, error) {
 	var entities []entity.Toy
 	if err := r.DB.WithContext(ctx).
@@ -50,3 +76,27 @@ func (r *ToyRepository) FindAll(ctx context.Context, limit int, offset int) ([]e
 	}
 	return entities, totalData, nil
 }
+
+// InsertMany menyimpan semua mainan beserta kategorinya dalam satu transaksi
+func (r *ToyRepository) InsertMany(ctx context.Context, toys []entity.Toy) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range toys {
+			if err := tx.Create(&toys[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindInBatches membaca seluruh katalog beserta kategori dan gambar per batch, dipakai untuk export
+// agar katalog tidak dimuat sekaligus ke memori
+func (r *ToyRepository) FindInBatches(ctx context.Context, batchSize int, fn func(toys []entity.Toy) error) error {
+	var toys []entity.Toy
+	return r.DB.WithContext(ctx).
+		Preload("Categories").
+		Preload("Images").
+		FindInBatches(&toys, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(toys)
+		}).Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IUserActionTokenRepository interface {
+	IBaseRepository[entity.UserActionToken]
+	FindByHash(ctx context.Context, purpose string, tokenHash string) (entity.UserActionToken, error)
+	ReplaceForUser(ctx context.Context, token *entity.UserActionToken) error
+	ConsumePasswordReset(ctx context.Context, token *entity.UserActionToken, hashedPassword string) error
+	ConsumeEmailVerification(ctx context.Context, token *entity.UserActionToken) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+type UserActionTokenRepository struct {
+	BaseRepository[entity.UserActionToken]
+}
+
+func NewUserActionTokenRepository(db *gorm.DB) IUserActionTokenRepository {
+	return &UserActionTokenRepository{
+		BaseRepository: BaseRepository[entity.UserActionToken]{DB: db},
+	}
+}
+
+func (r *UserActionTokenRepository) FindByHash(ctx context.Context, purpose string, tokenHash string) (entity.UserActionToken, error) {
+	var model entity.UserActionToken
+	if err := r.DB.WithContext(ctx).
+		Where("purpose = ? AND token_hash = ?", purpose, tokenHash).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// ReplaceForUser menyimpan token baru dan menonaktifkan token lain dengan tujuan yang sama,
+// sehingga hanya link yang terakhir dikirim yang bisa dipakai
+func (r *UserActionTokenRepository) ReplaceForUser(ctx context.Context, token *entity.UserActionToken) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.UserActionToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(token).Error
+	})
+}
+
+// ConsumePasswordReset menandai token terpakai dan mengganti password dalam satu transaksi
+func (r *UserActionTokenRepository) ConsumePasswordReset(ctx context.Context, token *entity.UserActionToken, hashedPassword string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := consumeActionToken(tx, token); err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.User{}).
+			Where("id = ?", token.UserID).
+			Update("password", hashedPassword).Error
+	})
+}
+
+// ConsumeEmailVerification menandai token terpakai dan mencatat waktu verifikasi email
+func (r *UserActionTokenRepository) ConsumeEmailVerification(ctx context.Context, token *entity.UserActionToken) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := consumeActionToken(tx, token); err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+}
+
+// DeleteExpired menghapus permanen token reset password dan verifikasi email yang sudah kedaluwarsa
+func (r *UserActionTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Unscoped().
+		Where("expires_at < ?", before).
+		Delete(&entity.UserActionToken{})
+	return result.RowsAffected, result.Error
+}
+
+// consumeActionToken memakai update bersyarat agar token yang sama tidak bisa dipakai dua kali secara paralel
+func consumeActionToken(tx *gorm.DB, token *entity.UserActionToken) error {
+	now := time.Now()
+	result := tx.Model(&entity.UserActionToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrActionTokenInvalid
+	}
+
+	token.UsedAt = &now
+	return nil
+}
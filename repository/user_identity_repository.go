@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type IUserIdentityRepository interface {
+	IBaseRepository[entity.UserIdentity]
+	FindByProviderSubject(ctx context.Context, provider string, subject string) (entity.UserIdentity, error)
+	FindByUserID(ctx context.Context, userID string) ([]entity.UserIdentity, error)
+	DeleteByUserAndProvider(ctx context.Context, userID string, provider string) error
+	InsertUserWithIdentity(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error
+	LinkAndVerifyUser(ctx context.Context, userID string, identity *entity.UserIdentity) error
+	SaveState(ctx context.Context, state *entity.OIDCState) error
+	ConsumeState(ctx context.Context, stateHash string) (entity.OIDCState, error)
+}
+
+type UserIdentityRepository struct {
+	BaseRepository[entity.UserIdentity]
+}
+
+func NewUserIdentityRepository(db *gorm.DB) IUserIdentityRepository {
+	return &UserIdentityRepository{
+		BaseRepository: BaseRepository[entity.UserIdentity]{DB: db},
+	}
+}
+
+func (r *UserIdentityRepository) FindByProviderSubject(ctx context.Context, provider string, subject string) (entity.UserIdentity, error) {
+	var model entity.UserIdentity
+	if err := r.DB.WithContext(ctx).
+		Where("provider = ? AND subject = ?", provider, subject).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *UserIdentityRepository) FindByUserID(ctx context.Context, userID string) ([]entity.UserIdentity, error) {
+	var entities []entity.UserIdentity
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("linked_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// DeleteByUserAndProvider menghapus identity secara permanen agar akun provider yang sama bisa dihubungkan lagi
+func (r *UserIdentityRepository) DeleteByUserAndProvider(ctx context.Context, userID string, provider string) error {
+	result := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Delete(&entity.UserIdentity{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// InsertUserWithIdentity membuat user baru dari login provider beserta identity-nya dalam satu transaksi
+func (r *UserIdentityRepository) InsertUserWithIdentity(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// LinkAndVerifyUser menghubungkan identity ke user yang emailnya belum terverifikasi. Email ditandai
+// terverifikasi dan password dikosongkan, karena password tersebut bisa saja dibuat oleh orang lain
+// yang mendaftar lebih dulu dengan email ini.
+func (r *UserIdentityRepository) LinkAndVerifyUser(ctx context.Context, userID string, identity *entity.UserIdentity) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"email_verified_at": time.Now(),
+				"password":          "",
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(identity).Error
+	})
+}
+
+// SaveState menyimpan state baru sekaligus membersihkan state yang sudah kadaluarsa
+func (r *UserIdentityRepository) SaveState(ctx context.Context, state *entity.OIDCState) error {
+	if err := r.DB.WithContext(ctx).
+		Unscoped().
+		Where("expires_at < ?", time.Now()).
+		Delete(&entity.OIDCState{}).Error; err != nil {
+		return err
+	}
+
+	return r.DB.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState mengambil dan menghapus state dalam satu query sehingga callback yang sama
+// tidak bisa diproses dua kali
+func (r *UserIdentityRepository) ConsumeState(ctx context.Context, stateHash string) (entity.OIDCState, error) {
+	var states []entity.OIDCState
+	if err := r.DB.WithContext(ctx).
+		Unscoped().
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states).Error; err != nil {
+		return entity.OIDCState{}, err
+	}
+
+	if len(states) == 0 {
+		return entity.OIDCState{}, gorm.ErrRecordNotFound
+	}
+	return states[0], nil
+}
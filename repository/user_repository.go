@@ -9,6 +9,9 @@ import (
 type IUserRepository interface {
 	IBaseRepository[entity.User]
 	FindByEmailOrUsername(ctx context.Context, email string) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	UpdateProfile(ctx context.Context, id string, fields map[string]interface{}) error
+	UpdatePassword(ctx context.Context, id string, hashedPassword string) error
 }
 
 type UserRepository struct {
@@ -28,3 +31,28 @@ func (r *UserRepository) FindByEmailOrUsername(ctx context.Context, emailOrUsern
 	}
 	return &user, nil
 }
+
+// FindByEmail mencari user berdasarkan email tanpa membedakan huruf besar kecil
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var user entity.User
+	if err := r.DB.WithContext(ctx).Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile memakai map agar field yang dikosongkan, misalnya nomor telepon, ikut tersimpan
+func (r *UserRepository) UpdateProfile(ctx context.Context, id string, fields map[string]interface{}) error {
+	result := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id string, hashedPassword string) error {
+	return r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Update("password", hashedPassword).Error
+}
@@ -2,15 +2,26 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"final-project/entity"
 	"gorm.io/gorm"
+	"time"
 )
 
+var ErrRefreshTokenUsed = errors.New("refresh token sudah digunakan")
+
 type IUserTokenRepository interface {
 	IBaseRepository[entity.UserToken]
 	FindByAccessToken(ctx context.Context, accessToken string) (entity.UserToken, error)
+	FindByRefreshToken(ctx context.Context, refreshToken string) (entity.UserToken, error)
 	DeleteByAccessToken(ctx context.Context, accessToken string) error
-	UpdateByRefreshToken(ctx context.Context, refreshToken string, entity *entity.UserToken) error
+	Rotate(ctx context.Context, oldToken *entity.UserToken, newToken *entity.UserToken) error
+	BlockFamily(ctx context.Context, token *entity.UserToken) error
+	FindActiveByUserID(ctx context.Context, userID string) ([]entity.UserToken, error)
+	BlockSession(ctx context.Context, userID string, sessionID string) (int64, error)
+	BlockAllByUserID(ctx context.Context, userID string, exceptSessionID string) error
+	UpdateLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 type UserTokenRepository struct {
@@ -31,10 +42,103 @@ func (r *UserTokenRepository) FindByAccessToken(ctx context.Context, accessToken
 	return userToken, nil
 }
 
+func (r *UserTokenRepository) FindByRefreshToken(ctx context.Context, refreshToken string) (entity.UserToken, error) {
+	var userToken entity.UserToken
+	if err := r.DB.WithContext(ctx).Where("refresh_token = ?", refreshToken).First(&userToken).Error; err != nil {
+		return userToken, err
+	}
+	return userToken, nil
+}
+
 func (r *UserTokenRepository) DeleteByAccessToken(ctx context.Context, accessToken string) error {
 	return r.DB.WithContext(ctx).Where("access_token = ?", accessToken).Delete(&entity.UserToken{}).Error
 }
 
-func (r *UserTokenRepository) UpdateByRefreshToken(ctx context.Context, refreshToken string, entity *entity.UserToken) error {
-	return r.DB.WithContext(ctx).Model(entity).Where("refresh_token = ?", refreshToken).Updates(entity).Error
+// Rotate menandai pasangan token lama sebagai terpakai dan menyimpan pasangan baru dalam family yang sama.
+// Update bersyarat mencegah dua request paralel merotasi refresh token yang sama.
+func (r *UserTokenRepository) Rotate(ctx context.Context, oldToken *entity.UserToken, newToken *entity.UserToken) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Token lama dari sebelum rotasi diperkenalkan belum punya family, gunakan ID-nya sendiri
+		familyID := oldToken.ID
+		if oldToken.FamilyID != nil {
+			familyID = *oldToken.FamilyID
+		}
+
+		now := time.Now()
+		result := tx.Model(&entity.UserToken{}).
+			Where("id = ? AND is_used = ? AND is_blocked = ?", oldToken.ID, false, false).
+			Updates(map[string]interface{}{
+				"family_id": familyID,
+				"is_used":   true,
+				"used_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+
+		oldToken.FamilyID = &familyID
+		oldToken.IsUsed = true
+		oldToken.UsedAt = &now
+		newToken.FamilyID = &familyID
+		return tx.Create(newToken).Error
+	})
+}
+
+// BlockFamily memblokir seluruh token yang berasal dari login yang sama
+func (r *UserTokenRepository) BlockFamily(ctx context.Context, token *entity.UserToken) error {
+	familyID := token.ID
+	if token.FamilyID != nil {
+		familyID = *token.FamilyID
+	}
+
+	return r.DB.WithContext(ctx).Model(&entity.UserToken{}).
+		Where("family_id = ? OR id = ?", familyID, token.ID).
+		Update("is_blocked", true).Error
+}
+
+// FindActiveByUserID mengembalikan token terbaru dari setiap sesi yang masih aktif
+func (r *UserTokenRepository) FindActiveByUserID(ctx context.Context, userID string) ([]entity.UserToken, error) {
+	var entities []entity.UserToken
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ? AND is_blocked = ? AND is_used = ? AND refresh_token_expires_at > ?", userID, false, false, time.Now()).
+		Order("created_at DESC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *UserTokenRepository) BlockSession(ctx context.Context, userID string, sessionID string) (int64, error) {
+	result := r.DB.WithContext(ctx).Model(&entity.UserToken{}).
+		Where("user_id = ? AND (family_id = ? OR id = ?) AND is_blocked = ?", userID, sessionID, sessionID, false).
+		Update("is_blocked", true)
+	return result.RowsAffected, result.Error
+}
+
+// BlockAllByUserID memblokir seluruh sesi milik user, kecuali sesi dengan exceptSessionID jika diisi
+func (r *UserTokenRepository) BlockAllByUserID(ctx context.Context, userID string, exceptSessionID string) error {
+	query := r.DB.WithContext(ctx).Model(&entity.UserToken{}).
+		Where("user_id = ? AND is_blocked = ?", userID, false)
+	if exceptSessionID != "" {
+		query = query.Where("COALESCE(family_id, id) <> ?", exceptSessionID)
+	}
+	return query.Update("is_blocked", true).Error
+}
+
+func (r *UserTokenRepository) UpdateLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	return r.DB.WithContext(ctx).Model(&entity.UserToken{}).
+		Where("id = ?", id).
+		UpdateColumn("last_seen_at", lastSeenAt).Error
+}
+
+// DeleteExpired menghapus permanen sesi yang refresh token-nya sudah kedaluwarsa sebelum waktu
+// tersebut. Sesi seperti ini tidak bisa dipakai lagi, termasuk untuk deteksi reuse refresh token.
+func (r *UserTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Unscoped().
+		Where("refresh_token_expires_at < ?", before).
+		Delete(&entity.UserToken{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IVoucherRepository interface {
+	IBaseRepository[entity.Voucher]
+	FindByCode(ctx context.Context, code string) (entity.Voucher, error)
+	CountRedemptionsByUser(ctx context.Context, voucherID string, userID string) (int64, error)
+	FindRedemptions(ctx context.Context, voucherID string, limit int, offset int) ([]entity.VoucherRedemption, int64, error)
+	Report(ctx context.Context, from *time.Time, to *time.Time) ([]entity.VoucherReport, error)
+}
+
+type VoucherRepository struct {
+	BaseRepository[entity.Voucher]
+}
+
+func NewVoucherRepository(db *gorm.DB) IVoucherRepository {
+	return &VoucherRepository{
+		BaseRepository: BaseRepository[entity.Voucher]{DB: db},
+	}
+}
+
+func (r *VoucherRepository) FindAll(ctx context.Context, limit int, offset int) ([]entity.Voucher, int64, error) {
+	var entities []entity.Voucher
+	if err := r.DB.WithContext(ctx).
+		Preload("Categories").
+		Preload("Toys").
+		Order("created_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var totalData int64
+	if err := r.DB.WithContext(ctx).Model(new(entity.Voucher)).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+	return entities, totalData, nil
+}
+
+func (r *VoucherRepository) FindById(ctx context.Context, id string) (entity.Voucher, error) {
+	var model entity.Voucher
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).
+		Preload("Categories").
+		Preload("Toys").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *VoucherRepository) FindByCode(ctx context.Context, code string) (entity.Voucher, error) {
+	var model entity.Voucher
+	if err := r.DB.WithContext(ctx).Where("code = ?", code).
+		Preload("Categories").
+		Preload("Toys").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// Insert menyimpan voucher beserta relasi kategori dan mainan yang sudah ada tanpa membuat data baru
+func (r *VoucherRepository) Insert(ctx context.Context, model *entity.Voucher) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories", "Toys").Create(model).Error; err != nil {
+			return err
+		}
+		return r.saveEligibility(tx, model)
+	})
+}
+
+func (r *VoucherRepository) UpdateById(ctx context.Context, id string, model *entity.Voucher) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.Voucher
+		if err := tx.Where("id = ?", id).First(&existing).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&existing).Updates(map[string]interface{}{
+			"code":           model.Code,
+			"description":    model.Description,
+			"discount_type":  model.DiscountType,
+			"discount_value": model.DiscountValue,
+			"max_discount":   model.MaxDiscount,
+			"min_spend":      model.MinSpend,
+			"valid_from":     model.ValidFrom,
+			"valid_until":    model.ValidUntil,
+			"usage_limit":    model.UsageLimit,
+			"per_user_limit": model.PerUserLimit,
+			"is_active":      model.IsActive,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("DELETE FROM voucher_categories WHERE voucher_id = ?", existing.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM voucher_toys WHERE voucher_id = ?", existing.ID).Error; err != nil {
+			return err
+		}
+
+		model.ID = existing.ID
+		return r.saveEligibility(tx, model)
+	})
+}
+
+func (r *VoucherRepository) saveEligibility(tx *gorm.DB, model *entity.Voucher) error {
+	for _, category := range model.Categories {
+		if err := tx.Exec("INSERT INTO voucher_categories (voucher_id, toy_category_id) VALUES (?, ?)",
+			model.ID, category.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, toy := range model.Toys {
+		if err := tx.Exec("INSERT INTO voucher_toys (voucher_id, toy_id) VALUES (?, ?)",
+			model.ID, toy.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *VoucherRepository) CountRedemptionsByUser(ctx context.Context, voucherID string, userID string) (int64, error) {
+	var total int64
+	if err := r.DB.WithContext(ctx).Model(&entity.VoucherRedemption{}).
+		Where("voucher_id = ? AND user_id = ?", voucherID, userID).
+		Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (r *VoucherRepository) FindRedemptions(ctx context.Context, voucherID string, limit int, offset int) ([]entity.VoucherRedemption, int64, error) {
+	var entities []entity.VoucherRedemption
+	if err := r.DB.WithContext(ctx).
+		Where("voucher_id = ?", voucherID).
+		Order("redeemed_at DESC").
+		Limit(limit).Offset(offset).
+		Find(&entities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var totalData int64
+	if err := r.DB.WithContext(ctx).Model(&entity.VoucherRedemption{}).
+		Where("voucher_id = ?", voucherID).
+		Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+	return entities, totalData, nil
+}
+
+// Report merangkum penggunaan setiap voucher, opsional dibatasi rentang waktu redeem
+func (r *VoucherRepository) Report(ctx context.Context, from *time.Time, to *time.Time) ([]entity.VoucherReport, error) {
+	joinCondition := "vr.voucher_id = v.id AND vr.deleted_at IS NULL"
+	var args []interface{}
+	if from != nil {
+		joinCondition += " AND vr.redeemed_at >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		joinCondition += " AND vr.redeemed_at <= ?"
+		args = append(args, *to)
+	}
+
+	var reports []entity.VoucherReport
+	if err := r.DB.WithContext(ctx).
+		Table("vouchers AS v").
+		Select(`v.id AS voucher_id, v.code, v.usage_limit, v.used_count,
+			COUNT(DISTINCT vr.user_id) AS unique_users,
+			COALESCE(SUM(vr.discount_amount), 0) AS total_discount,
+			COALESCE(SUM(rt.total_rental_price - rt.discount_amount), 0) AS total_revenue,
+			MAX(vr.redeemed_at) AS last_redeemed_at`).
+		Joins("LEFT JOIN voucher_redemptions AS vr ON "+joinCondition, args...).
+		Joins("LEFT JOIN rentals AS rt ON rt.id = vr.rental_id").
+		Where("v.deleted_at IS NULL").
+		Group("v.id, v.code, v.usage_limit, v.used_count").
+		Order("total_discount DESC").
+		Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
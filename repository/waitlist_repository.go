@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrInsufficientStock = errors.New("stok mainan tidak mencukupi")
+
+type IWaitlistRepository interface {
+	IBaseRepository[entity.WaitlistEntry]
+	FindByUserID(ctx context.Context, userID string) ([]entity.WaitlistEntry, error)
+	FindByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error)
+	FindWaitingByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error)
+	FindActiveByUserAndToy(ctx context.Context, userID string, toyID string) (entity.WaitlistEntry, error)
+	FindExpiredHolds(ctx context.Context, now time.Time) ([]entity.WaitlistEntry, error)
+	PlaceHold(ctx context.Context, entry *entity.WaitlistEntry, heldAt time.Time, expiresAt time.Time) error
+	ReleaseHold(ctx context.Context, entry *entity.WaitlistEntry, status string) error
+	UpdateStatus(ctx context.Context, id string, status string) error
+	ConfirmHold(ctx context.Context, entry *entity.WaitlistEntry, rental *entity.Rental) error
+}
+
+type WaitlistRepository struct {
+	BaseRepository[entity.WaitlistEntry]
+}
+
+func NewWaitlistRepository(db *gorm.DB) IWaitlistRepository {
+	return &WaitlistRepository{
+		BaseRepository: BaseRepository[entity.WaitlistEntry]{DB: db},
+	}
+}
+
+func (r *WaitlistRepository) FindById(ctx context.Context, id string) (entity.WaitlistEntry, error) {
+	var model entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).Where("id = ?", id).
+		Preload("Toy").
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *WaitlistRepository) FindByUserID(ctx context.Context, userID string) ([]entity.WaitlistEntry, error) {
+	var entities []entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).
+		Preload("Toy").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *WaitlistRepository) FindByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error) {
+	var entities []entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).
+		Where("toy_id = ? AND status IN ?", toyID, []string{entity.WaitlistStatusWaiting, entity.WaitlistStatusHeld}).
+		Order("created_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindWaitingByToyID mengambil antrian waitlist sebuah mainan sesuai urutan pendaftaran
+func (r *WaitlistRepository) FindWaitingByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error) {
+	var entities []entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).
+		Preload("User").
+		Preload("Toy").
+		Where("toy_id = ? AND status = ?", toyID, entity.WaitlistStatusWaiting).
+		Order("created_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// FindActiveByUserAndToy mengambil entry user untuk sebuah mainan yang masih menunggu atau ditahan
+func (r *WaitlistRepository) FindActiveByUserAndToy(ctx context.Context, userID string, toyID string) (entity.WaitlistEntry, error) {
+	var model entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ? AND toy_id = ? AND status IN ?", userID, toyID, []string{entity.WaitlistStatusWaiting, entity.WaitlistStatusHeld}).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+func (r *WaitlistRepository) FindExpiredHolds(ctx context.Context, now time.Time) ([]entity.WaitlistEntry, error) {
+	var entities []entity.WaitlistEntry
+	if err := r.DB.WithContext(ctx).
+		Where("status = ? AND hold_expires_at < ?", entity.WaitlistStatusHeld, now).
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// PlaceHold memotong stok mainan untuk pelanggan di antrian dan menandai entry sebagai held
+func (r *WaitlistRepository) PlaceHold(ctx context.Context, entry *entity.WaitlistEntry, heldAt time.Time, expiresAt time.Time) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Toy{}).
+			Where("id = ? AND stock >= ?", entry.ToyID, entry.Quantity).
+			UpdateColumn("stock", gorm.Expr("stock - ?", entry.Quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock
+		}
+		if err := resetWishlistNotifications(tx, entry.ToyID); err != nil {
+			return err
+		}
+
+		result = tx.Model(&entity.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, entity.WaitlistStatusWaiting).
+			Updates(map[string]interface{}{
+				"status":          entity.WaitlistStatusHeld,
+				"held_at":         heldAt,
+				"hold_expires_at": expiresAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		entry.Status = entity.WaitlistStatusHeld
+		entry.HeldAt = &heldAt
+		entry.HoldExpiresAt = &expiresAt
+		return nil
+	})
+}
+
+// ReleaseHold mengembalikan stok yang ditahan dan menutup entry dengan status yang diberikan
+func (r *WaitlistRepository) ReleaseHold(ctx context.Context, entry *entity.WaitlistEntry, status string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, entity.WaitlistStatusHeld).
+			Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Model(&entity.Toy{}).Where("id = ?", entry.ToyID).
+			UpdateColumn("stock", gorm.Expr("stock + ?", entry.Quantity)).Error; err != nil {
+			return err
+		}
+
+		entry.Status = status
+		return nil
+	})
+}
+
+func (r *WaitlistRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	return r.DB.WithContext(ctx).Model(&entity.WaitlistEntry{}).Where("id = ?", id).
+		Update("status", status).Error
+}
+
+// ConfirmHold membuat rental dari hold waitlist. Unit yang ditahan sudah dipotong dari stok
+// sehingga hanya sisanya yang dipotong saat rental disimpan.
+func (r *WaitlistRepository) ConfirmHold(ctx context.Context, entry *entity.WaitlistEntry, rental *entity.Rental) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := insertRental(tx, rental, map[uuid.UUID]int{entry.ToyID: entry.Quantity}); err != nil {
+			return err
+		}
+
+		result := tx.Model(&entity.WaitlistEntry{}).
+			Where("id = ? AND status = ?", entry.ID, entity.WaitlistStatusHeld).
+			Updates(map[string]interface{}{
+				"status":    entity.WaitlistStatusConfirmed,
+				"rental_id": rental.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrWaitlistNotHeld
+		}
+
+		entry.Status = entity.WaitlistStatusConfirmed
+		entry.RentalID = &rental.ID
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"final-project/entity"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IWishlistRepository interface {
+	IBaseRepository[entity.Wishlist]
+	FindByUserID(ctx context.Context, userID string) ([]entity.Wishlist, error)
+	FindByUserAndToy(ctx context.Context, userID string, toyID string) (entity.Wishlist, error)
+	FindSubscribersByToyID(ctx context.Context, toyID string) ([]entity.Wishlist, error)
+	UpdateNotifyMe(ctx context.Context, id string, notifyMe bool) error
+	MarkNotified(ctx context.Context, ids []string, notifiedAt time.Time) error
+	DeleteByUserAndToy(ctx context.Context, userID string, toyID string) error
+}
+
+type WishlistRepository struct {
+	BaseRepository[entity.Wishlist]
+}
+
+func NewWishlistRepository(db *gorm.DB) IWishlistRepository {
+	return &WishlistRepository{
+		BaseRepository: BaseRepository[entity.Wishlist]{DB: db},
+	}
+}
+
+func (r *WishlistRepository) FindByUserID(ctx context.Context, userID string) ([]entity.Wishlist, error) {
+	var entities []entity.Wishlist
+	if err := r.DB.WithContext(ctx).
+		Preload("Toy").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *WishlistRepository) FindByUserAndToy(ctx context.Context, userID string, toyID string) (entity.Wishlist, error) {
+	var model entity.Wishlist
+	if err := r.DB.WithContext(ctx).
+		Where("user_id = ? AND toy_id = ?", userID, toyID).
+		First(&model).Error; err != nil {
+		return model, err
+	}
+	return model, nil
+}
+
+// FindSubscribersByToyID mengambil wishlist yang meminta notifikasi dan belum dinotifikasi sejak
+// stok mainan terakhir kali habis
+func (r *WishlistRepository) FindSubscribersByToyID(ctx context.Context, toyID string) ([]entity.Wishlist, error) {
+	var entities []entity.Wishlist
+	if err := r.DB.WithContext(ctx).
+		Preload("User").
+		Preload("Toy").
+		Where("toy_id = ? AND notify_me = ? AND last_notified_at IS NULL", toyID, true).
+		Order("created_at ASC").
+		Find(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (r *WishlistRepository) UpdateNotifyMe(ctx context.Context, id string, notifyMe bool) error {
+	return r.DB.WithContext(ctx).Model(&entity.Wishlist{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"notify_me":        notifyMe,
+			"last_notified_at": nil,
+		}).Error
+}
+
+func (r *WishlistRepository) MarkNotified(ctx context.Context, ids []string, notifiedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.WithContext(ctx).Model(&entity.Wishlist{}).Where("id IN ?", ids).
+		UpdateColumn("last_notified_at", notifiedAt).Error
+}
+
+func (r *WishlistRepository) DeleteByUserAndToy(ctx context.Context, userID string, toyID string) error {
+	// Hapus permanen agar unique index user/toy tidak bentrok saat ditambahkan kembali
+	result := r.DB.WithContext(ctx).Unscoped().Where("user_id = ? AND toy_id = ?", userID, toyID).Delete(&entity.Wishlist{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// resetWishlistNotifications menghapus last_notified_at wishlist sebuah mainan jika stoknya habis,
+// sehingga pelanggan dinotifikasi lagi saat stok kembali. Dipanggil setiap kali stok dikurangi.
+func resetWishlistNotifications(tx *gorm.DB, toyID interface{}) error {
+	return tx.Model(&entity.Wishlist{}).
+		Where("toy_id = ? AND last_notified_at IS NOT NULL", toyID).
+		Where("EXISTS (SELECT 1 FROM toys WHERE toys.id = wishlists.toy_id AND toys.stock <= 0)").
+		UpdateColumn("last_notified_at", nil).Error
+}
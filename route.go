@@ -1,60 +1,277 @@
 package main
 
 import (
+	"context"
 	"final-project/config"
 	"final-project/controller"
+	"final-project/entity"
 	"final-project/middleware"
 	"final-project/repository"
 	"final-project/service"
 	"final-project/utils/helpers"
+	"final-project/utils/lifecycle"
+	"final-project/utils/mailer"
+	"final-project/utils/notifier"
+	"final-project/utils/oidc"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/gorm"
+	"time"
 )
 
-func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
+func setupRoutes(cfg *config.Config, db *gorm.DB, lc *lifecycle.Lifecycle) *gin.Engine {
 	if cfg.IsProd {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	r := gin.Default()
+	r.Use(middleware.RequestID())
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Health check
+	healthController := controller.NewHealthController(lc, db)
+	r.GET("/health/live", healthController.Live)
+	r.GET("/health/ready", healthController.Ready)
+
 	// JWT Konfigurasi
-	jwtHelper := helpers.NewJWTHelper(cfg.JWTSecret, cfg.AccessTokenExp, cfg.RefreshTokenExp, cfg.Issuer)
+	keySet := helpers.NewKeySet()
+	jwtHelper := helpers.NewJWTHelper(
+		cfg.JWTSecret,
+		cfg.JWTSigningAlg,
+		cfg.JWTAcceptHS256,
+		keySet,
+		cfg.AccessTokenExp,
+		cfg.RefreshTokenExp,
+		cfg.Issuer,
+	)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	signingKeySvc := service.NewSigningKeyService(signingKeyRepo, keySet, service.SigningKeyPolicy{
+		Algorithm:        cfg.JWTSigningAlg,
+		RotationInterval: time.Duration(cfg.JWTKeyRotationDays) * 24 * time.Hour,
+		Prepublish:       time.Duration(cfg.JWTKeyPrepublishMinutes) * time.Minute,
+		MaxTokenLifetime: jwtHelper.RefreshTokenExpiry(),
+	})
+	if err := signingKeySvc.EnsureKeys(context.Background()); err != nil {
+		helpers.Logger.Fatalf("Failed to load signing keys: %v", err)
+	}
+	lc.Go("signing key rotation", func(ctx context.Context) {
+		signingKeySvc.RunRotationWorker(ctx, time.Duration(cfg.JWTKeyRefreshInterval)*time.Second)
+	})
+	jwksController := controller.NewJWKSController(*jwtHelper)
+	r.GET("/.well-known/jwks.json", jwksController.FindAll)
 
 	// User token
 	userTokenRepo := repository.NewUserTokenRepository(db)
-	userTokenSvc := service.NewTokenService(userTokenRepo, *jwtHelper)
 
 	// Users
 	userRepo := repository.NewUserRepository(db)
-	userSvc := service.NewUserService(userRepo, userTokenRepo, *jwtHelper)
-	userController := controller.NewUserController(userSvc, userTokenSvc)
+	userTokenSvc := service.NewTokenService(userTokenRepo, userRepo, *jwtHelper, time.Duration(cfg.SessionCacheTTL)*time.Second)
+	mfaRepo := repository.NewMFARepository(db)
+	mfaSvc := service.NewMFAService(mfaRepo, userRepo, cfg.Issuer, cfg.MFAEnforcedRoles)
+	mfaController := controller.NewMFAController(mfaSvc)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	userSvc := service.NewUserService(
+		userRepo,
+		userTokenRepo,
+		loginAttemptRepo,
+		*jwtHelper,
+		service.LoginPolicy{
+			MaxAttempts:     cfg.LoginMaxAttempts,
+			IPMaxAttempts:   cfg.LoginIPMaxAttempts,
+			LockoutDuration: time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+			BaseDelay:       time.Duration(cfg.LoginDelayBaseSeconds) * time.Second,
+		},
+		mfaSvc,
+		time.Duration(cfg.MFATokenTTL)*time.Minute,
+	)
+	mail := mailer.NewMailer(mailer.Config{
+		Driver:   cfg.MailerDriver,
+		From:     cfg.MailerFrom,
+		FilePath: cfg.MailerFilePath,
+		SMTPHost: cfg.SMTPHost,
+		SMTPPort: cfg.SMTPPort,
+		SMTPUser: cfg.SMTPUsername,
+		SMTPPass: cfg.SMTPPassword,
+	})
+	actionTokenRepo := repository.NewUserActionTokenRepository(db)
+	accountSvc := service.NewAccountService(
+		userRepo,
+		actionTokenRepo,
+		userTokenSvc,
+		mail,
+		cfg.AppBaseURL,
+		time.Duration(cfg.PasswordResetTokenTTL)*time.Minute,
+		time.Duration(cfg.EmailVerificationTokenTTL)*time.Hour,
+	)
+	accountController := controller.NewAccountController(accountSvc)
+	profileController := controller.NewProfileController(accountSvc)
+	cookieOptions := controller.CookieOptions{
+		Domain:   cfg.CookieDomain,
+		Secure:   cfg.CookieSecure,
+		SameSite: cfg.CookieSameSiteMode(),
+	}
+	// Personal data export & account erasure
+	privacyRepo := repository.NewPrivacyRepository(db)
+	privacySvc := service.NewPrivacyService(privacyRepo, userTokenSvc, time.Duration(cfg.AccountErasureGraceDays)*24*time.Hour)
+	privacyController := controller.NewPrivacyController(privacySvc)
+	lc.Go("account erasure", func(ctx context.Context) {
+		privacySvc.RunErasureWorker(ctx, time.Duration(cfg.AccountErasureInterval)*time.Minute)
+	})
+
+	userController := controller.NewUserController(userSvc, userTokenSvc, accountSvc, privacySvc, cookieOptions)
+	sessionController := controller.NewSessionController(userTokenSvc, cookieOptions)
+
+	// OpenID Connect login
+	oidcProviders := make([]*oidc.Provider, 0, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         provider.Name,
+			Issuer:       provider.Issuer,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}))
+	}
+	userIdentityRepo := repository.NewUserIdentityRepository(db)
+	oidcSvc := service.NewOIDCService(
+		oidcProviders,
+		userIdentityRepo,
+		userRepo,
+		userSvc,
+		userTokenSvc,
+		time.Duration(cfg.OIDCStateTTL)*time.Minute,
+	)
+	oidcController := controller.NewOIDCController(oidcSvc, cookieOptions, cfg.OIDCStateTTL*60)
+
+	// Roles and permissions
+	roleRepo := repository.NewRoleRepository(db)
+	rbacSvc := service.NewRBACService(roleRepo, userTokenSvc, time.Duration(cfg.SessionCacheTTL)*time.Second)
+	roleController := controller.NewRoleController(rbacSvc)
+
+	// Partner API keys
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	apiKeySvc := service.NewAPIKeyService(apiKeyRepo, cfg.APIKeyRateLimit)
+	apiKeyController := controller.NewAPIKeyController(apiKeySvc)
+
+	// Audit log & impersonation
+	auditRepo := repository.NewAuditEventRepository(db)
+	auditSvc := service.NewAuditService(auditRepo)
+	impersonationSvc := service.NewImpersonationService(
+		userRepo,
+		userTokenRepo,
+		roleRepo,
+		auditSvc,
+		*jwtHelper,
+		time.Duration(cfg.ImpersonationTTL)*time.Minute,
+	)
+	impersonationController := controller.NewImpersonationController(impersonationSvc)
+	auditController := controller.NewAuditController(auditSvc)
 
 	// Toy category
 	toyCategoryRepo := repository.NewToyCategoryRepository(db)
 	toyCategorySvc := service.NewToyCategoryService(toyCategoryRepo)
 	toyCategoryController := controller.NewToyCategoryController(toyCategorySvc)
 
+	// Notifier
+	notif := notifier.NewNotifier(cfg.NotifierDriver, cfg.NotifierFilePath)
+
 	// Toy
 	toyRepo := repository.NewToyRepository(db)
-	toySvc := service.NewToyService(toyRepo)
+
+	// Wishlist
+	wishlistRepo := repository.NewWishlistRepository(db)
+	wishlistSvc := service.NewWishlistService(wishlistRepo, toyRepo, notif)
+	wishlistController := controller.NewWishlistController(wishlistSvc)
+
+	// Branch, address & fulfilment
+	branchRepo := repository.NewBranchRepository(db)
+	branchSvc := service.NewBranchService(branchRepo)
+	branchController := controller.NewBranchController(branchSvc)
+
+	addressRepo := repository.NewAddressRepository(db)
+	addressSvc := service.NewAddressService(addressRepo)
+	addressController := controller.NewAddressController(addressSvc)
+
+	// Format zona, slot, dan zona waktu sudah divalidasi oleh Config.Validate
+	deliveryZones, _ := cfg.DeliveryZoneList()
+	deliverySlots, _ := cfg.DeliverySlotWindows()
+	deliveryLocation, _ := time.LoadLocation(cfg.DeliveryTimezone)
+	fulfilmentRepo := repository.NewFulfilmentRepository(db)
+	fulfilmentSvc := service.NewFulfilmentService(fulfilmentRepo, branchRepo, addressRepo, service.FulfilmentPolicy{
+		Zones:        deliveryZones,
+		SlotWindows:  deliverySlots,
+		SlotCapacity: cfg.DeliverySlotCapacity,
+		LeadTime:     time.Duration(cfg.DeliveryLeadHours) * time.Hour,
+		Location:     deliveryLocation,
+	})
+	fulfilmentController := controller.NewFulfilmentController(fulfilmentSvc)
+
+	deliveryRouteRepo := repository.NewDeliveryRouteRepository(db)
+	deliveryRouteSvc := service.NewDeliveryRouteService(deliveryRouteRepo, branchRepo, deliveryLocation)
+	deliveryRouteController := controller.NewDeliveryRouteController(deliveryRouteSvc)
+
+	// Waitlist
+	waitlistRepo := repository.NewWaitlistRepository(db)
+	waitlistSvc := service.NewWaitlistService(waitlistRepo, userRepo, toyRepo, notif, fulfilmentSvc, time.Duration(cfg.WaitlistHoldHours)*time.Hour)
+	waitlistController := controller.NewWaitlistController(waitlistSvc)
+	lc.Go("waitlist expiry", func(ctx context.Context) {
+		waitlistSvc.RunExpiryWorker(ctx, time.Duration(cfg.WaitlistExpiryInterval)*time.Minute)
+	})
+
+	toySvc := service.NewToyService(toyRepo, wishlistSvc, waitlistSvc)
 	toyController := controller.NewToyController(toySvc)
 
+	toyCatalogSvc := service.NewToyCatalogService(toyRepo, toyCategoryRepo)
+	toyCatalogController := controller.NewToyCatalogController(toyCatalogSvc)
+
 	// Toy Images
 	toyImageRepo := repository.NewToyImageRepository(db)
 	toyImageSvc := service.NewToyImageService(toyImageRepo)
 	toyImageController := controller.NewToyImageController(toyImageSvc)
 
+	// Bundle
+	bundleRepo := repository.NewBundleRepository(db)
+	bundleSvc := service.NewBundleService(bundleRepo, toyRepo)
+	bundleController := controller.NewBundleController(bundleSvc)
+
+	// Voucher
+	voucherRepo := repository.NewVoucherRepository(db)
+	voucherSvc := service.NewVoucherService(voucherRepo, toyRepo, toyCategoryRepo)
+	voucherController := controller.NewVoucherController(voucherSvc)
+
+	// Plan & subscription
+	planRepo := repository.NewPlanRepository(db)
+	planSvc := service.NewPlanService(planRepo)
+	planController := controller.NewPlanController(planSvc)
+
+	paymentRepo := repository.NewPaymentRepository(db)
+	subscriptionRepo := repository.NewSubscriptionRepository(db)
+	subscriptionSvc := service.NewSubscriptionService(subscriptionRepo, planRepo, paymentRepo, toyRepo)
+	subscriptionController := controller.NewSubscriptionController(subscriptionSvc)
+	lc.Go("subscription renewal", func(ctx context.Context) {
+		subscriptionSvc.RunRenewalWorker(ctx, time.Duration(cfg.SubscriptionRenewalInterval)*time.Minute)
+	})
+
 	// Rental
 	rentalRepo := repository.NewRentalRepository(db)
-	rentalSvc := service.NewRentalService(rentalRepo, userRepo, toyRepo)
+	rentalSvc := service.NewRentalService(rentalRepo, userRepo, toyRepo, bundleRepo, wishlistSvc, waitlistSvc, voucherSvc, subscriptionSvc, fulfilmentSvc)
 	rentalController := controller.NewRentalController(rentalSvc)
 
 	// Middleware
-	authMiddleware := middleware.NewAuthMiddleware(*jwtHelper, userTokenSvc)
+	authMiddleware := middleware.NewAuthMiddleware(*jwtHelper, userTokenSvc, mfaSvc, rbacSvc, apiKeySvc, auditSvc)
+	if cfg.CSRFEnabled {
+		// Endpoint sebelum login tidak memakai cookie sesi, browser dengan cookie lama tetap bisa login ulang
+		csrfExempt := append([]string{
+			"/api/user/auth/register",
+			"/api/user/auth/login",
+			"/api/user/auth/mfa",
+			"/api/user/auth/password/",
+			"/api/user/auth/email/verify",
+		}, cfg.CSRFExemptPaths...)
+		r.Use(middleware.NewCSRFMiddleware(csrfExempt).Protect())
+	}
 
 	// Public routes
 	public := r.Group("/api")
@@ -64,6 +281,14 @@ func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
 		{
 			auth.POST("/auth/register", userController.Insert)
 			auth.POST("/auth/login", userController.Login)
+			auth.POST("/auth/refresh", userController.Refresh)
+			auth.POST("/auth/mfa", userController.VerifyMFA)
+			auth.POST("/auth/password/forgot", accountController.ForgotPassword)
+			auth.POST("/auth/password/reset", accountController.ResetPassword)
+			auth.POST("/auth/email/verify", accountController.VerifyEmail)
+			auth.GET("/auth/oidc/providers", oidcController.Providers)
+			auth.GET("/auth/oidc/:provider/login", oidcController.Login)
+			auth.GET("/auth/oidc/:provider/callback", oidcController.Callback)
 		}
 
 		// Toy category routes
@@ -79,6 +304,44 @@ func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
 			toy.GET("", toyController.FindAll)
 			toy.GET("/:id", toyController.FinById)
 		}
+
+		// Bundle routes
+		bundle := public.Group("/bundle")
+		{
+			bundle.GET("", bundleController.FindAll)
+			bundle.GET("/:id", bundleController.FindById)
+		}
+
+		// Plan routes
+		plan := public.Group("/plans")
+		{
+			plan.GET("", planController.FindAll)
+			plan.GET("/:id", planController.FindById)
+		}
+
+		// Branch & delivery slot routes
+		branch := public.Group("/branches")
+		{
+			branch.GET("", branchController.FindActive)
+			branch.GET("/:id", branchController.FinById)
+		}
+		public.GET("/fulfilment/slots", fulfilmentController.Slots)
+	}
+
+	// Routes reachable before two-factor is completed, for enrollment and logout
+	mfaSetup := r.Group("/api")
+	mfaSetup.Use(authMiddleware.MFASetupMiddleware())
+	{
+		mfaSetup.DELETE("/user/auth/logout", userController.Logout)
+
+		mfa := mfaSetup.Group("/user/mfa")
+		mfa.Use(authMiddleware.DenyImpersonation())
+		{
+			mfa.POST("/enroll", mfaController.Enroll)
+			mfa.POST("/confirm", mfaController.Confirm)
+			mfa.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
+			mfa.DELETE("", mfaController.Disable)
+		}
 	}
 
 	// Protected routes
@@ -88,32 +351,171 @@ func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
 		// User routes
 		auth := protected.Group("/user")
 		{
-			auth.PUT("/auth/:id", userController.UpdateById)
-			auth.DELETE("/auth/:id", userController.DeleteById)
-			auth.DELETE("/auth/logout", userController.Logout)
+			auth.PUT("/auth/:id", authMiddleware.DenyImpersonation(), userController.UpdateById)
+			auth.DELETE("/auth/:id", authMiddleware.DenyImpersonation(), userController.DeleteById)
+			auth.POST("/auth/email/resend", accountController.ResendVerification)
+		}
+
+		// Profile and personal data routes
+		me := protected.Group("/user/me")
+		{
+			me.GET("", profileController.FindMe)
+			me.PATCH("", authMiddleware.DenyImpersonation(), profileController.UpdateMe)
+			me.POST("/password", authMiddleware.DenyImpersonation(), profileController.ChangePassword)
+			me.GET("/export", authMiddleware.DenyImpersonation(), privacyController.Export)
+			me.DELETE("/erasure", authMiddleware.DenyImpersonation(), privacyController.CancelErasure)
+		}
+
+		// Session routes
+		session := protected.Group("/user/sessions")
+		{
+			session.GET("", sessionController.FindMine)
+			session.DELETE("", authMiddleware.DenyImpersonation(), sessionController.DeleteAll)
+			session.DELETE("/:id", authMiddleware.DenyImpersonation(), sessionController.DeleteById)
 		}
 
+		// Linked provider identity routes
+		identity := protected.Group("/user/identities")
+		identity.Use(authMiddleware.DenyImpersonation())
+		{
+			identity.GET("", oidcController.FindIdentities)
+			identity.GET("/:provider/link", oidcController.Link)
+			identity.DELETE("/:provider", oidcController.Unlink)
+		}
+
+		// Wishlist routes
+		wishlist := protected.Group("/user/wishlist")
+		{
+			wishlist.GET("", wishlistController.FindAll)
+			wishlist.POST("", wishlistController.Insert)
+			wishlist.PUT("/:toy_id/notify", wishlistController.UpdateNotify)
+			wishlist.DELETE("/:toy_id", wishlistController.DeleteByToyId)
+		}
+
+		// Address book routes
+		address := protected.Group("/user/addresses")
+		{
+			address.GET("", addressController.FindMine)
+			address.POST("", addressController.Insert)
+			address.PUT("/:id", addressController.UpdateById)
+			address.DELETE("/:id", addressController.DeleteById)
+		}
+		protected.GET("/fulfilment/quote", fulfilmentController.Quote)
+
 		// Rental routes
 		rental := protected.Group("/rental")
 		{
 			rental.POST("", rentalController.Insert)
 			rental.PUT("/:id", rentalController.UpdateById)
 		}
+
+		// Subscription routes
+		subscription := protected.Group("/user/subscription")
+		{
+			subscription.GET("", subscriptionController.FindMine)
+			subscription.POST("", subscriptionController.Subscribe)
+			subscription.DELETE("", authMiddleware.DenyImpersonation(), subscriptionController.Cancel)
+		}
+
+		// Waitlist routes
+		waitlist := protected.Group("/rental/waitlist")
+		{
+			waitlist.GET("", waitlistController.FindAll)
+			waitlist.POST("", waitlistController.Insert)
+			waitlist.POST("/:id/confirm", waitlistController.Confirm)
+			waitlist.DELETE("/:id", waitlistController.Cancel)
+		}
 	}
 
-	// Admin routes
+	// Admin routes, each route requires a permission of the user's role or a scope of the partner API key
 	admin := r.Group("/api")
-	admin.Use(authMiddleware.AdminMiddleware())
 	{
 		// Admin user routes
 		auth := admin.Group("/admin")
 		{
-			auth.GET("/users", userController.FindAll)
-			auth.GET("/user/:id", userController.FinById)
+			auth.GET("/users", authMiddleware.RequirePermission(entity.PermissionUserRead), userController.FindAll)
+			auth.GET("/user/:id", authMiddleware.RequirePermission(entity.PermissionUserRead), userController.FinById)
+			auth.GET("/users/:id/sessions", authMiddleware.RequirePermission(entity.PermissionUserRead), sessionController.FindByUserId)
+			auth.DELETE("/users/:id/sessions", authMiddleware.RequirePermission(entity.PermissionUserManage), sessionController.DeleteByUserId)
+			auth.POST("/users/:id/unlock", authMiddleware.RequirePermission(entity.PermissionUserManage), userController.Unlock)
+			auth.PUT("/users/:id/role", authMiddleware.RequirePermission(entity.PermissionRoleManage), roleController.AssignToUser)
+			auth.POST("/users/:id/impersonate", authMiddleware.RequirePermission(entity.PermissionUserImpersonate), impersonationController.Start)
+		}
+
+		// Admin role routes
+		role := admin.Group("/admin")
+		role.Use(authMiddleware.RequirePermission(entity.PermissionRoleManage))
+		{
+			role.GET("/roles", roleController.FindAll)
+			role.POST("/roles", roleController.Insert)
+			role.PUT("/roles/:id", roleController.UpdateById)
+			role.DELETE("/roles/:id", roleController.DeleteById)
+			role.GET("/permissions", roleController.FindPermissions)
+		}
+
+		// Admin API key routes
+		apiKey := admin.Group("/admin/api-keys")
+		apiKey.Use(authMiddleware.RequirePermission(entity.PermissionAPIKeyManage))
+		{
+			apiKey.GET("", apiKeyController.FindAll)
+			apiKey.POST("", apiKeyController.Insert)
+			apiKey.PUT("/:id", apiKeyController.UpdateById)
+			apiKey.DELETE("/:id", apiKeyController.DeleteById)
+		}
+
+		// Admin audit log routes
+		admin.GET("/admin/audit", authMiddleware.RequirePermission(entity.PermissionAuditRead), auditController.FindAll)
+
+		// Admin branch routes
+		branch := admin.Group("/admin/branches")
+		branch.Use(authMiddleware.RequirePermission(entity.PermissionBranchManage))
+		{
+			branch.GET("", branchController.FindAll)
+			branch.POST("", branchController.Insert)
+			branch.PUT("/:id", branchController.UpdateById)
+			branch.DELETE("/:id", branchController.DeleteById)
+		}
+
+		// Admin delivery route routes
+		delivery := admin.Group("/admin/delivery")
+		delivery.Use(authMiddleware.RequirePermission(entity.PermissionDeliveryManage))
+		{
+			delivery.GET("/routes", deliveryRouteController.FindByDate)
+			delivery.POST("/routes", deliveryRouteController.Plan)
+			delivery.PUT("/stops/:id", deliveryRouteController.UpdateStop)
+		}
+
+		// Admin plan routes
+		plan := admin.Group("/admin/plans")
+		plan.Use(authMiddleware.RequirePermission(entity.PermissionPlanManage))
+		{
+			plan.POST("", planController.Insert)
+			plan.PUT("/:id", planController.UpdateById)
+			plan.DELETE("/:id", planController.DeleteById)
+		}
+
+		// Admin payment routes
+		payment := admin.Group("/admin/payments")
+		payment.Use(authMiddleware.RequirePermission(entity.PermissionPaymentManage))
+		{
+			payment.PUT("/:id/status", subscriptionController.UpdatePaymentStatus)
+		}
+
+		// Admin voucher routes
+		voucher := admin.Group("/admin/vouchers")
+		{
+			voucher.GET("", authMiddleware.RequirePermission(entity.PermissionVoucherRead), voucherController.FindAll)
+			voucher.GET("/report", authMiddleware.RequirePermission(entity.PermissionVoucherRead), voucherController.Report)
+			voucher.GET("/:id", authMiddleware.RequirePermission(entity.PermissionVoucherRead), voucherController.FindById)
+			voucher.GET("/:id/redemptions", authMiddleware.RequirePermission(entity.PermissionVoucherRead), voucherController.FindRedemptions)
+			voucher.POST("", authMiddleware.RequirePermission(entity.PermissionVoucherManage), voucherController.Insert)
+			voucher.PUT("/:id", authMiddleware.RequirePermission(entity.PermissionVoucherManage), voucherController.UpdateById)
+			voucher.DELETE("/:id", authMiddleware.RequirePermission(entity.PermissionVoucherManage), voucherController.DeleteById)
 		}
 
 		// Admin toy category routes
 		toyCategory := admin.Group("/toy")
+		toyCategory.Use(authMiddleware.RequirePermission(entity.PermissionToyManage))
 		{
 			toyCategory.POST("/category", toyCategoryController.Insert)
 			toyCategory.PUT("/category/:id", toyCategoryController.UpdateById)
@@ -122,6 +524,7 @@ func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
 
 		// Admin toy images routes
 		toyImage := admin.Group("/toy")
+		toyImage.Use(authMiddleware.RequirePermission(entity.PermissionToyManage))
 		{
 			toyImage.POST("/image", toyImageController.Insert)
 			toyImage.PUT("/image/:id", toyImageController.FindAll)
@@ -131,17 +534,36 @@ func setupRoutes(cfg *config.Config, db *gorm.DB) *gin.Engine {
 		// Admin toy routes
 		toy := admin.Group("/toy")
 		{
-			toy.POST("", toyController.Insert)
-			toy.PUT("/:id", toyController.UpdateById)
-			toy.DELETE("/:id", toyController.DeleteById)
+			toy.POST("", authMiddleware.RequirePermission(entity.PermissionToyManage), toyController.Insert)
+			toy.PUT("/:id", authMiddleware.RequirePermission(entity.PermissionToyManage), toyController.UpdateById)
+			toy.PUT("/:id/stock", authMiddleware.RequirePermission(entity.PermissionToyStock), toyController.UpdateStock)
+			toy.DELETE("/:id", authMiddleware.RequirePermission(entity.PermissionToyManage), toyController.DeleteById)
+		}
+
+		// Admin toy catalogue routes
+		toyCatalog := admin.Group("/admin/toy")
+		toyCatalog.Use(authMiddleware.RequirePermission(entity.PermissionToyManage))
+		{
+			toyCatalog.POST("/import", toyCatalogController.Import)
+			toyCatalog.GET("/export", toyCatalogController.Export)
+		}
+
+		// Admin bundle routes
+		bundle := admin.Group("/bundle")
+		bundle.Use(authMiddleware.RequirePermission(entity.PermissionToyManage))
+		{
+			bundle.POST("", bundleController.Insert)
+			bundle.PUT("/:id", bundleController.UpdateById)
+			bundle.DELETE("/:id", bundleController.DeleteById)
 		}
 
 		// Admin rental routes
 		rental := admin.Group("/rental")
 		{
-			rental.GET("", rentalController.FindAll)
-			rental.GET("/:id", rentalController.FinById)
-			rental.PUT("/:id/return", rentalController.ReturnRental)
+			rental.GET("", authMiddleware.RequirePermission(entity.PermissionRentalRead), rentalController.FindAll)
+			rental.GET("/:id", authMiddleware.RequirePermission(entity.PermissionRentalRead), rentalController.FinById)
+			rental.PUT("/:id/return", authMiddleware.RequirePermission(entity.PermissionRentalReturn), rentalController.ReturnRental)
+			rental.GET("/waitlist/toy/:toy_id", authMiddleware.RequirePermission(entity.PermissionWaitlistRead), waitlistController.FindByToyId)
 		}
 	}
 
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/mailer"
+	"fmt"
+	"github.com/gofrs/uuid/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type IAccountService interface {
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, req entity.ResetPasswordRequest) error
+	SendEmailVerification(ctx context.Context, userID string) error
+	VerifyEmail(ctx context.Context, token string) error
+	FindProfile(ctx context.Context, userID string) (entity.User, error)
+	UpdateProfile(ctx context.Context, userID string, req entity.UpdateProfileRequest) (entity.User, error)
+	ChangePassword(ctx context.Context, userID string, currentSessionID string, req entity.ChangePasswordRequest) error
+}
+
+type AccountService struct {
+	userRepo        repository.IUserRepository
+	actionTokenRepo repository.IUserActionTokenRepository
+	tokenSvc        ITokenService
+	mailer          mailer.Mailer
+	appBaseURL      string
+	resetTTL        time.Duration
+	verificationTTL time.Duration
+}
+
+func NewAccountService(
+	userRepo repository.IUserRepository,
+	actionTokenRepo repository.IUserActionTokenRepository,
+	tokenSvc ITokenService,
+	mail mailer.Mailer,
+	appBaseURL string,
+	resetTTL time.Duration,
+	verificationTTL time.Duration,
+) IAccountService {
+	return &AccountService{
+		userRepo:        userRepo,
+		actionTokenRepo: actionTokenRepo,
+		tokenSvc:        tokenSvc,
+		mailer:          mail,
+		appBaseURL:      strings.TrimRight(appBaseURL, "/"),
+		resetTTL:        resetTTL,
+		verificationTTL: verificationTTL,
+	}
+}
+
+// RequestPasswordReset mengirim link reset password. Email yang tidak terdaftar tidak
+// menghasilkan error agar endpoint tidak bisa dipakai untuk menebak akun yang ada.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmailOrUsername(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.Email != email || !user.IsActive {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, entity.ActionTokenPasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset password akun Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda. Buka link berikut untuk membuat password baru:\n\n%s\n\nLink ini berlaku selama %s dan hanya dapat digunakan sekali. Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.FullName, s.link("/reset-password", token), s.resetTTL,
+		),
+	})
+}
+
+// ResetPassword mengganti password menggunakan token reset dan mencabut semua sesi yang masih aktif
+func (s *AccountService) ResetPassword(ctx context.Context, req entity.ResetPasswordRequest) error {
+	actionToken, err := s.findUsableToken(ctx, entity.ActionTokenPasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.actionTokenRepo.ConsumePasswordReset(ctx, &actionToken, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	return s.tokenSvc.RevokeAllSessions(ctx, actionToken.UserID.String(), "")
+}
+
+func (s *AccountService) SendEmailVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.IsEmailVerified() {
+		return entity.ErrEmailAlreadyVerified
+	}
+
+	token, err := s.issueToken(ctx, user.ID, entity.ActionTokenEmailVerification, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verifikasi email Anda",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nTerima kasih telah mendaftar. Buka link berikut untuk memverifikasi email Anda:\n\n%s\n\nLink ini berlaku selama %s. Email harus terverifikasi sebelum Anda dapat menyewa mainan.\n",
+			user.FullName, s.link("/verify-email", token), s.verificationTTL,
+		),
+	})
+}
+
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	actionToken, err := s.findUsableToken(ctx, entity.ActionTokenEmailVerification, token)
+	if err != nil {
+		return err
+	}
+
+	return s.actionTokenRepo.ConsumeEmailVerification(ctx, &actionToken)
+}
+
+func (s *AccountService) FindProfile(ctx context.Context, userID string) (entity.User, error) {
+	return s.userRepo.FindById(ctx, userID)
+}
+
+// UpdateProfile mengubah field yang dikirim saja. Email yang berganti harus diverifikasi ulang,
+// link verifikasi dikirim ke alamat yang baru.
+func (s *AccountService) UpdateProfile(ctx context.Context, userID string, req entity.UpdateProfileRequest) (entity.User, error) {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	fields := make(map[string]interface{})
+	emailChanged := false
+
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		if !strings.EqualFold(email, user.Email) {
+			if err := s.ensureAvailable(ctx, user.ID, email, entity.ErrEmailTaken); err != nil {
+				return entity.User{}, err
+			}
+			emailChanged = true
+			fields["email_verified_at"] = nil
+		}
+		if email != user.Email {
+			fields["email"] = email
+		}
+	}
+
+	if req.Username != nil {
+		username := strings.TrimSpace(*req.Username)
+		if username != user.Username {
+			if err := s.ensureAvailable(ctx, user.ID, username, entity.ErrUsernameTaken); err != nil {
+				return entity.User{}, err
+			}
+			fields["username"] = username
+		}
+	}
+
+	if req.FullName != nil {
+		fields["full_name"] = strings.TrimSpace(*req.FullName)
+	}
+	if req.PhoneNumber != nil {
+		fields["phone_number"] = strings.TrimSpace(*req.PhoneNumber)
+	}
+	if req.Address != nil {
+		fields["address"] = strings.TrimSpace(*req.Address)
+	}
+
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateProfile(ctx, userID, fields); err != nil {
+			return entity.User{}, err
+		}
+	}
+
+	// Gagal mengirim email tidak membatalkan perubahan, user bisa meminta kirim ulang
+	if emailChanged {
+		if err := s.SendEmailVerification(ctx, userID); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to send verification email to user %s: %v", userID, err))
+		}
+	}
+
+	return s.userRepo.FindById(ctx, userID)
+}
+
+// ChangePassword memverifikasi password saat ini lalu mencabut semua sesi lain. Sesi yang
+// sedang dipakai tetap aktif.
+func (s *AccountService) ChangePassword(ctx context.Context, userID string, currentSessionID string, req entity.ChangePasswordRequest) error {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		return entity.ErrCurrentPasswordInvalid
+	}
+
+	if req.NewPassword == req.CurrentPassword {
+		return entity.ErrPasswordUnchanged
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	return s.tokenSvc.RevokeAllSessions(ctx, userID, currentSessionID)
+}
+
+// ensureAvailable memastikan email atau username belum dipakai user lain. Email dan username
+// dicek bersilangan seperti saat login, email juga dicek tanpa membedakan huruf besar kecil.
+func (s *AccountService) ensureAvailable(ctx context.Context, userID uuid.UUID, emailOrUsername string, takenErr error) error {
+	for _, find := range []func(context.Context, string) (*entity.User, error){
+		s.userRepo.FindByEmailOrUsername,
+		s.userRepo.FindByEmail,
+	} {
+		existing, err := find(ctx, emailOrUsername)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		if existing.ID != userID {
+			return takenErr
+		}
+	}
+	return nil
+}
+
+func (s *AccountService) issueToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	token, tokenHash, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	actionToken := &entity.UserActionToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	if err := s.actionTokenRepo.ReplaceForUser(ctx, actionToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *AccountService) findUsableToken(ctx context.Context, purpose string, token string) (entity.UserActionToken, error) {
+	actionToken, err := s.actionTokenRepo.FindByHash(ctx, purpose, helpers.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.UserActionToken{}, entity.ErrActionTokenInvalid
+		}
+		return entity.UserActionToken{}, err
+	}
+
+	if !actionToken.IsUsable() {
+		return entity.UserActionToken{}, entity.ErrActionTokenInvalid
+	}
+
+	return actionToken, nil
+}
+
+func (s *AccountService) link(path string, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"github.com/gofrs/uuid/v5"
+)
+
+type IAddressService interface {
+	FindByUserID(ctx context.Context, userID string) ([]entity.Address, error)
+	Create(ctx context.Context, userID uuid.UUID, req entity.AddressRequest) (*entity.Address, error)
+	Update(ctx context.Context, userID uuid.UUID, id string, req entity.AddressRequest) (*entity.Address, error)
+	Delete(ctx context.Context, userID string, id string) error
+}
+
+type AddressService struct {
+	addressRepo repository.IAddressRepository
+}
+
+func NewAddressService(addressRepo repository.IAddressRepository) IAddressService {
+	return &AddressService{
+		addressRepo: addressRepo,
+	}
+}
+
+func (s *AddressService) FindByUserID(ctx context.Context, userID string) ([]entity.Address, error) {
+	return s.addressRepo.FindByUserID(ctx, userID)
+}
+
+func (s *AddressService) Create(ctx context.Context, userID uuid.UUID, req entity.AddressRequest) (*entity.Address, error) {
+	address := &entity.Address{
+		UserID:    userID,
+		IsDefault: req.IsDefault,
+	}
+	req.Apply(address)
+
+	if err := s.addressRepo.Create(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+func (s *AddressService) Update(ctx context.Context, userID uuid.UUID, id string, req entity.AddressRequest) (*entity.Address, error) {
+	address, err := s.addressRepo.FindByUserAndId(ctx, userID.String(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Alamat default hanya bisa diganti dengan menjadikan alamat lain sebagai default
+	req.Apply(&address)
+	address.IsDefault = address.IsDefault || req.IsDefault
+
+	if err := s.addressRepo.Update(ctx, &address); err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (s *AddressService) Delete(ctx context.Context, userID string, id string) error {
+	return s.addressRepo.DeleteByUserAndId(ctx, userID, id)
+}
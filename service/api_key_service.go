@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// apiKeyLastUsedInterval membatasi seberapa sering last_used_at ditulis untuk satu key
+const apiKeyLastUsedInterval = time.Minute
+
+type IAPIKeyService interface {
+	FindAll(ctx context.Context, limit int, offset int) ([]entity.APIKey, int64, error)
+	Create(ctx context.Context, req entity.APIKeyRequest, createdBy uuid.UUID) (entity.APIKeyCreatedResponse, error)
+	UpdateById(ctx context.Context, id string, req entity.APIKeyRequest) (entity.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+	Authenticate(ctx context.Context, rawKey string, ip string) (entity.APIKey, entity.APIKeyUsage, error)
+}
+
+type APIKeyService struct {
+	repo             repository.IAPIKeyRepository
+	defaultRateLimit int
+	limiter          *rateLimiter
+}
+
+func NewAPIKeyService(repo repository.IAPIKeyRepository, defaultRateLimit int) IAPIKeyService {
+	return &APIKeyService{
+		repo:             repo,
+		defaultRateLimit: defaultRateLimit,
+		limiter:          newRateLimiter(time.Minute),
+	}
+}
+
+func (s *APIKeyService) FindAll(ctx context.Context, limit int, offset int) ([]entity.APIKey, int64, error) {
+	return s.repo.FindAll(ctx, limit, offset)
+}
+
+// Create membuat key baru. Key asli hanya ada di response ini, setelahnya hanya hash yang tersimpan.
+func (s *APIKeyService) Create(ctx context.Context, req entity.APIKeyRequest, createdBy uuid.UUID) (entity.APIKeyCreatedResponse, error) {
+	token, _, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return entity.APIKeyCreatedResponse{}, err
+	}
+
+	rawKey := entity.APIKeyPrefix + token
+	key := entity.APIKey{
+		Name:      req.Name,
+		KeyPrefix: rawKey[:len(entity.APIKeyPrefix)+8],
+		KeyHash:   helpers.HashToken(rawKey),
+		Scopes:    req.Scopes,
+		RateLimit: req.RateLimit,
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.repo.Insert(ctx, &key); err != nil {
+		return entity.APIKeyCreatedResponse{}, err
+	}
+
+	return entity.APIKeyCreatedResponse{APIKey: key, Key: rawKey}, nil
+}
+
+func (s *APIKeyService) UpdateById(ctx context.Context, id string, req entity.APIKeyRequest) (entity.APIKey, error) {
+	key, err := s.findById(ctx, id)
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+
+	if key.RevokedAt != nil {
+		return entity.APIKey{}, entity.ErrAPIKeyRevoked
+	}
+
+	key.Name = req.Name
+	key.Scopes = req.Scopes
+	key.RateLimit = req.RateLimit
+	key.ExpiresAt = req.ExpiresAt
+	if err := s.repo.UpdateSettings(ctx, &key); err != nil {
+		return entity.APIKey{}, err
+	}
+
+	return key, nil
+}
+
+func (s *APIKeyService) Revoke(ctx context.Context, id string) error {
+	if _, err := s.findById(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.Revoke(ctx, id)
+}
+
+// Authenticate memvalidasi key dari request, menerapkan rate limit per key, dan mencatat pemakaian terakhir
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string, ip string) (entity.APIKey, entity.APIKeyUsage, error) {
+	var log = helpers.Logger
+
+	if !strings.HasPrefix(rawKey, entity.APIKeyPrefix) {
+		return entity.APIKey{}, entity.APIKeyUsage{}, entity.ErrAPIKeyInvalid
+	}
+
+	key, err := s.repo.FindByHash(ctx, helpers.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.APIKey{}, entity.APIKeyUsage{}, entity.ErrAPIKeyInvalid
+		}
+		return entity.APIKey{}, entity.APIKeyUsage{}, err
+	}
+
+	if !key.IsActive() {
+		return entity.APIKey{}, entity.APIKeyUsage{}, entity.ErrAPIKeyInvalid
+	}
+
+	limit := key.RateLimit
+	if limit <= 0 {
+		limit = s.defaultRateLimit
+	}
+
+	allowed, remaining, resetIn := s.limiter.allow(key.ID.String(), limit)
+	usage := entity.APIKeyUsage{Limit: limit, Remaining: remaining, ResetIn: resetIn}
+	if !allowed {
+		return key, usage, entity.ErrAPIKeyRateLimited
+	}
+
+	// Gagal mencatat pemakaian tidak membatalkan request
+	if err := s.repo.TouchLastUsed(ctx, key.ID.String(), ip, apiKeyLastUsedInterval); err != nil {
+		log.Error("Failed to update API key last used: ", err)
+	}
+
+	return key, usage, nil
+}
+
+func (s *APIKeyService) findById(ctx context.Context, id string) (entity.APIKey, error) {
+	key, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.APIKey{}, entity.ErrAPIKeyNotFound
+		}
+		return entity.APIKey{}, err
+	}
+	return key, nil
+}
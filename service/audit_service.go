@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"time"
+)
+
+type IAuditService interface {
+	Record(ctx context.Context, event *entity.AuditEvent) error
+	FindAll(ctx context.Context, filter entity.AuditEventFilter, limit int, offset int) ([]entity.AuditEvent, int64, error)
+}
+
+type AuditService struct {
+	repo repository.IAuditEventRepository
+}
+
+func NewAuditService(repo repository.IAuditEventRepository) IAuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// Record menyimpan audit event, waktu kejadian diisi otomatis jika kosong
+func (s *AuditService) Record(ctx context.Context, event *entity.AuditEvent) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	return s.repo.Insert(ctx, event)
+}
+
+func (s *AuditService) FindAll(ctx context.Context, filter entity.AuditEventFilter, limit int, offset int) ([]entity.AuditEvent, int64, error) {
+	return s.repo.FindFiltered(ctx, filter, limit, offset)
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+)
+
+type IBranchService interface {
+	IBaseService[entity.Branch]
+	FindActive(ctx context.Context) ([]entity.Branch, error)
+}
+
+type BranchService struct {
+	BaseService[entity.Branch]
+	branchRepo repository.IBranchRepository
+}
+
+func NewBranchService(repo repository.IBranchRepository) IBranchService {
+	return &BranchService{
+		BaseService: BaseService[entity.Branch]{repository: repo},
+		branchRepo:  repo,
+	}
+}
+
+func (s *BranchService) FindActive(ctx context.Context) ([]entity.Branch, error) {
+	return s.branchRepo.FindActive(ctx)
+}
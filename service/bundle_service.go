@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+)
+
+type IBundleService interface {
+	IBaseService[entity.Bundle]
+}
+
+type BundleService struct {
+	BaseService[entity.Bundle]
+	bundleRepo repository.IBundleRepository
+	toyRepo    repository.IToyRepository
+}
+
+func NewBundleService(repo repository.IBundleRepository, toyRepo repository.IToyRepository) IBundleService {
+	return &BundleService{
+		BaseService: BaseService[entity.Bundle]{repository: repo},
+		bundleRepo:  repo,
+		toyRepo:     toyRepo,
+	}
+}
+
+func (s *BundleService) FindAll(ctx context.Context, limit int, offset int) ([]entity.Bundle, int64, error) {
+	bundles, total, err := s.bundleRepo.FindAll(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range bundles {
+		bundles[i].AvailableStock = bundles[i].CalculateAvailability()
+	}
+	return bundles, total, nil
+}
+
+func (s *BundleService) FindById(ctx context.Context, id string) (entity.Bundle, error) {
+	bundle, err := s.bundleRepo.FindById(ctx, id)
+	if err != nil {
+		return bundle, err
+	}
+
+	bundle.AvailableStock = bundle.CalculateAvailability()
+	return bundle, nil
+}
+
+func (s *BundleService) Insert(ctx context.Context, bundle *entity.Bundle) error {
+	if err := s.validateComponents(ctx, bundle); err != nil {
+		return err
+	}
+	return s.bundleRepo.Insert(ctx, bundle)
+}
+
+func (s *BundleService) UpdateById(ctx context.Context, id string, bundle *entity.Bundle) error {
+	if err := s.validateComponents(ctx, bundle); err != nil {
+		return err
+	}
+	return s.bundleRepo.UpdateById(ctx, id, bundle)
+}
+
+func (s *BundleService) validateComponents(ctx context.Context, bundle *entity.Bundle) error {
+	seen := make(map[string]bool)
+	for i := range bundle.Items {
+		// Data mainan hanya direferensikan lewat ToyID, jangan ikut tersimpan dari request
+		bundle.Items[i].Toy = entity.Toy{}
+
+		toyID := bundle.Items[i].ToyID.String()
+		if seen[toyID] {
+			return errors.New("mainan duplikat dalam bundle: " + toyID)
+		}
+		seen[toyID] = true
+
+		if _, err := s.toyRepo.FindById(ctx, toyID); err != nil {
+			return errors.New("mainan tidak ditemukan: " + toyID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"math"
+	"sort"
+	"time"
+)
+
+type IDeliveryRouteService interface {
+	Plan(ctx context.Context, req entity.PlanDeliveryRoutesRequest) ([]entity.DeliveryRoute, error)
+	FindByBranchAndDate(ctx context.Context, branchID string, date string) ([]entity.DeliveryRoute, error)
+	UpdateStop(ctx context.Context, stopID string, req entity.UpdateDeliveryStopRequest) (entity.DeliveryStop, error)
+}
+
+type DeliveryRouteService struct {
+	routeRepo  repository.IDeliveryRouteRepository
+	branchRepo repository.IBranchRepository
+	location   *time.Location
+}
+
+func NewDeliveryRouteService(
+	routeRepo repository.IDeliveryRouteRepository,
+	branchRepo repository.IBranchRepository,
+	location *time.Location,
+) IDeliveryRouteService {
+	if location == nil {
+		location = time.Local
+	}
+
+	return &DeliveryRouteService{
+		routeRepo:  routeRepo,
+		branchRepo: branchRepo,
+		location:   location,
+	}
+}
+
+// Plan membagi pengantaran dan penjemputan yang belum dijadwalkan pada tanggal tersebut ke
+// beberapa driver. Titik dibagi per sektor arah dari cabang, lalu tiap rute diurutkan per slot
+// dengan nearest neighbour dan, jika dipilih, diperbaiki dengan 2-opt. Perencanaan ulang pada
+// hari yang sama hanya mengambil titik baru atau titik yang sebelumnya gagal.
+func (s *DeliveryRouteService) Plan(ctx context.Context, req entity.PlanDeliveryRoutesRequest) ([]entity.DeliveryRoute, error) {
+	day, err := s.parseDay(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := s.branchRepo.FindById(ctx, req.BranchID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrBranchUnavailable
+		}
+		return nil, err
+	}
+
+	jobs, err := s.routeRepo.FindJobs(ctx, branch.ID.String(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, entity.ErrNoDeliveryJobs
+	}
+
+	existing, err := s.routeRepo.FindByBranchAndDate(ctx, branch.ID.String(), day)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := req.Algorithm
+	if algorithm == "" {
+		algorithm = entity.RouteAlgorithmTwoOpt
+	}
+
+	drivers := req.Drivers
+	if drivers <= 0 {
+		drivers = 1
+	}
+
+	origin := routePoint{lat: branch.Latitude, lon: branch.Longitude}
+	groups := sweepGroups(origin, jobs, drivers)
+
+	routes := make([]entity.DeliveryRoute, 0, len(groups))
+	for i, group := range groups {
+		ordered, distance := orderStops(origin, group, algorithm == entity.RouteAlgorithmTwoOpt)
+
+		route := entity.DeliveryRoute{
+			BranchID:   branch.ID,
+			RouteDate:  day,
+			Number:     len(existing) + i + 1,
+			Algorithm:  algorithm,
+			DistanceKm: math.Round(distance*100) / 100,
+			Status:     entity.DeliveryRouteStatusPlanned,
+		}
+		for sequence, job := range ordered {
+			route.Stops = append(route.Stops, entity.DeliveryStop{
+				RentalID:       job.RentalID,
+				Type:           job.Type,
+				Sequence:       sequence + 1,
+				Address:        job.Address,
+				RecipientName:  job.RecipientName,
+				RecipientPhone: job.RecipientPhone,
+				AddressNotes:   job.AddressNotes,
+				Latitude:       job.Latitude,
+				Longitude:      job.Longitude,
+				SlotStart:      job.SlotStart,
+				SlotEnd:        job.SlotEnd,
+				Status:         entity.DeliveryStopStatusPending,
+			})
+		}
+		routes = append(routes, route)
+	}
+
+	if err := s.routeRepo.CreateRoutes(ctx, routes); err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+func (s *DeliveryRouteService) FindByBranchAndDate(ctx context.Context, branchID string, date string) ([]entity.DeliveryRoute, error) {
+	if _, err := uuid.FromString(branchID); err != nil {
+		return nil, entity.ErrBranchUnavailable
+	}
+
+	day, err := s.parseDay(date)
+	if err != nil {
+		return nil, err
+	}
+	return s.routeRepo.FindByBranchAndDate(ctx, branchID, day)
+}
+
+// UpdateStop mencatat hasil kunjungan driver. Pengantaran hanya bisa delivered atau failed,
+// penjemputan hanya bisa collected atau failed, dan status final tidak dapat diubah lagi.
+func (s *DeliveryRouteService) UpdateStop(ctx context.Context, stopID string, req entity.UpdateDeliveryStopRequest) (entity.DeliveryStop, error) {
+	if _, err := uuid.FromString(stopID); err != nil {
+		return entity.DeliveryStop{}, gorm.ErrRecordNotFound
+	}
+
+	stop, err := s.routeRepo.FindStopById(ctx, stopID)
+	if err != nil {
+		return entity.DeliveryStop{}, err
+	}
+	if stop.IsFinal() {
+		return entity.DeliveryStop{}, entity.ErrDeliveryStopCompleted
+	}
+
+	switch req.Status {
+	case entity.DeliveryStopStatusDelivered:
+		if stop.Type != entity.DeliveryStopDropOff {
+			return entity.DeliveryStop{}, entity.ErrDeliveryStopStatusWrong
+		}
+	case entity.DeliveryStopStatusCollected:
+		if stop.Type != entity.DeliveryStopCollection {
+			return entity.DeliveryStop{}, entity.ErrDeliveryStopStatusWrong
+		}
+	case entity.DeliveryStopStatusFailed:
+		if req.FailureReason == "" {
+			return entity.DeliveryStop{}, entity.ErrDeliveryFailureReason
+		}
+		stop.FailureReason = req.FailureReason
+	}
+
+	now := time.Now()
+	stop.Status = req.Status
+	stop.CompletedAt = &now
+
+	if err := s.routeRepo.CompleteStop(ctx, &stop); err != nil {
+		return entity.DeliveryStop{}, err
+	}
+	return stop, nil
+}
+
+// parseDay mengembalikan tengah malam tanggal YYYY-MM-DD di zona waktu pengiriman
+func (s *DeliveryRouteService) parseDay(date string) (time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", date, s.location)
+	if err != nil {
+		return time.Time{}, entity.ErrDeliveryRouteDateInvalid
+	}
+	return day, nil
+}
+
+type routePoint struct {
+	lat float64
+	lon float64
+}
+
+func jobPoint(job entity.DeliveryJob) routePoint {
+	return routePoint{lat: job.Latitude, lon: job.Longitude}
+}
+
+func pointDistance(a routePoint, b routePoint) float64 {
+	return distanceKm(a.lat, a.lon, b.lat, b.lon)
+}
+
+// sweepGroups membagi titik ke sejumlah driver berdasarkan arah dari cabang sehingga setiap
+// driver melayani satu sektor dan rute tidak saling bersilangan
+func sweepGroups(origin routePoint, jobs []entity.DeliveryJob, drivers int) [][]entity.DeliveryJob {
+	if drivers > len(jobs) {
+		drivers = len(jobs)
+	}
+
+	sorted := make([]entity.DeliveryJob, len(jobs))
+	copy(sorted, jobs)
+	bearing := func(job entity.DeliveryJob) float64 {
+		return math.Atan2(job.Latitude-origin.lat, job.Longitude-origin.lon)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bearing(sorted[i]) < bearing(sorted[j])
+	})
+
+	groups := make([][]entity.DeliveryJob, 0, drivers)
+	size := (len(sorted) + drivers - 1) / drivers
+	for start := 0; start < len(sorted); start += size {
+		end := start + size
+		if end > len(sorted) {
+			end = len(sorted)
+		}
+		groups = append(groups, sorted[start:end])
+	}
+	return groups
+}
+
+// orderStops mengurutkan titik satu driver. Titik dikelompokkan per slot agar jadwal yang
+// dijanjikan ke pelanggan tetap urut, lalu di dalam slot diurutkan dengan nearest neighbour dan
+// 2-opt. Mengembalikan urutan titik dan total jarak dari cabang kembali ke cabang.
+func orderStops(origin routePoint, jobs []entity.DeliveryJob, twoOpt bool) ([]entity.DeliveryJob, float64) {
+	slots := make(map[time.Time][]entity.DeliveryJob)
+	for _, job := range jobs {
+		var start time.Time
+		if job.SlotStart != nil {
+			start = job.SlotStart.UTC()
+		}
+		slots[start] = append(slots[start], job)
+	}
+
+	starts := make([]time.Time, 0, len(slots))
+	for start := range slots {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	ordered := make([]entity.DeliveryJob, 0, len(jobs))
+	current := origin
+	for i, start := range starts {
+		segment := nearestNeighbour(current, slots[start])
+		if twoOpt {
+			var end *routePoint
+			if i == len(starts)-1 {
+				end = &origin
+			}
+			segment = improveTwoOpt(current, segment, end)
+		}
+		ordered = append(ordered, segment...)
+		current = jobPoint(segment[len(segment)-1])
+	}
+
+	total, previous := 0.0, origin
+	for _, job := range ordered {
+		total += pointDistance(previous, jobPoint(job))
+		previous = jobPoint(job)
+	}
+	total += pointDistance(previous, origin)
+
+	return ordered, total
+}
+
+// nearestNeighbour selalu menuju titik terdekat yang belum dikunjungi
+func nearestNeighbour(from routePoint, jobs []entity.DeliveryJob) []entity.DeliveryJob {
+	remaining := make([]entity.DeliveryJob, len(jobs))
+	copy(remaining, jobs)
+
+	ordered := make([]entity.DeliveryJob, 0, len(jobs))
+	current := from
+	for len(remaining) > 0 {
+		nearest := 0
+		for i := 1; i < len(remaining); i++ {
+			if pointDistance(current, jobPoint(remaining[i])) < pointDistance(current, jobPoint(remaining[nearest])) {
+				nearest = i
+			}
+		}
+		ordered = append(ordered, remaining[nearest])
+		current = jobPoint(remaining[nearest])
+		remaining = append(remaining[:nearest], remaining[nearest+1:]...)
+	}
+	return ordered
+}
+
+// improveTwoOpt membalik potongan rute selama hasilnya lebih pendek. Rute dimulai dari from dan
+// berakhir di end, atau berakhir bebas jika end nil.
+func improveTwoOpt(from routePoint, jobs []entity.DeliveryJob, end *routePoint) []entity.DeliveryJob {
+	path := make([]entity.DeliveryJob, len(jobs))
+	copy(path, jobs)
+
+	point := func(i int) routePoint {
+		if i < 0 {
+			return from
+		}
+		return jobPoint(path[i])
+	}
+	// next mengembalikan jarak dari titik j ke titik setelahnya, nol jika j titik terakhir rute bebas
+	next := func(j int, p routePoint) float64 {
+		if j+1 < len(path) {
+			return pointDistance(p, jobPoint(path[j+1]))
+		}
+		if end != nil {
+			return pointDistance(p, *end)
+		}
+		return 0
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < len(path)-1; i++ {
+			for j := i + 1; j < len(path); j++ {
+				before := pointDistance(point(i-1), point(i)) + next(j, point(j))
+				after := pointDistance(point(i-1), point(j)) + next(j, point(i))
+				if after+1e-9 < before {
+					for left, right := i, j; left < right; left, right = left+1, right-1 {
+						path[left], path[right] = path[right], path[left]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+	return path
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeDeliveryRouteRepo struct {
+	repository.IDeliveryRouteRepository
+	stops     map[string]entity.DeliveryStop
+	completed *entity.DeliveryStop
+}
+
+func (r *fakeDeliveryRouteRepo) FindStopById(ctx context.Context, id string) (entity.DeliveryStop, error) {
+	stop, ok := r.stops[id]
+	if !ok {
+		return stop, gorm.ErrRecordNotFound
+	}
+	return stop, nil
+}
+
+func (r *fakeDeliveryRouteRepo) CompleteStop(ctx context.Context, stop *entity.DeliveryStop) error {
+	r.completed = stop
+	return nil
+}
+
+// newTestJob membuat titik yang diberi label lewat Address agar urutan mudah dibandingkan
+func newTestJob(label string, lat float64, lon float64, slotStart *time.Time) entity.DeliveryJob {
+	return entity.DeliveryJob{RentalID: uuid.Must(uuid.NewV7()), Type: entity.DeliveryStopDropOff, Address: label, Latitude: lat, Longitude: lon, SlotStart: slotStart}
+}
+
+func jobLabels(jobs []entity.DeliveryJob) []string {
+	labels := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		labels = append(labels, job.Address)
+	}
+	return labels
+}
+
+func TestOrderStops(t *testing.T) {
+	origin := routePoint{}
+	morning := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	afternoon := morning.Add(4 * time.Hour)
+
+	tests := []struct {
+		name   string
+		jobs   []entity.DeliveryJob
+		twoOpt bool
+		want   []string
+	}{
+		{
+			name: "titik terdekat dikunjungi lebih dulu",
+			jobs: []entity.DeliveryJob{
+				newTestJob("c", 0, 0.03, nil),
+				newTestJob("a", 0, 0.01, nil),
+				newTestJob("b", 0, 0.02, nil),
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "slot lebih awal didahulukan walaupun lebih jauh",
+			jobs: []entity.DeliveryJob{
+				newTestJob("dekat-sore", 0, 0.01, &afternoon),
+				newTestJob("jauh-pagi", 0, 0.05, &morning),
+				newTestJob("tengah-pagi", 0, 0.03, &morning),
+			},
+			want: []string{"tengah-pagi", "jauh-pagi", "dekat-sore"},
+		},
+		{
+			name: "titik tanpa slot dikunjungi sebelum titik bersalot",
+			jobs: []entity.DeliveryJob{
+				newTestJob("pagi", 0, 0.01, &morning),
+				newTestJob("bebas", 0, 0.02, nil),
+			},
+			want: []string{"bebas", "pagi"},
+		},
+		{
+			name: "2-opt tetap menjaga urutan slot",
+			jobs: []entity.DeliveryJob{
+				newTestJob("sore", 0, 0.01, &afternoon),
+				newTestJob("pagi-2", 0, 0.04, &morning),
+				newTestJob("pagi-1", 0, 0.02, &morning),
+			},
+			twoOpt: true,
+			want:   []string{"pagi-1", "pagi-2", "sore"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ordered, distance := orderStops(origin, tt.jobs, tt.twoOpt)
+			if got := jobLabels(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+
+			var want float64
+			previous := origin
+			for _, job := range ordered {
+				want += pointDistance(previous, jobPoint(job))
+				previous = jobPoint(job)
+			}
+			want += pointDistance(previous, origin)
+			if distance != want {
+				t.Errorf("distance = %f, want the round trip %f", distance, want)
+			}
+		})
+	}
+}
+
+func TestOrderStops_TwoOptNeverLonger(t *testing.T) {
+	origin := routePoint{lat: -6.2, lon: 106.8}
+
+	// Titik tersebar semu-acak tetapi deterministik di sekitar cabang
+	var jobs []entity.DeliveryJob
+	seed := 7
+	for i := 0; i < 12; i++ {
+		seed = (seed*31 + 17) % 101
+		lat := origin.lat + float64(seed%21-10)/200
+		seed = (seed*31 + 17) % 101
+		lon := origin.lon + float64(seed%21-10)/200
+		jobs = append(jobs, newTestJob(string(rune('a'+i)), lat, lon, nil))
+	}
+
+	nearest, nearestDistance := orderStops(origin, jobs, false)
+	improved, improvedDistance := orderStops(origin, jobs, true)
+	if improvedDistance > nearestDistance+1e-9 {
+		t.Errorf("2-opt distance = %f, longer than nearest neighbour %f", improvedDistance, nearestDistance)
+	}
+	if len(nearest) != len(jobs) || len(improved) != len(jobs) {
+		t.Fatalf("ordered %d and %d stops, want %d", len(nearest), len(improved), len(jobs))
+	}
+
+	seen := make(map[string]bool)
+	for _, job := range improved {
+		if seen[job.Address] {
+			t.Errorf("stop %s visited twice", job.Address)
+		}
+		seen[job.Address] = true
+	}
+}
+
+func TestImproveTwoOpt(t *testing.T) {
+	from := routePoint{}
+	jobs := []entity.DeliveryJob{
+		newTestJob("1", 0, 0.01, nil),
+		newTestJob("3", 0, 0.03, nil),
+		newTestJob("2", 0, 0.02, nil),
+		newTestJob("4", 0, 0.04, nil),
+	}
+
+	got := jobLabels(improveTwoOpt(from, jobs, nil))
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if jobs[1].Address != "3" {
+		t.Error("input slice was modified")
+	}
+}
+
+func TestSweepGroups(t *testing.T) {
+	origin := routePoint{}
+	jobs := []entity.DeliveryJob{
+		newTestJob("utara", 0.02, 0, nil),
+		newTestJob("timur", 0, 0.02, nil),
+		newTestJob("selatan", -0.02, 0, nil),
+		newTestJob("barat", 0, -0.02, nil),
+		newTestJob("timur-laut", 0.01, 0.01, nil),
+	}
+
+	tests := []struct {
+		name    string
+		drivers int
+		want    [][]string
+	}{
+		{
+			name:    "satu driver membawa semua titik",
+			drivers: 1,
+			want:    [][]string{{"selatan", "timur", "timur-laut", "utara", "barat"}},
+		},
+		{
+			name:    "titik dibagi per sektor arah",
+			drivers: 2,
+			want:    [][]string{{"selatan", "timur", "timur-laut"}, {"utara", "barat"}},
+		},
+		{
+			name:    "driver lebih banyak dari titik",
+			drivers: 8,
+			want:    [][]string{{"selatan"}, {"timur"}, {"timur-laut"}, {"utara"}, {"barat"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := sweepGroups(origin, jobs, tt.drivers)
+
+			got := make([][]string, 0, len(groups))
+			for _, group := range groups {
+				got = append(got, jobLabels(group))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groups = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryRouteService_UpdateStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		stopType string
+		status   string
+		current  string
+		reason   string
+		wantErr  error
+	}{
+		{name: "pengantaran berhasil", stopType: entity.DeliveryStopDropOff, status: entity.DeliveryStopStatusDelivered},
+		{name: "penjemputan berhasil", stopType: entity.DeliveryStopCollection, status: entity.DeliveryStopStatusCollected},
+		{name: "pengantaran gagal dengan alasan", stopType: entity.DeliveryStopDropOff, status: entity.DeliveryStopStatusFailed, reason: "Rumah kosong"},
+		{name: "penjemputan gagal dengan alasan", stopType: entity.DeliveryStopCollection, status: entity.DeliveryStopStatusFailed, reason: "Pelanggan minta dijadwal ulang"},
+		{name: "gagal tanpa alasan", stopType: entity.DeliveryStopDropOff, status: entity.DeliveryStopStatusFailed, wantErr: entity.ErrDeliveryFailureReason},
+		{name: "pengantaran tidak bisa collected", stopType: entity.DeliveryStopDropOff, status: entity.DeliveryStopStatusCollected, wantErr: entity.ErrDeliveryStopStatusWrong},
+		{name: "penjemputan tidak bisa delivered", stopType: entity.DeliveryStopCollection, status: entity.DeliveryStopStatusDelivered, wantErr: entity.ErrDeliveryStopStatusWrong},
+		{name: "status delivered sudah final", stopType: entity.DeliveryStopDropOff, current: entity.DeliveryStopStatusDelivered, status: entity.DeliveryStopStatusFailed, reason: "Salah input", wantErr: entity.ErrDeliveryStopCompleted},
+		{name: "status failed sudah final", stopType: entity.DeliveryStopCollection, current: entity.DeliveryStopStatusFailed, status: entity.DeliveryStopStatusCollected, wantErr: entity.ErrDeliveryStopCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := tt.current
+			if current == "" {
+				current = entity.DeliveryStopStatusPending
+			}
+			stop := entity.DeliveryStop{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Type: tt.stopType, Status: current}
+			repo := &fakeDeliveryRouteRepo{stops: map[string]entity.DeliveryStop{stop.ID.String(): stop}}
+			svc := NewDeliveryRouteService(repo, nil, time.UTC)
+
+			got, err := svc.UpdateStop(context.Background(), stop.ID.String(), entity.UpdateDeliveryStopRequest{Status: tt.status, FailureReason: tt.reason})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateStop() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.completed != nil {
+					t.Error("stop saved although the update was rejected")
+				}
+				return
+			}
+
+			if repo.completed == nil {
+				t.Fatal("stop was not saved")
+			}
+			if got.Status != tt.status || got.CompletedAt == nil {
+				t.Errorf("stop status = %s, completed at = %v, want %s with a completion time", got.Status, got.CompletedAt, tt.status)
+			}
+			if got.FailureReason != tt.reason {
+				t.Errorf("failure reason = %q, want %q", got.FailureReason, tt.reason)
+			}
+		})
+	}
+
+	t.Run("id tidak valid", func(t *testing.T) {
+		svc := NewDeliveryRouteService(&fakeDeliveryRouteRepo{}, nil, time.UTC)
+		if _, err := svc.UpdateStop(context.Background(), "bukan-uuid", entity.UpdateDeliveryStopRequest{Status: entity.DeliveryStopStatusDelivered}); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("UpdateStop() error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+}
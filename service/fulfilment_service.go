@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"math"
+	"sort"
+	"time"
+)
+
+// earthRadiusKm dipakai untuk menghitung jarak garis lurus antara cabang dan alamat
+const earthRadiusKm = 6371.0
+
+// FulfilmentPolicy mengatur tarif dan jadwal pengiriman. Zona diurutkan dari jarak terdekat,
+// slot adalah jendela waktu harian di Location, dan setiap slot menampung SlotCapacity
+// pengiriman atau penjemputan per cabang.
+type FulfilmentPolicy struct {
+	Zones        []entity.DeliveryZone
+	SlotWindows  []entity.DeliverySlotWindow
+	SlotCapacity int
+	LeadTime     time.Duration
+	Location     *time.Location
+}
+
+type IFulfilmentService interface {
+	Quote(ctx context.Context, userID string, addressID string, branchID string) (entity.DeliveryQuote, error)
+	AvailableSlots(ctx context.Context, branchID string, date time.Time) ([]entity.DeliverySlot, error)
+	Plan(ctx context.Context, rental *entity.Rental, req *entity.CreateFulfilmentRequest) error
+}
+
+type FulfilmentService struct {
+	fulfilmentRepo repository.IFulfilmentRepository
+	branchRepo     repository.IBranchRepository
+	addressRepo    repository.IAddressRepository
+	policy         FulfilmentPolicy
+}
+
+func NewFulfilmentService(
+	fulfilmentRepo repository.IFulfilmentRepository,
+	branchRepo repository.IBranchRepository,
+	addressRepo repository.IAddressRepository,
+	policy FulfilmentPolicy,
+) IFulfilmentService {
+	sort.Slice(policy.Zones, func(i, j int) bool {
+		return policy.Zones[i].MaxDistanceKm < policy.Zones[j].MaxDistanceKm
+	})
+	if policy.Location == nil {
+		policy.Location = time.Local
+	}
+
+	return &FulfilmentService{
+		fulfilmentRepo: fulfilmentRepo,
+		branchRepo:     branchRepo,
+		addressRepo:    addressRepo,
+		policy:         policy,
+	}
+}
+
+// Quote menghitung tarif pengiriman ke alamat user. Jika cabang tidak dipilih, dipakai cabang
+// aktif yang paling dekat.
+func (s *FulfilmentService) Quote(ctx context.Context, userID string, addressID string, branchID string) (entity.DeliveryQuote, error) {
+	address, err := s.findAddress(ctx, userID, addressID)
+	if err != nil {
+		return entity.DeliveryQuote{}, err
+	}
+
+	branch, distance, err := s.deliveryBranch(ctx, address, branchID)
+	if err != nil {
+		return entity.DeliveryQuote{}, err
+	}
+
+	fee, err := s.deliveryFee(distance)
+	if err != nil {
+		return entity.DeliveryQuote{}, err
+	}
+
+	return entity.DeliveryQuote{
+		BranchID:        branch.ID,
+		BranchName:      branch.Name,
+		DistanceKm:      distance,
+		DeliveryFee:     fee,
+		ReturnPickupFee: fee,
+	}, nil
+}
+
+// AvailableSlots mengembalikan slot pada tanggal kalender date beserta sisa kapasitasnya di cabang
+func (s *FulfilmentService) AvailableSlots(ctx context.Context, branchID string, date time.Time) ([]entity.DeliverySlot, error) {
+	branch, err := s.findBranch(ctx, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows := s.slotsOn(date)
+	starts := make([]time.Time, 0, len(windows))
+	for _, slot := range windows {
+		starts = append(starts, slot.Start)
+	}
+
+	counts, err := s.fulfilmentRepo.CountSlotBookings(ctx, branch.ID.String(), starts)
+	if err != nil {
+		return nil, err
+	}
+
+	earliest := time.Now().Add(s.policy.LeadTime)
+	for i := range windows {
+		remaining := s.policy.SlotCapacity - counts[windows[i].Start.UTC()]
+		if remaining < 0 {
+			remaining = 0
+		}
+		windows[i].Remaining = remaining
+		windows[i].Available = remaining > 0 && !windows[i].Start.Before(earliest)
+	}
+	return windows, nil
+}
+
+// Plan mengisi rental.Fulfilment dan rental.DeliveryFee dari pilihan user saat checkout.
+// Request kosong berarti rental tidak mencatat cara pengambilan. Kapasitas slot diperiksa oleh
+// repository di dalam transaksi saat rental disimpan.
+func (s *FulfilmentService) Plan(ctx context.Context, rental *entity.Rental, req *entity.CreateFulfilmentRequest) error {
+	if req == nil {
+		return nil
+	}
+
+	switch req.Method {
+	case entity.FulfilmentMethodPickup:
+		return s.planPickup(ctx, rental, req)
+	case entity.FulfilmentMethodDelivery:
+		return s.planDelivery(ctx, rental, req)
+	default:
+		return entity.ErrFulfilmentMethodInvalid
+	}
+}
+
+func (s *FulfilmentService) planPickup(ctx context.Context, rental *entity.Rental, req *entity.CreateFulfilmentRequest) error {
+	if req.BranchID == nil {
+		return entity.ErrBranchRequired
+	}
+	if req.ReturnPickupSlot != nil {
+		return entity.ErrReturnPickupNotAvailable
+	}
+
+	branch, err := s.findBranch(ctx, req.BranchID.String())
+	if err != nil {
+		return err
+	}
+
+	rental.DeliveryFee = 0
+	rental.Fulfilment = &entity.RentalFulfilment{
+		Method:   entity.FulfilmentMethodPickup,
+		BranchID: branch.ID,
+	}
+	return nil
+}
+
+func (s *FulfilmentService) planDelivery(ctx context.Context, rental *entity.Rental, req *entity.CreateFulfilmentRequest) error {
+	if req.AddressID == nil {
+		return entity.ErrAddressRequired
+	}
+	if req.DeliverySlot == nil {
+		return entity.ErrDeliverySlotRequired
+	}
+
+	address, err := s.findAddress(ctx, rental.UserID.String(), req.AddressID.String())
+	if err != nil {
+		return err
+	}
+
+	branchID := ""
+	if req.BranchID != nil {
+		branchID = req.BranchID.String()
+	}
+	branch, distance, err := s.deliveryBranch(ctx, address, branchID)
+	if err != nil {
+		return err
+	}
+
+	fee, err := s.deliveryFee(distance)
+	if err != nil {
+		return err
+	}
+
+	delivery, ok := s.matchSlot(*req.DeliverySlot, rental.RentalDate)
+	if !ok || delivery.Start.Before(time.Now().Add(s.policy.LeadTime)) {
+		return entity.ErrDeliverySlotInvalid
+	}
+
+	var returnPickup *entity.DeliverySlot
+	if req.ReturnPickupSlot != nil {
+		slot, ok := s.matchSlot(*req.ReturnPickupSlot, rental.ExpectedReturnDate)
+		if !ok || !slot.Start.After(delivery.Start) {
+			return entity.ErrReturnPickupSlotInvalid
+		}
+		returnPickup = &slot
+	}
+
+	latitude, longitude := address.Latitude, address.Longitude
+	fulfilment := &entity.RentalFulfilment{
+		Method:            entity.FulfilmentMethodDelivery,
+		BranchID:          branch.ID,
+		AddressID:         &address.ID,
+		DeliveryAddress:   address.FullAddress(),
+		RecipientName:     address.RecipientName,
+		RecipientPhone:    address.PhoneNumber,
+		AddressNotes:      address.Notes,
+		Latitude:          &latitude,
+		Longitude:         &longitude,
+		DistanceKm:        distance,
+		DeliverySlotStart: &delivery.Start,
+		DeliverySlotEnd:   &delivery.End,
+		SlotCapacity:      s.policy.SlotCapacity,
+	}
+
+	// Tarif dikenakan per perjalanan, penjemputan saat pengembalian dihitung satu perjalanan lagi
+	rental.DeliveryFee = fee
+	if returnPickup != nil {
+		fulfilment.ReturnPickupSlotStart = &returnPickup.Start
+		fulfilment.ReturnPickupSlotEnd = &returnPickup.End
+		rental.DeliveryFee += fee
+	}
+
+	rental.Fulfilment = fulfilment
+	return nil
+}
+
+// slotsOn menyusun slot pada tanggal kalender date di zona waktu pengiriman. Zona waktu date
+// sendiri diabaikan, pemanggil mengubahnya ke Location terlebih dahulu jika perlu.
+func (s *FulfilmentService) slotsOn(date time.Time) []entity.DeliverySlot {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, s.policy.Location)
+
+	slots := make([]entity.DeliverySlot, 0, len(s.policy.SlotWindows))
+	for _, window := range s.policy.SlotWindows {
+		slots = append(slots, entity.DeliverySlot{
+			Start: midnight.Add(window.Start),
+			End:   midnight.Add(window.End),
+		})
+	}
+	return slots
+}
+
+// matchSlot mencari slot pada tanggal day yang dimulai tepat pada waktu start
+func (s *FulfilmentService) matchSlot(start time.Time, day time.Time) (entity.DeliverySlot, bool) {
+	for _, slot := range s.slotsOn(day.In(s.policy.Location)) {
+		if slot.Start.Equal(start) {
+			return slot, true
+		}
+	}
+	return entity.DeliverySlot{}, false
+}
+
+func (s *FulfilmentService) deliveryFee(distance float64) (float64, error) {
+	for _, zone := range s.policy.Zones {
+		if distance <= zone.MaxDistanceKm {
+			return zone.Fee, nil
+		}
+	}
+	return 0, entity.ErrOutsideDeliveryArea
+}
+
+// deliveryBranch mengembalikan cabang yang dipilih, atau cabang aktif terdekat dari alamat
+func (s *FulfilmentService) deliveryBranch(ctx context.Context, address entity.Address, branchID string) (entity.Branch, float64, error) {
+	if branchID != "" {
+		branch, err := s.findBranch(ctx, branchID)
+		if err != nil {
+			return entity.Branch{}, 0, err
+		}
+		return branch, distanceKm(branch.Latitude, branch.Longitude, address.Latitude, address.Longitude), nil
+	}
+
+	branches, err := s.branchRepo.FindActive(ctx)
+	if err != nil {
+		return entity.Branch{}, 0, err
+	}
+	if len(branches) == 0 {
+		return entity.Branch{}, 0, entity.ErrBranchUnavailable
+	}
+
+	nearest, nearestDistance := branches[0], math.Inf(1)
+	for _, branch := range branches {
+		if distance := distanceKm(branch.Latitude, branch.Longitude, address.Latitude, address.Longitude); distance < nearestDistance {
+			nearest, nearestDistance = branch, distance
+		}
+	}
+	return nearest, nearestDistance, nil
+}
+
+func (s *FulfilmentService) findBranch(ctx context.Context, branchID string) (entity.Branch, error) {
+	if _, err := uuid.FromString(branchID); err != nil {
+		return entity.Branch{}, entity.ErrBranchUnavailable
+	}
+
+	branch, err := s.branchRepo.FindActiveById(ctx, branchID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Branch{}, entity.ErrBranchUnavailable
+		}
+		return entity.Branch{}, err
+	}
+	return branch, nil
+}
+
+func (s *FulfilmentService) findAddress(ctx context.Context, userID string, addressID string) (entity.Address, error) {
+	if _, err := uuid.FromString(addressID); err != nil {
+		return entity.Address{}, entity.ErrAddressNotFound
+	}
+
+	address, err := s.addressRepo.FindByUserAndId(ctx, userID, addressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Address{}, entity.ErrAddressNotFound
+		}
+		return entity.Address{}, err
+	}
+	return address, nil
+}
+
+// distanceKm menghitung jarak haversine dalam kilometer, dibulatkan dua desimal
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(degree float64) float64 { return degree * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	distance := 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+
+	return math.Round(distance*100) / 100
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"github.com/gofrs/uuid/v5"
+	"time"
+)
+
+type IImpersonationService interface {
+	Start(ctx context.Context, impersonatorID uuid.UUID, userID string, client entity.SessionClient) (entity.ImpersonationResponse, error)
+}
+
+type ImpersonationService struct {
+	userRepo      repository.IUserRepository
+	userTokenRepo repository.IUserTokenRepository
+	roleRepo      repository.IRoleRepository
+	auditSvc      IAuditService
+	jwtHelper     helpers.JWTHelper
+	ttl           time.Duration
+}
+
+func NewImpersonationService(
+	userRepo repository.IUserRepository,
+	userTokenRepo repository.IUserTokenRepository,
+	roleRepo repository.IRoleRepository,
+	auditSvc IAuditService,
+	jwtHelper helpers.JWTHelper,
+	ttl time.Duration,
+) IImpersonationService {
+	return &ImpersonationService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		roleRepo:      roleRepo,
+		auditSvc:      auditSvc,
+		jwtHelper:     jwtHelper,
+		ttl:           ttl,
+	}
+}
+
+// Start membuat sesi berumur pendek atas nama user. Hanya user tanpa permission admin yang bisa
+// di-impersonate agar admin tidak bisa memperoleh hak akses yang tidak dimilikinya.
+func (s *ImpersonationService) Start(ctx context.Context, impersonatorID uuid.UUID, userID string, client entity.SessionClient) (entity.ImpersonationResponse, error) {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+
+	if user.ID == impersonatorID {
+		return entity.ImpersonationResponse{}, entity.ErrImpersonateSelf
+	}
+	if !user.IsActive {
+		return entity.ImpersonationResponse{}, entity.ErrAccountInactive
+	}
+
+	if user.Role == entity.RoleAdmin {
+		return entity.ImpersonationResponse{}, entity.ErrImpersonationNotAllowed
+	}
+	role, err := s.roleRepo.FindByName(ctx, user.Role)
+	if err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+	if len(role.Permissions) > 0 {
+		return entity.ImpersonationResponse{}, entity.ErrImpersonationNotAllowed
+	}
+
+	accessToken, expiresAt, err := s.jwtHelper.GenerateImpersonationToken(user.ID, user.Email, user.Role, impersonatorID, s.ttl)
+	if err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+
+	// Refresh token diisi nilai acak yang bukan JWT sehingga sesi tidak pernah bisa diperpanjang
+	unusableRefreshToken, _, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+
+	userToken := &entity.UserToken{
+		UserID:                user.ID,
+		AccessToken:           accessToken,
+		RefreshToken:          unusableRefreshToken,
+		AccessTokenExpiresAt:  expiresAt,
+		RefreshTokenExpiresAt: expiresAt,
+		MFAVerified:           true,
+		UserAgent:             client.UserAgent,
+		IPAddress:             client.IPAddress,
+		ImpersonatorID:        &impersonatorID,
+	}
+	if err := s.userTokenRepo.Insert(ctx, userToken); err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+
+	if err := s.auditSvc.Record(ctx, &entity.AuditEvent{
+		ActorID:            &impersonatorID,
+		ImpersonatedUserID: &user.ID,
+		Action:             entity.AuditActionImpersonationStart,
+		EntityType:         "user",
+		EntityID:           user.ID.String(),
+		IPAddress:          client.IPAddress,
+		UserAgent:          client.UserAgent,
+	}); err != nil {
+		return entity.ImpersonationResponse{}, err
+	}
+
+	user.Password = ""
+	return entity.ImpersonationResponse{
+		TokenType:      "Bearer",
+		AccessToken:    accessToken,
+		ExpiresAt:      expiresAt,
+		ImpersonatorID: impersonatorID,
+		User:           user,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type fakeSessionRepo struct {
+	repository.IUserTokenRepository
+	inserted *entity.UserToken
+}
+
+func (r *fakeSessionRepo) Insert(ctx context.Context, token *entity.UserToken) error {
+	r.inserted = token
+	return nil
+}
+
+type fakeAuditService struct {
+	IAuditService
+	events []entity.AuditEvent
+}
+
+func (s *fakeAuditService) Record(ctx context.Context, event *entity.AuditEvent) error {
+	s.events = append(s.events, *event)
+	return nil
+}
+
+func TestImpersonationService_Start(t *testing.T) {
+	jwtHelper := newTestJWTHelper()
+	adminID := uuid.Must(uuid.NewV7())
+
+	newUser := func(role string, active bool) entity.User {
+		return entity.User{
+			BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+			Email:      role + "@example.com",
+			Password:   "hash",
+			Role:       role,
+			IsActive:   active,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		user    entity.User
+		self    bool
+		wantErr error
+	}{
+		{
+			name: "customer bisa di-impersonate",
+			user: newUser(entity.RoleCustomer, true),
+		},
+		{
+			name:    "tidak bisa impersonate diri sendiri",
+			user:    newUser(entity.RoleAdmin, true),
+			self:    true,
+			wantErr: entity.ErrImpersonateSelf,
+		},
+		{
+			name:    "akun nonaktif ditolak",
+			user:    newUser(entity.RoleCustomer, false),
+			wantErr: entity.ErrAccountInactive,
+		},
+		{
+			name:    "admin lain tidak bisa di-impersonate",
+			user:    newUser(entity.RoleAdmin, true),
+			wantErr: entity.ErrImpersonationNotAllowed,
+		},
+		{
+			name:    "role dengan permission tidak bisa di-impersonate",
+			user:    newUser(entity.RoleStaff, true),
+			wantErr: entity.ErrImpersonationNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			impersonatorID := adminID
+			if tt.self {
+				impersonatorID = tt.user.ID
+			}
+
+			roles := newTestRoles()
+			roles[entity.RoleCustomer] = entity.Role{Name: entity.RoleCustomer, IsSystem: true}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{tt.user.ID.String(): tt.user}}
+			sessionRepo := &fakeSessionRepo{}
+			auditSvc := &fakeAuditService{}
+			svc := NewImpersonationService(userRepo, sessionRepo, &fakeRoleRepo{roles: roles}, auditSvc, *jwtHelper, 15*time.Minute)
+
+			got, err := svc.Start(context.Background(), impersonatorID, tt.user.ID.String(), entity.SessionClient{UserAgent: "test", IPAddress: "10.0.0.1"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Start() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if sessionRepo.inserted != nil || len(auditSvc.events) > 0 {
+					t.Error("session created although impersonation was rejected")
+				}
+				return
+			}
+
+			claims, err := jwtHelper.ValidateAccessToken(got.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateAccessToken() error = %v", err)
+			}
+			if claims.ImpersonatorID == nil || *claims.ImpersonatorID != adminID {
+				t.Errorf("token impersonator = %v, want %s", claims.ImpersonatorID, adminID)
+			}
+			if got.ExpiresAt.After(time.Now().Add(15 * time.Minute)) {
+				t.Errorf("token expires at %s, later than the impersonation ttl", got.ExpiresAt)
+			}
+			if got.User.Password != "" {
+				t.Error("response leaks the password hash")
+			}
+
+			session := sessionRepo.inserted
+			if session == nil {
+				t.Fatal("impersonation session was not stored")
+			}
+			if session.ImpersonatorID == nil || *session.ImpersonatorID != adminID {
+				t.Errorf("session impersonator = %v, want %s", session.ImpersonatorID, adminID)
+			}
+			if _, err := jwtHelper.ValidateRefreshToken(session.RefreshToken); err == nil {
+				t.Error("impersonation session has a usable refresh token")
+			}
+
+			if len(auditSvc.events) != 1 || auditSvc.events[0].Action != entity.AuditActionImpersonationStart {
+				t.Errorf("audit events = %+v, want one impersonation start", auditSvc.events)
+			}
+		})
+	}
+}
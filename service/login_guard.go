@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"gorm.io/gorm"
+	"math"
+	"strings"
+	"time"
+)
+
+// maxLoginDelay membatasi delay progresif agar tidak melebihi durasi lockout yang wajar
+const maxLoginDelay = 5 * time.Minute
+
+// LoginPolicy mengatur batas percobaan login gagal
+type LoginPolicy struct {
+	MaxAttempts     int
+	IPMaxAttempts   int
+	LockoutDuration time.Duration
+	BaseDelay       time.Duration
+}
+
+// loginGuard menerapkan delay progresif dan lockout sementara per akun dan per IP
+type loginGuard struct {
+	repo   repository.ILoginAttemptRepository
+	policy LoginPolicy
+}
+
+type loginKey struct {
+	scope string
+	key   string
+}
+
+func accountLoginKey(user *entity.User, identifier string) loginKey {
+	if user != nil {
+		return loginKey{scope: entity.LoginAttemptScopeAccount, key: user.ID.String()}
+	}
+	return loginKey{scope: entity.LoginAttemptScopeAccount, key: strings.ToLower(strings.TrimSpace(identifier))}
+}
+
+func ipLoginKey(ip string) loginKey {
+	return loginKey{scope: entity.LoginAttemptScopeIP, key: ip}
+}
+
+// check mengembalikan LoginThrottledError jika akun atau IP sedang dikunci atau masih dalam masa delay
+func (g loginGuard) check(ctx context.Context, keys ...loginKey) error {
+	now := time.Now()
+	var retryAfter time.Duration
+
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+
+		attempt, err := g.repo.Find(ctx, k.scope, k.key)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+			continue
+		}
+
+		// Delay progresif hanya berlaku untuk akun, agar pengguna di belakang NAT yang sama tidak ikut tertahan
+		if k.scope == entity.LoginAttemptScopeAccount && now.Sub(attempt.LastFailedAt) < g.policy.LockoutDuration {
+			nextAllowed := attempt.LastFailedAt.Add(g.delay(attempt.FailedCount))
+			if now.Before(nextAllowed) {
+				retryAfter = max(retryAfter, nextAllowed.Sub(now))
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &entity.LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// fail mencatat percobaan gagal dan mengunci akun atau IP yang melewati batas
+func (g loginGuard) fail(ctx context.Context, keys ...loginKey) error {
+	now := time.Now()
+	for _, k := range keys {
+		if k.key == "" {
+			continue
+		}
+
+		attempt, err := g.repo.RecordFailure(ctx, k.scope, k.key, now, g.policy.LockoutDuration)
+		if err != nil {
+			return err
+		}
+
+		limit := g.policy.MaxAttempts
+		if k.scope == entity.LoginAttemptScopeIP {
+			limit = g.policy.IPMaxAttempts
+		}
+
+		if limit > 0 && attempt.FailedCount >= limit && attempt.LockedUntil == nil {
+			if err := g.repo.Lock(ctx, k.scope, k.key, now.Add(g.policy.LockoutDuration)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g loginGuard) reset(ctx context.Context, k loginKey) error {
+	return g.repo.Reset(ctx, k.scope, k.key)
+}
+
+// delay menghitung jeda sebelum percobaan berikutnya: BaseDelay, lalu dua kali lipat tiap kegagalan
+func (g loginGuard) delay(failedCount int) time.Duration {
+	if failedCount <= 0 || g.policy.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := time.Duration(float64(g.policy.BaseDelay) * math.Pow(2, float64(failedCount-1)))
+	if delay <= 0 || delay > maxLoginDelay {
+		return maxLoginDelay
+	}
+	return delay
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeLoginAttemptRepo menyimpan percobaan login di memori dengan aturan window yang sama seperti repository
+type fakeLoginAttemptRepo struct {
+	repository.ILoginAttemptRepository
+	attempts map[loginKey]entity.LoginAttempt
+}
+
+func newFakeLoginAttemptRepo() *fakeLoginAttemptRepo {
+	return &fakeLoginAttemptRepo{attempts: make(map[loginKey]entity.LoginAttempt)}
+}
+
+func (r *fakeLoginAttemptRepo) Find(ctx context.Context, scope string, key string) (entity.LoginAttempt, error) {
+	attempt, ok := r.attempts[loginKey{scope: scope, key: key}]
+	if !ok {
+		return attempt, gorm.ErrRecordNotFound
+	}
+	return attempt, nil
+}
+
+func (r *fakeLoginAttemptRepo) RecordFailure(ctx context.Context, scope string, key string, now time.Time, window time.Duration) (entity.LoginAttempt, error) {
+	k := loginKey{scope: scope, key: key}
+	attempt, ok := r.attempts[k]
+	if !ok {
+		attempt = entity.LoginAttempt{Scope: scope, Key: key}
+	}
+
+	if attempt.LockedUntil != nil && attempt.LockedUntil.Before(now) {
+		attempt.LockedUntil = nil
+	}
+	if attempt.LastFailedAt.Before(now.Add(-window)) && attempt.LockedUntil == nil {
+		attempt.FailedCount = 0
+	}
+	attempt.FailedCount++
+	attempt.LastFailedAt = now
+
+	r.attempts[k] = attempt
+	return attempt, nil
+}
+
+func (r *fakeLoginAttemptRepo) Lock(ctx context.Context, scope string, key string, until time.Time) error {
+	k := loginKey{scope: scope, key: key}
+	attempt := r.attempts[k]
+	attempt.LockedUntil = &until
+	r.attempts[k] = attempt
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) Reset(ctx context.Context, scope string, key string) error {
+	delete(r.attempts, loginKey{scope: scope, key: key})
+	return nil
+}
+
+type fakeMFAService struct {
+	IMFAService
+}
+
+func (s *fakeMFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	return false, nil
+}
+
+func TestLoginGuard_Delay(t *testing.T) {
+	guard := loginGuard{policy: LoginPolicy{BaseDelay: time.Second}}
+
+	tests := []struct {
+		name        string
+		failedCount int
+		want        time.Duration
+	}{
+		{name: "belum pernah gagal", failedCount: 0, want: 0},
+		{name: "gagal pertama memakai base delay", failedCount: 1, want: time.Second},
+		{name: "gagal kedua dua kali lipat", failedCount: 2, want: 2 * time.Second},
+		{name: "gagal keempat", failedCount: 4, want: 8 * time.Second},
+		{name: "dibatasi maksimum", failedCount: 10, want: maxLoginDelay},
+		{name: "tidak overflow", failedCount: 200, want: maxLoginDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guard.delay(tt.failedCount); got != tt.want {
+				t.Errorf("delay(%d) = %s, want %s", tt.failedCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserService_Login(t *testing.T) {
+	const password = "rahasia123"
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budi := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "budi@example.com", Username: "budi", Password: string(hash), Role: entity.RoleCustomer, IsActive: true}
+	sari := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "sari@example.com", Username: "sari", Password: string(hash), Role: entity.RoleCustomer, IsActive: true}
+	nonaktif := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "lama@example.com", Username: "lama", Password: string(hash), Role: entity.RoleCustomer}
+
+	type attempt struct {
+		identifier string
+		password   string
+		ip         string
+		wantErr    error
+	}
+
+	tests := []struct {
+		name      string
+		baseDelay time.Duration
+		attempts  []attempt
+	}{
+		{
+			name: "user tidak ada dan password salah menghasilkan error yang sama",
+			attempts: []attempt{
+				{identifier: "tidakada@example.com", password: password, ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.2", wantErr: entity.ErrInvalidCredentials},
+			},
+		},
+		{
+			name: "akun nonaktif hanya diberitahu setelah password benar",
+			attempts: []attempt{
+				{identifier: nonaktif.Email, password: "salah", ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: nonaktif.Email, password: password, ip: "10.0.0.1", wantErr: entity.ErrAccountInactive},
+			},
+		},
+		{
+			name: "akun terkunci setelah batas percobaan dari IP mana pun",
+			attempts: []attempt{
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Username, password: "salah", ip: "10.0.0.2", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.3", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: password, ip: "10.0.0.4", wantErr: entity.ErrTooManyLoginAttempts},
+				{identifier: sari.Email, password: password, ip: "10.0.0.1"},
+			},
+		},
+		{
+			name: "akun yang tidak ada juga terkunci",
+			attempts: []attempt{
+				{identifier: "tebak@example.com", password: "a", ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "tebak@example.com", password: "b", ip: "10.0.0.2", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "TEBAK@example.com", password: "c", ip: "10.0.0.3", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "tebak@example.com", password: "d", ip: "10.0.0.4", wantErr: entity.ErrTooManyLoginAttempts},
+			},
+		},
+		{
+			name: "IP terkunci setelah gagal di banyak akun",
+			attempts: []attempt{
+				{identifier: "a@example.com", password: "salah", ip: "10.0.0.9", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "b@example.com", password: "salah", ip: "10.0.0.9", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "c@example.com", password: "salah", ip: "10.0.0.9", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "d@example.com", password: "salah", ip: "10.0.0.9", wantErr: entity.ErrInvalidCredentials},
+				{identifier: "e@example.com", password: "salah", ip: "10.0.0.9", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: password, ip: "10.0.0.9", wantErr: entity.ErrTooManyLoginAttempts},
+				{identifier: budi.Email, password: password, ip: "10.0.0.10"},
+			},
+		},
+		{
+			name:      "delay progresif menahan percobaan berikutnya",
+			baseDelay: time.Minute,
+			attempts: []attempt{
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: password, ip: "10.0.0.1", wantErr: entity.ErrTooManyLoginAttempts},
+			},
+		},
+		{
+			name: "login berhasil menghapus hitungan gagal akun",
+			attempts: []attempt{
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.1", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.2", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: password, ip: "10.0.0.3"},
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.4", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: "salah", ip: "10.0.0.5", wantErr: entity.ErrInvalidCredentials},
+				{identifier: budi.Email, password: password, ip: "10.0.0.6"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &fakeUserRepo{users: map[string]entity.User{
+				budi.ID.String():     budi,
+				sari.ID.String():     sari,
+				nonaktif.ID.String(): nonaktif,
+			}}
+			policy := LoginPolicy{MaxAttempts: 3, IPMaxAttempts: 5, LockoutDuration: 15 * time.Minute, BaseDelay: tt.baseDelay}
+			svc := NewUserService(userRepo, &fakeSessionRepo{}, newFakeLoginAttemptRepo(), *newTestJWTHelper(), policy, &fakeMFAService{}, time.Minute)
+
+			for i, a := range tt.attempts {
+				result, err := svc.Login(context.Background(), a.identifier, a.password, entity.SessionClient{IPAddress: a.ip})
+				if !errors.Is(err, a.wantErr) {
+					t.Fatalf("attempt %d: Login() error = %v, want %v", i+1, err, a.wantErr)
+				}
+				if a.wantErr == nil && result.UserToken.AccessToken == "" {
+					t.Errorf("attempt %d: no session created", i+1)
+				}
+
+				var throttled *entity.LoginThrottledError
+				if errors.As(err, &throttled) && throttled.RetryAfter <= 0 {
+					t.Errorf("attempt %d: retry after = %s, want positive", i+1, throttled.RetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestUserService_Unlock(t *testing.T) {
+	user := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "budi@example.com", Username: "budi", IsActive: true}
+	userRepo := &fakeUserRepo{users: map[string]entity.User{user.ID.String(): user}}
+	attemptRepo := newFakeLoginAttemptRepo()
+	svc := NewUserService(userRepo, &fakeSessionRepo{}, attemptRepo, *newTestJWTHelper(), LoginPolicy{LockoutDuration: time.Minute}, &fakeMFAService{}, time.Minute)
+
+	// Percobaan gagal tercatat dengan email, username, dan ID user, juga dari IP
+	keys := []loginKey{
+		accountLoginKey(nil, user.Email),
+		accountLoginKey(nil, user.Username),
+		accountLoginKey(&user, ""),
+	}
+	ipKey := ipLoginKey("10.0.0.1")
+	for _, k := range append(keys, ipKey) {
+		if _, err := attemptRepo.RecordFailure(context.Background(), k.scope, k.key, time.Now(), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := svc.Unlock(context.Background(), user.ID.String()); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	for _, k := range keys {
+		if _, ok := attemptRepo.attempts[k]; ok {
+			t.Errorf("attempts for %s %q were not reset", k.scope, k.key)
+		}
+	}
+	if _, ok := attemptRepo.attempts[ipKey]; !ok {
+		t.Error("attempts of the ip were reset by an account unlock")
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/totp"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew menerima kode dari satu step sebelum dan sesudah untuk mengatasi selisih jam perangkat
+	totpSkew = 1
+)
+
+type IMFAService interface {
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+	IsEnforced(role string) bool
+	Enroll(ctx context.Context, userID string) (entity.MFAEnrollmentResponse, error)
+	Confirm(ctx context.Context, userID string, code string) ([]string, error)
+	Verify(ctx context.Context, userID string, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error)
+	Disable(ctx context.Context, userID string, code string) error
+}
+
+type MFAService struct {
+	mfaRepo       repository.IMFARepository
+	userRepo      repository.IUserRepository
+	issuer        string
+	enforcedRoles map[string]bool
+}
+
+func NewMFAService(
+	mfaRepo repository.IMFARepository,
+	userRepo repository.IUserRepository,
+	issuer string,
+	enforcedRoles []string,
+) IMFAService {
+	roles := make(map[string]bool, len(enforcedRoles))
+	for _, role := range enforcedRoles {
+		roles[role] = true
+	}
+
+	return &MFAService{
+		mfaRepo:       mfaRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+		enforcedRoles: roles,
+	}
+}
+
+func (s *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.IsEnabled(), nil
+}
+
+// IsEnforced menandakan role wajib menyelesaikan two-factor sebelum memakai endpoint yang dilindungi
+func (s *MFAService) IsEnforced(role string) bool {
+	return s.enforcedRoles[role]
+}
+
+// Enroll membuat secret baru yang belum aktif sampai dikonfirmasi dengan kode dari aplikasi authenticator
+func (s *MFAService) Enroll(ctx context.Context, userID string) (entity.MFAEnrollmentResponse, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return entity.MFAEnrollmentResponse{}, err
+	}
+	if enabled {
+		return entity.MFAEnrollmentResponse{}, entity.ErrMFAAlreadyEnabled
+	}
+
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return entity.MFAEnrollmentResponse{}, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return entity.MFAEnrollmentResponse{}, err
+	}
+
+	if err := s.mfaRepo.SaveEnrollment(ctx, &entity.UserMFA{UserID: user.ID, Secret: secret}); err != nil {
+		return entity.MFAEnrollmentResponse{}, err
+	}
+
+	return entity.MFAEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm mengaktifkan MFA dan mengembalikan recovery code yang hanya ditampilkan sekali
+func (s *MFAService) Confirm(ctx context.Context, userID string, code string) ([]string, error) {
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+	if mfa.IsEnabled() {
+		return nil, entity.ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(mfa.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, entity.ErrMFAInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.Confirm(ctx, &mfa, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify menerima kode TOTP atau recovery code. Keduanya hanya bisa dipakai sekali.
+func (s *MFAService) Verify(ctx context.Context, userID string, code string) error {
+	mfa, err := s.mfaRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrMFANotEnrolled
+		}
+		return err
+	}
+	if !mfa.IsEnabled() {
+		return entity.ErrMFANotEnrolled
+	}
+
+	if step, ok := totp.Validate(mfa.Secret, code, time.Now(), totpSkew); ok {
+		used, err := s.mfaRepo.UseStep(ctx, &mfa, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return entity.ErrMFAInvalidCode
+		}
+		return nil
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, helpers.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return entity.ErrMFAInvalidCode
+	}
+	return nil
+}
+
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *MFAService) Disable(ctx context.Context, userID string, code string) error {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.IsEnforced(user.Role) {
+		return entity.ErrMFARequiredForRole
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.DeleteByUserID(ctx, userID)
+}
+
+// generateRecoveryCodes membuat recovery code dengan format xxxxx-xxxxx beserta hash-nya
+func generateRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(buf))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, helpers.HashToken(raw))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/totp"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeMFARepo struct {
+	repository.IMFARepository
+	mfa           *entity.UserMFA
+	recoveryCodes map[string]bool
+	deleted       bool
+}
+
+func (r *fakeMFARepo) FindByUserID(ctx context.Context, userID string) (entity.UserMFA, error) {
+	if r.mfa == nil {
+		return entity.UserMFA{}, gorm.ErrRecordNotFound
+	}
+	return *r.mfa, nil
+}
+
+func (r *fakeMFARepo) UseStep(ctx context.Context, mfa *entity.UserMFA, step int64) (bool, error) {
+	if step <= r.mfa.LastUsedStep {
+		return false, nil
+	}
+	r.mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeMFARepo) UseRecoveryCode(ctx context.Context, userID string, codeHash string) (bool, error) {
+	if !r.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	delete(r.recoveryCodes, codeHash)
+	return true, nil
+}
+
+func (r *fakeMFARepo) DeleteByUserID(ctx context.Context, userID string) error {
+	r.deleted = true
+	return nil
+}
+
+func TestMFAService_Verify(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	currentCode, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		enrolled     bool
+		confirmed    bool
+		lastUsedStep int64
+		code         string
+		wantErr      error
+	}{
+		{
+			name:      "kode TOTP yang valid",
+			enrolled:  true,
+			confirmed: true,
+			code:      currentCode,
+		},
+		{
+			name:         "kode TOTP yang sudah dipakai ditolak",
+			enrolled:     true,
+			confirmed:    true,
+			lastUsedStep: totp.Step(time.Now()) + 1,
+			code:         currentCode,
+			wantErr:      entity.ErrMFAInvalidCode,
+		},
+		{
+			name:      "recovery code dengan huruf besar dan tanda hubung",
+			enrolled:  true,
+			confirmed: true,
+			code:      "ABCDE-FGHIJ",
+		},
+		{
+			name:      "kode salah",
+			enrolled:  true,
+			confirmed: true,
+			code:      "zzzzz-zzzzz",
+			wantErr:   entity.ErrMFAInvalidCode,
+		},
+		{
+			name:     "enrollment belum dikonfirmasi",
+			enrolled: true,
+			code:     currentCode,
+			wantErr:  entity.ErrMFANotEnrolled,
+		},
+		{
+			name:    "belum enrollment",
+			code:    currentCode,
+			wantErr: entity.ErrMFANotEnrolled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMFARepo{recoveryCodes: map[string]bool{helpers.HashToken("abcdefghij"): true}}
+			if tt.enrolled {
+				repo.mfa = &entity.UserMFA{Secret: secret, LastUsedStep: tt.lastUsedStep}
+				if tt.confirmed {
+					repo.mfa.ConfirmedAt = &confirmedAt
+				}
+			}
+			svc := NewMFAService(repo, nil, "Toy Rental", nil)
+
+			if err := svc.Verify(context.Background(), uuid.Must(uuid.NewV7()).String(), tt.code); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// Kode yang sama tidak boleh diterima untuk kedua kalinya
+			if err := svc.Verify(context.Background(), uuid.Must(uuid.NewV7()).String(), tt.code); !errors.Is(err, entity.ErrMFAInvalidCode) {
+				t.Errorf("second Verify() error = %v, want %v", err, entity.ErrMFAInvalidCode)
+			}
+		})
+	}
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		role        string
+		code        string
+		wantErr     error
+		wantDeleted bool
+	}{
+		{
+			name:        "customer mematikan two-factor",
+			role:        entity.RoleCustomer,
+			code:        code,
+			wantDeleted: true,
+		},
+		{
+			name:    "role yang wajib two-factor tidak bisa mematikannya",
+			role:    entity.RoleAdmin,
+			code:    code,
+			wantErr: entity.ErrMFARequiredForRole,
+		},
+		{
+			name:    "kode salah",
+			role:    entity.RoleCustomer,
+			code:    "zzzzz-zzzzz",
+			wantErr: entity.ErrMFAInvalidCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.Must(uuid.NewV7())
+			repo := &fakeMFARepo{mfa: &entity.UserMFA{Secret: secret, ConfirmedAt: &confirmedAt}}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{userID.String(): {BaseEntity: entity.BaseEntity{ID: userID}, Role: tt.role}}}
+			svc := NewMFAService(repo, userRepo, "Toy Rental", []string{entity.RoleAdmin})
+
+			if err := svc.Disable(context.Background(), userID.String(), tt.code); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Disable() error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", repo.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
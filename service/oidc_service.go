@@ -0,0 +1,342 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/oidc"
+	"fmt"
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// usernameInvalidChars dipakai untuk membentuk username dari email saat user dibuat lewat provider
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9._]`)
+
+type IOIDCService interface {
+	Providers() []string
+	StartLogin(ctx context.Context, provider string) (string, string, error)
+	StartLink(ctx context.Context, provider string, userID uuid.UUID) (string, string, error)
+	HandleCallback(ctx context.Context, provider string, code string, state string, client entity.SessionClient) (entity.OIDCCallbackResult, error)
+	FindIdentities(ctx context.Context, userID string) ([]entity.UserIdentity, error)
+	Unlink(ctx context.Context, userID string, provider string) error
+}
+
+type OIDCService struct {
+	providers    map[string]*oidc.Provider
+	identityRepo repository.IUserIdentityRepository
+	userRepo     repository.IUserRepository
+	userSvc      IUserService
+	tokenSvc     ITokenService
+	stateTTL     time.Duration
+}
+
+func NewOIDCService(
+	providers []*oidc.Provider,
+	identityRepo repository.IUserIdentityRepository,
+	userRepo repository.IUserRepository,
+	userSvc IUserService,
+	tokenSvc ITokenService,
+	stateTTL time.Duration,
+) IOIDCService {
+	providerMap := make(map[string]*oidc.Provider, len(providers))
+	for _, provider := range providers {
+		providerMap[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		providers:    providerMap,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		userSvc:      userSvc,
+		tokenSvc:     tokenSvc,
+		stateTTL:     stateTTL,
+	}
+}
+
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin mengembalikan URL authorization provider dan state yang harus diikat ke browser user
+func (s *OIDCService) StartLogin(ctx context.Context, provider string) (string, string, error) {
+	return s.start(ctx, provider, entity.OIDCFlowLogin, nil)
+}
+
+// StartLink sama seperti StartLogin, tetapi hasil callback dihubungkan ke user yang sedang login
+func (s *OIDCService) StartLink(ctx context.Context, provider string, userID uuid.UUID) (string, string, error) {
+	return s.start(ctx, provider, entity.OIDCFlowLink, &userID)
+}
+
+func (s *OIDCService) start(ctx context.Context, providerName string, flow string, userID *uuid.UUID) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", entity.ErrOIDCProviderNotFound
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallengeS256(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.identityRepo.SaveState(ctx, &entity.OIDCState{
+		StateHash:    helpers.HashToken(state),
+		Provider:     providerName,
+		Flow:         flow,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       userID,
+		ExpiresAt:    time.Now().Add(s.stateTTL),
+	}); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// HandleCallback memproses redirect dari provider: state dipakai sekali, code ditukar dengan PKCE,
+// lalu id token diverifikasi sebelum user login atau identity dihubungkan
+func (s *OIDCService) HandleCallback(ctx context.Context, providerName string, code string, state string, client entity.SessionClient) (entity.OIDCCallbackResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return entity.OIDCCallbackResult{}, entity.ErrOIDCProviderNotFound
+	}
+
+	savedState, err := s.identityRepo.ConsumeState(ctx, helpers.HashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.OIDCCallbackResult{}, entity.ErrOIDCStateInvalid
+		}
+		return entity.OIDCCallbackResult{}, err
+	}
+
+	if savedState.Provider != providerName || time.Now().After(savedState.ExpiresAt) {
+		return entity.OIDCCallbackResult{}, entity.ErrOIDCStateInvalid
+	}
+
+	token, err := provider.Exchange(ctx, code, savedState.CodeVerifier)
+	if err != nil {
+		return entity.OIDCCallbackResult{}, err
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, token.IDToken, savedState.Nonce)
+	if err != nil {
+		return entity.OIDCCallbackResult{}, err
+	}
+
+	if savedState.Flow == entity.OIDCFlowLink && savedState.UserID != nil {
+		identity, err := s.link(ctx, providerName, *savedState.UserID, claims)
+		if err != nil {
+			return entity.OIDCCallbackResult{}, err
+		}
+		return entity.OIDCCallbackResult{Flow: entity.OIDCFlowLink, Identity: identity}, nil
+	}
+
+	user, err := s.resolveUser(ctx, providerName, claims)
+	if err != nil {
+		return entity.OIDCCallbackResult{}, err
+	}
+
+	result, err := s.userSvc.LoginWithIdentity(ctx, user, client)
+	if err != nil {
+		return entity.OIDCCallbackResult{}, err
+	}
+	return entity.OIDCCallbackResult{Flow: entity.OIDCFlowLogin, LoginResult: result}, nil
+}
+
+// resolveUser mencari user dari identity yang sudah terhubung, lalu dari email terverifikasi,
+// dan terakhir membuat user baru
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, claims oidc.IDTokenClaims) (entity.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		return s.userRepo.FindById(ctx, identity.UserID.String())
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.User{}, err
+	}
+
+	// Akun hanya dihubungkan lewat email yang sudah diverifikasi provider
+	if claims.Email == "" || !bool(claims.EmailVerified) {
+		return entity.User{}, entity.ErrOIDCEmailNotVerified
+	}
+
+	newIdentity := entity.UserIdentity{
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		LinkedAt: time.Now(),
+	}
+
+	user, err := s.userRepo.FindByEmail(ctx, claims.Email)
+	if err == nil {
+		newIdentity.UserID = user.ID
+		if user.IsEmailVerified() {
+			if err := s.identityRepo.Insert(ctx, &newIdentity); err != nil {
+				return entity.User{}, err
+			}
+			return *user, nil
+		}
+
+		if err := s.identityRepo.LinkAndVerifyUser(ctx, user.ID.String(), &newIdentity); err != nil {
+			return entity.User{}, err
+		}
+		if err := s.tokenSvc.RevokeAllSessions(ctx, user.ID.String(), ""); err != nil {
+			return entity.User{}, err
+		}
+		return s.userRepo.FindById(ctx, user.ID.String())
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.User{}, err
+	}
+
+	username, err := s.availableUsername(ctx, claims.Email)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	fullName := strings.TrimSpace(claims.Name)
+	if len([]rune(fullName)) < 2 {
+		fullName = username
+	}
+
+	// Password kosong tidak pernah cocok saat login, user bisa membuatnya lewat lupa password
+	now := time.Now()
+	newUser := entity.User{
+		Email:           strings.ToLower(claims.Email),
+		Username:        username,
+		FullName:        fullName,
+		IsActive:        true,
+		Role:            entity.RoleCustomer,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.identityRepo.InsertUserWithIdentity(ctx, &newUser, &newIdentity); err != nil {
+		return entity.User{}, err
+	}
+	return newUser, nil
+}
+
+func (s *OIDCService) link(ctx context.Context, providerName string, userID uuid.UUID, claims oidc.IDTokenClaims) (entity.UserIdentity, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(ctx, providerName, claims.Subject)
+	if err == nil {
+		if identity.UserID != userID {
+			return entity.UserIdentity{}, entity.ErrIdentityAlreadyLinked
+		}
+		return identity, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.UserIdentity{}, err
+	}
+
+	identities, err := s.identityRepo.FindByUserID(ctx, userID.String())
+	if err != nil {
+		return entity.UserIdentity{}, err
+	}
+	for _, existing := range identities {
+		if existing.Provider == providerName {
+			return entity.UserIdentity{}, entity.ErrIdentityProviderLinked
+		}
+	}
+
+	identity = entity.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		LinkedAt: time.Now(),
+	}
+	if err := s.identityRepo.Insert(ctx, &identity); err != nil {
+		return entity.UserIdentity{}, err
+	}
+	return identity, nil
+}
+
+func (s *OIDCService) FindIdentities(ctx context.Context, userID string) ([]entity.UserIdentity, error) {
+	return s.identityRepo.FindByUserID(ctx, userID)
+}
+
+// Unlink memutus identity provider. User tanpa password harus menyisakan minimal satu identity
+// agar tetap bisa login.
+func (s *OIDCService) Unlink(ctx context.Context, userID string, provider string) error {
+	user, err := s.userRepo.FindById(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	identities, err := s.identityRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			found = true
+		}
+	}
+	if !found {
+		return entity.ErrIdentityNotFound
+	}
+
+	if user.Password == "" && len(identities) == 1 {
+		return entity.ErrIdentityLastLoginMethod
+	}
+
+	if err := s.identityRepo.DeleteByUserAndProvider(ctx, userID, provider); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrIdentityNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// availableUsername membentuk username dari bagian lokal email, ditambah akhiran acak jika sudah dipakai
+func (s *OIDCService) availableUsername(ctx context.Context, email string) (string, error) {
+	base := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	base = usernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) < 3 {
+		base = "user" + base
+	}
+	if len(base) > 90 {
+		base = base[:90]
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		_, err := s.userRepo.FindByEmailOrUsername(ctx, candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		} else if err != nil {
+			return "", err
+		}
+
+		suffix, err := oidc.RandomString(4)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + strings.ToLower(usernameInvalidChars.ReplaceAllString(suffix, ""))
+	}
+
+	return "", fmt.Errorf("gagal membuat username unik untuk %s", email)
+}
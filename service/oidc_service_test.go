@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/oidc"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+func (r *fakeUserRepo) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepo) FindByEmailOrUsername(ctx context.Context, emailOrUsername string) (*entity.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, emailOrUsername) || user.Username == emailOrUsername {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeIdentityRepo struct {
+	repository.IUserIdentityRepository
+	identities   []entity.UserIdentity
+	inserted     *entity.UserIdentity
+	linkVerified *entity.UserIdentity
+	createdUser  *entity.User
+}
+
+func (r *fakeIdentityRepo) FindByProviderSubject(ctx context.Context, provider string, subject string) (entity.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return entity.UserIdentity{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeIdentityRepo) FindByUserID(ctx context.Context, userID string) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID.String() == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeIdentityRepo) Insert(ctx context.Context, identity *entity.UserIdentity) error {
+	r.inserted = identity
+	return nil
+}
+
+func (r *fakeIdentityRepo) LinkAndVerifyUser(ctx context.Context, userID string, identity *entity.UserIdentity) error {
+	r.linkVerified = identity
+	return nil
+}
+
+func (r *fakeIdentityRepo) InsertUserWithIdentity(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	r.createdUser = user
+	r.inserted = identity
+	return nil
+}
+
+func newTestIDTokenClaims(subject string, email string, emailVerified bool) oidc.IDTokenClaims {
+	claims := oidc.IDTokenClaims{
+		Email:            email,
+		Name:             "Budi Santoso",
+		RegisteredClaims: jwt.RegisteredClaims{Subject: subject},
+	}
+	if emailVerified {
+		claims.EmailVerified = true
+	}
+	return claims
+}
+
+func TestOIDCService_ResolveUser(t *testing.T) {
+	now := time.Now()
+	verified := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "budi@example.com", Username: "budi", Password: "hash", IsActive: true, EmailVerifiedAt: &now}
+	unverified := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "sari@example.com", Username: "sari", Password: "hash", IsActive: true}
+
+	tests := []struct {
+		name         string
+		claims       oidc.IDTokenClaims
+		identities   []entity.UserIdentity
+		wantErr      error
+		wantUserID   uuid.UUID
+		wantInserted bool
+		wantVerified bool
+		wantRevoked  bool
+		wantNewUser  bool
+	}{
+		{
+			name:       "identity sudah terhubung",
+			claims:     newTestIDTokenClaims("sub-1", "lain@example.com", false),
+			identities: []entity.UserIdentity{{UserID: verified.ID, Provider: "google", Subject: "sub-1"}},
+			wantUserID: verified.ID,
+		},
+		{
+			name:    "email provider belum terverifikasi tidak dihubungkan",
+			claims:  newTestIDTokenClaims("sub-2", verified.Email, false),
+			wantErr: entity.ErrOIDCEmailNotVerified,
+		},
+		{
+			name:         "email terverifikasi dihubungkan ke akun terverifikasi",
+			claims:       newTestIDTokenClaims("sub-3", "BUDI@example.com", true),
+			wantUserID:   verified.ID,
+			wantInserted: true,
+		},
+		{
+			name:         "akun belum terverifikasi diambil alih provider dan sesinya dicabut",
+			claims:       newTestIDTokenClaims("sub-4", unverified.Email, true),
+			wantUserID:   unverified.ID,
+			wantVerified: true,
+			wantRevoked:  true,
+		},
+		{
+			name:         "email baru membuat user baru",
+			claims:       newTestIDTokenClaims("sub-5", "baru@example.com", true),
+			wantInserted: true,
+			wantNewUser:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identityRepo := &fakeIdentityRepo{identities: tt.identities}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{
+				verified.ID.String():   verified,
+				unverified.ID.String(): unverified,
+			}}
+			tokenRepo := &fakeUserTokenRepo{}
+			tokenSvc := NewTokenService(tokenRepo, userRepo, *newTestJWTHelper(), time.Minute)
+			svc := NewOIDCService(nil, identityRepo, userRepo, nil, tokenSvc, time.Minute).(*OIDCService)
+
+			user, err := svc.resolveUser(context.Background(), "google", tt.claims)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolveUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if identityRepo.inserted != nil || identityRepo.linkVerified != nil {
+					t.Error("identity linked although the login was rejected")
+				}
+				return
+			}
+
+			if tt.wantUserID != uuid.Nil && user.ID != tt.wantUserID {
+				t.Errorf("user = %s, want %s", user.ID, tt.wantUserID)
+			}
+			if inserted := identityRepo.inserted != nil; inserted != tt.wantInserted {
+				t.Errorf("identity inserted = %v, want %v", inserted, tt.wantInserted)
+			}
+			if linkVerified := identityRepo.linkVerified != nil; linkVerified != tt.wantVerified {
+				t.Errorf("identity linked with verification = %v, want %v", linkVerified, tt.wantVerified)
+			}
+			if revoked := tokenRepo.blockedUserID == tt.wantUserID.String(); revoked != tt.wantRevoked {
+				t.Errorf("sessions revoked = %v, want %v", revoked, tt.wantRevoked)
+			}
+			if createdUser := identityRepo.createdUser != nil; createdUser != tt.wantNewUser {
+				t.Fatalf("user created = %v, want %v", createdUser, tt.wantNewUser)
+			}
+			if tt.wantNewUser && (identityRepo.createdUser.Role != entity.RoleCustomer || !identityRepo.createdUser.IsEmailVerified()) {
+				t.Errorf("new user role = %s, verified = %v, want verified customer", identityRepo.createdUser.Role, identityRepo.createdUser.IsEmailVerified())
+			}
+		})
+	}
+}
+
+func TestOIDCService_Link(t *testing.T) {
+	userID := uuid.Must(uuid.NewV7())
+	otherID := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name         string
+		identities   []entity.UserIdentity
+		wantErr      error
+		wantInserted bool
+	}{
+		{
+			name:         "provider baru dihubungkan",
+			wantInserted: true,
+		},
+		{
+			name:       "identity sudah terhubung ke user yang sama",
+			identities: []entity.UserIdentity{{UserID: userID, Provider: "google", Subject: "sub-1"}},
+		},
+		{
+			name:       "identity milik user lain ditolak",
+			identities: []entity.UserIdentity{{UserID: otherID, Provider: "google", Subject: "sub-1"}},
+			wantErr:    entity.ErrIdentityAlreadyLinked,
+		},
+		{
+			name:       "user sudah menghubungkan akun lain dari provider yang sama",
+			identities: []entity.UserIdentity{{UserID: userID, Provider: "google", Subject: "sub-lama"}},
+			wantErr:    entity.ErrIdentityProviderLinked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identityRepo := &fakeIdentityRepo{identities: tt.identities}
+			svc := NewOIDCService(nil, identityRepo, nil, nil, nil, time.Minute).(*OIDCService)
+
+			identity, err := svc.link(context.Background(), "google", userID, newTestIDTokenClaims("sub-1", "budi@example.com", true))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("link() error = %v, want %v", err, tt.wantErr)
+			}
+			if inserted := identityRepo.inserted != nil; inserted != tt.wantInserted {
+				t.Errorf("identity inserted = %v, want %v", inserted, tt.wantInserted)
+			}
+			if tt.wantErr == nil && identity.UserID != userID {
+				t.Errorf("identity user = %s, want %s", identity.UserID, userID)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+)
+
+type IPlanService interface {
+	IBaseService[entity.Plan]
+	FindAllActive(ctx context.Context) ([]entity.Plan, error)
+}
+
+type PlanService struct {
+	BaseService[entity.Plan]
+	planRepo repository.IPlanRepository
+}
+
+func NewPlanService(repo repository.IPlanRepository) IPlanService {
+	return &PlanService{
+		BaseService: BaseService[entity.Plan]{repository: repo},
+		planRepo:    repo,
+	}
+}
+
+func (s *PlanService) FindAllActive(ctx context.Context) ([]entity.Plan, error) {
+	return s.planRepo.FindAllActive(ctx)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+type IPrivacyService interface {
+	Export(ctx context.Context, userID string) ([]byte, error)
+	RequestErasure(ctx context.Context, userID string, currentSessionID string) (entity.ErasureResponse, error)
+	CancelErasure(ctx context.Context, userID string) error
+	EraseDue(ctx context.Context) error
+	RunErasureWorker(ctx context.Context, interval time.Duration)
+}
+
+type PrivacyService struct {
+	privacyRepo repository.IPrivacyRepository
+	tokenSvc    ITokenService
+	gracePeriod time.Duration
+}
+
+func NewPrivacyService(privacyRepo repository.IPrivacyRepository, tokenSvc ITokenService, gracePeriod time.Duration) IPrivacyService {
+	return &PrivacyService{
+		privacyRepo: privacyRepo,
+		tokenSvc:    tokenSvc,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Export membuat arsip ZIP berisi satu file JSON untuk setiap jenis data pribadi user
+func (s *PrivacyService) Export(ctx context.Context, userID string) ([]byte, error) {
+	data, err := s.privacyRepo.FindExportData(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", data.Profile},
+		{"addresses.json", data.Addresses},
+		{"rentals.json", data.Rentals},
+		{"payments.json", data.Payments},
+		{"subscriptions.json", data.Subscriptions},
+		{"sessions.json", data.Sessions},
+		{"identities.json", data.Identities},
+		{"wishlist.json", data.Wishlist},
+		{"waitlist.json", data.Waitlist},
+		{"voucher_redemptions.json", data.VoucherRedemptions},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("gagal membuat %s: %w", file.name, err)
+		}
+
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write(content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RequestErasure menjadwalkan anonimisasi akun setelah masa tenggang. Sesi lain dicabut, sesi
+// yang sedang dipakai tetap aktif agar user masih bisa membatalkan.
+func (s *PrivacyService) RequestErasure(ctx context.Context, userID string, currentSessionID string) (entity.ErasureResponse, error) {
+	if err := s.privacyRepo.CheckErasable(ctx, userID); err != nil {
+		return entity.ErasureResponse{}, err
+	}
+
+	now := time.Now()
+	if err := s.privacyRepo.RequestErasure(ctx, userID, now); err != nil {
+		return entity.ErasureResponse{}, err
+	}
+
+	if err := s.tokenSvc.RevokeAllSessions(ctx, userID, currentSessionID); err != nil {
+		return entity.ErasureResponse{}, err
+	}
+
+	return entity.ErasureResponse{
+		RequestedAt: now,
+		ErasesAt:    now.Add(s.gracePeriod),
+	}, nil
+}
+
+func (s *PrivacyService) CancelErasure(ctx context.Context, userID string) error {
+	return s.privacyRepo.CancelErasure(ctx, userID)
+}
+
+// EraseDue menganonimkan akun yang masa tenggangnya sudah habis. Akun yang masih punya transaksi
+// berjalan dilewati dan dicoba lagi pada putaran berikutnya.
+func (s *PrivacyService) EraseDue(ctx context.Context) error {
+	now := time.Now()
+	users, err := s.privacyRepo.FindDueErasures(ctx, now.Add(-s.gracePeriod))
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		userID := user.ID.String()
+		if err := s.privacyRepo.CheckErasable(ctx, userID); err != nil {
+			helpers.Logger.Error(fmt.Errorf("account erasure of user %s postponed: %v", userID, err))
+			continue
+		}
+
+		if err := s.privacyRepo.Anonymize(ctx, userID, now); err != nil {
+			if !errors.Is(err, entity.ErrErasureNotRequested) && !errors.Is(err, gorm.ErrRecordNotFound) {
+				helpers.Logger.Error(fmt.Errorf("failed to erase user %s: %v", userID, err))
+			}
+			continue
+		}
+
+		if err := s.tokenSvc.RevokeAllSessions(ctx, userID, ""); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to revoke sessions of erased user %s: %v", userID, err))
+		}
+		helpers.Logger.Infof("User %s has been erased", userID)
+	}
+
+	return nil
+}
+
+func (s *PrivacyService) RunErasureWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.EraseDue(ctx); err != nil {
+				helpers.Logger.Error("Failed to erase accounts: ", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiterSweepSize adalah jumlah window sebelum window yang sudah lewat dibersihkan
+const rateLimiterSweepSize = 10000
+
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// rateLimiter membatasi jumlah request per key dalam window waktu tetap.
+// Hitungan disimpan di memori sehingga batasnya berlaku per instance.
+type rateLimiter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	windows map[string]rateWindow
+}
+
+func newRateLimiter(window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		window:  window,
+		windows: make(map[string]rateWindow),
+	}
+}
+
+// allow mencatat satu request untuk key. Jika batas sudah tercapai, dikembalikan false
+// beserta sisa waktu sampai window berikutnya.
+func (l *rateLimiter) allow(key string, limit int) (bool, int, time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.windows) >= rateLimiterSweepSize {
+		for k, w := range l.windows {
+			if now.After(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.After(w.resetAt) {
+		w = rateWindow{resetAt: now.Add(l.window)}
+	}
+
+	if w.count >= limit {
+		l.windows[key] = w
+		return false, 0, w.resetAt.Sub(now)
+	}
+
+	w.count++
+	l.windows[key] = w
+	return true, limit - w.count, w.resetAt.Sub(now)
+}
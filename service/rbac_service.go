@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"fmt"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+type IRBACService interface {
+	HasPermission(ctx context.Context, role string, permission string) (bool, error)
+	FindAllRoles(ctx context.Context) ([]entity.Role, error)
+	FindAllPermissions(ctx context.Context) ([]entity.Permission, error)
+	CreateRole(ctx context.Context, callerRole string, req entity.RoleRequest) (entity.Role, error)
+	UpdateRole(ctx context.Context, callerRole string, id string, req entity.RoleRequest) (entity.Role, error)
+	DeleteRole(ctx context.Context, id string) error
+	AssignRole(ctx context.Context, callerID string, callerRole string, userID string, roleName string) error
+}
+
+type rolePermissionCache struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+type RBACService struct {
+	roleRepo repository.IRoleRepository
+	tokenSvc ITokenService
+	cacheTTL time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]rolePermissionCache
+}
+
+func NewRBACService(roleRepo repository.IRoleRepository, tokenSvc ITokenService, cacheTTL time.Duration) IRBACService {
+	return &RBACService{
+		roleRepo: roleRepo,
+		tokenSvc: tokenSvc,
+		cacheTTL: cacheTTL,
+		cache:    make(map[string]rolePermissionCache),
+	}
+}
+
+// HasPermission memeriksa permission role. Daftar permission per role di-cache sebentar
+// karena dipanggil di setiap request admin.
+func (s *RBACService) HasPermission(ctx context.Context, role string, permission string) (bool, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[role]
+	s.mu.RUnlock()
+
+	if !ok || time.Now().After(cached.expiresAt) {
+		model, err := s.roleRepo.FindByName(ctx, role)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, err
+		}
+
+		cached = rolePermissionCache{
+			permissions: make(map[string]bool, len(model.Permissions)),
+			expiresAt:   time.Now().Add(s.cacheTTL),
+		}
+		for _, code := range model.PermissionCodes() {
+			cached.permissions[code] = true
+		}
+
+		s.mu.Lock()
+		s.cache[role] = cached
+		s.mu.Unlock()
+	}
+
+	return cached.permissions[permission], nil
+}
+
+func (s *RBACService) FindAllRoles(ctx context.Context) ([]entity.Role, error) {
+	return s.roleRepo.FindAllWithPermissions(ctx)
+}
+
+func (s *RBACService) FindAllPermissions(ctx context.Context) ([]entity.Permission, error) {
+	return s.roleRepo.FindPermissions(ctx)
+}
+
+// CreateRole membuat role baru. Pemanggil hanya boleh memberikan permission yang dimiliki
+// role-nya sendiri agar role.manage tidak bisa dipakai untuk menaikkan hak akses.
+func (s *RBACService) CreateRole(ctx context.Context, callerRole string, req entity.RoleRequest) (entity.Role, error) {
+	if err := s.ensureHeld(ctx, callerRole, req.Permissions); err != nil {
+		return entity.Role{}, err
+	}
+
+	if _, err := s.roleRepo.FindByName(ctx, req.Name); err == nil {
+		return entity.Role{}, entity.ErrRoleAlreadyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.Role{}, err
+	}
+
+	permissions, err := s.roleRepo.FindPermissionsByCodes(ctx, req.Permissions)
+	if err != nil {
+		return entity.Role{}, err
+	}
+
+	role := entity.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+	}
+	if err := s.roleRepo.InsertWithPermissions(ctx, &role); err != nil {
+		return entity.Role{}, err
+	}
+
+	s.invalidate(role.Name)
+	return role, nil
+}
+
+// UpdateRole mengganti deskripsi dan permission role. Role bawaan hanya boleh diubah permission-nya,
+// kecuali admin yang selalu memiliki semua permission. Seperti CreateRole, permission yang baru
+// ditambahkan harus dimiliki role pemanggil.
+func (s *RBACService) UpdateRole(ctx context.Context, callerRole string, id string, req entity.RoleRequest) (entity.Role, error) {
+	role, err := s.roleRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Role{}, entity.ErrRoleNotFound
+		}
+		return entity.Role{}, err
+	}
+
+	if role.Name == entity.RoleAdmin || (role.IsSystem && req.Name != role.Name) {
+		return entity.Role{}, entity.ErrSystemRoleReadOnly
+	}
+
+	current := make(map[string]bool, len(role.Permissions))
+	for _, code := range role.PermissionCodes() {
+		current[code] = true
+	}
+	var added []string
+	for _, code := range req.Permissions {
+		if !current[code] {
+			added = append(added, code)
+		}
+	}
+	if err := s.ensureHeld(ctx, callerRole, added); err != nil {
+		return entity.Role{}, err
+	}
+
+	if req.Name != role.Name {
+		if _, err := s.roleRepo.FindByName(ctx, req.Name); err == nil {
+			return entity.Role{}, entity.ErrRoleAlreadyExists
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Role{}, err
+		}
+
+		// Role yang sudah dipakai user tidak boleh diganti namanya karena users.role menyimpan nama role
+		total, err := s.roleRepo.CountUsersByRole(ctx, role.Name)
+		if err != nil {
+			return entity.Role{}, err
+		}
+		if total > 0 {
+			return entity.Role{}, entity.ErrRoleInUse
+		}
+	}
+
+	permissions, err := s.roleRepo.FindPermissionsByCodes(ctx, req.Permissions)
+	if err != nil {
+		return entity.Role{}, err
+	}
+
+	oldName := role.Name
+	role.Name = req.Name
+	role.Description = req.Description
+	role.Permissions = permissions
+	if err := s.roleRepo.UpdateWithPermissions(ctx, &role); err != nil {
+		return entity.Role{}, err
+	}
+
+	s.invalidate(oldName)
+	s.invalidate(role.Name)
+	return role, nil
+}
+
+func (s *RBACService) DeleteRole(ctx context.Context, id string) error {
+	role, err := s.roleRepo.FindById(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrRoleNotFound
+		}
+		return err
+	}
+
+	if role.IsSystem {
+		return entity.ErrSystemRoleReadOnly
+	}
+
+	total, err := s.roleRepo.CountUsersByRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return entity.ErrRoleInUse
+	}
+
+	if err := s.roleRepo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidate(role.Name)
+	return nil
+}
+
+// AssignRole mengganti role user dan mencabut sesinya, karena role tersimpan di access token.
+// Pemanggil tidak boleh mengubah role-nya sendiri, memberikan role yang memiliki permission
+// di luar permission role pemanggil, atau mengubah role user yang permission-nya tidak dimiliki
+// pemanggil. Admin terakhir tidak bisa diturunkan.
+func (s *RBACService) AssignRole(ctx context.Context, callerID string, callerRole string, userID string, roleName string) error {
+	if callerID == userID {
+		return entity.ErrRoleSelfAssign
+	}
+
+	role, err := s.findRoleByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureHeld(ctx, callerRole, rolePermissionCodes(role)); err != nil {
+		return err
+	}
+
+	currentRoleName, err := s.roleRepo.FindRoleNameByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	currentRole, err := s.findRoleByName(ctx, currentRoleName)
+	if err != nil && !errors.Is(err, entity.ErrRoleNotFound) {
+		return err
+	}
+	if err := s.ensureHeld(ctx, callerRole, rolePermissionCodes(currentRole)); err != nil {
+		return err
+	}
+
+	if currentRoleName == entity.RoleAdmin && roleName != entity.RoleAdmin {
+		total, err := s.roleRepo.CountUsersByRole(ctx, entity.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if total <= 1 {
+			return entity.ErrLastAdmin
+		}
+	}
+
+	if err := s.roleRepo.AssignToUser(ctx, userID, roleName); err != nil {
+		return err
+	}
+
+	return s.tokenSvc.RevokeAllSessions(ctx, userID, "")
+}
+
+func (s *RBACService) findRoleByName(ctx context.Context, name string) (entity.Role, error) {
+	role, err := s.roleRepo.FindByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.Role{Name: name}, entity.ErrRoleNotFound
+		}
+		return entity.Role{}, err
+	}
+	return role, nil
+}
+
+// rolePermissionCodes mengembalikan permission role. Admin selalu mendapat semua permission
+// meskipun baris role_permissions-nya belum lengkap.
+func rolePermissionCodes(role entity.Role) []string {
+	if role.Name != entity.RoleAdmin {
+		return role.PermissionCodes()
+	}
+
+	permissions := make([]string, 0, len(entity.DefaultPermissions))
+	for code := range entity.DefaultPermissions {
+		permissions = append(permissions, code)
+	}
+	return permissions
+}
+
+// ensureHeld memastikan role pemanggil memiliki semua permission yang akan diberikan
+func (s *RBACService) ensureHeld(ctx context.Context, callerRole string, permissions []string) error {
+	for _, permission := range permissions {
+		allowed, err := s.HasPermission(ctx, callerRole, permission)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("%w: %s", entity.ErrPermissionNotHeld, permission)
+		}
+	}
+	return nil
+}
+
+func (s *RBACService) invalidate(role string) {
+	s.mu.Lock()
+	delete(s.cache, role)
+	s.mu.Unlock()
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeRoleRepo struct {
+	repository.IRoleRepository
+	roles     map[string]entity.Role
+	userRoles map[string]string
+	saved     *entity.Role
+	assigned  string
+}
+
+func (r *fakeRoleRepo) FindByName(ctx context.Context, name string) (entity.Role, error) {
+	role, ok := r.roles[name]
+	if !ok {
+		return role, gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) FindById(ctx context.Context, id string) (entity.Role, error) {
+	for _, role := range r.roles {
+		if role.ID.String() == id {
+			return role, nil
+		}
+	}
+	return entity.Role{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepo) FindPermissionsByCodes(ctx context.Context, codes []string) ([]entity.Permission, error) {
+	return newPermissions(codes...), nil
+}
+
+func (r *fakeRoleRepo) InsertWithPermissions(ctx context.Context, role *entity.Role) error {
+	r.saved = role
+	return nil
+}
+
+func (r *fakeRoleRepo) UpdateWithPermissions(ctx context.Context, role *entity.Role) error {
+	r.saved = role
+	return nil
+}
+
+func (r *fakeRoleRepo) FindRoleNameByUserID(ctx context.Context, userID string) (string, error) {
+	role, ok := r.userRoles[userID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return role, nil
+}
+
+func (r *fakeRoleRepo) CountUsersByRole(ctx context.Context, name string) (int64, error) {
+	var total int64
+	for _, role := range r.userRoles {
+		if role == name {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (r *fakeRoleRepo) AssignToUser(ctx context.Context, userID string, roleName string) error {
+	r.assigned = roleName
+	return nil
+}
+
+func newPermissions(codes ...string) []entity.Permission {
+	permissions := make([]entity.Permission, 0, len(codes))
+	for _, code := range codes {
+		permissions = append(permissions, entity.Permission{Code: code})
+	}
+	return permissions
+}
+
+func newTestRoles() map[string]entity.Role {
+	admin := make([]string, 0, len(entity.DefaultPermissions))
+	for code := range entity.DefaultPermissions {
+		admin = append(admin, code)
+	}
+
+	roles := map[string]entity.Role{
+		entity.RoleAdmin: {Name: entity.RoleAdmin, IsSystem: true, Permissions: newPermissions(admin...)},
+		entity.RoleStaff: {Name: entity.RoleStaff, IsSystem: true, Permissions: newPermissions(entity.DefaultRolePermissions[entity.RoleStaff]...)},
+		// Role custom yang boleh mengelola role tetapi hanya memiliki satu permission lain
+		"role_manager": {Name: "role_manager", Permissions: newPermissions(entity.PermissionRoleManage, entity.PermissionRentalRead)},
+		"auditor":      {Name: "auditor", Permissions: newPermissions(entity.PermissionAuditRead)},
+	}
+	for name, role := range roles {
+		role.ID = uuid.Must(uuid.NewV7())
+		roles[name] = role
+	}
+	return roles
+}
+
+func newTestRBACService(roleRepo *fakeRoleRepo, tokenRepo *fakeUserTokenRepo) IRBACService {
+	tokenSvc := NewTokenService(tokenRepo, nil, *newTestJWTHelper(), time.Minute)
+	return NewRBACService(roleRepo, tokenSvc, time.Minute)
+}
+
+func TestRBACService_CreateRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		callerRole  string
+		permissions []string
+		wantErr     error
+	}{
+		{
+			name:        "admin memberikan permission apa pun",
+			callerRole:  entity.RoleAdmin,
+			permissions: []string{entity.PermissionUserManage, entity.PermissionRoleManage},
+		},
+		{
+			name:        "permission yang dimiliki pemanggil",
+			callerRole:  "role_manager",
+			permissions: []string{entity.PermissionRentalRead},
+		},
+		{
+			name:        "permission yang tidak dimiliki pemanggil ditolak",
+			callerRole:  "role_manager",
+			permissions: []string{entity.PermissionRentalRead, entity.PermissionUserManage},
+			wantErr:     entity.ErrPermissionNotHeld,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roleRepo := &fakeRoleRepo{roles: newTestRoles()}
+			svc := newTestRBACService(roleRepo, &fakeUserTokenRepo{})
+
+			_, err := svc.CreateRole(context.Background(), tt.callerRole, entity.RoleRequest{Name: "gudang", Permissions: tt.permissions})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if saved := roleRepo.saved != nil; saved != (tt.wantErr == nil) {
+				t.Errorf("role saved = %v, want %v", saved, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestRBACService_UpdateRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		callerRole  string
+		role        string
+		permissions []string
+		wantErr     error
+	}{
+		{
+			name:        "permission lama dipertahankan dan permission baru dimiliki pemanggil",
+			callerRole:  "role_manager",
+			role:        "auditor",
+			permissions: []string{entity.PermissionAuditRead, entity.PermissionRentalRead},
+		},
+		{
+			name:        "menambah permission ke role sendiri ditolak jika tidak dimiliki",
+			callerRole:  "role_manager",
+			role:        "role_manager",
+			permissions: []string{entity.PermissionRoleManage, entity.PermissionUserImpersonate},
+			wantErr:     entity.ErrPermissionNotHeld,
+		},
+		{
+			name:        "role admin tidak bisa diubah",
+			callerRole:  entity.RoleAdmin,
+			role:        entity.RoleAdmin,
+			permissions: []string{entity.PermissionRoleManage},
+			wantErr:     entity.ErrSystemRoleReadOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := newTestRoles()
+			roleRepo := &fakeRoleRepo{roles: roles}
+			svc := newTestRBACService(roleRepo, &fakeUserTokenRepo{})
+
+			_, err := svc.UpdateRole(context.Background(), tt.callerRole, roles[tt.role].ID.String(),
+				entity.RoleRequest{Name: tt.role, Permissions: tt.permissions})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if saved := roleRepo.saved != nil; saved != (tt.wantErr == nil) {
+				t.Errorf("role saved = %v, want %v", saved, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestRBACService_AssignRole(t *testing.T) {
+	callerID := uuid.Must(uuid.NewV7()).String()
+	userID := uuid.Must(uuid.NewV7()).String()
+	otherAdminID := uuid.Must(uuid.NewV7()).String()
+
+	tests := []struct {
+		name        string
+		callerRole  string
+		userID      string
+		currentRole string
+		otherAdmin  bool
+		role        string
+		wantErr     error
+	}{
+		{
+			name:       "admin memberikan role admin",
+			callerRole: entity.RoleAdmin,
+			userID:     userID,
+			role:       entity.RoleAdmin,
+		},
+		{
+			name:       "role yang permission-nya dimiliki pemanggil",
+			callerRole: "role_manager",
+			userID:     userID,
+			role:       entity.RoleCustomer,
+		},
+		{
+			name:       "role admin tidak bisa diberikan pemanggil non admin",
+			callerRole: "role_manager",
+			userID:     userID,
+			role:       entity.RoleAdmin,
+			wantErr:    entity.ErrPermissionNotHeld,
+		},
+		{
+			name:       "role dengan permission di luar milik pemanggil",
+			callerRole: "role_manager",
+			userID:     userID,
+			role:       "auditor",
+			wantErr:    entity.ErrPermissionNotHeld,
+		},
+		{
+			name:        "pemanggil non admin tidak bisa menurunkan admin",
+			callerRole:  "role_manager",
+			userID:      userID,
+			currentRole: entity.RoleAdmin,
+			otherAdmin:  true,
+			role:        entity.RoleCustomer,
+			wantErr:     entity.ErrPermissionNotHeld,
+		},
+		{
+			name:        "admin menurunkan admin lain",
+			callerRole:  entity.RoleAdmin,
+			userID:      userID,
+			currentRole: entity.RoleAdmin,
+			otherAdmin:  true,
+			role:        entity.RoleStaff,
+		},
+		{
+			name:        "admin terakhir tidak bisa diturunkan",
+			callerRole:  entity.RoleAdmin,
+			userID:      userID,
+			currentRole: entity.RoleAdmin,
+			role:        entity.RoleCustomer,
+			wantErr:     entity.ErrLastAdmin,
+		},
+		{
+			name:       "mengubah role sendiri ditolak",
+			callerRole: entity.RoleAdmin,
+			userID:     callerID,
+			role:       entity.RoleStaff,
+			wantErr:    entity.ErrRoleSelfAssign,
+		},
+		{
+			name:       "role tidak ditemukan",
+			callerRole: entity.RoleAdmin,
+			userID:     userID,
+			role:       "tidak_ada",
+			wantErr:    entity.ErrRoleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := newTestRoles()
+			roles[entity.RoleCustomer] = entity.Role{Name: entity.RoleCustomer, IsSystem: true}
+			currentRole := tt.currentRole
+			if currentRole == "" {
+				currentRole = entity.RoleCustomer
+			}
+			userRoles := map[string]string{tt.userID: currentRole}
+			if tt.otherAdmin {
+				userRoles[otherAdminID] = entity.RoleAdmin
+			}
+			roleRepo := &fakeRoleRepo{roles: roles, userRoles: userRoles}
+			tokenRepo := &fakeUserTokenRepo{}
+			svc := newTestRBACService(roleRepo, tokenRepo)
+
+			err := svc.AssignRole(context.Background(), callerID, tt.callerRole, tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AssignRole() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if roleRepo.assigned != "" {
+					t.Errorf("role %s assigned although the assignment was rejected", roleRepo.assigned)
+				}
+				return
+			}
+
+			if roleRepo.assigned != tt.role {
+				t.Errorf("assigned role = %q, want %q", roleRepo.assigned, tt.role)
+			}
+			if tokenRepo.blockedUserID != tt.userID {
+				t.Error("sessions of the user were not revoked")
+			}
+		})
+	}
+}
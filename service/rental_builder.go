@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"math"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+// rentalBuilder menyusun rental beserta item dan total harganya dari request,
+// dipakai bersama oleh checkout biasa dan konfirmasi waitlist
+type rentalBuilder struct {
+	userRepo      repository.IUserRepository
+	toyRepo       repository.IToyRepository
+	bundleRepo    repository.IBundleRepository
+	fulfilmentSvc IFulfilmentService
+}
+
+// build membuat rental dari request. reserved berisi jumlah unit per mainan yang sudah
+// dipotong dari stok (misalnya hold waitlist) sehingga tidak perlu dicek ulang terhadap stok.
+func (b rentalBuilder) build(ctx context.Context, req entity.CreateRentalRequest, reserved map[uuid.UUID]int) (*entity.Rental, error) {
+	user, err := b.userRepo.FindById(ctx, req.UserID.String())
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsEmailVerified() {
+		return nil, entity.ErrEmailNotVerified
+	}
+
+	rental := &entity.Rental{
+		UserID:             req.UserID,
+		Status:             "pending",
+		RentalDate:         req.RentalDate,
+		ExpectedReturnDate: req.ExpectedReturnDate,
+		TotalRentalPrice:   0,
+		PaymentStatus:      "unpaid",
+		Notes:              req.Notes,
+		RentalItems:        make([]entity.RentalItem, 0, len(req.Items)),
+	}
+
+	toys := make(map[uuid.UUID]entity.Toy)
+	requested := make(map[uuid.UUID]int)
+
+	// Jumlah yang diminta diakumulasi per mainan agar item biasa dan komponen bundle
+	// untuk mainan yang sama tidak melebihi stok
+	reserve := func(toy entity.Toy, quantity int) error {
+		requested[toy.ID] += quantity
+		if toy.Stock+reserved[toy.ID] < requested[toy.ID] {
+			return errors.New("stok mainan tidak mencukupi: " + toy.Name)
+		}
+		return nil
+	}
+
+	var totalPrice float64 = 0
+	for _, item := range req.Items {
+		toy, ok := toys[item.ToyID]
+		if !ok {
+			found, err := b.toyRepo.FindById(ctx, item.ToyID.String())
+			if err != nil {
+				return nil, errors.New("mainan tidak ditemukan: " + item.ToyID.String())
+			}
+			toy = found
+			toys[toy.ID] = toy
+		}
+
+		if err := reserve(toy, item.Quantity); err != nil {
+			return nil, err
+		}
+
+		conditionBefore := item.ConditionBefore
+		if conditionBefore == "" {
+			conditionBefore = toy.Condition
+		}
+
+		pricePerUnit := toy.RentalPrice
+		itemTotalPrice := float64(item.Quantity) * pricePerUnit
+		totalPrice += itemTotalPrice
+
+		rentalItem := entity.RentalItem{
+			ToyID:           item.ToyID,
+			Quantity:        item.Quantity,
+			PricePerUnit:    pricePerUnit,
+			ConditionBefore: conditionBefore,
+			ConditionAfter:  conditionBefore,
+			Status:          "rented",
+		}
+
+		rental.RentalItems = append(rental.RentalItems, rentalItem)
+	}
+
+	for _, bundleReq := range req.Bundles {
+		if bundleReq.Quantity < 1 {
+			return nil, errors.New("jumlah bundle minimal 1")
+		}
+
+		bundle, err := b.bundleRepo.FindById(ctx, bundleReq.BundleID.String())
+		if err != nil {
+			return nil, errors.New("bundle tidak ditemukan: " + bundleReq.BundleID.String())
+		}
+
+		if !bundle.IsAvailable || len(bundle.Items) == 0 {
+			return nil, errors.New("bundle tidak tersedia: " + bundle.Name)
+		}
+
+		// Harga bundle dibagi ke setiap komponen secara proporsional terhadap harga rental normalnya
+		var retailPrice float64 = 0
+		for _, component := range bundle.Items {
+			retailPrice += component.Toy.RentalPrice * float64(component.Quantity)
+		}
+
+		bundleID := bundle.ID
+		for _, component := range bundle.Items {
+			toy, ok := toys[component.ToyID]
+			if !ok {
+				toy = component.Toy
+				toys[toy.ID] = toy
+			}
+
+			quantity := component.Quantity * bundleReq.Quantity
+			if err := reserve(toy, quantity); err != nil {
+				return nil, err
+			}
+
+			share := bundle.Price / float64(len(bundle.Items))
+			if retailPrice > 0 {
+				share = bundle.Price * toy.RentalPrice * float64(component.Quantity) / retailPrice
+			}
+			pricePerUnit := math.Round(share/float64(component.Quantity)*100) / 100
+
+			conditionBefore := bundleReq.ConditionBefore
+			if conditionBefore == "" {
+				conditionBefore = toy.Condition
+			}
+
+			rental.RentalItems = append(rental.RentalItems, entity.RentalItem{
+				ToyID:           component.ToyID,
+				BundleID:        &bundleID,
+				Quantity:        quantity,
+				PricePerUnit:    pricePerUnit,
+				ConditionBefore: conditionBefore,
+				ConditionAfter:  conditionBefore,
+				Status:          "rented",
+			})
+		}
+
+		totalPrice += bundle.Price * float64(bundleReq.Quantity)
+	}
+
+	if len(rental.RentalItems) == 0 {
+		return nil, errors.New("rental minimal berisi satu mainan atau bundle")
+	}
+
+	rental.TotalRentalPrice = totalPrice
+
+	if err := b.fulfilmentSvc.Plan(ctx, rental, req.Fulfilment); err != nil {
+		return nil, err
+	}
+	return rental, nil
+}
@@ -5,76 +5,77 @@ import (
 	"errors"
 	"final-project/entity"
 	"final-project/repository"
+	"final-project/utils/helpers"
 	"fmt"
 	"github.com/gofrs/uuid/v5"
+	"time"
 )
 
 type IRentalService interface {
 	IBaseService[entity.Rental]
 	CreateRental(ctx context.Context, req entity.CreateRentalRequest) (*entity.Rental, error)
 	ReturnRental(ctx context.Context, id string, req entity.ReturnRentalRequest) (*entity.Rental, error)
+	RecomputeFees(ctx context.Context, dryRun bool) ([]entity.RentalFeeAdjustment, error)
 }
 
 type RentalService struct {
 	BaseService[entity.Rental]
-	rentalRepo repository.IRentalRepository
-	userRepo   repository.IUserRepository
-	toyRepo    repository.IToyRepository
+	rentalRepo      repository.IRentalRepository
+	userRepo        repository.IUserRepository
+	toyRepo         repository.IToyRepository
+	wishlistSvc     IWishlistService
+	waitlistSvc     IWaitlistService
+	voucherSvc      IVoucherService
+	subscriptionSvc ISubscriptionService
+
+	rentalBuilder rentalBuilder
 }
 
 func NewRentalService(
 	repo repository.IRentalRepository,
 	userRepo repository.IUserRepository,
 	toyRepo repository.IToyRepository,
+	bundleRepo repository.IBundleRepository,
+	wishlistSvc IWishlistService,
+	waitlistSvc IWaitlistService,
+	voucherSvc IVoucherService,
+	subscriptionSvc ISubscriptionService,
+	fulfilmentSvc IFulfilmentService,
 ) IRentalService {
 	return &RentalService{
-		BaseService: BaseService[entity.Rental]{repository: repo},
-		rentalRepo:  repo,
-		userRepo:    userRepo,
-		toyRepo:     toyRepo,
+		BaseService:     BaseService[entity.Rental]{repository: repo},
+		rentalRepo:      repo,
+		userRepo:        userRepo,
+		toyRepo:         toyRepo,
+		wishlistSvc:     wishlistSvc,
+		waitlistSvc:     waitlistSvc,
+		voucherSvc:      voucherSvc,
+		subscriptionSvc: subscriptionSvc,
+
+		rentalBuilder: rentalBuilder{userRepo: userRepo, toyRepo: toyRepo, bundleRepo: bundleRepo, fulfilmentSvc: fulfilmentSvc},
 	}
 }
 
 func (s *RentalService) CreateRental(ctx context.Context, req entity.CreateRentalRequest) (*entity.Rental, error) {
-	rental := &entity.Rental{
-		UserID:             req.UserID,
-		Status:             "pending",
-		RentalDate:         req.RentalDate,
-		ExpectedReturnDate: req.ExpectedReturnDate,
-		TotalRentalPrice:   0,
-		PaymentStatus:      "unpaid",
-		Notes:              req.Notes,
-		RentalItems:        make([]entity.RentalItem, 0, len(req.Items)),
-	}
-
-	var totalPrice float64 = 0
-	for _, item := range req.Items {
-		toy, err := s.toyRepo.FindById(ctx, item.ToyID.String())
-		if err != nil {
-			return nil, errors.New("mainan tidak ditemukan: " + item.ToyID.String())
-		}
+	rental, err := s.rentalBuilder.build(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		if toy.Stock < item.Quantity {
-			return nil, errors.New("stok mainan tidak mencukupi: " + toy.Name)
+	if req.UseSubscription {
+		if req.VoucherCode != "" {
+			return nil, entity.ErrSubscriptionWithVoucher
 		}
 
-		pricePerUnit := toy.RentalPrice
-		itemTotalPrice := float64(item.Quantity) * pricePerUnit
-		totalPrice += itemTotalPrice
-
-		rentalItem := entity.RentalItem{
-			ToyID:           item.ToyID,
-			Quantity:        item.Quantity,
-			PricePerUnit:    pricePerUnit,
-			ConditionBefore: item.ConditionBefore,
-			ConditionAfter:  item.ConditionBefore,
-			Status:          "rented",
+		if err := s.subscriptionSvc.ApplyToRental(ctx, req.UserID, rental); err != nil {
+			return nil, err
+		}
+	} else if req.VoucherCode != "" {
+		if err := s.voucherSvc.ApplyVoucher(ctx, req.VoucherCode, req.UserID, rental); err != nil {
+			return nil, err
 		}
-
-		rental.RentalItems = append(rental.RentalItems, rentalItem)
 	}
 
-	rental.TotalRentalPrice = totalPrice
 	if err := s.repository.Insert(ctx, rental); err != nil {
 		return nil, err
 	}
@@ -89,10 +90,6 @@ func (s *RentalService) ReturnRental(ctx context.Context, id string, req entity.
 		return nil, errors.New("rental tidak ditemukan")
 	}
 
-	for _, item := range rental.RentalItems {
-		fmt.Println(item)
-	}
-
 	// Validasi status rental
 	if rental.Status == entity.RentalStatusCompleted || rental.Status == entity.RentalStatusCancelled {
 		return nil, errors.New("rental sudah selesai atau dibatalkan")
@@ -112,29 +109,13 @@ func (s *RentalService) ReturnRental(ctx context.Context, id string, req entity.
 		rentalItemMap[rental.RentalItems[i].ID] = &rental.RentalItems[i]
 	}
 
-	fmt.Println(rentalItemMap)
-
 	// Hitung late fee jika terlambat
-	var totalLateFee float64 = 0
-	//var isLate bool = false
+	totalLateFee, err := s.lateFee(ctx, rental, req.ActualReturnDate)
+	if err != nil {
+		return nil, err
+	}
 
 	if req.ActualReturnDate.After(rental.ExpectedReturnDate) {
-		days := int(req.ActualReturnDate.Sub(rental.ExpectedReturnDate).Hours()/48) + 1
-		//isLate = true
-
-		// Iterasi setiap item untuk menghitung late fee berdasarkan LateFeePerDay tiap mainan
-		for _, rentalItem := range rental.RentalItems {
-			// Ambil data mainan untuk mendapatkan LateFeePerDay
-			toy, err := s.toyRepo.FindById(ctx, rentalItem.ToyID.String())
-			if err != nil {
-				return nil, errors.New("tidak dapat mendapatkan data mainan: " + rentalItem.ToyID.String())
-			}
-
-			// Hitung late fee: LateFeePerDay * jumlah hari terlambat * quantity
-			itemLateFee := toy.LateFeePerDay * float64(days) * float64(rentalItem.Quantity)
-			totalLateFee += itemLateFee
-		}
-
 		rental.Status = "overdue"
 	} else {
 		rental.Status = "completed"
@@ -145,6 +126,7 @@ func (s *RentalService) ReturnRental(ctx context.Context, id string, req entity.
 	// Proses setiap item
 	var totalDamageFee float64 = 0
 	//allReturned := true
+	restockedToyIDs := make(map[uuid.UUID]struct{})
 
 	for _, itemReq := range req.Items {
 		rentalItem, exists := rentalItemMap[itemReq.RentalItemID]
@@ -216,6 +198,8 @@ func (s *RentalService) ReturnRental(ctx context.Context, id string, req entity.
 		// Cek apakah semua item dikembalikan
 		if rentalItem.Status != "returned" {
 			//allReturned = false
+		} else {
+			restockedToyIDs[rentalItem.ToyID] = struct{}{}
 		}
 	}
 
@@ -228,12 +212,98 @@ func (s *RentalService) ReturnRental(ctx context.Context, id string, req entity.
 	}
 
 	// Hitung total amount
-	rental.TotalAmount = rental.TotalRentalPrice + rental.LateFee + rental.DamageFee
+	rental.TotalAmount = rental.CalculateTotalAmount()
 
 	// Simpan perubahan rental
 	if err := s.rentalRepo.ReturnRental(ctx, &rental); err != nil {
 		return nil, err
 	}
 
+	// Tawarkan unit yang dikembalikan ke antrian waitlist terlebih dahulu,
+	// sisa stok kemudian dinotifikasikan ke pelanggan yang memasukkannya ke wishlist
+	for toyID := range restockedToyIDs {
+		if err := s.waitlistSvc.ProcessAvailability(ctx, toyID.String()); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to process waitlist for toy %s: %v", toyID, err))
+		}
+		if err := s.wishlistSvc.NotifyBackInStock(ctx, toyID.String()); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to send back in stock notification for toy %s: %v", toyID, err))
+		}
+	}
+
 	return &rental, nil
 }
+
+// RecomputeFees menghitung ulang late fee, damage fee, dan total tagihan rental yang sudah dikembalikan dengan
+// harga mainan saat ini dan damage fee per item. Hanya rental yang nilainya berubah dikembalikan,
+// dan perubahan tidak disimpan jika dryRun.
+func (s *RentalService) RecomputeFees(ctx context.Context, dryRun bool) ([]entity.RentalFeeAdjustment, error) {
+	rentals, err := s.rentalRepo.FindReturned(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var adjustments []entity.RentalFeeAdjustment
+	for i := range rentals {
+		rental := &rentals[i]
+
+		lateFee, err := s.lateFee(ctx, *rental, *rental.ActualReturnDate)
+		if err != nil {
+			return adjustments, err
+		}
+
+		var damageFee float64
+		for _, item := range rental.RentalItems {
+			damageFee += item.DamageFee
+		}
+
+		if lateFee == rental.LateFee && damageFee == rental.DamageFee {
+			continue
+		}
+
+		adjustment := entity.RentalFeeAdjustment{
+			RentalID:       rental.ID,
+			OldLateFee:     rental.LateFee,
+			NewLateFee:     lateFee,
+			OldDamageFee:   rental.DamageFee,
+			NewDamageFee:   damageFee,
+			OldTotalAmount: rental.CalculateTotalAmount(),
+		}
+
+		rental.LateFee = lateFee
+		rental.DamageFee = damageFee
+		rental.TotalAmount = rental.CalculateTotalAmount()
+		adjustment.NewTotalAmount = rental.TotalAmount
+		adjustments = append(adjustments, adjustment)
+
+		if dryRun {
+			continue
+		}
+
+		if err := s.rentalRepo.UpdateFees(ctx, rental); err != nil {
+			return adjustments, err
+		}
+	}
+
+	return adjustments, nil
+}
+
+// lateFee menghitung denda keterlambatan: LateFeePerDay * jumlah hari terlambat * quantity per item
+func (s *RentalService) lateFee(ctx context.Context, rental entity.Rental, returnedAt time.Time) (float64, error) {
+	if !returnedAt.After(rental.ExpectedReturnDate) {
+		return 0, nil
+	}
+
+	days := int(returnedAt.Sub(rental.ExpectedReturnDate).Hours()/48) + 1
+
+	var total float64
+	for _, rentalItem := range rental.RentalItems {
+		// Ambil data mainan untuk mendapatkan LateFeePerDay
+		toy, err := s.toyRepo.FindById(ctx, rentalItem.ToyID.String())
+		if err != nil {
+			return 0, errors.New("tidak dapat mendapatkan data mainan: " + rentalItem.ToyID.String())
+		}
+
+		total += toy.LateFeePerDay * float64(days) * float64(rentalItem.Quantity)
+	}
+	return total, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"final-project/entity"
+	"sync"
+	"time"
+)
+
+// sessionCacheSweepSize adalah jumlah entry sebelum entry kadaluarsa dibersihkan saat set
+const sessionCacheSweepSize = 10000
+
+type sessionCacheEntry struct {
+	token     entity.UserToken
+	expiresAt time.Time
+}
+
+// sessionCache menyimpan hasil validasi sesi untuk sementara agar setiap request
+// tidak perlu membaca tabel user_tokens. TTL yang pendek membatasi berapa lama
+// sesi yang dicabut dari instance lain masih bisa dipakai.
+type sessionCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]sessionCacheEntry
+}
+
+func newSessionCache(ttl time.Duration) *sessionCache {
+	return &sessionCache{
+		ttl:     ttl,
+		entries: make(map[string]sessionCacheEntry),
+	}
+}
+
+func (c *sessionCache) get(accessToken string) (entity.UserToken, bool) {
+	if c.ttl <= 0 {
+		return entity.UserToken{}, false
+	}
+
+	c.mu.RLock()
+	entry, ok := c.entries[accessToken]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return entity.UserToken{}, false
+	}
+	return entry.token, true
+}
+
+func (c *sessionCache) set(accessToken string, token entity.UserToken) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= sessionCacheSweepSize {
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	c.entries[accessToken] = sessionCacheEntry{
+		token:     token,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+func (c *sessionCache) delete(accessToken string) {
+	c.mu.Lock()
+	delete(c.entries, accessToken)
+	c.mu.Unlock()
+}
+
+// deleteWhere menghapus semua entry yang cocok, dipakai saat sesi dicabut tanpa mengetahui access token-nya
+func (c *sessionCache) deleteWhere(match func(token entity.UserToken) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if match(entry.token) {
+			delete(c.entries, key)
+		}
+	}
+}
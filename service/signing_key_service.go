@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"fmt"
+	"github.com/gofrs/uuid/v5"
+	"time"
+)
+
+// SigningKeyPolicy mengatur rotasi key JWT. Key baru dipublikasikan di JWKS selama Prepublish
+// sebelum dipakai, dan key lama tetap dipakai verifikasi selama MaxTokenLifetime setelah diganti.
+type SigningKeyPolicy struct {
+	Algorithm        string
+	RotationInterval time.Duration
+	Prepublish       time.Duration
+	MaxTokenLifetime time.Duration
+}
+
+type ISigningKeyService interface {
+	EnsureKeys(ctx context.Context) error
+	RunRotationWorker(ctx context.Context, interval time.Duration)
+}
+
+type SigningKeyService struct {
+	repo   repository.ISigningKeyRepository
+	keys   *helpers.KeySet
+	policy SigningKeyPolicy
+}
+
+func NewSigningKeyService(repo repository.ISigningKeyRepository, keys *helpers.KeySet, policy SigningKeyPolicy) ISigningKeyService {
+	return &SigningKeyService{
+		repo:   repo,
+		keys:   keys,
+		policy: policy,
+	}
+}
+
+// EnsureKeys merotasi key jika sudah waktunya lalu memuat ulang key dari database ke KeySet.
+// Dipanggil saat startup dan secara berkala agar semua instance memakai key yang sama.
+func (s *SigningKeyService) EnsureKeys(ctx context.Context) error {
+	if s.policy.Algorithm != helpers.SigningAlgHS256 {
+		rotated, err := s.repo.Rotate(ctx, s.policy.Algorithm, s.decideRotation)
+		if err != nil {
+			return fmt.Errorf("gagal merotasi signing key: %w", err)
+		}
+		if rotated {
+			helpers.Logger.Info("New JWT signing key created")
+		}
+	}
+
+	return s.load(ctx)
+}
+
+func (s *SigningKeyService) RunRotationWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.EnsureKeys(ctx); err != nil {
+				helpers.Logger.Error("Failed to refresh signing keys: ", err)
+			}
+		}
+	}
+}
+
+// decideRotation membuat key pertama yang langsung aktif, atau key pengganti yang aktif setelah
+// masa prepublish jika key terbaru sudah melewati interval rotasi
+func (s *SigningKeyService) decideRotation(latest *entity.SigningKey) (*entity.SigningKey, *time.Time) {
+	now := time.Now()
+
+	activatesAt := now
+	if latest != nil {
+		// Key pengganti sudah dibuat dan sedang menunggu aktif
+		if latest.ActivatesAt.After(now) || now.Sub(latest.ActivatesAt) < s.policy.RotationInterval {
+			return nil, nil
+		}
+		activatesAt = now.Add(s.policy.Prepublish)
+	}
+
+	kid, err := uuid.NewV7()
+	if err != nil {
+		helpers.Logger.Error("Failed to generate signing key id: ", err)
+		return nil, nil
+	}
+
+	privateKey, err := helpers.GenerateSigningKeyPEM(s.policy.Algorithm)
+	if err != nil {
+		helpers.Logger.Error("Failed to generate signing key: ", err)
+		return nil, nil
+	}
+
+	oldExpiresAt := activatesAt.Add(s.policy.MaxTokenLifetime)
+	return &entity.SigningKey{
+		Kid:         kid.String(),
+		Algorithm:   s.policy.Algorithm,
+		PrivateKey:  privateKey,
+		ActivatesAt: activatesAt,
+	}, &oldExpiresAt
+}
+
+// load mengganti isi KeySet. Key signing adalah key terbaru dengan algoritma yang dikonfigurasi
+// yang sudah aktif, key lain hanya dipakai untuk verifikasi.
+func (s *SigningKeyService) load(ctx context.Context) error {
+	now := time.Now()
+	models, err := s.repo.FindUsable(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]helpers.SigningKey, 0, len(models))
+	signingKid := ""
+	for _, model := range models {
+		key, err := helpers.ParseSigningKeyPEM(model.Kid, model.Algorithm, model.PrivateKey)
+		if err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to parse signing key %s: %v", model.Kid, err))
+			continue
+		}
+		keys = append(keys, key)
+
+		if model.Algorithm == s.policy.Algorithm && !model.ActivatesAt.After(now) {
+			signingKid = model.Kid
+		}
+	}
+
+	if signingKid == "" && s.policy.Algorithm != helpers.SigningAlgHS256 {
+		return fmt.Errorf("tidak ada signing key %s yang aktif", s.policy.Algorithm)
+	}
+
+	s.keys.Replace(signingKid, keys)
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"testing"
+	"time"
+)
+
+// fakeSigningKeyRepo menjalankan keputusan rotasi terhadap key di memori dengan aturan yang sama seperti repository
+type fakeSigningKeyRepo struct {
+	repository.ISigningKeyRepository
+	keys []entity.SigningKey
+}
+
+func (r *fakeSigningKeyRepo) FindUsable(ctx context.Context, now time.Time) ([]entity.SigningKey, error) {
+	var usable []entity.SigningKey
+	for _, key := range r.keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			usable = append(usable, key)
+		}
+	}
+	return usable, nil
+}
+
+func (r *fakeSigningKeyRepo) Rotate(ctx context.Context, algorithm string, decide func(latest *entity.SigningKey) (*entity.SigningKey, *time.Time)) (bool, error) {
+	var latest *entity.SigningKey
+	for i := range r.keys {
+		if r.keys[i].Algorithm == algorithm && (latest == nil || r.keys[i].ActivatesAt.After(latest.ActivatesAt)) {
+			latest = &r.keys[i]
+		}
+	}
+
+	newKey, oldExpiresAt := decide(latest)
+	if newKey == nil {
+		return false, nil
+	}
+
+	for i := range r.keys {
+		if r.keys[i].RetiredAt == nil {
+			r.keys[i].RetiredAt = &newKey.ActivatesAt
+			r.keys[i].ExpiresAt = oldExpiresAt
+		}
+	}
+	r.keys = append(r.keys, *newKey)
+	return true, nil
+}
+
+func TestSigningKeyService_EnsureKeys(t *testing.T) {
+	policy := SigningKeyPolicy{
+		Algorithm:        helpers.SigningAlgEdDSA,
+		RotationInterval: 30 * 24 * time.Hour,
+		Prepublish:       time.Hour,
+		MaxTokenLifetime: 7 * 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name string
+		// activeFor adalah umur key yang sudah ada, nol berarti belum ada key
+		activeFor time.Duration
+		// pending menandakan sudah ada key pengganti yang menunggu aktif
+		pending       bool
+		wantKeys      int
+		wantSigningIs string
+	}{
+		{
+			name:          "key pertama langsung aktif",
+			wantKeys:      1,
+			wantSigningIs: "new",
+		},
+		{
+			name:          "key belum waktunya dirotasi",
+			activeFor:     24 * time.Hour,
+			wantKeys:      1,
+			wantSigningIs: "existing",
+		},
+		{
+			name:          "key pengganti dipublikasikan sebelum dipakai",
+			activeFor:     31 * 24 * time.Hour,
+			wantKeys:      2,
+			wantSigningIs: "existing",
+		},
+		{
+			name:          "key pengganti yang menunggu tidak dibuat ulang",
+			activeFor:     31 * 24 * time.Hour,
+			pending:       true,
+			wantKeys:      2,
+			wantSigningIs: "existing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSigningKeyRepo{}
+			existingKid := ""
+			if tt.activeFor > 0 {
+				privateKey, err := helpers.GenerateSigningKeyPEM(policy.Algorithm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				existingKid = "key-lama"
+				repo.keys = append(repo.keys, entity.SigningKey{Kid: existingKid, Algorithm: policy.Algorithm, PrivateKey: privateKey, ActivatesAt: time.Now().Add(-tt.activeFor)})
+			}
+			if tt.pending {
+				privateKey, err := helpers.GenerateSigningKeyPEM(policy.Algorithm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				repo.keys = append(repo.keys, entity.SigningKey{Kid: "key-menunggu", Algorithm: policy.Algorithm, PrivateKey: privateKey, ActivatesAt: time.Now().Add(time.Minute)})
+			}
+
+			keys := helpers.NewKeySet()
+			svc := NewSigningKeyService(repo, keys, policy)
+			if err := svc.EnsureKeys(context.Background()); err != nil {
+				t.Fatalf("EnsureKeys() error = %v", err)
+			}
+
+			jwks := keys.JWKS()
+			if len(jwks.Keys) != tt.wantKeys {
+				t.Fatalf("published %d keys, want %d", len(jwks.Keys), tt.wantKeys)
+			}
+
+			signing, err := keys.Signing()
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch tt.wantSigningIs {
+			case "existing":
+				if signing.Kid != existingKid {
+					t.Errorf("signing kid = %q, want the active key %q", signing.Kid, existingKid)
+				}
+			case "new":
+				if signing.Kid != repo.keys[len(repo.keys)-1].Kid {
+					t.Errorf("signing kid = %q, want the newly created key", signing.Kid)
+				}
+			}
+
+			// Key yang digantikan tetap dipakai verifikasi sampai token terlamanya kadaluarsa
+			if tt.wantKeys == 2 && !tt.pending {
+				old := repo.keys[0]
+				replacement := repo.keys[1]
+				if old.RetiredAt == nil || !old.RetiredAt.Equal(replacement.ActivatesAt) {
+					t.Errorf("old key retired at %v, want %v", old.RetiredAt, replacement.ActivatesAt)
+				}
+				if wantExpiry := replacement.ActivatesAt.Add(policy.MaxTokenLifetime); old.ExpiresAt == nil || !old.ExpiresAt.Equal(wantExpiry) {
+					t.Errorf("old key expires at %v, want %v", old.ExpiresAt, wantExpiry)
+				}
+				if replacement.ActivatesAt.Before(time.Now().Add(policy.Prepublish - time.Minute)) {
+					t.Errorf("replacement activates at %v, before the prepublish window ends", replacement.ActivatesAt)
+				}
+			}
+		})
+	}
+}
+
+func TestSigningKeyService_EnsureKeys_DropsExpiredKeys(t *testing.T) {
+	policy := SigningKeyPolicy{Algorithm: helpers.SigningAlgEdDSA, RotationInterval: 30 * 24 * time.Hour, Prepublish: time.Hour, MaxTokenLifetime: time.Hour}
+
+	var keys []entity.SigningKey
+	for _, kid := range []string{"key-kadaluarsa", "key-aktif"} {
+		privateKey, err := helpers.GenerateSigningKeyPEM(policy.Algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, entity.SigningKey{Kid: kid, Algorithm: policy.Algorithm, PrivateKey: privateKey, ActivatesAt: time.Now().Add(-48 * time.Hour)})
+	}
+	expiredAt := time.Now().Add(-time.Minute)
+	keys[0].RetiredAt = &expiredAt
+	keys[0].ExpiresAt = &expiredAt
+	keys[1].ActivatesAt = time.Now().Add(-24 * time.Hour)
+
+	keySet := helpers.NewKeySet()
+	if err := NewSigningKeyService(&fakeSigningKeyRepo{keys: keys}, keySet, policy).EnsureKeys(context.Background()); err != nil {
+		t.Fatalf("EnsureKeys() error = %v", err)
+	}
+
+	if _, ok := keySet.Lookup("key-kadaluarsa"); ok {
+		t.Error("expired key is still accepted for verification")
+	}
+	if signing, err := keySet.Signing(); err != nil || signing.Kid != "key-aktif" {
+		t.Errorf("signing key = %q (%v), want key-aktif", signing.Kid, err)
+	}
+}
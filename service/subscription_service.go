@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type ISubscriptionService interface {
+	IBaseService[entity.Subscription]
+	Subscribe(ctx context.Context, userID uuid.UUID, req entity.SubscribeRequest) (*entity.Subscription, error)
+	GetUsage(ctx context.Context, userID string) (*entity.SubscriptionUsage, error)
+	Cancel(ctx context.Context, userID string) (*entity.Subscription, error)
+	ApplyToRental(ctx context.Context, userID uuid.UUID, rental *entity.Rental) error
+	UpdatePaymentStatus(ctx context.Context, paymentID string, req entity.UpdatePaymentStatusRequest) (*entity.Payment, error)
+	RenewDue(ctx context.Context) error
+	RunRenewalWorker(ctx context.Context, interval time.Duration)
+}
+
+type SubscriptionService struct {
+	BaseService[entity.Subscription]
+	subscriptionRepo repository.ISubscriptionRepository
+	planRepo         repository.IPlanRepository
+	paymentRepo      repository.IPaymentRepository
+	toyRepo          repository.IToyRepository
+}
+
+func NewSubscriptionService(
+	repo repository.ISubscriptionRepository,
+	planRepo repository.IPlanRepository,
+	paymentRepo repository.IPaymentRepository,
+	toyRepo repository.IToyRepository,
+) ISubscriptionService {
+	return &SubscriptionService{
+		BaseService:      BaseService[entity.Subscription]{repository: repo},
+		subscriptionRepo: repo,
+		planRepo:         planRepo,
+		paymentRepo:      paymentRepo,
+		toyRepo:          toyRepo,
+	}
+}
+
+// Subscribe membuat langganan beserta tagihan pertamanya. Langganan baru aktif setelah tagihan
+// pertama lunas, periodenya dimulai saat aktivasi.
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID uuid.UUID, req entity.SubscribeRequest) (*entity.Subscription, error) {
+	plan, err := s.planRepo.FindById(ctx, req.PlanID.String())
+	if err != nil || !plan.IsActive {
+		return nil, entity.ErrPlanNotAvailable
+	}
+
+	if _, err := s.subscriptionRepo.FindCurrentByUserID(ctx, userID.String()); err == nil {
+		return nil, entity.ErrSubscriptionAlreadyActive
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	now := time.Now()
+	subscription := &entity.Subscription{
+		UserID:             userID,
+		PlanID:             plan.ID,
+		Status:             entity.SubscriptionStatusPending,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.AddDate(0, 1, 0),
+	}
+
+	if err := s.subscriptionRepo.Subscribe(ctx, subscription, newSubscriptionPayment(plan)); err != nil {
+		return nil, err
+	}
+
+	subscription.Plan = plan
+	return subscription, nil
+}
+
+func (s *SubscriptionService) GetUsage(ctx context.Context, userID string) (*entity.SubscriptionUsage, error) {
+	subscription, err := s.subscriptionRepo.FindCurrentByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNoActiveSubscription
+		}
+		return nil, err
+	}
+
+	activeToys, err := s.subscriptionRepo.CountActiveToys(ctx, subscription.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	swapsUsed, err := s.subscriptionRepo.CountRentalsSince(ctx, subscription.ID.String(), subscription.CurrentPeriodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &entity.SubscriptionUsage{
+		Subscription:      subscription,
+		ActiveToys:        activeToys,
+		MaxConcurrentToys: subscription.Plan.MaxConcurrentToys,
+		SwapsUsed:         swapsUsed,
+		SwapsPerMonth:     subscription.Plan.SwapsPerMonth,
+		SwapsRemaining:    max(subscription.Plan.SwapsPerMonth-swapsUsed, 0),
+		MaxToyValue:       subscription.Plan.MaxToyValue,
+	}
+
+	if payment, err := s.paymentRepo.FindLatestBySubscriptionID(ctx, subscription.ID.String()); err == nil {
+		usage.LatestPayment = &payment
+	}
+
+	return usage, nil
+}
+
+// Cancel menghentikan perpanjangan langganan di akhir periode berjalan. Langganan yang belum
+// dibayar langsung dibatalkan.
+func (s *SubscriptionService) Cancel(ctx context.Context, userID string) (*entity.Subscription, error) {
+	subscription, err := s.subscriptionRepo.FindCurrentByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNoActiveSubscription
+		}
+		return nil, err
+	}
+
+	if subscription.Status == entity.SubscriptionStatusPending {
+		if err := s.subscriptionRepo.UpdateStatus(ctx, subscription.ID.String(), entity.SubscriptionStatusCancelled); err != nil {
+			return nil, err
+		}
+		subscription.Status = entity.SubscriptionStatusCancelled
+		return &subscription, nil
+	}
+
+	if err := s.subscriptionRepo.UpdateCancelAtPeriodEnd(ctx, subscription.ID.String(), true); err != nil {
+		return nil, err
+	}
+
+	subscription.CancelAtPeriodEnd = true
+	return &subscription, nil
+}
+
+// ApplyToRental memeriksa batas paket langganan lalu menandai rental sebagai rental langganan tanpa
+// biaya sewa. Biaya pengiriman tetap ditagihkan sehingga rental baru dianggap lunas jika tidak ada
+// biaya pengiriman. Batas paket diperiksa ulang oleh repository di dalam transaksi saat rental disimpan.
+func (s *SubscriptionService) ApplyToRental(ctx context.Context, userID uuid.UUID, rental *entity.Rental) error {
+	subscription, err := s.subscriptionRepo.FindCurrentByUserID(ctx, userID.String())
+	if err != nil || subscription.Status != entity.SubscriptionStatusActive {
+		return entity.ErrNoActiveSubscription
+	}
+	plan := subscription.Plan
+
+	toyIDs := make([]string, 0, len(rental.RentalItems))
+	requestedToys := 0
+	for _, item := range rental.RentalItems {
+		toyIDs = append(toyIDs, item.ToyID.String())
+		requestedToys += item.Quantity
+	}
+
+	toys, err := s.toyRepo.FindByIdsWithCategories(ctx, toyIDs)
+	if err != nil {
+		return err
+	}
+	for _, toy := range toys {
+		if !plan.AllowsToy(toy) {
+			return fmt.Errorf("%w: %s", entity.ErrPlanToyValueExceeded, toy.Name)
+		}
+	}
+
+	activeToys, err := s.subscriptionRepo.CountActiveToys(ctx, subscription.ID.String())
+	if err != nil {
+		return err
+	}
+
+	swapsUsed, err := s.subscriptionRepo.CountRentalsSince(ctx, subscription.ID.String(), subscription.CurrentPeriodStart)
+	if err != nil {
+		return err
+	}
+
+	if err := plan.CheckLimits(activeToys, requestedToys, swapsUsed); err != nil {
+		return err
+	}
+
+	subscriptionID := subscription.ID
+	rental.SubscriptionID = &subscriptionID
+	for i := range rental.RentalItems {
+		rental.RentalItems[i].PricePerUnit = 0
+	}
+	rental.TotalRentalPrice = 0
+	rental.TotalAmount = rental.TotalRentalPrice - rental.DiscountAmount + rental.DeliveryFee
+	if rental.TotalAmount == 0 {
+		rental.PaymentStatus = entity.PaymentStatusPaid
+	}
+
+	return nil
+}
+
+// UpdatePaymentStatus mencatat status transaksi pembayaran. Langganan baru diaktifkan ketika
+// tagihan pertamanya lunas dan dibatalkan jika tagihan tersebut gagal, langganan yang menunggak
+// diaktifkan kembali ketika tagihannya lunas.
+func (s *SubscriptionService) UpdatePaymentStatus(ctx context.Context, paymentID string, req entity.UpdatePaymentStatusRequest) (*entity.Payment, error) {
+	payment, err := s.paymentRepo.FindById(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payment.TransactionStatus = req.TransactionStatus
+	payment.TransactionTime = &now
+	if req.PaymentMethod != "" {
+		payment.PaymentMethod = req.PaymentMethod
+	}
+	if req.TransactionID != "" {
+		payment.TransactionID = &req.TransactionID
+	}
+
+	if err := s.paymentRepo.UpdateStatus(ctx, &payment); err != nil {
+		return nil, err
+	}
+
+	if payment.SubscriptionID == nil {
+		return &payment, nil
+	}
+
+	subscription, err := s.subscriptionRepo.FindById(ctx, payment.SubscriptionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case subscription.Status == entity.SubscriptionStatusPending && payment.IsSettled():
+		subscription.CurrentPeriodStart = now
+		subscription.CurrentPeriodEnd = now.AddDate(0, 1, 0)
+		if err := s.subscriptionRepo.Activate(ctx, &subscription); err != nil {
+			return nil, err
+		}
+	case subscription.Status == entity.SubscriptionStatusPending && payment.IsFailed():
+		if err := s.subscriptionRepo.UpdateStatus(ctx, subscription.ID.String(), entity.SubscriptionStatusCancelled); err != nil {
+			return nil, err
+		}
+	case subscription.Status == entity.SubscriptionStatusPastDue && payment.IsSettled():
+		if err := s.subscriptionRepo.UpdateStatus(ctx, subscription.ID.String(), entity.SubscriptionStatusActive); err != nil {
+			return nil, err
+		}
+	}
+
+	return &payment, nil
+}
+
+// RenewDue memperpanjang langganan yang periodenya sudah berakhir dan membuat tagihan baru.
+// Langganan dibatalkan jika pembayaran pertamanya tidak pernah lunas, atau jika masih menunggak
+// saat periode berikutnya berakhir.
+func (s *SubscriptionService) RenewDue(ctx context.Context) error {
+	subscriptions, err := s.subscriptionRepo.FindDueForRenewal(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+
+		lastPayment, err := s.paymentRepo.FindLatestBySubscriptionID(ctx, subscription.ID.String())
+		unpaid := err == nil && !lastPayment.IsSettled()
+
+		if subscription.CancelAtPeriodEnd ||
+			subscription.Status == entity.SubscriptionStatusPending ||
+			(subscription.Status == entity.SubscriptionStatusPastDue && unpaid) {
+			if err := s.subscriptionRepo.UpdateStatus(ctx, subscription.ID.String(), entity.SubscriptionStatusCancelled); err != nil {
+				helpers.Logger.Error(fmt.Errorf("failed to cancel subscription %s: %v", subscription.ID, err))
+			}
+			continue
+		}
+
+		// Tagihan periode sebelumnya yang belum lunas membuat langganan menunggak
+		subscription.Status = entity.SubscriptionStatusActive
+		if unpaid {
+			subscription.Status = entity.SubscriptionStatusPastDue
+		}
+
+		subscription.CurrentPeriodStart = subscription.CurrentPeriodEnd
+		subscription.CurrentPeriodEnd = subscription.CurrentPeriodEnd.AddDate(0, 1, 0)
+
+		if err := s.subscriptionRepo.Renew(ctx, subscription, newSubscriptionPayment(subscription.Plan)); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to renew subscription %s: %v", subscription.ID, err))
+		}
+	}
+
+	return nil
+}
+
+// RunRenewalWorker menjalankan RenewDue secara berkala sampai context dibatalkan
+func (s *SubscriptionService) RunRenewalWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RenewDue(ctx); err != nil {
+				helpers.Logger.Error("Failed to renew subscriptions: ", err)
+			}
+		}
+	}
+}
+
+func newSubscriptionPayment(plan entity.Plan) *entity.Payment {
+	return &entity.Payment{
+		PaymentType:       entity.PaymentTypeSubscription,
+		GrossAmount:       plan.MonthlyFee,
+		TransactionStatus: entity.TransactionStatusPending,
+	}
+}
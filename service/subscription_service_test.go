@@ -0,0 +1,457 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeSubscriptionRepo struct {
+	repository.ISubscriptionRepository
+	subscriptions map[string]entity.Subscription
+	activeToys    int
+	swapsUsed     int
+	created       *entity.Subscription
+	renewed       *entity.Subscription
+}
+
+func (r *fakeSubscriptionRepo) FindById(ctx context.Context, id string) (entity.Subscription, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return subscription, gorm.ErrRecordNotFound
+	}
+	return subscription, nil
+}
+
+func (r *fakeSubscriptionRepo) FindCurrentByUserID(ctx context.Context, userID string) (entity.Subscription, error) {
+	for _, subscription := range r.subscriptions {
+		if subscription.UserID.String() == userID && subscription.Status != entity.SubscriptionStatusCancelled {
+			return subscription, nil
+		}
+	}
+	return entity.Subscription{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeSubscriptionRepo) FindDueForRenewal(ctx context.Context, now time.Time) ([]entity.Subscription, error) {
+	var subscriptions []entity.Subscription
+	for _, subscription := range r.subscriptions {
+		if subscription.Status != entity.SubscriptionStatusCancelled && !subscription.CurrentPeriodEnd.After(now) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *fakeSubscriptionRepo) CountActiveToys(ctx context.Context, subscriptionID string) (int, error) {
+	return r.activeToys, nil
+}
+
+func (r *fakeSubscriptionRepo) CountRentalsSince(ctx context.Context, subscriptionID string, since time.Time) (int, error) {
+	return r.swapsUsed, nil
+}
+
+func (r *fakeSubscriptionRepo) Subscribe(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error {
+	r.created = subscription
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) Renew(ctx context.Context, subscription *entity.Subscription, payment *entity.Payment) error {
+	r.renewed = subscription
+	r.subscriptions[subscription.ID.String()] = *subscription
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) Activate(ctx context.Context, subscription *entity.Subscription) error {
+	subscription.Status = entity.SubscriptionStatusActive
+	r.subscriptions[subscription.ID.String()] = *subscription
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) UpdateStatus(ctx context.Context, id string, status string) error {
+	subscription := r.subscriptions[id]
+	subscription.Status = status
+	r.subscriptions[id] = subscription
+	return nil
+}
+
+type fakePaymentRepo struct {
+	repository.IPaymentRepository
+	payments map[string]entity.Payment
+}
+
+func (r *fakePaymentRepo) FindById(ctx context.Context, id string) (entity.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return payment, gorm.ErrRecordNotFound
+	}
+	return payment, nil
+}
+
+func (r *fakePaymentRepo) UpdateStatus(ctx context.Context, payment *entity.Payment) error {
+	r.payments[payment.ID.String()] = *payment
+	return nil
+}
+
+func (r *fakePaymentRepo) FindLatestBySubscriptionID(ctx context.Context, subscriptionID string) (entity.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.SubscriptionID != nil && payment.SubscriptionID.String() == subscriptionID {
+			return payment, nil
+		}
+	}
+	return entity.Payment{}, gorm.ErrRecordNotFound
+}
+
+type fakePlanRepo struct {
+	repository.IPlanRepository
+	plan entity.Plan
+}
+
+func (r *fakePlanRepo) FindById(ctx context.Context, id string) (entity.Plan, error) {
+	return r.plan, nil
+}
+
+func newTestPlan() entity.Plan {
+	return entity.Plan{
+		BaseEntity:        entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+		Name:              "Keluarga",
+		MonthlyFee:        150000,
+		MaxConcurrentToys: 3,
+		SwapsPerMonth:     2,
+		MaxToyValue:       500000,
+		IsActive:          true,
+	}
+}
+
+func newTestSubscription(status string, periodEnd time.Time) entity.Subscription {
+	plan := newTestPlan()
+	return entity.Subscription{
+		BaseEntity:         entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+		UserID:             uuid.Must(uuid.NewV7()),
+		PlanID:             plan.ID,
+		Plan:               plan,
+		Status:             status,
+		CurrentPeriodStart: periodEnd.AddDate(0, -1, 0),
+		CurrentPeriodEnd:   periodEnd,
+	}
+}
+
+func TestSubscriptionService_Subscribe(t *testing.T) {
+	plan := newTestPlan()
+
+	tests := []struct {
+		name     string
+		existing []entity.Subscription
+		wantErr  error
+	}{
+		{
+			name: "langganan baru menunggu pembayaran pertama",
+		},
+		{
+			name:     "masih memiliki langganan yang belum dibayar",
+			existing: []entity.Subscription{newTestSubscription(entity.SubscriptionStatusPending, time.Now().AddDate(0, 1, 0))},
+			wantErr:  entity.ErrSubscriptionAlreadyActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.Must(uuid.NewV7())
+			subscriptionRepo := &fakeSubscriptionRepo{subscriptions: map[string]entity.Subscription{}}
+			for _, subscription := range tt.existing {
+				subscription.UserID = userID
+				subscriptionRepo.subscriptions[subscription.ID.String()] = subscription
+			}
+			svc := NewSubscriptionService(subscriptionRepo, &fakePlanRepo{plan: plan}, &fakePaymentRepo{}, nil)
+
+			subscription, err := svc.Subscribe(context.Background(), userID, entity.SubscribeRequest{PlanID: plan.ID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Subscribe() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if subscription.Status != entity.SubscriptionStatusPending {
+				t.Errorf("status = %s, want %s", subscription.Status, entity.SubscriptionStatusPending)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_UpdatePaymentStatus(t *testing.T) {
+	periodEnd := time.Now().AddDate(0, 0, 10)
+
+	tests := []struct {
+		name              string
+		status            string
+		transactionStatus string
+		wantStatus        string
+		wantNewPeriod     bool
+	}{
+		{
+			name:              "pembayaran pertama lunas mengaktifkan langganan",
+			status:            entity.SubscriptionStatusPending,
+			transactionStatus: entity.TransactionStatusSettlement,
+			wantStatus:        entity.SubscriptionStatusActive,
+			wantNewPeriod:     true,
+		},
+		{
+			name:              "pembayaran pertama kadaluarsa membatalkan langganan",
+			status:            entity.SubscriptionStatusPending,
+			transactionStatus: entity.TransactionStatusExpire,
+			wantStatus:        entity.SubscriptionStatusCancelled,
+		},
+		{
+			name:              "pembayaran pertama masih pending",
+			status:            entity.SubscriptionStatusPending,
+			transactionStatus: entity.TransactionStatusPending,
+			wantStatus:        entity.SubscriptionStatusPending,
+		},
+		{
+			name:              "tunggakan lunas mengaktifkan kembali langganan",
+			status:            entity.SubscriptionStatusPastDue,
+			transactionStatus: entity.TransactionStatusCapture,
+			wantStatus:        entity.SubscriptionStatusActive,
+		},
+		{
+			name:              "pembayaran gagal tidak membatalkan langganan yang sudah aktif",
+			status:            entity.SubscriptionStatusActive,
+			transactionStatus: entity.TransactionStatusDeny,
+			wantStatus:        entity.SubscriptionStatusActive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := newTestSubscription(tt.status, periodEnd)
+			subscriptionID := subscription.ID
+			payment := entity.Payment{
+				BaseEntity:        entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+				SubscriptionID:    &subscriptionID,
+				PaymentType:       entity.PaymentTypeSubscription,
+				TransactionStatus: entity.TransactionStatusPending,
+			}
+
+			subscriptionRepo := &fakeSubscriptionRepo{subscriptions: map[string]entity.Subscription{subscriptionID.String(): subscription}}
+			paymentRepo := &fakePaymentRepo{payments: map[string]entity.Payment{payment.ID.String(): payment}}
+			svc := NewSubscriptionService(subscriptionRepo, nil, paymentRepo, nil)
+
+			if _, err := svc.UpdatePaymentStatus(context.Background(), payment.ID.String(),
+				entity.UpdatePaymentStatusRequest{TransactionStatus: tt.transactionStatus}); err != nil {
+				t.Fatalf("UpdatePaymentStatus() error = %v", err)
+			}
+
+			got := subscriptionRepo.subscriptions[subscriptionID.String()]
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if newPeriod := !got.CurrentPeriodEnd.Equal(periodEnd); newPeriod != tt.wantNewPeriod {
+				t.Errorf("period restarted = %v, want %v", newPeriod, tt.wantNewPeriod)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_RenewDue(t *testing.T) {
+	periodEnd := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name              string
+		status            string
+		cancelAtPeriodEnd bool
+		lastPayment       string
+		wantStatus        string
+		wantRenewed       bool
+	}{
+		{
+			name:        "tagihan lunas diperpanjang",
+			status:      entity.SubscriptionStatusActive,
+			lastPayment: entity.TransactionStatusSettlement,
+			wantStatus:  entity.SubscriptionStatusActive,
+			wantRenewed: true,
+		},
+		{
+			name:        "tagihan belum lunas menjadi menunggak",
+			status:      entity.SubscriptionStatusActive,
+			lastPayment: entity.TransactionStatusPending,
+			wantStatus:  entity.SubscriptionStatusPastDue,
+			wantRenewed: true,
+		},
+		{
+			name:        "masih menunggak di akhir periode berikutnya dibatalkan",
+			status:      entity.SubscriptionStatusPastDue,
+			lastPayment: entity.TransactionStatusPending,
+			wantStatus:  entity.SubscriptionStatusCancelled,
+		},
+		{
+			name:        "menunggak tetapi tagihan terakhir sudah lunas diperpanjang",
+			status:      entity.SubscriptionStatusPastDue,
+			lastPayment: entity.TransactionStatusSettlement,
+			wantStatus:  entity.SubscriptionStatusActive,
+			wantRenewed: true,
+		},
+		{
+			name:        "pembayaran pertama tidak pernah lunas dibatalkan",
+			status:      entity.SubscriptionStatusPending,
+			lastPayment: entity.TransactionStatusPending,
+			wantStatus:  entity.SubscriptionStatusCancelled,
+		},
+		{
+			name:              "dibatalkan di akhir periode",
+			status:            entity.SubscriptionStatusActive,
+			cancelAtPeriodEnd: true,
+			lastPayment:       entity.TransactionStatusSettlement,
+			wantStatus:        entity.SubscriptionStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := newTestSubscription(tt.status, periodEnd)
+			subscription.CancelAtPeriodEnd = tt.cancelAtPeriodEnd
+			subscriptionID := subscription.ID
+			payment := entity.Payment{
+				BaseEntity:        entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+				SubscriptionID:    &subscriptionID,
+				TransactionStatus: tt.lastPayment,
+			}
+
+			subscriptionRepo := &fakeSubscriptionRepo{subscriptions: map[string]entity.Subscription{subscriptionID.String(): subscription}}
+			paymentRepo := &fakePaymentRepo{payments: map[string]entity.Payment{payment.ID.String(): payment}}
+			svc := NewSubscriptionService(subscriptionRepo, nil, paymentRepo, nil)
+
+			if err := svc.RenewDue(context.Background()); err != nil {
+				t.Fatalf("RenewDue() error = %v", err)
+			}
+
+			got := subscriptionRepo.subscriptions[subscriptionID.String()]
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			if renewed := subscriptionRepo.renewed != nil; renewed != tt.wantRenewed {
+				t.Errorf("renewed = %v, want %v", renewed, tt.wantRenewed)
+			}
+			if tt.wantRenewed && !got.CurrentPeriodStart.Equal(periodEnd) {
+				t.Errorf("new period starts at %s, want %s", got.CurrentPeriodStart, periodEnd)
+			}
+		})
+	}
+}
+
+func TestSubscriptionService_ApplyToRental(t *testing.T) {
+	toy := entity.Toy{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Name: "Balok", ReplacementPrice: 200000}
+	expensiveToy := entity.Toy{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Name: "Robot", ReplacementPrice: 900000}
+
+	tests := []struct {
+		name              string
+		status            string
+		toy               entity.Toy
+		quantity          int
+		deliveryFee       float64
+		activeToys        int
+		swapsUsed         int
+		wantErr           error
+		wantPaymentStatus string
+	}{
+		{
+			name:              "rental langganan tanpa biaya",
+			status:            entity.SubscriptionStatusActive,
+			toy:               toy,
+			quantity:          2,
+			wantPaymentStatus: entity.PaymentStatusPaid,
+		},
+		{
+			name:              "biaya pengiriman tetap ditagihkan",
+			status:            entity.SubscriptionStatusActive,
+			toy:               toy,
+			quantity:          1,
+			deliveryFee:       15000,
+			wantPaymentStatus: entity.PaymentStatusUnpaid,
+		},
+		{
+			name:     "langganan belum dibayar",
+			status:   entity.SubscriptionStatusPending,
+			toy:      toy,
+			quantity: 1,
+			wantErr:  entity.ErrNoActiveSubscription,
+		},
+		{
+			name:     "langganan menunggak",
+			status:   entity.SubscriptionStatusPastDue,
+			toy:      toy,
+			quantity: 1,
+			wantErr:  entity.ErrNoActiveSubscription,
+		},
+		{
+			name:       "melebihi batas mainan bersamaan",
+			status:     entity.SubscriptionStatusActive,
+			toy:        toy,
+			quantity:   2,
+			activeToys: 2,
+			wantErr:    entity.ErrPlanConcurrentLimitReached,
+		},
+		{
+			name:      "jatah tukar habis",
+			status:    entity.SubscriptionStatusActive,
+			toy:       toy,
+			quantity:  1,
+			swapsUsed: 2,
+			wantErr:   entity.ErrPlanSwapLimitReached,
+		},
+		{
+			name:     "nilai mainan melebihi tier paket",
+			status:   entity.SubscriptionStatusActive,
+			toy:      expensiveToy,
+			quantity: 1,
+			wantErr:  entity.ErrPlanToyValueExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := newTestSubscription(tt.status, time.Now().AddDate(0, 0, 10))
+			subscriptionRepo := &fakeSubscriptionRepo{
+				subscriptions: map[string]entity.Subscription{subscription.ID.String(): subscription},
+				activeToys:    tt.activeToys,
+				swapsUsed:     tt.swapsUsed,
+			}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{tt.toy.ID.String(): tt.toy}}
+			svc := NewSubscriptionService(subscriptionRepo, nil, &fakePaymentRepo{}, toyRepo)
+
+			rental := &entity.Rental{
+				TotalRentalPrice: 50000,
+				DeliveryFee:      tt.deliveryFee,
+				PaymentStatus:    entity.PaymentStatusUnpaid,
+				RentalItems:      []entity.RentalItem{{ToyID: tt.toy.ID, Quantity: tt.quantity, PricePerUnit: 25000}},
+			}
+			err := svc.ApplyToRental(context.Background(), subscription.UserID, rental)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyToRental() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if rental.SubscriptionID != nil {
+					t.Error("subscription applied although a plan limit was exceeded")
+				}
+				return
+			}
+
+			if rental.SubscriptionID == nil || *rental.SubscriptionID != subscription.ID {
+				t.Errorf("subscription id = %v, want %s", rental.SubscriptionID, subscription.ID)
+			}
+			if rental.TotalRentalPrice != 0 || rental.RentalItems[0].PricePerUnit != 0 {
+				t.Errorf("rental price = %v, item price = %v, want 0", rental.TotalRentalPrice, rental.RentalItems[0].PricePerUnit)
+			}
+			if rental.TotalAmount != tt.deliveryFee {
+				t.Errorf("total amount = %v, want %v", rental.TotalAmount, tt.deliveryFee)
+			}
+			if rental.PaymentStatus != tt.wantPaymentStatus {
+				t.Errorf("payment status = %s, want %s", rental.PaymentStatus, tt.wantPaymentStatus)
+			}
+		})
+	}
+}
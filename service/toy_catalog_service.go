@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/spreadsheet"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// toyExportBatchSize adalah jumlah mainan yang dibaca dari database setiap kali sebelum ditulis ke file export
+const toyExportBatchSize = 200
+
+type IToyCatalogService interface {
+	Parse(filename string, file io.ReaderAt, size int64) ([]entity.ToyImportRow, error)
+	Import(ctx context.Context, rows []entity.ToyImportRow, req entity.ToyImportRequest) (entity.ToyImportResult, error)
+	Export(ctx context.Context, format string, w io.Writer) error
+}
+
+type ToyCatalogService struct {
+	toyRepo         repository.IToyRepository
+	toyCategoryRepo repository.IToyCategoryRepository
+}
+
+func NewToyCatalogService(toyRepo repository.IToyRepository, toyCategoryRepo repository.IToyCategoryRepository) IToyCatalogService {
+	return &ToyCatalogService{
+		toyRepo:         toyRepo,
+		toyCategoryRepo: toyCategoryRepo,
+	}
+}
+
+// Parse membaca file import sesuai ekstensinya, .csv atau .xlsx
+func (s *ToyCatalogService) Parse(filename string, file io.ReaderAt, size int64) ([]entity.ToyImportRow, error) {
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")) {
+	case entity.ToyCatalogFormatCSV:
+		return ParseToyCSV(io.NewSectionReader(file, 0, size))
+	case entity.ToyCatalogFormatXLSX:
+		records, err := spreadsheet.ReadXLSX(file, size)
+		if err != nil {
+			return nil, err
+		}
+		return parseToyRecords(records)
+	default:
+		return nil, entity.ErrToyCatalogFormat
+	}
+}
+
+// Import memvalidasi setiap baris dengan Toy.Validate dan mencocokkan kategori berdasarkan nama.
+// Dry run hanya melaporkan hasil validasi. Mode atomic menyimpan semua baris dalam satu transaksi
+// dan tidak menyimpan apa pun jika ada baris yang tidak valid, mode row menyimpan baris yang valid
+// satu per satu.
+func (s *ToyCatalogService) Import(ctx context.Context, rows []entity.ToyImportRow, req entity.ToyImportRequest) (entity.ToyImportResult, error) {
+	if len(rows) == 0 {
+		return entity.ToyImportResult{}, entity.ErrToyImportEmpty
+	}
+
+	categories, err := s.resolveCategories(ctx, rows)
+	if err != nil {
+		return entity.ToyImportResult{}, err
+	}
+
+	result := entity.ToyImportResult{DryRun: req.DryRun, Mode: req.Mode, Total: len(rows)}
+	toys := make([]entity.Toy, len(rows))
+	for i, row := range rows {
+		toy := row.Toy
+		rowResult := entity.ToyImportRowResult{Row: row.Row, Name: toy.Name, Errors: row.Errors}
+
+		toy.Categories = nil
+		for _, name := range row.Categories {
+			category, ok := categories[strings.ToLower(name)]
+			if !ok {
+				rowResult.Errors = append(rowResult.Errors, fmt.Sprintf("Kategori %q tidak ditemukan", name))
+				continue
+			}
+			toy.Categories = append(toy.Categories, category)
+		}
+		rowResult.Errors = append(rowResult.Errors, toy.Validate()...)
+
+		if len(rowResult.Errors) == 0 {
+			result.Valid++
+			if !req.DryRun && req.Mode == entity.ToyImportModeRow {
+				if err := s.toyRepo.Insert(ctx, &toy); err != nil {
+					rowResult.Errors = append(rowResult.Errors, err.Error())
+				} else {
+					rowResult.ToyID = &toy.ID
+					result.Imported++
+				}
+			}
+		}
+
+		if len(rowResult.Errors) > 0 {
+			result.Failed++
+		}
+		toys[i] = toy
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	if req.DryRun || req.Mode != entity.ToyImportModeAtomic || result.Failed > 0 {
+		return result, nil
+	}
+
+	if err := s.toyRepo.InsertMany(ctx, toys); err != nil {
+		return entity.ToyImportResult{}, err
+	}
+	for i := range toys {
+		result.Rows[i].ToyID = &toys[i].ID
+	}
+	result.Imported = len(toys)
+	return result, nil
+}
+
+// Export menulis seluruh katalog dengan kolom entity.ToyExportColumns. Data ditulis per batch
+// sehingga bisa dialirkan langsung ke response.
+func (s *ToyCatalogService) Export(ctx context.Context, format string, w io.Writer) error {
+	var writer toyCatalogWriter
+	switch format {
+	case entity.ToyCatalogFormatCSV:
+		writer = &csvCatalogWriter{w: csv.NewWriter(w)}
+	case entity.ToyCatalogFormatXLSX:
+		xlsx, err := spreadsheet.NewXLSXWriter(w, "Katalog")
+		if err != nil {
+			return err
+		}
+		writer = xlsx
+	default:
+		return entity.ErrToyCatalogFormat
+	}
+
+	header := make([]interface{}, len(entity.ToyExportColumns))
+	for i, column := range entity.ToyExportColumns {
+		header[i] = column
+	}
+	if err := writer.WriteRow(header); err != nil {
+		return err
+	}
+
+	err := s.toyRepo.FindInBatches(ctx, toyExportBatchSize, func(toys []entity.Toy) error {
+		for _, toy := range toys {
+			categories := make([]string, len(toy.Categories))
+			for i, category := range toy.Categories {
+				categories[i] = category.Name
+			}
+			images := make([]string, len(toy.Images))
+			for i, image := range toy.Images {
+				images[i] = image.ImageURL
+			}
+
+			if err := writer.WriteRow([]interface{}{
+				toy.ID.String(),
+				toy.Name,
+				toy.Description,
+				toy.AgeRecommendation,
+				toy.Condition,
+				toy.RentalPrice,
+				toy.LateFeePerDay,
+				toy.ReplacementPrice,
+				toy.Stock,
+				strings.Join(categories, ";"),
+				strings.Join(images, ";"),
+			}); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// resolveCategories mengambil semua kategori yang disebut di file sekaligus, dikunci dengan nama huruf kecil
+func (s *ToyCatalogService) resolveCategories(ctx context.Context, rows []entity.ToyImportRow) (map[string]entity.ToyCategory, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, row := range rows {
+		for _, name := range row.Categories {
+			if key := strings.ToLower(name); !seen[key] {
+				seen[key] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	found, err := s.toyCategoryRepo.FindByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]entity.ToyCategory, len(found))
+	for _, category := range found {
+		if _, ok := categories[strings.ToLower(category.Name)]; !ok {
+			categories[strings.ToLower(category.Name)] = category
+		}
+	}
+	return categories, nil
+}
+
+// ParseToyCSV membaca katalog dengan header sesuai entity.ToyImportColumns. Urutan kolom bebas,
+// kolom yang tidak dikenal diabaikan, dan angka yang tidak valid dicatat sebagai error baris.
+func ParseToyCSV(r io.Reader) ([]entity.ToyImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return parseToyRecords(records)
+}
+
+func parseToyRecords(records [][]string) ([]entity.ToyImportRow, error) {
+	if len(records) == 0 {
+		return nil, entity.ErrToyImportEmpty
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))] = i
+	}
+	for _, column := range []string{"name", "condition", "rental_price", "late_fee_per_day", "replacement_price", "stock", "categories"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("%w: %s", entity.ErrToyImportMissingColumn, column)
+		}
+	}
+
+	var rows []entity.ToyImportRow
+	for i, record := range records[1:] {
+		value := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return spreadsheet.UnescapeFormula(strings.TrimSpace(record[index]))
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := entity.ToyImportRow{
+			Row: i + 2,
+			Toy: entity.Toy{
+				Name:              value("name"),
+				Description:       value("description"),
+				AgeRecommendation: value("age_recommendation"),
+				Condition:         strings.ToLower(value("condition")),
+				IsAvailable:       true,
+			},
+		}
+
+		parseFloat := func(column string, target *float64) {
+			raw := value(column)
+			if raw == "" {
+				return
+			}
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("Kolom %s harus berupa angka", column))
+				return
+			}
+			*target = parsed
+		}
+		parseFloat("rental_price", &row.Toy.RentalPrice)
+		parseFloat("late_fee_per_day", &row.Toy.LateFeePerDay)
+		parseFloat("replacement_price", &row.Toy.ReplacementPrice)
+
+		if raw := value("stock"); raw != "" {
+			stock, err := strconv.Atoi(raw)
+			if err != nil {
+				row.Errors = append(row.Errors, "Kolom stock harus berupa bilangan bulat")
+			}
+			row.Toy.Stock = stock
+		}
+
+		for _, name := range strings.Split(value("categories"), ";") {
+			if name = strings.TrimSpace(name); name != "" {
+				row.Categories = append(row.Categories, name)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, entity.ErrToyImportEmpty
+	}
+	return rows, nil
+}
+
+// toyCatalogWriter menulis baris export, diimplementasikan untuk CSV dan spreadsheet.XLSXWriter
+type toyCatalogWriter interface {
+	WriteRow(values []interface{}) error
+	Flush() error
+	Close() error
+}
+
+// csvCatalogWriter menulis angka apa adanya dan teks yang diamankan dengan spreadsheet.EscapeFormula
+type csvCatalogWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvCatalogWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case int:
+			record[i] = strconv.Itoa(v)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			record[i] = spreadsheet.EscapeFormula(fmt.Sprint(v))
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvCatalogWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvCatalogWriter) Close() error {
+	return c.Flush()
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"final-project/entity"
+	"final-project/utils/spreadsheet"
+	"testing"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+func (r *fakeToyRepo) FindInBatches(ctx context.Context, batchSize int, fn func(toys []entity.Toy) error) error {
+	var toys []entity.Toy
+	for _, toy := range r.toys {
+		toys = append(toys, toy)
+	}
+	return fn(toys)
+}
+
+func TestToyCatalogService_Export_EscapesFormulas(t *testing.T) {
+	tests := []struct {
+		name     string
+		toyName  string
+		wantCell string
+	}{
+		{name: "formula sama dengan", toyName: `=HYPERLINK("http://evil.example","klik")`, wantCell: `'=HYPERLINK("http://evil.example","klik")`},
+		{name: "diawali plus", toyName: "+62 Robot", wantCell: "'+62 Robot"},
+		{name: "diawali minus", toyName: "-2+3", wantCell: "'-2+3"},
+		{name: "diawali at", toyName: "@SUM(A1)", wantCell: "'@SUM(A1)"},
+		{name: "teks biasa tidak diubah", toyName: "Balok Kayu", wantCell: "Balok Kayu"},
+	}
+
+	for _, format := range []string{entity.ToyCatalogFormatCSV, entity.ToyCatalogFormatXLSX} {
+		for _, tt := range tests {
+			t.Run(format+" "+tt.name, func(t *testing.T) {
+				toy := entity.Toy{
+					BaseEntity:        entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+					Name:              tt.toyName,
+					Condition:         "good",
+					RentalPrice:       10000,
+					LateFeePerDay:     2000,
+					ReplacementPrice:  150000,
+					Stock:             3,
+					AgeRecommendation: "3+",
+				}
+				svc := NewToyCatalogService(&fakeToyRepo{toys: map[string]entity.Toy{toy.ID.String(): toy}}, nil)
+
+				var buf bytes.Buffer
+				if err := svc.Export(context.Background(), format, &buf); err != nil {
+					t.Fatalf("Export() error = %v", err)
+				}
+
+				var records [][]string
+				var err error
+				if format == entity.ToyCatalogFormatCSV {
+					records, err = csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+				} else {
+					records, err = spreadsheet.ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(records) != 2 {
+					t.Fatalf("exported %d rows, want header and one toy", len(records))
+				}
+				if got := records[1][1]; got != tt.wantCell {
+					t.Errorf("name cell = %q, want %q", got, tt.wantCell)
+				}
+
+				// File export harus bisa diimport kembali dengan nama asli
+				rows, err := svc.Parse("katalog."+format, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				if rows[0].Toy.Name != tt.toyName {
+					t.Errorf("imported name = %q, want %q", rows[0].Toy.Name, tt.toyName)
+				}
+			})
+		}
+	}
+}
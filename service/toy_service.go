@@ -1,20 +1,79 @@
 package service
 
 import (
+	"context"
 	"final-project/entity"
 	"final-project/repository"
+	"final-project/utils/helpers"
+	"fmt"
 )
 
 type IToyService interface {
 	IBaseService[entity.Toy]
+	UpdateStock(ctx context.Context, id string, stock int) (*entity.Toy, error)
 }
 
 type ToyService struct {
 	BaseService[entity.Toy]
+	toyRepo     repository.IToyRepository
+	wishlistSvc IWishlistService
+	waitlistSvc IWaitlistService
 }
 
-func NewToyService(repo repository.IToyRepository) IToyService {
+func NewToyService(
+	repo repository.IToyRepository,
+	wishlistSvc IWishlistService,
+	waitlistSvc IWaitlistService,
+) IToyService {
 	return &ToyService{
 		BaseService: BaseService[entity.Toy]{repository: repo},
+		toyRepo:     repo,
+		wishlistSvc: wishlistSvc,
+		waitlistSvc: waitlistSvc,
+	}
+}
+
+func (s *ToyService) UpdateById(ctx context.Context, id string, toy *entity.Toy) error {
+	oldToy, err := s.toyRepo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.toyRepo.UpdateById(ctx, id, toy); err != nil {
+		return err
+	}
+
+	if toy.Stock > oldToy.Stock {
+		s.notifyBackInStock(ctx, id)
+	}
+
+	return nil
+}
+
+func (s *ToyService) UpdateStock(ctx context.Context, id string, stock int) (*entity.Toy, error) {
+	oldToy, err := s.toyRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.toyRepo.UpdateStock(ctx, id, stock); err != nil {
+		return nil, err
+	}
+
+	if stock > oldToy.Stock {
+		s.notifyBackInStock(ctx, id)
+	}
+
+	oldToy.Stock = stock
+	return &oldToy, nil
+}
+
+func (s *ToyService) notifyBackInStock(ctx context.Context, id string) {
+	// Antrian waitlist didahulukan sebelum notifikasi wishlist
+	if err := s.waitlistSvc.ProcessAvailability(ctx, id); err != nil {
+		helpers.Logger.Error(fmt.Errorf("failed to process waitlist for toy %s: %v", id, err))
+	}
+	if err := s.wishlistSvc.NotifyBackInStock(ctx, id); err != nil {
+		helpers.Logger.Error(fmt.Errorf("failed to send back in stock notification for toy %s: %v", id, err))
 	}
 }
@@ -6,84 +6,279 @@ import (
 	"final-project/entity"
 	"final-project/repository"
 	"final-project/utils/helpers"
+	"github.com/gofrs/uuid/v5"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"time"
 )
 
 type IUserService interface {
 	IBaseService[entity.User]
-	Login(ctx context.Context, emailOrUsername string, password string) (entity.User, entity.UserToken, error)
+	Login(ctx context.Context, emailOrUsername string, password string, client entity.SessionClient) (entity.LoginResult, error)
+	CompleteMFALogin(ctx context.Context, req entity.MFALoginRequest, client entity.SessionClient) (entity.LoginResult, error)
+	LoginWithIdentity(ctx context.Context, user entity.User, client entity.SessionClient) (entity.LoginResult, error)
+	Unlock(ctx context.Context, id string) error
 }
 
+// dummyPasswordHash dipakai saat user tidak ditemukan agar waktu respons login sama
+// dengan saat password salah, sehingga keberadaan akun tidak bisa ditebak dari timing
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+
 type UserService struct {
 	BaseService[entity.User]
 	UserRepository      repository.IUserRepository
 	UserTokenRepository repository.IUserTokenRepository
 	JwtHelper           helpers.JWTHelper
+	loginGuard          loginGuard
+	mfaSvc              IMFAService
+	mfaTokenTTL         time.Duration
 }
 
 func NewUserService(
 	userRepo repository.IUserRepository,
 	userTokenRepo repository.IUserTokenRepository,
+	loginAttemptRepo repository.ILoginAttemptRepository,
 	jwtHelper helpers.JWTHelper,
+	loginPolicy LoginPolicy,
+	mfaSvc IMFAService,
+	mfaTokenTTL time.Duration,
 ) IUserService {
 	return &UserService{
 		BaseService:         BaseService[entity.User]{repository: userRepo},
 		UserRepository:      userRepo,
 		UserTokenRepository: userTokenRepo,
 		JwtHelper:           jwtHelper,
+		loginGuard:          loginGuard{repo: loginAttemptRepo, policy: loginPolicy},
+		mfaSvc:              mfaSvc,
+		mfaTokenTTL:         mfaTokenTTL,
 	}
 }
 
-func (s *UserService) Insert(ctx context.Context, entity *entity.User) error {
-	userData, err := s.UserRepository.FindByEmailOrUsername(ctx, entity.Email)
+func (s *UserService) Insert(ctx context.Context, user *entity.User) error {
+	userData, err := s.UserRepository.FindByEmailOrUsername(ctx, user.Email)
 	if err == nil && userData != nil {
 		return errors.New("Email sudah terdaftar")
 	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	userData, err = s.UserRepository.FindByEmailOrUsername(ctx, entity.Username)
+	userData, err = s.UserRepository.FindByEmailOrUsername(ctx, user.Username)
 	if err == nil && userData != nil {
 		return errors.New("Username sudah terdaftar")
 	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(entity.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
-	entity.Password = string(hashedPassword)
-	return s.repository.Insert(ctx, entity)
+	user.Password = string(hashedPassword)
+	// Email selalu dimulai belum terverifikasi, verifikasi hanya lewat link email.
+	// Registrasi selalu menjadi customer, role lain hanya bisa diberikan admin.
+	user.EmailVerifiedAt = nil
+	user.Role = entity.RoleCustomer
+	user.ErasureRequestedAt = nil
+	user.ErasedAt = nil
+	return s.repository.Insert(ctx, user)
+}
+
+func (s *UserService) UpdateById(ctx context.Context, id string, user *entity.User) error {
+	user.EmailVerifiedAt = nil
+	user.Role = ""
+	user.ErasureRequestedAt = nil
+	user.ErasedAt = nil
+	return s.repository.UpdateById(ctx, id, user)
 }
 
-func (s *UserService) Login(ctx context.Context, emailOrUsername string, password string) (entity.User, entity.UserToken, error) {
+// Login memverifikasi password. Jika user mengaktifkan two-factor, yang dikembalikan hanya
+// token mfa pending yang harus ditukar lewat CompleteMFALogin.
+func (s *UserService) Login(ctx context.Context, emailOrUsername string, password string, client entity.SessionClient) (entity.LoginResult, error) {
 	user, err := s.UserRepository.FindByEmailOrUsername(ctx, emailOrUsername)
 	if err != nil {
-		return entity.User{}, entity.UserToken{}, errors.New("Username atau email tidak ditemukan")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.LoginResult{}, err
+		}
+		user = nil
+	}
+
+	accountKey := accountLoginKey(user, emailOrUsername)
+	ipKey := ipLoginKey(client.IPAddress)
+	if err := s.loginGuard.check(ctx, accountKey, ipKey); err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	// User tidak ditemukan dan password salah menghasilkan pesan yang sama
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = []byte(user.Password)
+	}
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(password)); err != nil || user == nil {
+		if err := s.loginGuard.fail(ctx, accountKey, ipKey); err != nil {
+			return entity.LoginResult{}, err
+		}
+		return entity.LoginResult{}, entity.ErrInvalidCredentials
+	}
+
+	if !user.IsActive {
+		return entity.LoginResult{}, entity.ErrAccountInactive
+	}
+
+	mfaEnabled, err := s.mfaSvc.IsEnabled(ctx, user.ID.String())
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	if mfaEnabled {
+		return s.mfaPendingResult(*user)
+	}
+
+	if err := s.loginGuard.reset(ctx, accountKey); err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	userToken, err := s.createSession(ctx, user, client, false)
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	return entity.LoginResult{User: *user, UserToken: userToken}, nil
+}
+
+// CompleteMFALogin menukar token mfa pending dan kode TOTP atau recovery code dengan sesi penuh.
+// Kode yang salah dihitung sebagai login gagal agar kode 6 digit tidak bisa ditebak.
+func (s *UserService) CompleteMFALogin(ctx context.Context, req entity.MFALoginRequest, client entity.SessionClient) (entity.LoginResult, error) {
+	claims, err := s.JwtHelper.ValidateMFAToken(req.MFAToken)
+	if err != nil {
+		return entity.LoginResult{}, entity.ErrMFATokenInvalid
+	}
+
+	user, err := s.UserRepository.FindById(ctx, claims.UserID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.LoginResult{}, entity.ErrMFATokenInvalid
+		}
+		return entity.LoginResult{}, err
+	}
+
+	if !user.IsActive {
+		return entity.LoginResult{}, entity.ErrAccountInactive
+	}
+
+	accountKey := accountLoginKey(&user, "")
+	ipKey := ipLoginKey(client.IPAddress)
+	if err := s.loginGuard.check(ctx, accountKey, ipKey); err != nil {
+		return entity.LoginResult{}, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return entity.User{}, entity.UserToken{}, errors.New("Password salah")
+	if err := s.mfaSvc.Verify(ctx, user.ID.String(), req.Code); err != nil {
+		if errors.Is(err, entity.ErrMFAInvalidCode) {
+			if err := s.loginGuard.fail(ctx, accountKey, ipKey); err != nil {
+				return entity.LoginResult{}, err
+			}
+		}
+		return entity.LoginResult{}, err
 	}
 
-	accessToken, accessTokenExp, _ := s.JwtHelper.GenerateAccessToken(user.ID, user.Email, user.Role)
-	refreshToken, refreshTokenExp, _ := s.JwtHelper.GenerateRefreshToken(user.ID)
+	if err := s.loginGuard.reset(ctx, accountKey); err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	userToken, err := s.createSession(ctx, &user, client, true)
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	return entity.LoginResult{User: user, UserToken: userToken}, nil
+}
+
+// LoginWithIdentity membuat sesi untuk user yang sudah diautentikasi oleh provider OIDC.
+// Two-factor tetap diminta jika user mengaktifkannya.
+func (s *UserService) LoginWithIdentity(ctx context.Context, user entity.User, client entity.SessionClient) (entity.LoginResult, error) {
+	if !user.IsActive {
+		return entity.LoginResult{}, entity.ErrAccountInactive
+	}
+
+	mfaEnabled, err := s.mfaSvc.IsEnabled(ctx, user.ID.String())
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	if mfaEnabled {
+		return s.mfaPendingResult(user)
+	}
+
+	userToken, err := s.createSession(ctx, &user, client, false)
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	return entity.LoginResult{User: user, UserToken: userToken}, nil
+}
+
+// mfaPendingResult membuat token mfa pending yang harus ditukar lewat CompleteMFALogin
+func (s *UserService) mfaPendingResult(user entity.User) (entity.LoginResult, error) {
+	mfaToken, expiresAt, err := s.JwtHelper.GenerateMFAToken(user.ID, s.mfaTokenTTL)
+	if err != nil {
+		return entity.LoginResult{}, err
+	}
+
+	return entity.LoginResult{
+		User:              user,
+		MFARequired:       true,
+		MFAToken:          mfaToken,
+		MFATokenExpiresAt: expiresAt,
+	}, nil
+}
+
+func (s *UserService) createSession(ctx context.Context, user *entity.User, client entity.SessionClient, mfaVerified bool) (entity.UserToken, error) {
+	accessToken, accessTokenExp, err := s.JwtHelper.GenerateAccessToken(user.ID, user.Email, user.Role)
+	if err != nil {
+		return entity.UserToken{}, err
+	}
+
+	refreshToken, refreshTokenExp, err := s.JwtHelper.GenerateRefreshToken(user.ID)
+	if err != nil {
+		return entity.UserToken{}, err
+	}
+
+	familyID, err := uuid.NewV7()
+	if err != nil {
+		return entity.UserToken{}, err
+	}
 
 	userToken := &entity.UserToken{
 		UserID:                user.ID,
+		FamilyID:              &familyID,
 		AccessToken:           accessToken,
 		RefreshToken:          refreshToken,
 		AccessTokenExpiresAt:  accessTokenExp,
 		RefreshTokenExpiresAt: refreshTokenExp,
+		MFAVerified:           mfaVerified,
+		UserAgent:             client.UserAgent,
+		IPAddress:             client.IPAddress,
+	}
+
+	if err := s.UserTokenRepository.Insert(ctx, userToken); err != nil {
+		return entity.UserToken{}, err
 	}
 
-	err = s.UserTokenRepository.Insert(ctx, userToken)
+	return *userToken, nil
+}
+
+// Unlock menghapus catatan login gagal sehingga akun yang terkunci bisa login kembali
+func (s *UserService) Unlock(ctx context.Context, id string) error {
+	user, err := s.UserRepository.FindById(ctx, id)
 	if err != nil {
-		return entity.User{}, entity.UserToken{}, err
+		return err
+	}
+
+	for _, identifier := range []string{user.Email, user.Username} {
+		if err := s.loginGuard.reset(ctx, accountLoginKey(nil, identifier)); err != nil {
+			return err
+		}
 	}
 
-	return *user, *userToken, nil
+	return s.loginGuard.reset(ctx, accountLoginKey(&user, ""))
 }
@@ -2,71 +2,224 @@ package service
 
 import (
 	"context"
+	"errors"
 	"final-project/entity"
 	"final-project/repository"
 	"final-project/utils/helpers"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token tidak valid")
+	ErrRefreshTokenReused  = errors.New("refresh token sudah digunakan, seluruh sesi terkait telah dicabut")
+	ErrSessionRevoked      = errors.New("sesi sudah tidak berlaku")
+	ErrSessionExpired      = errors.New("sesi sudah kadaluarsa")
+	ErrSessionNotFound     = errors.New("sesi tidak ditemukan")
 )
 
 type ITokenService interface {
 	IBaseService[entity.UserToken]
 	FindByAccessToken(ctx context.Context, accessToken string) (entity.UserToken, error)
 	DeleteByAccessToken(ctx context.Context, accessToken string) error
-	RefreshToken(ctx context.Context, accessToken string, claimsToken helpers.ClaimsToken) (entity.UserToken, error)
+	RefreshToken(ctx context.Context, refreshToken string, client entity.SessionClient) (entity.UserToken, error)
+	ValidateSession(ctx context.Context, accessToken string) (entity.UserToken, error)
+	FindSessionsByUserID(ctx context.Context, userID string) ([]entity.UserToken, error)
+	RevokeSession(ctx context.Context, userID string, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string, exceptSessionID string) error
 }
 
 type TokenService struct {
 	BaseService[entity.UserToken]
 	userTokenRepository repository.IUserTokenRepository
+	userRepository      repository.IUserRepository
 	jwtHelper           helpers.JWTHelper
+	cache               *sessionCache
 }
 
 func NewTokenService(
 	tokenRepo repository.IUserTokenRepository,
+	userRepo repository.IUserRepository,
 	jwtHelper helpers.JWTHelper,
+	sessionCacheTTL time.Duration,
 ) ITokenService {
 	return &TokenService{
 		BaseService:         BaseService[entity.UserToken]{repository: tokenRepo},
 		userTokenRepository: tokenRepo,
+		userRepository:      userRepo,
 		jwtHelper:           jwtHelper,
+		cache:               newSessionCache(sessionCacheTTL),
 	}
 }
 
 func (s *TokenService) FindByAccessToken(ctx context.Context, accessToken string) (entity.UserToken, error) {
-	return s.FindByAccessToken(ctx, accessToken)
+	return s.userTokenRepository.FindByAccessToken(ctx, accessToken)
 }
 
 func (s *TokenService) DeleteByAccessToken(ctx context.Context, accessToken string) error {
-	return s.userTokenRepository.DeleteByAccessToken(ctx, accessToken)
+	if err := s.userTokenRepository.DeleteByAccessToken(ctx, accessToken); err != nil {
+		return err
+	}
+
+	s.cache.delete(accessToken)
+	return nil
 }
 
-func (s *TokenService) RefreshToken(ctx context.Context, accessToken string, claimsToken helpers.ClaimsToken) (entity.UserToken, error) {
+// ValidateSession memastikan access token masih tercatat aktif di user_tokens.
+// Hasilnya di-cache sebentar, last_seen_at hanya diperbarui ketika cache terlewat.
+func (s *TokenService) ValidateSession(ctx context.Context, accessToken string) (entity.UserToken, error) {
+	if userToken, ok := s.cache.get(accessToken); ok {
+		if userToken.IsAccessTokenExpired() {
+			s.cache.delete(accessToken)
+			return entity.UserToken{}, ErrSessionExpired
+		}
+		return userToken, nil
+	}
+
 	userToken, err := s.userTokenRepository.FindByAccessToken(ctx, accessToken)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.UserToken{}, ErrSessionRevoked
+		}
 		return entity.UserToken{}, err
 	}
 
-	newAccessToken, accessTokenExp, err := s.jwtHelper.GenerateAccessToken(userToken.UserID, claimsToken.Email, claimsToken.Role)
+	// Pasangan token yang sudah dirotasi tidak boleh dipakai lagi
+	if userToken.IsBlocked || userToken.IsUsed {
+		return entity.UserToken{}, ErrSessionRevoked
+	}
+
+	if userToken.IsAccessTokenExpired() {
+		return entity.UserToken{}, ErrSessionExpired
+	}
+
+	now := time.Now()
+	if err := s.userTokenRepository.UpdateLastSeen(ctx, userToken.ID.String(), now); err != nil {
+		helpers.Logger.Error("Failed to update session last seen: ", err)
+	} else {
+		userToken.LastSeenAt = &now
+	}
+
+	s.cache.set(accessToken, userToken)
+	return userToken, nil
+}
+
+func (s *TokenService) FindSessionsByUserID(ctx context.Context, userID string) ([]entity.UserToken, error) {
+	return s.userTokenRepository.FindActiveByUserID(ctx, userID)
+}
+
+func (s *TokenService) RevokeSession(ctx context.Context, userID string, sessionID string) error {
+	affected, err := s.userTokenRepository.BlockSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrSessionNotFound
+	}
+
+	s.cache.deleteWhere(func(token entity.UserToken) bool {
+		return token.SessionID().String() == sessionID
+	})
+	return nil
+}
+
+// RevokeAllSessions mencabut semua sesi user. exceptSessionID dipakai agar sesi yang sedang dipakai tetap aktif.
+func (s *TokenService) RevokeAllSessions(ctx context.Context, userID string, exceptSessionID string) error {
+	if err := s.userTokenRepository.BlockAllByUserID(ctx, userID, exceptSessionID); err != nil {
+		return err
+	}
+
+	s.cache.deleteWhere(func(token entity.UserToken) bool {
+		return token.UserID.String() == userID && token.SessionID().String() != exceptSessionID
+	})
+	return nil
+}
+
+// RefreshToken merotasi pasangan token. Refresh token yang sudah pernah dipakai
+// dianggap dicuri, sehingga seluruh family token dari login yang sama diblokir.
+func (s *TokenService) RefreshToken(ctx context.Context, refreshToken string, client entity.SessionClient) (entity.UserToken, error) {
+	claims, err := s.jwtHelper.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return entity.UserToken{}, err
+	}
+
+	userToken, err := s.userTokenRepository.FindByRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.UserToken{}, ErrRefreshTokenInvalid
+		}
+		return entity.UserToken{}, err
+	}
+
+	// Sesi impersonation tidak boleh diperpanjang
+	if userToken.IsBlocked || userToken.UserID != claims.UserID || userToken.IsRefreshTokenExpired() || userToken.IsImpersonation() {
+		return entity.UserToken{}, ErrRefreshTokenInvalid
+	}
+
+	if userToken.IsUsed {
+		if err := s.blockFamily(ctx, &userToken); err != nil {
+			return entity.UserToken{}, err
+		}
+		return entity.UserToken{}, ErrRefreshTokenReused
+	}
+
+	user, err := s.userRepository.FindById(ctx, userToken.UserID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.UserToken{}, ErrRefreshTokenInvalid
+		}
+		return entity.UserToken{}, err
+	}
+
+	// Akun yang dinonaktifkan admin tidak boleh mendapatkan token baru
+	if !user.IsActive {
+		return entity.UserToken{}, ErrRefreshTokenInvalid
+	}
+
+	newAccessToken, accessTokenExp, err := s.jwtHelper.GenerateAccessToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		return entity.UserToken{}, err
 	}
 
-	refreshToken, refreshTokenExp, err := s.jwtHelper.GenerateRefreshToken(userToken.UserID)
+	newRefreshToken, refreshTokenExp, err := s.jwtHelper.GenerateRefreshToken(user.ID)
 	if err != nil {
 		return entity.UserToken{}, err
 	}
 
 	newUserToken := &entity.UserToken{
-		UserID:                userToken.UserID,
+		UserID:                user.ID,
 		AccessToken:           newAccessToken,
-		RefreshToken:          refreshToken,
+		RefreshToken:          newRefreshToken,
 		AccessTokenExpiresAt:  accessTokenExp,
 		RefreshTokenExpiresAt: refreshTokenExp,
+		MFAVerified:           userToken.MFAVerified,
+		UserAgent:             client.UserAgent,
+		IPAddress:             client.IPAddress,
 	}
 
-	err = s.userTokenRepository.UpdateByRefreshToken(ctx, userToken.RefreshToken, newUserToken)
-	if err != nil {
+	if err := s.userTokenRepository.Rotate(ctx, &userToken, newUserToken); err != nil {
+		// Request lain merotasi token ini lebih dulu, perlakukan sebagai reuse
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			if err := s.blockFamily(ctx, &userToken); err != nil {
+				return entity.UserToken{}, err
+			}
+			return entity.UserToken{}, ErrRefreshTokenReused
+		}
 		return entity.UserToken{}, err
 	}
 
+	s.cache.delete(userToken.AccessToken)
 	return *newUserToken, nil
 }
+
+func (s *TokenService) blockFamily(ctx context.Context, userToken *entity.UserToken) error {
+	if err := s.userTokenRepository.BlockFamily(ctx, userToken); err != nil {
+		return err
+	}
+
+	sessionID := userToken.SessionID()
+	s.cache.deleteWhere(func(token entity.UserToken) bool {
+		return token.SessionID() == sessionID || token.ID == userToken.ID
+	})
+	return nil
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeUserTokenRepo struct {
+	repository.IUserTokenRepository
+	tokens        map[string]entity.UserToken
+	rotateErr     error
+	rotated       *entity.UserToken
+	blockedFamily *entity.UserToken
+	blockedUserID string
+}
+
+func (r *fakeUserTokenRepo) FindByRefreshToken(ctx context.Context, refreshToken string) (entity.UserToken, error) {
+	token, ok := r.tokens[refreshToken]
+	if !ok {
+		return token, gorm.ErrRecordNotFound
+	}
+	return token, nil
+}
+
+func (r *fakeUserTokenRepo) FindByAccessToken(ctx context.Context, accessToken string) (entity.UserToken, error) {
+	for _, token := range r.tokens {
+		if token.AccessToken == accessToken {
+			return token, nil
+		}
+	}
+	return entity.UserToken{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserTokenRepo) UpdateLastSeen(ctx context.Context, id string, lastSeenAt time.Time) error {
+	return nil
+}
+
+func (r *fakeUserTokenRepo) Rotate(ctx context.Context, oldToken *entity.UserToken, newToken *entity.UserToken) error {
+	if r.rotateErr != nil {
+		return r.rotateErr
+	}
+	if token, ok := r.tokens[oldToken.RefreshToken]; ok {
+		token.IsUsed = true
+		r.tokens[oldToken.RefreshToken] = token
+	}
+	r.rotated = newToken
+	return nil
+}
+
+func (r *fakeUserTokenRepo) BlockSession(ctx context.Context, userID string, sessionID string) (int64, error) {
+	var affected int64
+	for key, token := range r.tokens {
+		if token.UserID.String() == userID && token.SessionID().String() == sessionID {
+			token.IsBlocked = true
+			r.tokens[key] = token
+			affected++
+		}
+	}
+	return affected, nil
+}
+
+func (r *fakeUserTokenRepo) BlockFamily(ctx context.Context, token *entity.UserToken) error {
+	r.blockedFamily = token
+	return nil
+}
+
+func (r *fakeUserTokenRepo) BlockAllByUserID(ctx context.Context, userID string, exceptSessionID string) error {
+	r.blockedUserID = userID
+	for key, token := range r.tokens {
+		if token.UserID.String() == userID && token.SessionID().String() != exceptSessionID {
+			token.IsBlocked = true
+			r.tokens[key] = token
+		}
+	}
+	return nil
+}
+
+func newTestJWTHelper() *helpers.JWTHelper {
+	return helpers.NewJWTHelper("test-secret", helpers.SigningAlgHS256, false, helpers.NewKeySet(), 1, 7, "test")
+}
+
+func TestTokenService_RefreshToken(t *testing.T) {
+	jwtHelper := newTestJWTHelper()
+	userID := uuid.Must(uuid.NewV7())
+	impersonatorID := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name         string
+		modify       func(token *entity.UserToken, user *entity.User)
+		rotateErr    error
+		tokenUserID  uuid.UUID
+		wantErr      error
+		wantBlocked  bool
+		wantRotation bool
+	}{
+		{
+			name:         "token dirotasi",
+			wantRotation: true,
+		},
+		{
+			name: "refresh token yang sudah dipakai mencabut seluruh family",
+			modify: func(token *entity.UserToken, user *entity.User) {
+				token.IsUsed = true
+			},
+			wantErr:     ErrRefreshTokenReused,
+			wantBlocked: true,
+		},
+		{
+			name:        "request lain merotasi token lebih dulu",
+			rotateErr:   repository.ErrRefreshTokenUsed,
+			wantErr:     ErrRefreshTokenReused,
+			wantBlocked: true,
+		},
+		{
+			name: "akun dinonaktifkan",
+			modify: func(token *entity.UserToken, user *entity.User) {
+				user.IsActive = false
+			},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "sesi sudah dicabut",
+			modify: func(token *entity.UserToken, user *entity.User) {
+				token.IsBlocked = true
+			},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "refresh token kadaluarsa",
+			modify: func(token *entity.UserToken, user *entity.User) {
+				token.RefreshTokenExpiresAt = time.Now().Add(-time.Minute)
+			},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name: "sesi impersonation tidak bisa diperpanjang",
+			modify: func(token *entity.UserToken, user *entity.User) {
+				token.ImpersonatorID = &impersonatorID
+			},
+			wantErr: ErrRefreshTokenInvalid,
+		},
+		{
+			name:        "token milik user lain",
+			tokenUserID: uuid.Must(uuid.NewV7()),
+			wantErr:     ErrRefreshTokenInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokenUserID := userID
+			if tt.tokenUserID != uuid.Nil {
+				tokenUserID = tt.tokenUserID
+			}
+			refreshToken, refreshTokenExp, err := jwtHelper.GenerateRefreshToken(tokenUserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			user := entity.User{BaseEntity: entity.BaseEntity{ID: userID}, Email: "budi@example.com", Role: entity.RoleCustomer, IsActive: true}
+			token := entity.UserToken{
+				BaseEntity:            entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+				UserID:                userID,
+				AccessToken:           "access-token",
+				RefreshToken:          refreshToken,
+				AccessTokenExpiresAt:  time.Now().Add(time.Hour),
+				RefreshTokenExpiresAt: refreshTokenExp,
+				MFAVerified:           true,
+			}
+			if tt.modify != nil {
+				tt.modify(&token, &user)
+			}
+
+			tokenRepo := &fakeUserTokenRepo{tokens: map[string]entity.UserToken{refreshToken: token}, rotateErr: tt.rotateErr}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{userID.String(): user}}
+			svc := NewTokenService(tokenRepo, userRepo, *jwtHelper, time.Minute)
+
+			got, err := svc.RefreshToken(context.Background(), refreshToken, entity.SessionClient{UserAgent: "test"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RefreshToken() error = %v, want %v", err, tt.wantErr)
+			}
+			if blocked := tokenRepo.blockedFamily != nil; blocked != tt.wantBlocked {
+				t.Errorf("family blocked = %v, want %v", blocked, tt.wantBlocked)
+			}
+			if rotated := tokenRepo.rotated != nil; rotated != tt.wantRotation {
+				t.Fatalf("rotated = %v, want %v", rotated, tt.wantRotation)
+			}
+			if !tt.wantRotation {
+				return
+			}
+
+			if got.RefreshToken == refreshToken || got.AccessToken == token.AccessToken {
+				t.Error("token pair was not replaced")
+			}
+			if !got.MFAVerified {
+				t.Error("rotated session lost its mfa verification")
+			}
+		})
+	}
+}
+
+func TestTokenService_SessionRevocation(t *testing.T) {
+	jwtHelper := newTestJWTHelper()
+	user := entity.User{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}, Email: "budi@example.com", Role: entity.RoleCustomer, IsActive: true}
+
+	tests := []struct {
+		name string
+		// revoke dijalankan setelah kedua sesi masuk cache, current adalah sesi yang sedang dipakai
+		revoke  func(svc ITokenService, current entity.UserToken, other entity.UserToken) error
+		wantErr error
+	}{
+		{
+			name: "sesi lain dicabut",
+			revoke: func(svc ITokenService, current entity.UserToken, other entity.UserToken) error {
+				return svc.RevokeSession(context.Background(), user.ID.String(), other.SessionID().String())
+			},
+		},
+		{
+			name: "semua sesi dicabut kecuali sesi saat ini",
+			revoke: func(svc ITokenService, current entity.UserToken, other entity.UserToken) error {
+				return svc.RevokeAllSessions(context.Background(), user.ID.String(), current.SessionID().String())
+			},
+		},
+		{
+			name: "token dirotasi",
+			revoke: func(svc ITokenService, current entity.UserToken, other entity.UserToken) error {
+				_, err := svc.RefreshToken(context.Background(), other.RefreshToken, entity.SessionClient{UserAgent: "test"})
+				return err
+			},
+		},
+		{
+			name: "sesi milik user lain tidak ditemukan",
+			revoke: func(svc ITokenService, current entity.UserToken, other entity.UserToken) error {
+				return svc.RevokeSession(context.Background(), uuid.Must(uuid.NewV7()).String(), other.SessionID().String())
+			},
+			wantErr: ErrSessionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := make(map[string]entity.UserToken)
+			var sessions []entity.UserToken
+			for _, accessToken := range []string{"access-token-saat-ini", "access-token-lain"} {
+				refreshToken, refreshTokenExp, err := jwtHelper.GenerateRefreshToken(user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				token := entity.UserToken{
+					BaseEntity:            entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+					UserID:                user.ID,
+					AccessToken:           accessToken,
+					RefreshToken:          refreshToken,
+					AccessTokenExpiresAt:  time.Now().Add(time.Hour),
+					RefreshTokenExpiresAt: refreshTokenExp,
+				}
+				tokens[refreshToken] = token
+				sessions = append(sessions, token)
+			}
+			current, other := sessions[0], sessions[1]
+
+			tokenRepo := &fakeUserTokenRepo{tokens: tokens}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{user.ID.String(): user}}
+			svc := NewTokenService(tokenRepo, userRepo, *jwtHelper, time.Minute)
+			cache := svc.(*TokenService).cache
+
+			for _, session := range sessions {
+				if _, err := svc.ValidateSession(context.Background(), session.AccessToken); err != nil {
+					t.Fatalf("ValidateSession(%s) error = %v", session.AccessToken, err)
+				}
+				if _, ok := cache.get(session.AccessToken); !ok {
+					t.Fatalf("session %s was not cached", session.AccessToken)
+				}
+			}
+
+			err := tt.revoke(svc, current, other)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("revoke error = %v, want %v", err, tt.wantErr)
+			}
+
+			if _, err := svc.ValidateSession(context.Background(), current.AccessToken); err != nil {
+				t.Errorf("current session rejected: %v", err)
+			}
+
+			_, cached := cache.get(other.AccessToken)
+			_, err = svc.ValidateSession(context.Background(), other.AccessToken)
+			if tt.wantErr != nil {
+				if err != nil {
+					t.Errorf("session rejected although nothing was revoked: %v", err)
+				}
+				return
+			}
+			if cached {
+				t.Error("revoked session is still in the cache")
+			}
+			if !errors.Is(err, ErrSessionRevoked) {
+				t.Errorf("ValidateSession() of the revoked session error = %v, want %v", err, ErrSessionRevoked)
+			}
+		})
+	}
+}
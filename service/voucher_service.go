@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type IVoucherService interface {
+	IBaseService[entity.Voucher]
+	ApplyVoucher(ctx context.Context, code string, userID uuid.UUID, rental *entity.Rental) error
+	FindRedemptions(ctx context.Context, voucherID string, limit int, offset int) ([]entity.VoucherRedemption, int64, error)
+	Report(ctx context.Context, from *time.Time, to *time.Time) ([]entity.VoucherReport, error)
+}
+
+type VoucherService struct {
+	BaseService[entity.Voucher]
+	voucherRepo     repository.IVoucherRepository
+	toyRepo         repository.IToyRepository
+	toyCategoryRepo repository.IToyCategoryRepository
+}
+
+func NewVoucherService(
+	repo repository.IVoucherRepository,
+	toyRepo repository.IToyRepository,
+	toyCategoryRepo repository.IToyCategoryRepository,
+) IVoucherService {
+	return &VoucherService{
+		BaseService:     BaseService[entity.Voucher]{repository: repo},
+		voucherRepo:     repo,
+		toyRepo:         toyRepo,
+		toyCategoryRepo: toyCategoryRepo,
+	}
+}
+
+func (s *VoucherService) Insert(ctx context.Context, voucher *entity.Voucher) error {
+	if err := s.validateEligibility(ctx, voucher); err != nil {
+		return err
+	}
+
+	if _, err := s.voucherRepo.FindByCode(ctx, voucher.Code); err == nil {
+		return errors.New("kode voucher sudah digunakan")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.voucherRepo.Insert(ctx, voucher)
+}
+
+func (s *VoucherService) UpdateById(ctx context.Context, id string, voucher *entity.Voucher) error {
+	if err := s.validateEligibility(ctx, voucher); err != nil {
+		return err
+	}
+
+	if existing, err := s.voucherRepo.FindByCode(ctx, voucher.Code); err == nil && existing.ID.String() != id {
+		return errors.New("kode voucher sudah digunakan")
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return s.voucherRepo.UpdateById(ctx, id, voucher)
+}
+
+// ApplyVoucher memvalidasi voucher terhadap rental lalu mencatat potongan pada rental.
+// Pemakaian voucher baru dihitung saat rental disimpan oleh repository, yang memeriksa ulang
+// kuota dan batas per user di dalam transaksi.
+func (s *VoucherService) ApplyVoucher(ctx context.Context, code string, userID uuid.UUID, rental *entity.Rental) error {
+	voucher, err := s.voucherRepo.FindByCode(ctx, entity.NormalizeCode(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ErrVoucherNotFound
+		}
+		return err
+	}
+
+	if err := voucher.CheckValidity(time.Now()); err != nil {
+		return err
+	}
+
+	if voucher.PerUserLimit > 0 {
+		used, err := s.voucherRepo.CountRedemptionsByUser(ctx, voucher.ID.String(), userID.String())
+		if err != nil {
+			return err
+		}
+		if err := voucher.CheckUserLimit(used); err != nil {
+			return err
+		}
+	}
+
+	if rental.TotalRentalPrice < voucher.MinSpend {
+		return entity.ErrVoucherMinSpend
+	}
+
+	eligibleSubtotal := rental.TotalRentalPrice
+	if voucher.HasEligibilityRules() {
+		eligibleSubtotal, err = s.eligibleSubtotal(ctx, voucher, rental)
+		if err != nil {
+			return err
+		}
+	}
+
+	if eligibleSubtotal <= 0 {
+		return entity.ErrVoucherNotEligible
+	}
+
+	voucherID := voucher.ID
+	rental.VoucherID = &voucherID
+	rental.DiscountAmount = voucher.CalculateDiscount(eligibleSubtotal)
+	return nil
+}
+
+func (s *VoucherService) FindRedemptions(ctx context.Context, voucherID string, limit int, offset int) ([]entity.VoucherRedemption, int64, error) {
+	return s.voucherRepo.FindRedemptions(ctx, voucherID, limit, offset)
+}
+
+func (s *VoucherService) Report(ctx context.Context, from *time.Time, to *time.Time) ([]entity.VoucherReport, error) {
+	return s.voucherRepo.Report(ctx, from, to)
+}
+
+func (s *VoucherService) eligibleSubtotal(ctx context.Context, voucher entity.Voucher, rental *entity.Rental) (float64, error) {
+	toyIDs := make([]string, 0, len(rental.RentalItems))
+	for _, item := range rental.RentalItems {
+		toyIDs = append(toyIDs, item.ToyID.String())
+	}
+
+	toys, err := s.toyRepo.FindByIdsWithCategories(ctx, toyIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	eligibleToys := make(map[uuid.UUID]bool, len(toys))
+	for _, toy := range toys {
+		eligibleToys[toy.ID] = voucher.IsToyEligible(toy)
+	}
+
+	var subtotal float64 = 0
+	for _, item := range rental.RentalItems {
+		if eligibleToys[item.ToyID] {
+			subtotal += item.PricePerUnit * float64(item.Quantity)
+		}
+	}
+	return subtotal, nil
+}
+
+// validateEligibility memastikan kategori dan mainan voucher ada. ID yang sama dalam satu daftar
+// hanya disimpan sekali karena tabel relasinya memakai ID tersebut sebagai primary key.
+func (s *VoucherService) validateEligibility(ctx context.Context, voucher *entity.Voucher) error {
+	seen := make(map[uuid.UUID]bool)
+
+	categories := voucher.Categories[:0]
+	for _, category := range voucher.Categories {
+		if seen[category.ID] {
+			continue
+		}
+		seen[category.ID] = true
+
+		if _, err := s.toyCategoryRepo.FindById(ctx, category.ID.String()); err != nil {
+			return errors.New("kategori tidak ditemukan: " + category.ID.String())
+		}
+		categories = append(categories, category)
+	}
+	voucher.Categories = categories
+
+	toys := voucher.Toys[:0]
+	for _, toy := range voucher.Toys {
+		if seen[toy.ID] {
+			continue
+		}
+		seen[toy.ID] = true
+
+		if _, err := s.toyRepo.FindById(ctx, toy.ID.String()); err != nil {
+			return errors.New("mainan tidak ditemukan: " + toy.ID.String())
+		}
+		toys = append(toys, toy)
+	}
+	voucher.Toys = toys
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeVoucherRepo struct {
+	repository.IVoucherRepository
+	vouchers    map[string]entity.Voucher
+	redemptions int64
+	inserted    *entity.Voucher
+}
+
+func (r *fakeVoucherRepo) FindByCode(ctx context.Context, code string) (entity.Voucher, error) {
+	voucher, ok := r.vouchers[code]
+	if !ok {
+		return voucher, gorm.ErrRecordNotFound
+	}
+	return voucher, nil
+}
+
+func (r *fakeVoucherRepo) CountRedemptionsByUser(ctx context.Context, voucherID string, userID string) (int64, error) {
+	return r.redemptions, nil
+}
+
+func (r *fakeVoucherRepo) Insert(ctx context.Context, voucher *entity.Voucher) error {
+	r.inserted = voucher
+	return nil
+}
+
+type fakeToyCategoryRepo struct {
+	repository.IToyCategoryRepository
+}
+
+func (r *fakeToyCategoryRepo) FindById(ctx context.Context, id string) (entity.ToyCategory, error) {
+	return entity.ToyCategory{}, nil
+}
+
+func (r *fakeToyRepo) FindByIdsWithCategories(ctx context.Context, ids []string) ([]entity.Toy, error) {
+	var toys []entity.Toy
+	for _, id := range ids {
+		if toy, ok := r.toys[id]; ok {
+			toys = append(toys, toy)
+		}
+	}
+	return toys, nil
+}
+
+func TestVoucherService_ApplyVoucher(t *testing.T) {
+	now := time.Now()
+	eligibleToy := entity.Toy{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}}
+	otherToy := entity.Toy{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}}
+
+	voucher := func(modify func(v *entity.Voucher)) entity.Voucher {
+		v := entity.Voucher{
+			BaseEntity:    entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+			Code:          "HEMAT",
+			DiscountType:  entity.DiscountTypePercentage,
+			DiscountValue: 10,
+			ValidFrom:     now.Add(-time.Hour),
+			ValidUntil:    now.Add(time.Hour),
+			IsActive:      true,
+		}
+		if modify != nil {
+			modify(&v)
+		}
+		return v
+	}
+
+	rental := func() *entity.Rental {
+		return &entity.Rental{
+			TotalRentalPrice: 100000,
+			RentalItems: []entity.RentalItem{
+				{ToyID: eligibleToy.ID, Quantity: 2, PricePerUnit: 30000},
+				{ToyID: otherToy.ID, Quantity: 1, PricePerUnit: 40000},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		voucher      entity.Voucher
+		redemptions  int64
+		code         string
+		wantErr      error
+		wantDiscount float64
+	}{
+		{
+			name:         "diskon persentase dari total rental",
+			voucher:      voucher(nil),
+			code:         " hemat ",
+			wantDiscount: 10000,
+		},
+		{
+			name: "diskon persentase dibatasi maksimal diskon",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.MaxDiscount = 5000
+			}),
+			code:         "HEMAT",
+			wantDiscount: 5000,
+		},
+		{
+			name: "diskon hanya dari mainan yang memenuhi syarat",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.Toys = []entity.Toy{eligibleToy}
+			}),
+			code:         "HEMAT",
+			wantDiscount: 6000,
+		},
+		{
+			name: "diskon tetap tidak melebihi subtotal",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.DiscountType = entity.DiscountTypeFixed
+				v.DiscountValue = 80000
+				v.Toys = []entity.Toy{eligibleToy}
+			}),
+			code:         "HEMAT",
+			wantDiscount: 60000,
+		},
+		{
+			name:    "kode tidak ditemukan",
+			voucher: voucher(nil),
+			code:    "LAINNYA",
+			wantErr: entity.ErrVoucherNotFound,
+		},
+		{
+			name: "kuota habis",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.UsageLimit = 5
+				v.UsedCount = 5
+			}),
+			code:    "HEMAT",
+			wantErr: entity.ErrVoucherUsageLimitReached,
+		},
+		{
+			name: "batas per user tercapai",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.PerUserLimit = 1
+			}),
+			redemptions: 1,
+			code:        "HEMAT",
+			wantErr:     entity.ErrVoucherUserLimitReached,
+		},
+		{
+			name: "minimum pembelanjaan belum terpenuhi",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.MinSpend = 150000
+			}),
+			code:    "HEMAT",
+			wantErr: entity.ErrVoucherMinSpend,
+		},
+		{
+			name: "tidak ada mainan yang memenuhi syarat",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.Toys = []entity.Toy{{BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())}}}
+			}),
+			code:    "HEMAT",
+			wantErr: entity.ErrVoucherNotEligible,
+		},
+		{
+			name: "voucher kadaluarsa",
+			voucher: voucher(func(v *entity.Voucher) {
+				v.ValidUntil = now.Add(-time.Minute)
+			}),
+			code:    "HEMAT",
+			wantErr: entity.ErrVoucherExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucherRepo := &fakeVoucherRepo{vouchers: map[string]entity.Voucher{tt.voucher.Code: tt.voucher}, redemptions: tt.redemptions}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{
+				eligibleToy.ID.String(): eligibleToy,
+				otherToy.ID.String():    otherToy,
+			}}
+			svc := NewVoucherService(voucherRepo, toyRepo, &fakeToyCategoryRepo{})
+
+			r := rental()
+			err := svc.ApplyVoucher(context.Background(), tt.code, uuid.Must(uuid.NewV7()), r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ApplyVoucher() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if r.VoucherID != nil {
+					t.Error("voucher applied although validation failed")
+				}
+				return
+			}
+
+			if r.VoucherID == nil || *r.VoucherID != tt.voucher.ID {
+				t.Errorf("voucher id = %v, want %s", r.VoucherID, tt.voucher.ID)
+			}
+			if r.DiscountAmount != tt.wantDiscount {
+				t.Errorf("discount = %v, want %v", r.DiscountAmount, tt.wantDiscount)
+			}
+		})
+	}
+}
+
+func TestVoucherService_Insert_DuplicateEligibility(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	categoryID := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name           string
+		request        entity.VoucherRequest
+		wantToys       int
+		wantCategories int
+	}{
+		{
+			name: "id duplikat hanya disimpan sekali",
+			request: entity.VoucherRequest{
+				Code:        "HEMAT",
+				ToyIDs:      []uuid.UUID{toyID, toyID},
+				CategoryIDs: []uuid.UUID{categoryID, categoryID, categoryID},
+			},
+			wantToys:       1,
+			wantCategories: 1,
+		},
+		{
+			name:    "tanpa syarat mainan atau kategori",
+			request: entity.VoucherRequest{Code: "HEMAT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voucherRepo := &fakeVoucherRepo{}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{toyID.String(): {BaseEntity: entity.BaseEntity{ID: toyID}}}}
+			svc := NewVoucherService(voucherRepo, toyRepo, &fakeToyCategoryRepo{})
+
+			voucher := tt.request.ToVoucher()
+			if err := svc.Insert(context.Background(), &voucher); err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+
+			if len(voucherRepo.inserted.Toys) != tt.wantToys || len(voucherRepo.inserted.Categories) != tt.wantCategories {
+				t.Errorf("saved %d toys and %d categories, want %d and %d",
+					len(voucherRepo.inserted.Toys), len(voucherRepo.inserted.Categories), tt.wantToys, tt.wantCategories)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/notifier"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type IWaitlistService interface {
+	IBaseService[entity.WaitlistEntry]
+	FindByUserID(ctx context.Context, userID string) ([]entity.WaitlistEntry, error)
+	FindByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error)
+	JoinWaitlist(ctx context.Context, userID uuid.UUID, req entity.JoinWaitlistRequest) (*entity.WaitlistEntry, error)
+	ConfirmHold(ctx context.Context, userID string, id string, req entity.ConfirmWaitlistRequest) (*entity.Rental, error)
+	CancelEntry(ctx context.Context, userID string, id string) error
+	ProcessAvailability(ctx context.Context, toyID string) error
+	ExpireHolds(ctx context.Context) error
+	RunExpiryWorker(ctx context.Context, interval time.Duration)
+}
+
+type WaitlistService struct {
+	BaseService[entity.WaitlistEntry]
+	waitlistRepo repository.IWaitlistRepository
+	toyRepo      repository.IToyRepository
+	notifier     notifier.Notifier
+	holdDuration time.Duration
+
+	rentalBuilder rentalBuilder
+}
+
+func NewWaitlistService(
+	repo repository.IWaitlistRepository,
+	userRepo repository.IUserRepository,
+	toyRepo repository.IToyRepository,
+	notif notifier.Notifier,
+	fulfilmentSvc IFulfilmentService,
+	holdDuration time.Duration,
+) IWaitlistService {
+	return &WaitlistService{
+		BaseService:  BaseService[entity.WaitlistEntry]{repository: repo},
+		waitlistRepo: repo,
+		toyRepo:      toyRepo,
+		notifier:     notif,
+		holdDuration: holdDuration,
+
+		rentalBuilder: rentalBuilder{userRepo: userRepo, toyRepo: toyRepo, fulfilmentSvc: fulfilmentSvc},
+	}
+}
+
+func (s *WaitlistService) FindByUserID(ctx context.Context, userID string) ([]entity.WaitlistEntry, error) {
+	return s.waitlistRepo.FindByUserID(ctx, userID)
+}
+
+func (s *WaitlistService) FindByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error) {
+	return s.waitlistRepo.FindByToyID(ctx, toyID)
+}
+
+func (s *WaitlistService) JoinWaitlist(ctx context.Context, userID uuid.UUID, req entity.JoinWaitlistRequest) (*entity.WaitlistEntry, error) {
+	toy, err := s.toyRepo.FindById(ctx, req.ToyID.String())
+	if err != nil {
+		return nil, errors.New("mainan tidak ditemukan: " + req.ToyID.String())
+	}
+
+	if toy.Stock >= req.Quantity {
+		return nil, entity.ErrWaitlistStockAvailable
+	}
+
+	if _, err := s.waitlistRepo.FindActiveByUserAndToy(ctx, userID.String(), req.ToyID.String()); err == nil {
+		return nil, entity.ErrWaitlistAlreadyJoined
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	entry := &entity.WaitlistEntry{
+		UserID:             userID,
+		ToyID:              req.ToyID,
+		Quantity:           req.Quantity,
+		RentalDate:         req.RentalDate,
+		ExpectedReturnDate: req.ExpectedReturnDate,
+		Status:             entity.WaitlistStatusWaiting,
+	}
+	if err := s.waitlistRepo.Insert(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	entry.Toy = toy
+	return entry, nil
+}
+
+func (s *WaitlistService) ConfirmHold(ctx context.Context, userID string, id string, req entity.ConfirmWaitlistRequest) (*entity.Rental, error) {
+	entry, err := s.findOwnedEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Status == entity.WaitlistStatusWaiting {
+		return nil, entity.ErrWaitlistNotHeld
+	}
+	if entry.Status != entity.WaitlistStatusHeld {
+		return nil, entity.ErrWaitlistAlreadyClosed
+	}
+
+	if entry.IsHoldExpired() {
+		s.expireHold(ctx, &entry)
+		return nil, entity.ErrWaitlistHoldExpired
+	}
+
+	rentalReq := entity.CreateRentalRequest{
+		UserID:             entry.UserID,
+		RentalDate:         entry.RentalDate,
+		ExpectedReturnDate: entry.ExpectedReturnDate,
+		Items: []entity.CreateRentalItemRequest{
+			{ToyID: entry.ToyID, Quantity: entry.Quantity},
+		},
+		Fulfilment: req.Fulfilment,
+		Notes:      req.Notes,
+	}
+
+	rental, err := s.rentalBuilder.build(ctx, rentalReq, map[uuid.UUID]int{entry.ToyID: entry.Quantity})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.waitlistRepo.ConfirmHold(ctx, &entry, rental); err != nil {
+		return nil, err
+	}
+
+	return rental, nil
+}
+
+func (s *WaitlistService) CancelEntry(ctx context.Context, userID string, id string) error {
+	entry, err := s.findOwnedEntry(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Status {
+	case entity.WaitlistStatusWaiting:
+		return s.waitlistRepo.UpdateStatus(ctx, id, entity.WaitlistStatusCancelled)
+	case entity.WaitlistStatusHeld:
+		if err := s.waitlistRepo.ReleaseHold(ctx, &entry, entity.WaitlistStatusCancelled); err != nil {
+			return err
+		}
+		s.passToNext(ctx, entry.ToyID.String())
+		return nil
+	default:
+		return entity.ErrWaitlistAlreadyClosed
+	}
+}
+
+// ProcessAvailability memberikan hold ke pelanggan sesuai urutan antrian selama stok mainan masih
+// tersedia. Pelanggan yang tidak bisa dipenuhi tidak dilewati, antrian berhenti sampai stok cukup.
+func (s *WaitlistService) ProcessAvailability(ctx context.Context, toyID string) error {
+	queue, err := s.waitlistRepo.FindWaitingByToyID(ctx, toyID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range queue {
+		entry := &queue[i]
+
+		// Periode rental sudah lewat sehingga entry tidak lagi relevan
+		if !entry.ExpectedReturnDate.After(now) {
+			if err := s.waitlistRepo.UpdateStatus(ctx, entry.ID.String(), entity.WaitlistStatusExpired); err != nil {
+				return err
+			}
+			continue
+		}
+
+		expiresAt := now.Add(s.holdDuration)
+		err := s.waitlistRepo.PlaceHold(ctx, entry, now, expiresAt)
+		if errors.Is(err, repository.ErrInsufficientStock) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		notification := notifier.Notification{
+			Type:    notifier.TypeWaitlistHold,
+			UserID:  entry.UserID,
+			Email:   entry.User.Email,
+			Subject: "Mainan di waitlist Anda sudah ditahan",
+			Message: fmt.Sprintf("%s (%d unit) ditahan untuk Anda hingga %s, segera konfirmasi rental Anda",
+				entry.Toy.Name, entry.Quantity, expiresAt.Format(time.RFC1123)),
+			CreatedAt: now,
+		}
+		if err := s.notifier.Notify(ctx, notification); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to notify waitlist hold %s: %v", entry.ID, err))
+		}
+	}
+
+	return nil
+}
+
+// ExpireHolds menutup hold yang melewati batas waktu dan meneruskan stok ke antrian berikutnya
+func (s *WaitlistService) ExpireHolds(ctx context.Context) error {
+	entries, err := s.waitlistRepo.FindExpiredHolds(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range entries {
+		s.expireHold(ctx, &entries[i])
+	}
+
+	return nil
+}
+
+// RunExpiryWorker menjalankan ExpireHolds secara berkala sampai context dibatalkan
+func (s *WaitlistService) RunExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireHolds(ctx); err != nil {
+				helpers.Logger.Error("Failed to expire waitlist holds: ", err)
+			}
+		}
+	}
+}
+
+func (s *WaitlistService) expireHold(ctx context.Context, entry *entity.WaitlistEntry) {
+	if err := s.waitlistRepo.ReleaseHold(ctx, entry, entity.WaitlistStatusExpired); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			helpers.Logger.Error(fmt.Errorf("failed to expire waitlist hold %s: %v", entry.ID, err))
+		}
+		return
+	}
+
+	s.passToNext(ctx, entry.ToyID.String())
+}
+
+func (s *WaitlistService) passToNext(ctx context.Context, toyID string) {
+	if err := s.ProcessAvailability(ctx, toyID); err != nil {
+		helpers.Logger.Error(fmt.Errorf("failed to process waitlist for toy %s: %v", toyID, err))
+	}
+}
+
+func (s *WaitlistService) findOwnedEntry(ctx context.Context, userID string, id string) (entity.WaitlistEntry, error) {
+	entry, err := s.waitlistRepo.FindById(ctx, id)
+	if err != nil {
+		return entry, err
+	}
+
+	if entry.UserID.String() != userID {
+		return entity.WaitlistEntry{}, gorm.ErrRecordNotFound
+	}
+
+	return entry, nil
+}
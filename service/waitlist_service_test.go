@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/notifier"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type fakeWaitlistRepo struct {
+	repository.IWaitlistRepository
+	entries   []entity.WaitlistEntry
+	stock     map[uuid.UUID]int
+	inserted  []entity.WaitlistEntry
+	confirmed *entity.Rental
+}
+
+func (r *fakeWaitlistRepo) FindById(ctx context.Context, id string) (entity.WaitlistEntry, error) {
+	for _, entry := range r.entries {
+		if entry.ID.String() == id {
+			return entry, nil
+		}
+	}
+	return entity.WaitlistEntry{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWaitlistRepo) FindWaitingByToyID(ctx context.Context, toyID string) ([]entity.WaitlistEntry, error) {
+	var entries []entity.WaitlistEntry
+	for _, entry := range r.entries {
+		if entry.ToyID.String() == toyID && entry.Status == entity.WaitlistStatusWaiting {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *fakeWaitlistRepo) FindActiveByUserAndToy(ctx context.Context, userID string, toyID string) (entity.WaitlistEntry, error) {
+	for _, entry := range r.entries {
+		if entry.UserID.String() == userID && entry.ToyID.String() == toyID &&
+			(entry.Status == entity.WaitlistStatusWaiting || entry.Status == entity.WaitlistStatusHeld) {
+			return entry, nil
+		}
+	}
+	return entity.WaitlistEntry{}, gorm.ErrRecordNotFound
+}
+
+func (r *fakeWaitlistRepo) Insert(ctx context.Context, entry *entity.WaitlistEntry) error {
+	r.inserted = append(r.inserted, *entry)
+	return nil
+}
+
+func (r *fakeWaitlistRepo) PlaceHold(ctx context.Context, entry *entity.WaitlistEntry, heldAt time.Time, expiresAt time.Time) error {
+	if r.stock[entry.ToyID] < entry.Quantity {
+		return repository.ErrInsufficientStock
+	}
+	r.stock[entry.ToyID] -= entry.Quantity
+	r.setStatus(entry.ID.String(), entity.WaitlistStatusHeld)
+	return nil
+}
+
+func (r *fakeWaitlistRepo) UpdateStatus(ctx context.Context, id string, status string) error {
+	r.setStatus(id, status)
+	return nil
+}
+
+func (r *fakeWaitlistRepo) ConfirmHold(ctx context.Context, entry *entity.WaitlistEntry, rental *entity.Rental) error {
+	r.confirmed = rental
+	return nil
+}
+
+func (r *fakeWaitlistRepo) setStatus(id string, status string) {
+	for i := range r.entries {
+		if r.entries[i].ID.String() == id {
+			r.entries[i].Status = status
+		}
+	}
+}
+
+func (r *fakeWaitlistRepo) statuses() []string {
+	statuses := make([]string, len(r.entries))
+	for i, entry := range r.entries {
+		statuses[i] = entry.Status
+	}
+	return statuses
+}
+
+type fakeUserRepo struct {
+	repository.IUserRepository
+	users map[string]entity.User
+}
+
+func (r *fakeUserRepo) FindById(ctx context.Context, id string) (entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return user, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+type fakeFulfilmentService struct {
+	IFulfilmentService
+}
+
+func (s fakeFulfilmentService) Plan(ctx context.Context, rental *entity.Rental, req *entity.CreateFulfilmentRequest) error {
+	return nil
+}
+
+func newWaitlistEntry(toyID uuid.UUID, quantity int, returnDate time.Time) entity.WaitlistEntry {
+	return entity.WaitlistEntry{
+		BaseEntity:         entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+		UserID:             uuid.Must(uuid.NewV7()),
+		ToyID:              toyID,
+		Quantity:           quantity,
+		RentalDate:         returnDate.AddDate(0, 0, -3),
+		ExpectedReturnDate: returnDate,
+		Status:             entity.WaitlistStatusWaiting,
+	}
+}
+
+func TestWaitlistService_ProcessAvailability(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	future := time.Now().AddDate(0, 0, 7)
+	past := time.Now().AddDate(0, 0, -1)
+
+	tests := []struct {
+		name         string
+		stock        int
+		entries      []entity.WaitlistEntry
+		wantStatuses []string
+		wantStock    int
+	}{
+		{
+			name:  "hold diberikan sesuai urutan selama stok cukup",
+			stock: 3,
+			entries: []entity.WaitlistEntry{
+				newWaitlistEntry(toyID, 1, future),
+				newWaitlistEntry(toyID, 2, future),
+				newWaitlistEntry(toyID, 1, future),
+			},
+			wantStatuses: []string{entity.WaitlistStatusHeld, entity.WaitlistStatusHeld, entity.WaitlistStatusWaiting},
+			wantStock:    0,
+		},
+		{
+			name:  "antrian berhenti di entry pertama yang tidak bisa dipenuhi",
+			stock: 2,
+			entries: []entity.WaitlistEntry{
+				newWaitlistEntry(toyID, 3, future),
+				newWaitlistEntry(toyID, 1, future),
+			},
+			wantStatuses: []string{entity.WaitlistStatusWaiting, entity.WaitlistStatusWaiting},
+			wantStock:    2,
+		},
+		{
+			name:  "entry dengan periode rental yang sudah lewat dikadaluarsakan",
+			stock: 1,
+			entries: []entity.WaitlistEntry{
+				newWaitlistEntry(toyID, 1, past),
+				newWaitlistEntry(toyID, 1, future),
+			},
+			wantStatuses: []string{entity.WaitlistStatusExpired, entity.WaitlistStatusHeld},
+			wantStock:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWaitlistRepo{entries: tt.entries, stock: map[uuid.UUID]int{toyID: tt.stock}}
+			notif := notifier.NewFileNotifier(filepath.Join(t.TempDir(), "notifications.log"))
+			svc := NewWaitlistService(repo, nil, nil, notif, nil, time.Hour)
+
+			if err := svc.ProcessAvailability(context.Background(), toyID.String()); err != nil {
+				t.Fatalf("ProcessAvailability() error = %v", err)
+			}
+
+			statuses := repo.statuses()
+			for i := range tt.wantStatuses {
+				if statuses[i] != tt.wantStatuses[i] {
+					t.Errorf("entry %d status = %s, want %s", i, statuses[i], tt.wantStatuses[i])
+				}
+			}
+			if repo.stock[toyID] != tt.wantStock {
+				t.Errorf("stock = %d, want %d", repo.stock[toyID], tt.wantStock)
+			}
+		})
+	}
+}
+
+func TestWaitlistService_JoinWaitlist(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	future := time.Now().AddDate(0, 0, 7)
+	existing := newWaitlistEntry(toyID, 1, future)
+
+	tests := []struct {
+		name         string
+		userID       uuid.UUID
+		stock        int
+		entryStatus  string
+		wantErr      error
+		wantInserted bool
+	}{
+		{
+			name:         "bergabung ke waitlist",
+			userID:       uuid.Must(uuid.NewV7()),
+			wantInserted: true,
+		},
+		{
+			name:    "stok masih tersedia",
+			userID:  uuid.Must(uuid.NewV7()),
+			stock:   1,
+			wantErr: entity.ErrWaitlistStockAvailable,
+		},
+		{
+			name:        "sudah menunggu di waitlist yang sama",
+			userID:      existing.UserID,
+			entryStatus: entity.WaitlistStatusWaiting,
+			wantErr:     entity.ErrWaitlistAlreadyJoined,
+		},
+		{
+			name:        "sudah mendapatkan hold di waitlist yang sama",
+			userID:      existing.UserID,
+			entryStatus: entity.WaitlistStatusHeld,
+			wantErr:     entity.ErrWaitlistAlreadyJoined,
+		},
+		{
+			name:         "entry sebelumnya sudah dibatalkan",
+			userID:       existing.UserID,
+			entryStatus:  entity.WaitlistStatusCancelled,
+			wantInserted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := existing
+			entry.Status = tt.entryStatus
+			repo := &fakeWaitlistRepo{entries: []entity.WaitlistEntry{entry}}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{toyID.String(): {BaseEntity: entity.BaseEntity{ID: toyID}, Stock: tt.stock}}}
+			svc := NewWaitlistService(repo, nil, toyRepo, nil, nil, time.Hour)
+
+			_, err := svc.JoinWaitlist(context.Background(), tt.userID, entity.JoinWaitlistRequest{
+				ToyID:              toyID,
+				Quantity:           1,
+				RentalDate:         future.AddDate(0, 0, -3),
+				ExpectedReturnDate: future,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("JoinWaitlist() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(repo.inserted) == 1; got != tt.wantInserted {
+				t.Errorf("inserted = %v, want %v", got, tt.wantInserted)
+			}
+		})
+	}
+}
+
+func TestWaitlistService_ConfirmHold(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	future := time.Now().AddDate(0, 0, 7)
+	verifiedAt := time.Now().Add(-time.Hour)
+	holdExpiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		emailVerified bool
+		status        string
+		wantErr       error
+	}{
+		{
+			name:          "hold dikonfirmasi menjadi rental",
+			emailVerified: true,
+			status:        entity.WaitlistStatusHeld,
+		},
+		{
+			name:    "email belum diverifikasi",
+			status:  entity.WaitlistStatusHeld,
+			wantErr: entity.ErrEmailNotVerified,
+		},
+		{
+			name:          "entry belum mendapatkan hold",
+			emailVerified: true,
+			status:        entity.WaitlistStatusWaiting,
+			wantErr:       entity.ErrWaitlistNotHeld,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newWaitlistEntry(toyID, 2, future)
+			entry.Status = tt.status
+			entry.HoldExpiresAt = &holdExpiresAt
+
+			user := entity.User{BaseEntity: entity.BaseEntity{ID: entry.UserID}}
+			if tt.emailVerified {
+				user.EmailVerifiedAt = &verifiedAt
+			}
+
+			// Stok sudah dipotong oleh hold sehingga tersisa 0
+			toy := entity.Toy{BaseEntity: entity.BaseEntity{ID: toyID}, Name: "Balok", Condition: "good", RentalPrice: 10000}
+			repo := &fakeWaitlistRepo{entries: []entity.WaitlistEntry{entry}}
+			userRepo := &fakeUserRepo{users: map[string]entity.User{entry.UserID.String(): user}}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{toyID.String(): toy}}
+			svc := NewWaitlistService(repo, userRepo, toyRepo, nil, fakeFulfilmentService{}, time.Hour)
+
+			rental, err := svc.ConfirmHold(context.Background(), entry.UserID.String(), entry.ID.String(), entity.ConfirmWaitlistRequest{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ConfirmHold() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.confirmed != nil {
+					t.Error("rental saved although confirmation failed")
+				}
+				return
+			}
+
+			if repo.confirmed != rental || len(rental.RentalItems) != 1 || rental.RentalItems[0].Quantity != entry.Quantity {
+				t.Fatalf("confirmed rental = %+v, want one item of %d units", repo.confirmed, entry.Quantity)
+			}
+			if rental.TotalRentalPrice != 20000 {
+				t.Errorf("total rental price = %v, want 20000", rental.TotalRentalPrice)
+			}
+		})
+	}
+}
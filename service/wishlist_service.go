@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/helpers"
+	"final-project/utils/notifier"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"gorm.io/gorm"
+)
+
+type IWishlistService interface {
+	IBaseService[entity.Wishlist]
+	FindByUserID(ctx context.Context, userID string) ([]entity.Wishlist, error)
+	AddToWishlist(ctx context.Context, userID uuid.UUID, req entity.AddWishlistRequest) (*entity.Wishlist, error)
+	UpdateNotifyMe(ctx context.Context, userID string, toyID string, notifyMe bool) (*entity.Wishlist, error)
+	RemoveFromWishlist(ctx context.Context, userID string, toyID string) error
+	NotifyBackInStock(ctx context.Context, toyID string) error
+}
+
+type WishlistService struct {
+	BaseService[entity.Wishlist]
+	wishlistRepo repository.IWishlistRepository
+	toyRepo      repository.IToyRepository
+	notifier     notifier.Notifier
+}
+
+func NewWishlistService(
+	repo repository.IWishlistRepository,
+	toyRepo repository.IToyRepository,
+	notif notifier.Notifier,
+) IWishlistService {
+	return &WishlistService{
+		BaseService:  BaseService[entity.Wishlist]{repository: repo},
+		wishlistRepo: repo,
+		toyRepo:      toyRepo,
+		notifier:     notif,
+	}
+}
+
+func (s *WishlistService) FindByUserID(ctx context.Context, userID string) ([]entity.Wishlist, error) {
+	return s.wishlistRepo.FindByUserID(ctx, userID)
+}
+
+func (s *WishlistService) AddToWishlist(ctx context.Context, userID uuid.UUID, req entity.AddWishlistRequest) (*entity.Wishlist, error) {
+	toy, err := s.toyRepo.FindById(ctx, req.ToyID.String())
+	if err != nil {
+		return nil, errors.New("mainan tidak ditemukan: " + req.ToyID.String())
+	}
+
+	wishlist, err := s.wishlistRepo.FindByUserAndToy(ctx, userID.String(), req.ToyID.String())
+	if err == nil {
+		if wishlist.NotifyMe != req.NotifyMe {
+			if err := s.wishlistRepo.UpdateNotifyMe(ctx, wishlist.ID.String(), req.NotifyMe); err != nil {
+				return nil, err
+			}
+			wishlist.NotifyMe = req.NotifyMe
+			wishlist.LastNotifiedAt = nil
+		}
+		wishlist.Toy = toy
+		return &wishlist, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	wishlist = entity.Wishlist{
+		UserID:   userID,
+		ToyID:    req.ToyID,
+		NotifyMe: req.NotifyMe,
+	}
+	if err := s.wishlistRepo.Insert(ctx, &wishlist); err != nil {
+		return nil, err
+	}
+
+	wishlist.Toy = toy
+	return &wishlist, nil
+}
+
+func (s *WishlistService) UpdateNotifyMe(ctx context.Context, userID string, toyID string, notifyMe bool) (*entity.Wishlist, error) {
+	wishlist, err := s.wishlistRepo.FindByUserAndToy(ctx, userID, toyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reset waktu notifikasi agar pengguna mendapat notifikasi lagi saat stok kembali
+	if err := s.wishlistRepo.UpdateNotifyMe(ctx, wishlist.ID.String(), notifyMe); err != nil {
+		return nil, err
+	}
+
+	wishlist.NotifyMe = notifyMe
+	wishlist.LastNotifiedAt = nil
+	return &wishlist, nil
+}
+
+func (s *WishlistService) RemoveFromWishlist(ctx context.Context, userID string, toyID string) error {
+	return s.wishlistRepo.DeleteByUserAndToy(ctx, userID, toyID)
+}
+
+// NotifyBackInStock mengirim notifikasi ke pelanggan yang menunggu mainan tersedia kembali
+func (s *WishlistService) NotifyBackInStock(ctx context.Context, toyID string) error {
+	toy, err := s.toyRepo.FindById(ctx, toyID)
+	if err != nil {
+		return err
+	}
+
+	if toy.Stock <= 0 || !toy.IsAvailable {
+		return nil
+	}
+
+	subscribers, err := s.wishlistRepo.FindSubscribersByToyID(ctx, toyID)
+	if err != nil {
+		return err
+	}
+
+	var notifiedIDs []string
+	for _, subscriber := range subscribers {
+		notification := notifier.Notification{
+			Type:      notifier.TypeBackInStock,
+			UserID:    subscriber.UserID,
+			Email:     subscriber.User.Email,
+			Subject:   "Mainan yang Anda tunggu tersedia kembali",
+			Message:   fmt.Sprintf("%s kini tersedia kembali dengan stok %d unit", toy.Name, toy.Stock),
+			CreatedAt: time.Now(),
+		}
+
+		if err := s.notifier.Notify(ctx, notification); err != nil {
+			helpers.Logger.Error(fmt.Errorf("failed to notify user %s for toy %s: %v", subscriber.UserID, toyID, err))
+			continue
+		}
+
+		notifiedIDs = append(notifiedIDs, subscriber.ID.String())
+	}
+
+	return s.wishlistRepo.MarkNotified(ctx, notifiedIDs, time.Now())
+}
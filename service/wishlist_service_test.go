@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"final-project/entity"
+	"final-project/repository"
+	"final-project/utils/notifier"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type fakeWishlistRepo struct {
+	repository.IWishlistRepository
+	subscribers []entity.Wishlist
+	notified    []string
+}
+
+func (r *fakeWishlistRepo) FindSubscribersByToyID(ctx context.Context, toyID string) ([]entity.Wishlist, error) {
+	var subscribers []entity.Wishlist
+	for _, subscriber := range r.subscribers {
+		if subscriber.ToyID.String() == toyID && subscriber.NotifyMe && subscriber.LastNotifiedAt == nil {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	return subscribers, nil
+}
+
+func (r *fakeWishlistRepo) MarkNotified(ctx context.Context, ids []string, notifiedAt time.Time) error {
+	r.notified = append(r.notified, ids...)
+	for i := range r.subscribers {
+		for _, id := range ids {
+			if r.subscribers[i].ID.String() == id {
+				r.subscribers[i].LastNotifiedAt = &notifiedAt
+			}
+		}
+	}
+	return nil
+}
+
+type fakeToyRepo struct {
+	repository.IToyRepository
+	toys map[string]entity.Toy
+}
+
+func (r *fakeToyRepo) FindById(ctx context.Context, id string) (entity.Toy, error) {
+	return r.toys[id], nil
+}
+
+func readNotifications(t *testing.T, path string) []notifier.Notification {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var notifications []notifier.Notification
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var notification notifier.Notification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			t.Fatal(err)
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+func TestWishlistService_NotifyBackInStock(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	notifiedAt := time.Now().Add(-time.Hour)
+
+	subscriber := func(email string, notifyMe bool, lastNotifiedAt *time.Time) entity.Wishlist {
+		return entity.Wishlist{
+			BaseEntity:     entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+			UserID:         uuid.Must(uuid.NewV7()),
+			ToyID:          toyID,
+			NotifyMe:       notifyMe,
+			LastNotifiedAt: lastNotifiedAt,
+			User:           entity.User{Email: email},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		toy         entity.Toy
+		subscribers []entity.Wishlist
+		wantEmails  []string
+	}{
+		{
+			name:        "stok habis tidak mengirim notifikasi",
+			toy:         entity.Toy{Name: "Balok", Stock: 0, IsAvailable: true},
+			subscribers: []entity.Wishlist{subscriber("a@test.com", true, nil)},
+		},
+		{
+			name:        "mainan tidak tersedia tidak mengirim notifikasi",
+			toy:         entity.Toy{Name: "Balok", Stock: 3, IsAvailable: false},
+			subscribers: []entity.Wishlist{subscriber("a@test.com", true, nil)},
+		},
+		{
+			name: "hanya pelanggan notify me yang belum dinotifikasi",
+			toy:  entity.Toy{Name: "Balok", Stock: 2, IsAvailable: true},
+			subscribers: []entity.Wishlist{
+				subscriber("a@test.com", true, nil),
+				subscriber("b@test.com", false, nil),
+				subscriber("c@test.com", true, &notifiedAt),
+				subscriber("d@test.com", true, nil),
+			},
+			wantEmails: []string{"a@test.com", "d@test.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifications.log")
+			tt.toy.ID = toyID
+			wishlistRepo := &fakeWishlistRepo{subscribers: tt.subscribers}
+			toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{toyID.String(): tt.toy}}
+			svc := NewWishlistService(wishlistRepo, toyRepo, notifier.NewFileNotifier(path))
+
+			if err := svc.NotifyBackInStock(context.Background(), toyID.String()); err != nil {
+				t.Fatalf("NotifyBackInStock() error = %v", err)
+			}
+
+			notifications := readNotifications(t, path)
+			if len(notifications) != len(tt.wantEmails) {
+				t.Fatalf("got %d notifications, want %d", len(notifications), len(tt.wantEmails))
+			}
+			for i, notification := range notifications {
+				if notification.Email != tt.wantEmails[i] || notification.Type != notifier.TypeBackInStock {
+					t.Errorf("notification %d = %s %s, want %s %s", i, notification.Type, notification.Email, notifier.TypeBackInStock, tt.wantEmails[i])
+				}
+			}
+			if len(wishlistRepo.notified) != len(tt.wantEmails) {
+				t.Errorf("marked %d wishlists as notified, want %d", len(wishlistRepo.notified), len(tt.wantEmails))
+			}
+		})
+	}
+}
+
+// Stok yang habis lalu kembali harus menotifikasi pelanggan yang sama sekali lagi. Repository
+// mengosongkan last_notified_at saat stok mencapai 0, di sini disimulasikan secara langsung.
+func TestWishlistService_NotifyBackInStock_AfterStockRunsOutAgain(t *testing.T) {
+	toyID := uuid.Must(uuid.NewV7())
+	path := filepath.Join(t.TempDir(), "notifications.log")
+
+	wishlistRepo := &fakeWishlistRepo{subscribers: []entity.Wishlist{{
+		BaseEntity: entity.BaseEntity{ID: uuid.Must(uuid.NewV7())},
+		UserID:     uuid.Must(uuid.NewV7()),
+		ToyID:      toyID,
+		NotifyMe:   true,
+		User:       entity.User{Email: "a@test.com"},
+	}}}
+	toyRepo := &fakeToyRepo{toys: map[string]entity.Toy{toyID.String(): {Name: "Balok", Stock: 1, IsAvailable: true}}}
+	svc := NewWishlistService(wishlistRepo, toyRepo, notifier.NewFileNotifier(path))
+
+	ctx := context.Background()
+	if err := svc.NotifyBackInStock(ctx, toyID.String()); err != nil {
+		t.Fatal(err)
+	}
+	// Restock kedua tanpa stok habis di antaranya tidak mengirim ulang
+	if err := svc.NotifyBackInStock(ctx, toyID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(readNotifications(t, path)); got != 1 {
+		t.Fatalf("got %d notifications before stock ran out, want 1", got)
+	}
+
+	wishlistRepo.subscribers[0].LastNotifiedAt = nil
+	if err := svc.NotifyBackInStock(ctx, toyID.String()); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(readNotifications(t, path)); got != 2 {
+		t.Fatalf("got %d notifications after stock came back, want 2", got)
+	}
+}
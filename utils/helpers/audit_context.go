@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type auditActorKey struct{}
+
+// AuditActor adalah informasi pelaku request yang dibawa lewat context sampai ke callback
+// audit database. UserID kosong untuk request tanpa login atau proses background.
+type AuditActor struct {
+	UserID         *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	APIKeyID       *uuid.UUID
+	IPAddress      string
+	UserAgent      string
+	RequestID      string
+}
+
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func AuditActorFromContext(ctx context.Context) (AuditActor, bool) {
+	if ctx == nil {
+		return AuditActor{}, false
+	}
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
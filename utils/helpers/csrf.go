@@ -0,0 +1,25 @@
+package helpers
+
+import (
+	"crypto/subtle"
+)
+
+const (
+	// CSRFCookieName sengaja tidak HttpOnly agar frontend bisa membacanya dan mengirim ulang di header
+	CSRFCookieName = "csrf_token"
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// GenerateCSRFToken membuat token acak untuk pola double-submit cookie
+func GenerateCSRFToken() (string, error) {
+	token, _, err := GenerateSecureToken()
+	return token, err
+}
+
+// CSRFTokenMatches membandingkan token dari header dan cookie dalam waktu konstan
+func CSRFTokenMatches(headerToken string, cookieToken string) bool {
+	if headerToken == "" || cookieToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(headerToken), []byte(cookieToken)) == 1
+}
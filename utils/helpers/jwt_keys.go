@@ -0,0 +1,193 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+const (
+	SigningAlgHS256 = "HS256"
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+)
+
+var ErrSigningKeyNotFound = errors.New("signing key tidak ditemukan")
+
+// SigningKey adalah key asimetris yang dipakai untuk menandatangani atau memverifikasi token
+type SigningKey struct {
+	Kid        string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// JWK adalah representasi public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet menyimpan key yang sedang berlaku. Isinya diganti secara utuh oleh proses rotasi
+// sehingga JWTHelper yang disalin tetap melihat key yang sama.
+type KeySet struct {
+	mu         sync.RWMutex
+	signingKid string
+	keys       map[string]SigningKey
+	order      []string
+}
+
+func NewKeySet() *KeySet {
+	return &KeySet{keys: make(map[string]SigningKey)}
+}
+
+// Replace mengganti seluruh key. signingKid adalah key yang dipakai untuk token baru,
+// key lain hanya dipakai untuk verifikasi.
+func (s *KeySet) Replace(signingKid string, keys []SigningKey) {
+	keyMap := make(map[string]SigningKey, len(keys))
+	order := make([]string, 0, len(keys))
+	for _, key := range keys {
+		keyMap[key.Kid] = key
+		order = append(order, key.Kid)
+	}
+
+	s.mu.Lock()
+	s.signingKid = signingKid
+	s.keys = keyMap
+	s.order = order
+	s.mu.Unlock()
+}
+
+func (s *KeySet) Signing() (SigningKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[s.signingKid]
+	if !ok || key.PrivateKey == nil {
+		return SigningKey{}, ErrSigningKeyNotFound
+	}
+	return key, nil
+}
+
+func (s *KeySet) Lookup(kid string) (SigningKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// JWKS mengembalikan public key yang masih berlaku, termasuk key yang belum aktif
+// agar layanan lain sudah mengenalnya sebelum dipakai
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.order))}
+	for _, kid := range s.order {
+		if jwk, err := s.keys[kid].JWK(); err == nil {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	return jwks
+}
+
+func (k SigningKey) JWK() (JWK, error) {
+	switch pub := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: k.Algorithm,
+			Kid: k.Kid,
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: k.Algorithm,
+			Kid: k.Kid,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(pub),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("tipe public key %T tidak didukung", k.PublicKey)
+	}
+}
+
+// GenerateSigningKeyPEM membuat private key baru untuk algoritma yang diminta dalam format PKCS#8 PEM
+func GenerateSigningKeyPEM(algorithm string) (string, error) {
+	var privateKey crypto.PrivateKey
+	switch algorithm {
+	case SigningAlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return "", err
+		}
+		privateKey = key
+	case SigningAlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", err
+		}
+		privateKey = key
+	default:
+		return "", fmt.Errorf("algoritma %s tidak didukung", algorithm)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// ParseSigningKeyPEM membaca private key PKCS#8 PEM dan memastikan tipenya sesuai algoritma
+func ParseSigningKeyPEM(kid string, algorithm string, privateKeyPEM string) (SigningKey, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("private key %s bukan PEM yang valid", kid)
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return SigningKey{}, err
+	}
+
+	key := SigningKey{Kid: kid, Algorithm: algorithm, PrivateKey: parsed}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != SigningAlgRS256 {
+			return SigningKey{}, fmt.Errorf("key %s bukan key %s", kid, algorithm)
+		}
+		key.PublicKey = &private.PublicKey
+	case ed25519.PrivateKey:
+		if algorithm != SigningAlgEdDSA {
+			return SigningKey{}, fmt.Errorf("key %s bukan key %s", kid, algorithm)
+		}
+		key.PublicKey = private.Public()
+	default:
+		return SigningKey{}, fmt.Errorf("tipe private key %T tidak didukung", parsed)
+	}
+	return key, nil
+}
@@ -13,40 +13,83 @@ var (
 	ErrExpiredToken      = errors.New("token telah kadaluarsa")
 	ErrTokenNotProvided  = errors.New("token tidak ditemukan")
 	ErrInvalidSignMethod = errors.New("metode signing tidak valid")
+	ErrInvalidTokenType  = errors.New("tipe token tidak valid")
+)
+
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeRefresh    = "refresh"
+	TokenTypeMFAPending = "mfa_pending"
 )
 
 type ClaimsToken struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Role   string    `json:"role"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenType string    `json:"token_type,omitempty"`
+	// ImpersonatorID adalah admin yang memakai token ini atas nama UserID
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// IsImpersonation menandakan token dipakai admin atas nama user lain
+func (c *ClaimsToken) IsImpersonation() bool {
+	return c.ImpersonatorID != nil
+}
+
+// JWTHelper menandatangani token dengan key asimetris dari KeySet (RS256 atau EdDSA) atau dengan
+// secret HS256. Token HS256 lama tetap bisa diverifikasi selama acceptHS256 aktif.
 type JWTHelper struct {
 	jwtSecret          string
+	algorithm          string
+	acceptHS256        bool
+	keys               *KeySet
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
 	issuer             string
 }
 
-func NewJWTHelper(jwtSecret string, accessTokenExp int, refreshTokenExp int, issuer string) *JWTHelper {
+func NewJWTHelper(
+	jwtSecret string,
+	algorithm string,
+	acceptHS256 bool,
+	keys *KeySet,
+	accessTokenExp int,
+	refreshTokenExp int,
+	issuer string,
+) *JWTHelper {
 	return &JWTHelper{
 		jwtSecret:          jwtSecret,
+		algorithm:          algorithm,
+		acceptHS256:        acceptHS256 || algorithm == SigningAlgHS256,
+		keys:               keys,
 		accessTokenExpiry:  time.Duration(accessTokenExp*24) * time.Hour,
 		refreshTokenExpiry: time.Duration(refreshTokenExp*24) * time.Hour,
 		issuer:             issuer,
 	}
 }
 
+// RefreshTokenExpiry adalah umur token terpanjang, dipakai untuk menentukan kapan key lama boleh dibuang
+func (j *JWTHelper) RefreshTokenExpiry() time.Duration {
+	return j.refreshTokenExpiry
+}
+
+// JWKS mengembalikan public key untuk dipublikasikan ke layanan lain
+func (j *JWTHelper) JWKS() JWKS {
+	return j.keys.JWKS()
+}
+
 // GenerateAccessToken membuat token akses baru
 func (j *JWTHelper) GenerateAccessToken(userID uuid.UUID, email, role string) (string, time.Time, error) {
 	expiryTime := time.Now().Add(j.accessTokenExpiry)
 
 	claims := &ClaimsToken{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			ExpiresAt: jwt.NewNumericDate(expiryTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -55,8 +98,7 @@ func (j *JWTHelper) GenerateAccessToken(userID uuid.UUID, email, role string) (s
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(j.jwtSecret))
+	signedToken, err := j.sign(claims)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("gagal membuat access token: %w", err)
 	}
@@ -64,13 +106,43 @@ func (j *JWTHelper) GenerateAccessToken(userID uuid.UUID, email, role string) (s
 	return signedToken, expiryTime, nil
 }
 
+// GenerateImpersonationToken membuat access token berumur pendek untuk userID yang dipakai oleh admin impersonatorID
+func (j *JWTHelper) GenerateImpersonationToken(userID uuid.UUID, email, role string, impersonatorID uuid.UUID, ttl time.Duration) (string, time.Time, error) {
+	expiryTime := time.Now().Add(ttl)
+
+	claims := &ClaimsToken{
+		UserID:         userID,
+		Email:          email,
+		Role:           role,
+		TokenType:      TokenTypeAccess,
+		ImpersonatorID: &impersonatorID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(expiryTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    j.issuer,
+			Subject:   userID.String(),
+		},
+	}
+
+	signedToken, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("gagal membuat impersonation token: %w", err)
+	}
+
+	return signedToken, expiryTime, nil
+}
+
 // GenerateRefreshToken membuat token refresh baru
 func (j *JWTHelper) GenerateRefreshToken(userID uuid.UUID) (string, time.Time, error) {
 	expiryTime := time.Now().Add(j.refreshTokenExpiry)
 
 	claims := &ClaimsToken{
-		UserID: userID,
+		UserID:    userID,
+		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
 			ExpiresAt: jwt.NewNumericDate(expiryTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -79,8 +151,7 @@ func (j *JWTHelper) GenerateRefreshToken(userID uuid.UUID) (string, time.Time, e
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(j.jwtSecret))
+	signedToken, err := j.sign(claims)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("gagal membuat refresh token: %w", err)
 	}
@@ -88,19 +159,81 @@ func (j *JWTHelper) GenerateRefreshToken(userID uuid.UUID) (string, time.Time, e
 	return signedToken, expiryTime, nil
 }
 
+// GenerateMFAToken membuat token berumur pendek yang menandakan password sudah benar
+// tetapi verifikasi two-factor belum dilakukan
+func (j *JWTHelper) GenerateMFAToken(userID uuid.UUID, ttl time.Duration) (string, time.Time, error) {
+	expiryTime := time.Now().Add(ttl)
+
+	claims := &ClaimsToken{
+		UserID:    userID,
+		TokenType: TokenTypeMFAPending,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(expiryTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    j.issuer,
+			Subject:   userID.String(),
+		},
+	}
+
+	signedToken, err := j.sign(claims)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("gagal membuat mfa token: %w", err)
+	}
+
+	return signedToken, expiryTime, nil
+}
+
 // ValidateAccessToken memvalidasi token akses
 func (j *JWTHelper) ValidateAccessToken(tokenString string) (*ClaimsToken, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Token lama tanpa token_type tetap diterima sebagai access token
+	if claims.TokenType != "" && claims.TokenType != TokenTypeAccess {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// ValidateRefreshToken memvalidasi signature, masa berlaku, dan tipe token refresh
+func (j *JWTHelper) ValidateRefreshToken(tokenString string) (*ClaimsToken, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// ValidateMFAToken memvalidasi token mfa pending
+func (j *JWTHelper) ValidateMFAToken(tokenString string) (*ClaimsToken, error) {
+	claims, err := j.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != TokenTypeMFAPending {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+func (j *JWTHelper) parseToken(tokenString string) (*ClaimsToken, error) {
 	if tokenString == "" {
 		return nil, ErrTokenNotProvided
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, &ClaimsToken{}, func(token *jwt.Token) (interface{}, error) {
-		// Validasi metode signing
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidSignMethod
-		}
-		return []byte(j.jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &ClaimsToken{}, j.verificationKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -121,23 +254,61 @@ func (j *JWTHelper) ValidateAccessToken(tokenString string) (*ClaimsToken, error
 	return claims, nil
 }
 
-// Ekstrak token claims
-func (j *JWTHelper) ExtractTokenClaims(tokenString string) (*ClaimsToken, error) {
-	if tokenString == "" {
-		return nil, ErrTokenNotProvided
+// sign menandatangani claims dengan algoritma yang dikonfigurasi. Token asimetris membawa kid
+// agar verifikasi tetap berjalan setelah key dirotasi.
+func (j *JWTHelper) sign(claims *ClaimsToken) (string, error) {
+	if j.algorithm == SigningAlgHS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(j.jwtSecret))
 	}
 
-	// Parse token tanpa validasi
-	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, &ClaimsToken{})
+	key, err := j.keys.Signing()
 	if err != nil {
-		return nil, fmt.Errorf("gagal parse token: %w", err)
+		return "", err
 	}
 
-	// Ekstrak claims
-	claims, ok := token.Claims.(*ClaimsToken)
-	if !ok {
-		return nil, fmt.Errorf("gagal ekstrak claims")
+	var method jwt.SigningMethod
+	switch key.Algorithm {
+	case SigningAlgRS256:
+		method = jwt.SigningMethodRS256
+	case SigningAlgEdDSA:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return "", ErrInvalidSignMethod
 	}
 
-	return claims, nil
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.Kid
+	return token.SignedString(key.PrivateKey)
+}
+
+// verificationKey memilih key berdasarkan algoritma dan kid di header token
+func (j *JWTHelper) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if !j.acceptHS256 || token.Method.Alg() != SigningAlgHS256 {
+			return nil, ErrInvalidSignMethod
+		}
+		return []byte(j.jwtSecret), nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keys.Lookup(kid)
+		if !ok {
+			return nil, ErrSigningKeyNotFound
+		}
+		if key.Algorithm != token.Method.Alg() {
+			return nil, ErrInvalidSignMethod
+		}
+		return key.PublicKey, nil
+	default:
+		return nil, ErrInvalidSignMethod
+	}
+}
+
+// newTokenID membuat jti unik agar token yang dibuat pada detik yang sama tetap berbeda
+func newTokenID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.Must(uuid.NewV4()).String()
+	}
+	return id.String()
 }
@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestSigningKey(t *testing.T, kid string, algorithm string) SigningKey {
+	t.Helper()
+
+	privateKeyPEM, err := GenerateSigningKeyPEM(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseSigningKeyPEM(kid, algorithm, privateKeyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func tokenKid(t *testing.T, tokenString string) string {
+	t.Helper()
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &ClaimsToken{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTHelper_SigningKeyRotation(t *testing.T) {
+	oldKey := newTestSigningKey(t, "key-lama", SigningAlgEdDSA)
+	newKey := newTestSigningKey(t, "key-baru", SigningAlgEdDSA)
+	userID := uuid.Must(uuid.NewV7())
+
+	keys := NewKeySet()
+	keys.Replace(oldKey.Kid, []SigningKey{oldKey})
+	helper := NewJWTHelper("test-secret", SigningAlgEdDSA, false, keys, 1, 7, "test")
+
+	oldToken, _, err := helper.GenerateAccessToken(userID, "budi@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, oldToken); kid != oldKey.Kid {
+		t.Fatalf("token kid = %q, want %q", kid, oldKey.Kid)
+	}
+
+	// Key baru dipublikasikan lebih dulu, token baru masih memakai key lama
+	keys.Replace(oldKey.Kid, []SigningKey{oldKey, newKey})
+	prepublishedToken, _, err := helper.GenerateAccessToken(userID, "budi@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, prepublishedToken); kid != oldKey.Kid {
+		t.Errorf("token signed with %q before the new key is active, want %q", kid, oldKey.Kid)
+	}
+
+	// Key baru aktif, token lama tetap bisa diverifikasi
+	keys.Replace(newKey.Kid, []SigningKey{oldKey, newKey})
+	newToken, _, err := helper.GenerateAccessToken(userID, "budi@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, newToken); kid != newKey.Kid {
+		t.Errorf("token kid = %q, want %q", kid, newKey.Kid)
+	}
+	if _, err := helper.ValidateAccessToken(oldToken); err != nil {
+		t.Errorf("token signed with the retired key rejected before it expires: %v", err)
+	}
+
+	// Key lama kadaluarsa dan dibuang dari KeySet
+	keys.Replace(newKey.Kid, []SigningKey{newKey})
+	if _, err := helper.ValidateAccessToken(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token signed with an expired key: error = %v, want %v", err, ErrInvalidToken)
+	}
+	if _, err := helper.ValidateAccessToken(newToken); err != nil {
+		t.Errorf("token signed with the active key rejected: %v", err)
+	}
+}
+
+func TestJWTHelper_ValidateAccessToken(t *testing.T) {
+	edKey := newTestSigningKey(t, "key-ed", SigningAlgEdDSA)
+	rsaKey := newTestSigningKey(t, "key-rsa", SigningAlgRS256)
+	userID := uuid.Must(uuid.NewV7())
+
+	hsToken, _, err := NewJWTHelper("test-secret", SigningAlgHS256, false, NewKeySet(), 1, 7, "test").
+		GenerateAccessToken(userID, "budi@example.com", "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token RS256 yang mengaku memakai kid milik key EdDSA
+	claims := &ClaimsToken{UserID: userID, TokenType: TokenTypeAccess, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+	mismatched := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	mismatched.Header["kid"] = edKey.Kid
+	mismatchedToken, err := mismatched.SignedString(rsaKey.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		acceptHS256 bool
+		token       string
+		wantErr     bool
+	}{
+		{name: "token HS256 ditolak secara default", token: hsToken, wantErr: true},
+		{name: "token HS256 diterima selama masa migrasi", acceptHS256: true, token: hsToken},
+		{name: "algoritma tidak sesuai key", token: mismatchedToken, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := NewKeySet()
+			keys.Replace(edKey.Kid, []SigningKey{edKey})
+			helper := NewJWTHelper("test-secret", SigningAlgEdDSA, tt.acceptHS256, keys, 1, 7, "test")
+
+			_, err := helper.ValidateAccessToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAccessToken() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	rsaKey := newTestSigningKey(t, "key-rsa", SigningAlgRS256)
+	edKey := newTestSigningKey(t, "key-ed", SigningAlgEdDSA)
+
+	keys := NewKeySet()
+	keys.Replace(rsaKey.Kid, []SigningKey{rsaKey, edKey})
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("published %d keys, want 2", len(jwks.Keys))
+	}
+
+	want := map[string]JWK{
+		rsaKey.Kid: {Kty: "RSA", Alg: SigningAlgRS256},
+		edKey.Kid:  {Kty: "OKP", Alg: SigningAlgEdDSA, Crv: "Ed25519"},
+	}
+	for _, jwk := range jwks.Keys {
+		w, ok := want[jwk.Kid]
+		if !ok {
+			t.Errorf("unexpected key %q published", jwk.Kid)
+			continue
+		}
+		if jwk.Kty != w.Kty || jwk.Alg != w.Alg || jwk.Crv != w.Crv || jwk.Use != "sig" {
+			t.Errorf("key %q = %+v, want %+v", jwk.Kid, jwk, w)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package lifecycle mengatur urutan start dan shutdown komponen aplikasi: HTTP server, worker
+// latar belakang, dan koneksi database.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"final-project/utils/helpers"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle menyimpan hook shutdown dalam urutan registrasi dan menjalankannya terbalik, seperti
+// defer. Komponen yang didaftarkan lebih dulu (database) dihentikan paling akhir, sehingga HTTP
+// server yang didaftarkan terakhir selesai di-drain sebelum worker dan database ditutup.
+type Lifecycle struct {
+	ready atomic.Bool
+
+	mu       sync.Mutex
+	hooks    []hook
+	stopping bool
+	once     sync.Once
+	err      error
+}
+
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Ready menandakan aplikasi siap menerima traffic, dipakai oleh endpoint readiness
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+func (l *Lifecycle) SetReady(ready bool) {
+	l.ready.Store(ready)
+}
+
+// OnStop mendaftarkan fungsi yang dipanggil saat shutdown
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook{name: name, stop: stop})
+}
+
+// Go menjalankan worker di goroutine terpisah. Saat shutdown context worker dibatalkan, query
+// yang sedang berjalan dengan context tersebut ikut dibatalkan dan transaksinya di-rollback, lalu
+// Lifecycle menunggu worker keluar sebelum lanjut ke hook berikutnya.
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.mu.Lock()
+	if l.stopping {
+		l.mu.Unlock()
+		helpers.Logger.Warnf("Worker %s not started because the application is shutting down", name)
+		return
+	}
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+
+	l.OnStop(name, func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return fmt.Errorf("worker did not stop in time: %w", stopCtx.Err())
+		}
+	})
+}
+
+// Shutdown menandai aplikasi tidak siap, menunggu drainDelay agar load balancer berhenti mengirim
+// request baru, lalu menjalankan hook dari yang terakhir didaftarkan. Hook yang gagal dicatat dan
+// hook berikutnya tetap dijalankan. Pemanggilan berikutnya mengembalikan hasil yang sama.
+func (l *Lifecycle) Shutdown(ctx context.Context, drainDelay time.Duration) error {
+	l.once.Do(func() {
+		l.SetReady(false)
+
+		l.mu.Lock()
+		l.stopping = true
+		hooks := l.hooks
+		l.mu.Unlock()
+
+		if drainDelay > 0 {
+			helpers.Logger.Infof("Not ready, waiting %s before draining", drainDelay)
+			select {
+			case <-time.After(drainDelay):
+			case <-ctx.Done():
+			}
+		}
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			started := time.Now()
+			if err := hooks[i].stop(ctx); err != nil {
+				helpers.Logger.Errorf("Failed to stop %s: %v", hooks[i].name, err)
+				errs = append(errs, fmt.Errorf("%s: %w", hooks[i].name, err))
+				continue
+			}
+			helpers.Logger.Infof("Stopped %s in %s", hooks[i].name, time.Since(started).Round(time.Millisecond))
+		}
+		l.err = errors.Join(errs...)
+	})
+	return l.err
+}
@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder mencatat urutan hook yang dijalankan dari beberapa goroutine
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestLifecycle_ShutdownOrder(t *testing.T) {
+	const drainDelay = 50 * time.Millisecond
+
+	l := New()
+	events := &recorder{}
+	var drainedAfter time.Duration
+	start := time.Now()
+
+	// Urutan registrasi mengikuti main: database, worker, lalu HTTP server
+	l.OnStop("database", func(ctx context.Context) error {
+		events.add("database")
+		return nil
+	})
+	l.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		events.add("worker")
+	})
+	l.OnStop("http", func(ctx context.Context) error {
+		drainedAfter = time.Since(start)
+		if l.Ready() {
+			t.Error("still ready while the http server is draining")
+		}
+		events.add("http")
+		return nil
+	})
+	l.SetReady(true)
+
+	if err := l.Shutdown(context.Background(), drainDelay); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if want := []string{"http", "worker", "database"}; !reflect.DeepEqual(events.list(), want) {
+		t.Errorf("stop order = %v, want %v", events.list(), want)
+	}
+	if drainedAfter < drainDelay {
+		t.Errorf("http server stopped after %s, before the drain delay of %s", drainedAfter, drainDelay)
+	}
+	if l.Ready() {
+		t.Error("still ready after shutdown")
+	}
+
+	l.Go("terlambat", func(ctx context.Context) {
+		events.add("terlambat")
+	})
+	time.Sleep(10 * time.Millisecond)
+	if got := events.list(); len(got) != 3 {
+		t.Errorf("worker started after shutdown, events = %v", got)
+	}
+}
+
+func TestLifecycle_ShutdownTimeout(t *testing.T) {
+	l := New()
+	events := &recorder{}
+	stuck := make(chan struct{})
+	defer close(stuck)
+
+	l.OnStop("database", func(ctx context.Context) error {
+		events.add("database")
+		return nil
+	})
+	// Worker yang tidak memperhatikan context tidak boleh menahan shutdown melewati timeout
+	l.Go("worker", func(ctx context.Context) {
+		<-stuck
+	})
+	l.OnStop("http", func(ctx context.Context) error {
+		events.add("http")
+		return errors.New("listener sudah ditutup")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	err := l.Shutdown(ctx, time.Hour)
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("Shutdown() took %s, want it to return once the timeout passes", elapsed)
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v from the stuck worker", err, context.DeadlineExceeded)
+	}
+	if err == nil || !reflect.DeepEqual(events.list(), []string{"http", "database"}) {
+		t.Errorf("events = %v, want the remaining hooks to run after failures", events.list())
+	}
+
+	if again := l.Shutdown(context.Background(), 0); again != err {
+		t.Errorf("second Shutdown() error = %v, want the first result %v", again, err)
+	}
+	if got := events.list(); len(got) != 2 {
+		t.Errorf("hooks ran again on the second shutdown, events = %v", got)
+	}
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileMailer menulis email sebagai JSON per baris ke sebuah file
+type FileMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path string) *FileMailer {
+	return &FileMailer{path: path}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	if message.SentAt.IsZero() {
+		message.SentAt = time.Now()
+	}
+
+	line, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("gagal encode email: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("gagal membuka file email: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("gagal menulis email: %w", err)
+	}
+
+	return nil
+}
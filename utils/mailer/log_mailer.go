@@ -0,0 +1,23 @@
+package mailer
+
+import (
+	"context"
+	"final-project/utils/helpers"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogMailer menulis email ke logger aplikasi, cocok untuk development
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	helpers.Logger.WithFields(logrus.Fields{
+		"to":      message.To,
+		"subject": message.Subject,
+	}).Info(message.Body)
+	return nil
+}
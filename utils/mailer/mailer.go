@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// Mailer mengirim email transaksional seperti reset password dan verifikasi email
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Config struct {
+	Driver   string
+	From     string
+	FilePath string
+	SMTPHost string
+	SMTPPort string
+	SMTPUser string
+	SMTPPass string
+}
+
+// NewMailer membuat mailer sesuai driver yang dikonfigurasi
+func NewMailer(cfg Config) Mailer {
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.From)
+	case DriverFile:
+		return NewFileMailer(cfg.FilePath)
+	default:
+		return NewLogMailer()
+	}
+}
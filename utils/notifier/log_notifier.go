@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+	"final-project/utils/helpers"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogNotifier menulis notifikasi ke logger aplikasi
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	helpers.Logger.WithFields(logrus.Fields{
+		"type":    notification.Type,
+		"user_id": notification.UserID,
+		"email":   notification.Email,
+		"subject": notification.Subject,
+	}).Info(notification.Message)
+	return nil
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+const (
+	TypeBackInStock  = "back_in_stock"
+	TypeWaitlistHold = "waitlist_hold"
+)
+
+type Notification struct {
+	Type      string    `json:"type"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Subject   string    `json:"subject"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Notifier mengirim notifikasi ke pengguna
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// NewNotifier membuat notifier sesuai driver yang dikonfigurasi
+func NewNotifier(driver string, filePath string) Notifier {
+	switch driver {
+	case DriverFile:
+		return NewFileNotifier(filePath)
+	default:
+		return NewLogNotifier()
+	}
+}
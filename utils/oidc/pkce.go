@@ -0,0 +1,27 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// RandomString menghasilkan string acak base64url dari n byte, dipakai untuk state, nonce, dan code verifier
+func RandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GenerateCodeVerifier membuat code verifier PKCE sepanjang 43 karakter (RFC 7636)
+func GenerateCodeVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// CodeChallengeS256 menghitung code challenge dengan metode S256 dari code verifier
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,280 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("id token tidak valid")
+	ErrNonceMismatch  = errors.New("nonce id token tidak cocok")
+)
+
+const (
+	// discoveryTTL adalah lama dokumen discovery dan JWKS disimpan sebelum diambil ulang
+	discoveryTTL = time.Hour
+	// keyRefreshInterval membatasi pengambilan ulang JWKS saat menemukan kid yang belum dikenal
+	keyRefreshInterval = time.Minute
+)
+
+// Config berisi pengaturan satu provider OIDC. Issuer bisa diarahkan ke provider lokal
+// seperti mock-oauth2-server saat development dan pengujian.
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// TokenResponse adalah response token endpoint, hanya id token yang dipakai aplikasi
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// IDTokenClaims berisi claim id token yang dipakai untuk mencari atau membuat user
+type IDTokenClaims struct {
+	Email         string       `json:"email"`
+	EmailVerified flexibleBool `json:"email_verified"`
+	Name          string       `json:"name"`
+	Nonce         string       `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// flexibleBool menerima boolean maupun string "true"/"false", karena beberapa provider
+// mengirim email_verified sebagai string
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	*b = flexibleBool(strings.EqualFold(value, "true"))
+	return nil
+}
+
+type Provider struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	discoveredAt  time.Time
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewProvider(cfg Config) *Provider {
+	return &Provider{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL membuat URL authorization code flow dengan PKCE S256
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange menukar authorization code dan code verifier dengan token dari provider
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (TokenResponse, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"client_id":     {p.cfg.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	if p.cfg.ClientSecret != "" {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token TokenResponse
+	if err := p.doJSON(req, &token); err != nil {
+		return TokenResponse{}, fmt.Errorf("gagal menukar authorization code: %w", err)
+	}
+	if token.IDToken == "" {
+		return TokenResponse{}, fmt.Errorf("%w: response token tidak berisi id_token", ErrInvalidIDToken)
+	}
+	return token, nil
+}
+
+// VerifyIDToken memverifikasi signature, issuer, audience, masa berlaku, dan nonce id token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (IDTokenClaims, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return IDTokenClaims{}, err
+	}
+
+	var claims IDTokenClaims
+	_, err = jwt.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, doc, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return IDTokenClaims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Nonce != nonce {
+		return IDTokenClaims{}, ErrNonceMismatch
+	}
+	if claims.Subject == "" {
+		return IDTokenClaims{}, fmt.Errorf("%w: claim sub kosong", ErrInvalidIDToken)
+	}
+	return claims, nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	wellKnown := strings.TrimRight(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc discoveryDocument
+	if err := p.doJSON(req, &doc); err != nil {
+		return nil, fmt.Errorf("gagal mengambil konfigurasi provider %s: %w", p.cfg.Name, err)
+	}
+
+	// Issuer dari discovery harus sama dengan yang dikonfigurasi (OIDC Discovery 4.3)
+	if strings.TrimRight(doc.Issuer, "/") != strings.TrimRight(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("issuer provider %s tidak cocok: %s", p.cfg.Name, doc.Issuer)
+	}
+
+	p.discovery = &doc
+	p.discoveredAt = time.Now()
+	p.keys = nil
+	return p.discovery, nil
+}
+
+// getKey mengambil public key berdasarkan kid. JWKS diambil ulang jika kid belum dikenal,
+// sehingga rotasi key di provider tidak memerlukan restart.
+func (p *Provider) getKey(ctx context.Context, doc *discoveryDocument, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok && time.Since(p.keysFetchedAt) < discoveryTTL {
+		return key, nil
+	}
+
+	if time.Since(p.keysFetchedAt) >= keyRefreshInterval || p.keys == nil {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, doc.JWKSURI, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var set jsonWebKeySet
+		if err := p.doJSON(req, &set); err != nil {
+			return nil, fmt.Errorf("gagal mengambil JWKS provider %s: %w", p.cfg.Name, err)
+		}
+
+		p.keys = parseKeySet(set)
+		p.keysFetchedAt = time.Now()
+	}
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key %q tidak ditemukan di JWKS", kid)
+}
+
+// lookupKey mencari key berdasarkan kid. Jika token tidak memiliki kid dan JWKS hanya berisi
+// satu key, key tersebut dipakai.
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) doJSON(req *http.Request, target interface{}) error {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, target)
+}
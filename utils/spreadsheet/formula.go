@@ -0,0 +1,25 @@
+package spreadsheet
+
+import "strings"
+
+// formulaPrefixes adalah karakter awal yang membuat Excel, LibreOffice, dan Google Sheets
+// memperlakukan isi sel sebagai formula
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeFormula menambahkan tanda kutip tunggal di depan teks yang diawali karakter formula agar
+// data dari user, misalnya nama mainan, tidak dijalankan sebagai formula saat file export dibuka
+func EscapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeFormula membuang tanda kutip yang ditambahkan EscapeFormula sehingga file export bisa
+// diimport kembali tanpa mengubah isinya
+func UnescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
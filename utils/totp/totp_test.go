@@ -0,0 +1,73 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret adalah secret ASCII "12345678901234567890" dari RFC 6238 dalam format base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// Vektor uji SHA1 dari RFC 6238 Appendix B, diambil enam digit terakhir
+	tests := []struct {
+		name string
+		unix int64
+		want string
+	}{
+		{name: "detik ke-59", unix: 59, want: "287082"},
+		{name: "1111111109", unix: 1111111109, want: "081804"},
+		{name: "1111111111", unix: 1111111111, want: "050471"},
+		{name: "1234567890", unix: 1234567890, want: "005924"},
+		{name: "2000000000", unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Code() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code := func(offset int64) string {
+		c, err := Code(rfcSecret, Step(now)+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		skew     int
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "kode step saat ini", code: code(0), skew: 1, wantStep: Step(now), wantOK: true},
+		{name: "kode step sebelumnya dalam toleransi", code: code(-1), skew: 1, wantStep: Step(now) - 1, wantOK: true},
+		{name: "kode step berikutnya dalam toleransi", code: code(1), skew: 1, wantStep: Step(now) + 1, wantOK: true},
+		{name: "kode di luar toleransi", code: code(-2), skew: 1},
+		{name: "tanpa toleransi", code: code(1), skew: 0},
+		{name: "spasi di sekitar kode diabaikan", code: " " + code(0) + " ", skew: 1, wantStep: Step(now), wantOK: true},
+		{name: "panjang kode salah", code: "12345", skew: 1},
+		{name: "kode salah", code: "000000", skew: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, tt.code, now, tt.skew)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}